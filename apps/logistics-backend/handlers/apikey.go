@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"logistics-backend/internal/domain/apikey"
+	middleware "logistics-backend/internal/middleware"
+	usecase "logistics-backend/internal/usecase/apikey"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	UC *usecase.UseCase
+}
+
+func NewAPIKeyHandler(uc *usecase.UseCase) *APIKeyHandler {
+	return &APIKeyHandler{UC: uc}
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Security JWT
+// @Description Issues a key for one of the caller's stores, limited to the given scopes (orders:create, orders:read, orders:list, inventories:read, inventories:write, stores:read). The full key is only returned once; send it as X-API-Key or as a Bearer token.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param key body apikey.CreateAPIKeyRequest true "API key payload"
+// @Success 201 {object} apikey.CreateAPIKeyResponse
+// @Failure 400 {object} handlers.ErrorResponse "Invalid scopes"
+// @Failure 404 {object} handlers.ErrorResponse "Store not found"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /api-keys/create [post]
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req apikey.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" || req.StoreID == uuid.Nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	k := req.ToAPIKey(adminID)
+	raw, err := h.UC.CreateAPIKey(r.Context(), k)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(apikey.CreateAPIKeyResponse{APIKey: k, Key: raw})
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Security JWT
+// @Description Get the caller's API keys, including revoked and expired ones. Only the key prefix is shown.
+// @Tags API Keys
+// @Produce json
+// @Success 200 {array} apikey.APIKey
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /api-keys/all_api_keys [get]
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	keys, err := h.UC.ListAPIKeys(r.Context(), adminID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch API keys", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Security JWT
+// @Description Revokes one of the caller's API keys; it stops working immediately
+// @Tags API Keys
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} handlers.ErrorResponse "Invalid API key ID"
+// @Failure 404 {object} handlers.ErrorResponse "API key not found"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	if err := h.UC.RevokeAPIKey(r.Context(), keyID, adminID); err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "API key revoked"})
+}
+
+func writeAPIKeyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apikey.ErrInvalidScope), errors.Is(err, apikey.ErrNoScopes):
+		writeJSONError(w, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, apikey.ErrStoreNotOwned), errors.Is(err, apikey.ErrAPIKeyNotFound):
+		writeJSONError(w, http.StatusNotFound, err.Error(), err)
+	default:
+		writeJSONError(w, http.StatusInternalServerError, "API key request failed", err)
+	}
+}
+
+// keyStore is the store the request's API key is bound to, nil for user sessions. Use cases
+// take it to keep a key inside its store.
+func keyStore(r *http.Request) *uuid.UUID {
+	if storeID, isKey := middleware.GetAPIKeyStoreFromContext(r.Context()); isKey {
+		return &storeID
+	}
+	return nil
+}
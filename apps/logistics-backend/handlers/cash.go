@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"logistics-backend/internal/domain/payment"
+	middleware "logistics-backend/internal/middleware"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// CollectCash godoc
+// @Summary Record cash collected at delivery
+// @Security JWT
+// @Description Records the cash the driver took for one of their picked-up or delivered orders and completes the order's cash-on-delivery payment. The amount, in cents, must match the amount due.
+// @Tags drivers
+// @Accept json
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Param body body payment.CollectCashRequest true "Amount collected in cents"
+// @Success 201 {object} payment.CashCollection
+// @Failure 400 {object} handlers.ErrorResponse "Invalid amount"
+// @Failure 404 {object} handlers.ErrorResponse "Delivery not found"
+// @Failure 409 {object} handlers.ErrorResponse "Not collectable, already collected or paid another way"
+// @Failure 422 {object} handlers.ErrorResponse "Amount does not match the amount due"
+// @Router /drivers/me/deliveries/{id}/cash [post]
+func (ph *PaymentHandler) CollectCash(w http.ResponseWriter, r *http.Request) {
+	driverID, err := middleware.GetDriverIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	deliveryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid delivery ID", nil)
+		return
+	}
+
+	var req payment.CollectCashRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	c, err := ph.PH.CollectCash(r.Context(), driverID, deliveryID, req.Amount)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(c)
+}
+
+// MyCashCollections godoc
+// @Summary List my cash collections
+// @Security JWT
+// @Tags drivers
+// @Produce json
+// @Param limit query int false "Limit number of items"
+// @Param offset query int false "Offset for pagination"
+// @Success 200 {array} payment.CashCollection
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router /drivers/me/cash/collections [get]
+func (ph *PaymentHandler) MyCashCollections(w http.ResponseWriter, r *http.Request) {
+	driverID, err := middleware.GetDriverIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	limit, offset := cashPage(r)
+	collections, err := ph.PH.ListCashCollections(r.Context(), driverID, limit, offset)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch cash collections", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(collections)
+}
+
+// MyCashBalance godoc
+// @Summary Get my cash in hand
+// @Security JWT
+// @Description Cash the driver holds per store owner and currency: collected, confirmed as handed in, declared but not yet counted, and still in hand.
+// @Tags drivers
+// @Produce json
+// @Success 200 {array} payment.CashBalance
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router /drivers/me/cash [get]
+func (ph *PaymentHandler) MyCashBalance(w http.ResponseWriter, r *http.Request) {
+	driverID, err := middleware.GetDriverIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	balances, err := ph.PH.DriverCashBalances(r.Context(), driverID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch cash balance", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balances)
+}
+
+// DeclareRemittance godoc
+// @Summary Hand in cash
+// @Security JWT
+// @Description Declares cash handed in to a store owner, who confirms what they counted. The amount may not exceed the cash held for that owner less handovers not yet confirmed.
+// @Tags drivers
+// @Accept json
+// @Produce json
+// @Param body body payment.DeclareRemittanceRequest true "Store owner, amount in cents and currency"
+// @Success 201 {object} payment.CashRemittance
+// @Failure 400 {object} handlers.ErrorResponse "Invalid amount"
+// @Failure 422 {object} handlers.ErrorResponse "Amount exceeds the cash in hand"
+// @Router /drivers/me/cash/remittances [post]
+func (ph *PaymentHandler) DeclareRemittance(w http.ResponseWriter, r *http.Request) {
+	driverID, err := middleware.GetDriverIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var req payment.DeclareRemittanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.AdminID == uuid.Nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	rm, err := ph.PH.DeclareRemittance(r.Context(), driverID, &req)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rm)
+}
+
+// MyRemittances godoc
+// @Summary List my cash handovers
+// @Security JWT
+// @Tags drivers
+// @Produce json
+// @Param status query string false "pending or confirmed"
+// @Param limit query int false "Limit number of items"
+// @Param offset query int false "Offset for pagination"
+// @Success 200 {array} payment.CashRemittance
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router /drivers/me/cash/remittances [get]
+func (ph *PaymentHandler) MyRemittances(w http.ResponseWriter, r *http.Request) {
+	driverID, err := middleware.GetDriverIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	status, ok := remittanceStatus(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset := cashPage(r)
+	remittances, err := ph.PH.ListDriverRemittances(r.Context(), driverID, status, limit, offset)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch remittances", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(remittances)
+}
+
+// CashBalances godoc
+// @Summary List drivers' cash in hand
+// @Security JWT
+// @Description Cash each driver holds for the store owner, per currency.
+// @Tags payments
+// @Produce json
+// @Param driver_id query string false "Only this driver"
+// @Success 200 {array} payment.CashBalance
+// @Failure 400 {object} handlers.ErrorResponse "Invalid driver ID"
+// @Router /payments/cash/balances [get]
+func (ph *PaymentHandler) CashBalances(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	driverID, ok := driverFilter(w, r)
+	if !ok {
+		return
+	}
+
+	balances, err := ph.PH.AdminCashBalances(r.Context(), adminID, driverID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch cash balances", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balances)
+}
+
+// ListRemittances godoc
+// @Summary List cash handed in by drivers
+// @Security JWT
+// @Tags payments
+// @Produce json
+// @Param status query string false "pending or confirmed"
+// @Param driver_id query string false "Only this driver"
+// @Param limit query int false "Limit number of items"
+// @Param offset query int false "Offset for pagination"
+// @Success 200 {array} payment.CashRemittance
+// @Failure 400 {object} handlers.ErrorResponse "Invalid filter"
+// @Router /payments/cash/remittances [get]
+func (ph *PaymentHandler) ListRemittances(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	driverID, ok := driverFilter(w, r)
+	if !ok {
+		return
+	}
+	status, ok := remittanceStatus(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset := cashPage(r)
+	remittances, err := ph.PH.ListAdminRemittances(r.Context(), adminID, driverID, status, limit, offset)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch remittances", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(remittances)
+}
+
+// ConfirmRemittance godoc
+// @Summary Confirm cash handed in
+// @Security JWT
+// @Description Records the amount counted for a driver's handover. Anything short of the declared amount stays in the driver's hand and shows as a shortfall in the cash report.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Remittance ID"
+// @Param body body payment.ConfirmRemittanceRequest true "Amount counted in cents"
+// @Success 200 {object} payment.CashRemittance
+// @Failure 400 {object} handlers.ErrorResponse "Invalid amount"
+// @Failure 404 {object} handlers.ErrorResponse "Remittance not found"
+// @Failure 409 {object} handlers.ErrorResponse "Already confirmed"
+// @Failure 422 {object} handlers.ErrorResponse "Amount exceeds the driver's cash in hand"
+// @Router /payments/cash/remittances/{id}/confirm [post]
+func (ph *PaymentHandler) ConfirmRemittance(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid remittance ID", nil)
+		return
+	}
+
+	var req payment.ConfirmRemittanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	rm, err := ph.PH.ConfirmRemittance(r.Context(), adminID, id, &req)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rm)
+}
+
+// CashReport godoc
+// @Summary Daily cash reconciliation
+// @Security JWT
+// @Description Per driver, day and currency: cash collected, declared, counted, awaiting confirmation, shortfall on confirmed handovers, and collected cash not yet handed in. Days run midnight to midnight in tz.
+// @Tags payments
+// @Produce json
+// @Param from query string false "First day, YYYY-MM-DD (default today)"
+// @Param to query string false "Last day, YYYY-MM-DD (default from)"
+// @Param tz query string false "IANA time zone (default Africa/Nairobi)"
+// @Param driver_id query string false "Only this driver"
+// @Success 200 {array} payment.CashDay
+// @Failure 400 {object} handlers.ErrorResponse "Invalid range"
+// @Router /payments/cash/report [get]
+func (ph *PaymentHandler) CashReport(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	driverID, ok := driverFilter(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	days, err := ph.PH.CashReport(r.Context(), adminID, driverID, q.Get("from"), q.Get("to"), q.Get("tz"))
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(days)
+}
+
+func cashPage(r *http.Request) (int, int) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 0 // no limit
+	}
+
+	offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	return limit, offset
+}
+
+func driverFilter(w http.ResponseWriter, r *http.Request) (*uuid.UUID, bool) {
+	v := r.URL.Query().Get("driver_id")
+	if v == "" {
+		return nil, true
+	}
+
+	id, err := uuid.Parse(v)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid driver ID", nil)
+		return nil, false
+	}
+	return &id, true
+}
+
+func remittanceStatus(w http.ResponseWriter, r *http.Request) (*payment.RemittanceStatus, bool) {
+	s := payment.RemittanceStatus(r.URL.Query().Get("status"))
+	switch s {
+	case "":
+		return nil, true
+	case payment.RemittancePending, payment.RemittanceConfirmed:
+		return &s, true
+	default:
+		writeJSONError(w, http.StatusBadRequest, "Invalid status", nil)
+		return nil, false
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"logistics-backend/internal/application"
 	"logistics-backend/internal/domain/delivery"
+	"logistics-backend/internal/domain/user"
 	middleware "logistics-backend/internal/middleware"
 	"net/http"
 	"strings"
@@ -23,6 +24,36 @@ func NewDeliveryHandler(uc *application.OrderService) *DeliveryHandler {
 	return &DeliveryHandler{UC: uc}
 }
 
+// authorizeDelivery loads the delivery if the caller may see it: admins only for their own
+// orders, drivers only for deliveries assigned to them. It writes the error response otherwise.
+func (h *DeliveryHandler) authorizeDelivery(w http.ResponseWriter, r *http.Request, deliveryID uuid.UUID) (*delivery.Delivery, bool) {
+	callerID, role, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return nil, false
+	}
+
+	var d *delivery.Delivery
+	switch role {
+	case string(user.Admin):
+		d, err = h.UC.Deliveries.UseCase.GetDeliveryForAdmin(r.Context(), deliveryID, callerID)
+	case string(user.Driver):
+		d, err = h.UC.Deliveries.UseCase.GetDeliveryByID(r.Context(), deliveryID)
+		if err == nil && d.DriverID != callerID {
+			err = delivery.ErrorNotFound
+		}
+	default:
+		writeJSONError(w, http.StatusForbidden, "Forbidden", nil)
+		return nil, false
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, "No delivery found", err)
+		return nil, false
+	}
+
+	return d, true
+}
+
 // GetDeliveryByID godoc
 // @Summary Get delivery by ID
 // @Security JWT
@@ -42,9 +73,8 @@ func (h *DeliveryHandler) GetDeliveryByID(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	d, err := h.UC.Deliveries.UseCase.GetDeliveryByID(r.Context(), deliveryID)
-	if err != nil {
-		writeJSONError(w, http.StatusNotFound, "No delivery found", err)
+	d, ok := h.authorizeDelivery(w, r, deliveryID)
+	if !ok {
 		return
 	}
 
@@ -86,6 +116,10 @@ func (h *DeliveryHandler) UpdateDelivery(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if _, ok := h.authorizeDelivery(w, r, deliveryID); !ok {
+		return
+	}
+
 	if err := h.UC.Deliveries.UseCase.UpdateDelivery(r.Context(), deliveryID, column, req.Value); err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Failed to update delivery", err)
 		return
@@ -102,13 +136,19 @@ func (h *DeliveryHandler) UpdateDelivery(w http.ResponseWriter, r *http.Request)
 // ListDeliveries godoc
 // @Summary List all deliveries
 // @Security JWT
-// @Description Get a list of all deliveries
+// @Description Get a list of the deliveries for the authenticated store owner's orders
 // @Tags deliveries
 // @Produce  json
 // @Success 200 {array} delivery.Delivery
 // @Router /deliveries/all_deliveries [get]
 func (h *DeliveryHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
-	deliveries, err := h.UC.Deliveries.UseCase.ListDeliveries(r.Context())
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	deliveries, err := h.UC.Deliveries.UseCase.ListDeliveries(r.Context(), adminID)
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Could not fetch deliveries", err)
 		return
@@ -128,6 +168,7 @@ func (h *DeliveryHandler) ListDeliveries(w http.ResponseWriter, r *http.Request)
 // @Param id path string true "Delivery ID"
 // @Success 200 {object} map[string]string "Delivery deleted"
 // @Failure 400 {object} handlers.ErrorResponse "Invalid Delivery ID"
+// @Failure 404 {object} handlers.ErrorResponse "Delivery not found"
 // @Failure 500 {object} handlers.ErrorResponse "Internal server error"
 // @Router /deliveries/{id} [delete]
 func (h *DeliveryHandler) DeleteDelivery(w http.ResponseWriter, r *http.Request) {
@@ -138,6 +179,10 @@ func (h *DeliveryHandler) DeleteDelivery(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if _, ok := h.authorizeDelivery(w, r, deliveryID); !ok {
+		return
+	}
+
 	if err := h.UC.Deliveries.UseCase.DeleteDelivery(r.Context(), deliveryID); err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Failed to delete delivery", err)
 		return
@@ -0,0 +1,365 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"logistics-backend/internal/domain/document"
+	"logistics-backend/internal/domain/user"
+	middleware "logistics-backend/internal/middleware"
+	usecase "logistics-backend/internal/usecase/document"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const maxDocumentUploadSize = 10 << 20 // 10 MB
+
+type DocumentHandler struct {
+	UC *usecase.UseCase
+}
+
+func NewDocumentHandler(uc *usecase.UseCase) *DocumentHandler {
+	return &DocumentHandler{UC: uc}
+}
+
+// authorize lets the caller at driverID's documents: the driver themselves, or a store owner the
+// driver works for (write: whose account they provisioned). It writes the error response itself.
+func (h *DocumentHandler) authorize(w http.ResponseWriter, r *http.Request, driverID uuid.UUID, write bool) bool {
+	callerID, role, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return false
+	}
+
+	check := h.UC.AuthorizeRead
+	if write {
+		check = h.UC.AuthorizeWrite
+	}
+	if err := check(r.Context(), driverID, callerID, user.Role(role)); err != nil {
+		writeDocumentError(w, err)
+		return false
+	}
+
+	return true
+}
+
+// document fetches the document in the id path parameter and checks the caller may access it.
+func (h *DocumentHandler) document(w http.ResponseWriter, r *http.Request, write bool) (*document.Document, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid document ID", nil)
+		return nil, false
+	}
+
+	d, err := h.UC.GetDocument(r.Context(), id)
+	if err != nil {
+		writeDocumentError(w, err)
+		return nil, false
+	}
+
+	if !h.authorize(w, r, d.DriverID, write) {
+		return nil, false
+	}
+	return d, true
+}
+
+// UploadDocument godoc
+// @Summary Upload a driver compliance document
+// @Security JWT
+// @Description Uploads a licence, insurance or vehicle inspection document for a driver. The document starts as pending verification.
+// @Tags documents
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param type formData string true "Document type (licence, insurance, vehicle_inspection)"
+// @Param number formData string true "Document number"
+// @Param issued_at formData string true "Issue date (YYYY-MM-DD)"
+// @Param expires_at formData string true "Expiry date (YYYY-MM-DD)"
+// @Param file formData file true "Scanned document"
+// @Success 201 {object} document.Document
+// @Failure 400 {object} handlers.ErrorResponse "Invalid request"
+// @Failure 404 {object} handlers.ErrorResponse "Driver not found"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /drivers/{id}/documents [post]
+func (h *DocumentHandler) UploadDocument(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	driverID, err := uuid.Parse(idStr)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid driver ID", nil)
+		return
+	}
+
+	if !h.authorize(w, r, driverID, true) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxDocumentUploadSize)
+	if err := r.ParseMultipartForm(maxDocumentUploadSize); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid multipart form", err)
+		return
+	}
+
+	issuedAt, err := time.Parse("2006-01-02", r.FormValue("issued_at"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid issued_at date", nil)
+		return
+	}
+
+	expiresAt, err := time.Parse("2006-01-02", r.FormValue("expires_at"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid expires_at date", nil)
+		return
+	}
+
+	number := r.FormValue("number")
+	if number == "" {
+		writeJSONError(w, http.StatusBadRequest, "Missing document number", nil)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Missing document file", nil)
+		return
+	}
+	defer file.Close()
+
+	req := document.UploadDocumentRequest{
+		DriverID:  driverID,
+		Type:      document.DocumentType(r.FormValue("type")),
+		Number:    number,
+		IssuedAt:  issuedAt,
+		ExpiresAt: expiresAt,
+	}
+	d := req.ToDocument()
+
+	if err := h.UC.UploadDocument(r.Context(), d, header.Filename, file); err != nil {
+		switch {
+		case errors.Is(err, document.ErrInvalidType),
+			errors.Is(err, document.ErrInvalidDates),
+			errors.Is(err, document.ErrMissingFile):
+			writeJSONError(w, http.StatusBadRequest, err.Error(), nil)
+		default:
+			writeJSONError(w, http.StatusInternalServerError, "Could not upload document", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(d)
+}
+
+// ListDriverDocuments godoc
+// @Summary List a driver's documents
+// @Security JWT
+// @Description Get all compliance documents uploaded for a driver
+// @Tags documents
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Success 200 {array} document.Document
+// @Failure 400 {object} handlers.ErrorResponse "Invalid driver ID"
+// @Failure 404 {object} handlers.ErrorResponse "Driver not found"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /drivers/{id}/documents [get]
+func (h *DocumentHandler) ListDriverDocuments(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	driverID, err := uuid.Parse(idStr)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid driver ID", nil)
+		return
+	}
+
+	if !h.authorize(w, r, driverID, false) {
+		return
+	}
+
+	docs, err := h.UC.ListDriverDocuments(r.Context(), driverID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch documents", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(docs)
+}
+
+// GetDocumentByID godoc
+// @Summary Get driver document by ID
+// @Security JWT
+// @Description Retrieve a driver compliance document by its ID
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID"
+// @Success 200 {object} document.Document
+// @Failure 400 {object} handlers.ErrorResponse "Invalid ID"
+// @Failure 404 {object} handlers.ErrorResponse "Document not found"
+// @Router /documents/by-id/{id} [get]
+func (h *DocumentHandler) GetDocumentByID(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.document(w, r, false)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d)
+}
+
+// DownloadDocument godoc
+// @Summary Download a driver document file
+// @Security JWT
+// @Description Streams the uploaded file of a driver compliance document
+// @Tags documents
+// @Produce octet-stream
+// @Param id path string true "Document ID"
+// @Success 200 {file} file
+// @Failure 400 {object} handlers.ErrorResponse "Invalid ID"
+// @Failure 404 {object} handlers.ErrorResponse "Document not found"
+// @Router /documents/{id}/file [get]
+func (h *DocumentHandler) DownloadDocument(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.document(w, r, false)
+	if !ok {
+		return
+	}
+
+	f, err := h.UC.OpenDocumentFile(r.Context(), d)
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, "Document file not found", err)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", string(d.Type)+filepath.Ext(d.FilePath)))
+	io.Copy(w, f)
+}
+
+// VerifyDocument godoc
+// @Summary Verify or reject a driver document
+// @Security JWT
+// @Description Admin sets the verification status of a driver document
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param body body document.VerifyDocumentRequest true "Verification status (verified or rejected)"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} handlers.ErrorResponse "Invalid request"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 409 {object} handlers.ErrorResponse "Already verified"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /documents/{id}/verify [put]
+func (h *DocumentHandler) VerifyDocument(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	d, ok := h.document(w, r, true)
+	if !ok {
+		return
+	}
+	id := d.ID
+
+	var req document.VerifyDocumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.UC.VerifyDocument(r.Context(), id, req.Status, adminID); err != nil {
+		switch {
+		case errors.Is(err, document.ErrInvalidStatus):
+			writeJSONError(w, http.StatusBadRequest, err.Error(), nil)
+		case errors.Is(err, document.ErrAlreadyVerified):
+			writeJSONError(w, http.StatusConflict, err.Error(), nil)
+		default:
+			writeJSONError(w, http.StatusInternalServerError, "Failed to verify document", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": fmt.Sprintf("document %s %s", id, req.Status),
+	})
+}
+
+// ListExpiringDocuments godoc
+// @Summary List expiring driver documents
+// @Security JWT
+// @Description Get the documents of the caller's drivers that have expired or expire within the given number of days
+// @Tags documents
+// @Produce json
+// @Param days query int false "Expiry window in days (default 30)"
+// @Success 200 {array} document.Document
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /documents/expiring [get]
+func (h *DocumentHandler) ListExpiringDocuments(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days <= 0 {
+		days = 30
+	}
+
+	docs, err := h.UC.ListExpiringDocuments(r.Context(), adminID, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch documents", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(docs)
+}
+
+// DeleteDocument godoc
+// @Summary Delete a driver document
+// @Security JWT
+// @Description Deletes a driver document and its stored file
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID"
+// @Success 200 {object} map[string]string "Document deleted"
+// @Failure 400 {object} handlers.ErrorResponse "Invalid document ID"
+// @Failure 404 {object} handlers.ErrorResponse "Document not found"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /documents/{id} [delete]
+func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.document(w, r, true)
+	if !ok {
+		return
+	}
+	id := d.ID
+
+	if err := h.UC.DeleteDocument(r.Context(), id); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Failed to delete document", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": fmt.Sprintf("document %s deleted", id),
+	})
+}
+
+func writeDocumentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, document.ErrNotFound):
+		writeJSONError(w, http.StatusNotFound, err.Error(), err)
+	default:
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch document", err)
+	}
+}
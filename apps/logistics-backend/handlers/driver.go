@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"logistics-backend/internal/application"
 	"logistics-backend/internal/domain/driver"
+	"logistics-backend/internal/domain/user"
+	middleware "logistics-backend/internal/middleware"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -20,6 +23,39 @@ func NewDriverHandler(uc *application.OrderService) *DriverHandler {
 	return &DriverHandler{UC: uc}
 }
 
+// authorizeDriver writes an error and returns false unless the caller may act on driverID:
+// drivers only on themselves, admins on drivers within their tenant, or for writes only on
+// drivers whose account they provisioned.
+func (h *DriverHandler) authorizeDriver(w http.ResponseWriter, r *http.Request, driverID uuid.UUID, write bool) bool {
+	callerID, role, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return false
+	}
+
+	switch role {
+	case string(user.Admin):
+		get := h.UC.Drivers.UseCase.GetDriverForOwner
+		if write {
+			get = h.UC.Drivers.UseCase.GetDriverOwnedBy
+		}
+		if _, err := get(r.Context(), driverID, callerID); err != nil {
+			writeJSONError(w, http.StatusNotFound, "Driver not found", err)
+			return false
+		}
+	case string(user.Driver):
+		if driverID != callerID {
+			writeJSONError(w, http.StatusNotFound, "Driver not found", driver.ErrDriverNotFound)
+			return false
+		}
+	default:
+		writeJSONError(w, http.StatusForbidden, "Forbidden", nil)
+		return false
+	}
+
+	return true
+}
+
 // UpdateDriverProfile godoc
 // @Summary Update driver profile
 // @Description Updates the vehicle information and current location of a driver
@@ -48,6 +84,10 @@ func (h *DriverHandler) UpdateDriverProfile(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if !h.authorizeDriver(w, r, driverID, true) {
+		return
+	}
+
 	if err := h.UC.Drivers.UseCase.UpdateDriverProfile(r.Context(), driverID, &req); err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Failed to update driver profile", err)
 		return
@@ -86,6 +126,10 @@ func (h *DriverHandler) UpdateDriver(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.authorizeDriver(w, r, driverID, true) {
+		return
+	}
+
 	if err := h.UC.Drivers.UseCase.UpdateDriver(r.Context(), driverID, &req); err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Failed to update driver", err)
 		return
@@ -117,6 +161,10 @@ func (h *DriverHandler) GetDriverByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.authorizeDriver(w, r, id, false) {
+		return
+	}
+
 	d, err := h.UC.Drivers.UseCase.GetDriver(r.Context(), id)
 	if err != nil {
 		writeJSONError(w, http.StatusNotFound, "Driver not found", err)
@@ -152,6 +200,10 @@ func (h *DriverHandler) GetDriverByEmail(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !h.authorizeDriver(w, r, d.ID, false) {
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(d)
 }
@@ -159,13 +211,19 @@ func (h *DriverHandler) GetDriverByEmail(w http.ResponseWriter, r *http.Request)
 // ListDrivers godoc
 // @Summary List all drivers
 // @Security JWT
-// @Description Get a list of all registered drivers
+// @Description Get a list of the drivers working for the authenticated store owner
 // @Tags drivers
 // @Produce  json
 // @Success 200 {array} driver.Driver
 // @Router /drivers/all_drivers [get]
 func (h *DriverHandler) ListDrivers(w http.ResponseWriter, r *http.Request) {
-	drivers, err := h.UC.Drivers.UseCase.ListDrivers(r.Context())
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	drivers, err := h.UC.Drivers.UseCase.ListDrivers(r.Context(), adminID)
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Could not fetch drivers", err)
 		return
@@ -195,6 +253,10 @@ func (h *DriverHandler) DeleteDriver(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.authorizeDriver(w, r, driverID, true) {
+		return
+	}
+
 	if err := h.UC.Drivers.UseCase.DeleteDriver(r.Context(), driverID); err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Failed to delete user", err)
 		return
@@ -206,3 +268,150 @@ func (h *DriverHandler) DeleteDriver(w http.ResponseWriter, r *http.Request) {
 		"message": fmt.Sprintf("driver %s deleted", driverID),
 	})
 }
+
+// MyActiveDeliveries godoc
+// @Summary List my active deliveries
+// @Security JWT
+// @Description Get the authenticated driver's deliveries that are picked up and in progress
+// @Tags drivers
+// @Produce json
+// @Success 200 {array} delivery.Delivery
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /drivers/me/deliveries/active [get]
+func (h *DriverHandler) MyActiveDeliveries(w http.ResponseWriter, r *http.Request) {
+	driverID, err := middleware.GetDriverIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	deliveries, err := h.UC.Deliveries.UseCase.ListDriverActiveDeliveries(r.Context(), driverID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch deliveries", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// MyDeliveryHistory godoc
+// @Summary List my past deliveries
+// @Security JWT
+// @Description Get the authenticated driver's delivered and failed deliveries, newest first
+// @Tags drivers
+// @Produce json
+// @Param limit query int false "Limit number of items"
+// @Param offset query int false "Offset for pagination"
+// @Success 200 {array} delivery.Delivery
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /drivers/me/deliveries/history [get]
+func (h *DriverHandler) MyDeliveryHistory(w http.ResponseWriter, r *http.Request) {
+	driverID, err := middleware.GetDriverIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 0 // no limit
+	}
+
+	offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	deliveries, err := h.UC.Deliveries.UseCase.ListDriverDeliveryHistory(r.Context(), driverID, limit, offset)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch deliveries", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// MyOffers godoc
+// @Summary List my pending delivery offers
+// @Security JWT
+// @Description Get deliveries assigned to the authenticated driver that are waiting to be accepted
+// @Tags drivers
+// @Produce json
+// @Success 200 {array} delivery.Delivery
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /drivers/me/offers [get]
+func (h *DriverHandler) MyOffers(w http.ResponseWriter, r *http.Request) {
+	driverID, err := middleware.GetDriverIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	offers, err := h.UC.Deliveries.UseCase.ListDriverOffers(r.Context(), driverID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch offers", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(offers)
+}
+
+// MyRoute godoc
+// @Summary Get my route
+// @Security JWT
+// @Description Get every stop the authenticated driver still has to make, whenever it was assigned: picked up parcels first, then assigned deliveries still to collect, with pickup and drop-off details
+// @Tags drivers
+// @Produce json
+// @Success 200 {array} delivery.RouteStop
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /drivers/me/route [get]
+func (h *DriverHandler) MyRoute(w http.ResponseWriter, r *http.Request) {
+	driverID, err := middleware.GetDriverIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	stops, err := h.UC.GetDriverRoute(r.Context(), driverID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch route", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stops)
+}
+
+// MyEarnings godoc
+// @Summary Get my earnings summary
+// @Security JWT
+// @Description Get the authenticated driver's completed deliveries and payout for today, this week, this month and all time
+// @Tags drivers
+// @Produce json
+// @Success 200 {object} delivery.EarningsSummary
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /drivers/me/earnings [get]
+func (h *DriverHandler) MyEarnings(w http.ResponseWriter, r *http.Request) {
+	driverID, err := middleware.GetDriverIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	summary, err := h.UC.Deliveries.UseCase.GetDriverEarnings(r.Context(), driverID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch earnings", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
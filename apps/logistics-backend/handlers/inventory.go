@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"log"
 	"logistics-backend/internal/application"
+	"logistics-backend/internal/domain/apikey"
 	"logistics-backend/internal/domain/inventory"
+	"logistics-backend/internal/domain/store"
+	"logistics-backend/internal/domain/user"
 	context "logistics-backend/internal/middleware"
 	"net/http"
 	"strconv"
@@ -33,6 +36,8 @@ func NewInventoryHandler(uc *application.OrderService) *InventoryHandler {
 // @Param inventory body inventory.CreateInventoryRequest true "Inventory input"
 // @Success 201 {object} inventory.Inventory
 // @Failure 400 {object} handlers.ErrorResponse "Invalid inventory ID or request body"
+// @Failure 403 {object} handlers.ErrorResponse "API key not valid for this store"
+// @Failure 404 {object} handlers.ErrorResponse "Store not found"
 // @Failure 500 {object} handlers.ErrorResponse "Internal server error"
 // @Router /inventories/create [post]
 func (h *InventoryHandler) CreateInventory(w http.ResponseWriter, r *http.Request) {
@@ -42,19 +47,28 @@ func (h *InventoryHandler) CreateInventory(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	i := req.ToInventory()
-	if err := h.UC.Inventories.UseCase.CreateInventory(r.Context(), i); err != nil {
-		log.Printf("create inventory failed: %v", err)
-		writeJSONError(w, http.StatusInternalServerError, "could not create inventory", err)
-		return
-	}
-
 	adminID, err := context.GetAdminIDFromContext(r.Context())
 	if err != nil {
 		writeJSONError(w, http.StatusUnauthorized, "unauthorized access", err)
 		return
 	}
-	req.AdminID = adminID
+
+	// an API key may only add stock to the store it was issued for
+	if store := keyStore(r); store != nil && *store != req.StoreID {
+		writeJSONError(w, http.StatusForbidden, apikey.ErrStoreMismatch.Error(), nil)
+		return
+	}
+
+	i := req.ToInventory()
+	if err := h.UC.Inventories.UseCase.CreateInventory(r.Context(), i, adminID); err != nil {
+		if errors.Is(err, store.ErrStoreNotFound) {
+			writeJSONError(w, http.StatusNotFound, "store not found", err)
+			return
+		}
+		log.Printf("create inventory failed: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "could not create inventory", err)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -92,7 +106,18 @@ func (h *InventoryHandler) GetByInventoryID(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	i, err := h.UC.Inventories.GetInventoryByID(r.Context(), id)
+	callerID, role, err := context.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized access", err)
+		return
+	}
+
+	var i *inventory.Inventory
+	if role == string(user.Admin) {
+		i, err = h.UC.Inventories.UseCase.GetOwnedInventory(r.Context(), id, callerID, keyStore(r))
+	} else {
+		i, err = h.UC.Inventories.GetInventoryByID(r.Context(), id)
+	}
 	if err != nil {
 		writeJSONError(w, http.StatusNotFound, "No inventory found", err)
 		return
@@ -104,7 +129,7 @@ func (h *InventoryHandler) GetByInventoryID(w http.ResponseWriter, r *http.Reque
 
 // @Summary Get inventory by name
 // @Security JWT
-// @Description Search inventory by item name (exact match)
+// @Description Search inventory by item name (exact match); admins only search their own stores, API keys only their store
 // @Tags inventories
 // @Produce json
 // @Param name query string true "Inventory Name"
@@ -119,7 +144,18 @@ func (h *InventoryHandler) GetByInventoryName(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	i, err := h.UC.Inventories.UseCase.GetInventoryByName(r.Context(), nameStr)
+	callerID, role, err := context.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized access", err)
+		return
+	}
+
+	var i *inventory.Inventory
+	if role == string(user.Admin) {
+		i, err = h.UC.Inventories.UseCase.GetOwnedInventoryByName(r.Context(), nameStr, callerID, keyStore(r))
+	} else {
+		i, err = h.UC.Inventories.UseCase.GetInventoryByName(r.Context(), nameStr)
+	}
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeJSONError(w, http.StatusNotFound, fmt.Sprintf("No inventory found with name '%s'", nameStr), err)
@@ -135,7 +171,7 @@ func (h *InventoryHandler) GetByInventoryName(w http.ResponseWriter, r *http.Req
 
 // @Summary List all inventories
 // @Security JWT
-// @Description List all inventories with optional pagination
+// @Description List inventories with optional pagination; admins only see their own stores' inventories, API keys only their store's
 // @Tags inventories
 // @Produce json
 // @Param limit query int false "Limit number of items"
@@ -157,7 +193,18 @@ func (h *InventoryHandler) ListInventories(w http.ResponseWriter, r *http.Reques
 		offset = 0
 	}
 
-	inventories, err := h.UC.Inventories.UseCase.List(r.Context(), limit, offset)
+	callerID, role, err := context.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized access", err)
+		return
+	}
+
+	var inventories []*inventory.Inventory
+	if role == string(user.Admin) {
+		inventories, err = h.UC.Inventories.UseCase.ListByOwner(r.Context(), callerID, keyStore(r), limit, offset)
+	} else {
+		inventories, err = h.UC.Inventories.UseCase.List(r.Context(), limit, offset)
+	}
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Could not fetch inventories", err)
 		return
@@ -169,7 +216,7 @@ func (h *InventoryHandler) ListInventories(w http.ResponseWriter, r *http.Reques
 
 // @Summary Get inventories by category
 // @Security JWT
-// @Description Get all inventory items in a specific category
+// @Description Get all inventory items in a specific category; admins only see their own stores' items, API keys only their store's
 // @Tags inventories
 // @Produce json
 // @Param category query string true "Category Name"
@@ -184,7 +231,18 @@ func (h *InventoryHandler) GetInventoryByCategory(w http.ResponseWriter, r *http
 		return
 	}
 
-	inventories, err := h.UC.Inventories.UseCase.GetByCategory(r.Context(), category)
+	callerID, role, err := context.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized access", err)
+		return
+	}
+
+	var inventories []*inventory.Inventory
+	if role == string(user.Admin) {
+		inventories, err = h.UC.Inventories.UseCase.GetOwnedByCategory(r.Context(), category, callerID, keyStore(r))
+	} else {
+		inventories, err = h.UC.Inventories.UseCase.GetByCategory(r.Context(), category)
+	}
 	if err != nil {
 		writeJSONError(w, http.StatusNotFound, "No Inventories found for this category", err)
 		return
@@ -213,7 +271,18 @@ func (h *InventoryHandler) GetInventoryByStore(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	inventories, err := h.UC.Inventories.UseCase.GetByStore(r.Context(), storeID)
+	callerID, role, err := context.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized access", err)
+		return
+	}
+
+	var inventories []*inventory.Inventory
+	if role == string(user.Admin) {
+		inventories, err = h.UC.Inventories.UseCase.GetByOwnedStore(r.Context(), storeID, callerID, keyStore(r))
+	} else {
+		inventories, err = h.UC.Inventories.UseCase.GetByStore(r.Context(), storeID)
+	}
 	if err != nil {
 		writeJSONError(w, http.StatusNotFound, "No inventories found", err)
 		return
@@ -252,6 +321,7 @@ func (h *InventoryHandler) ListCategories(w http.ResponseWriter, r *http.Request
 // @Param id path string true "Inventory ID"
 // @Success 200 {object} map[string]string "Inventory deleted"
 // @Failure 400 {object} handlers.ErrorResponse "Invalid inventory ID"
+// @Failure 404 {object} handlers.ErrorResponse "Inventory not found"
 // @Failure 500 {object} handlers.ErrorResponse "Internal server error"
 // @Router /inventories/{id} [delete]
 func (h *InventoryHandler) DeleteInventory(w http.ResponseWriter, r *http.Request) {
@@ -263,7 +333,24 @@ func (h *InventoryHandler) DeleteInventory(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if err := h.UC.Inventories.UseCase.DeleteByID(r.Context(), inventoryID); err != nil {
+	adminID, err := context.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized access", err)
+		return
+	}
+
+	if store := keyStore(r); store != nil {
+		if _, err := h.UC.Inventories.UseCase.GetOwnedInventory(r.Context(), inventoryID, adminID, store); err != nil {
+			writeJSONError(w, http.StatusNotFound, "Inventory not found", err)
+			return
+		}
+	}
+
+	if err := h.UC.Inventories.UseCase.DeleteByID(r.Context(), inventoryID, adminID); err != nil {
+		if errors.Is(err, inventory.ErrInventoryNotFound) {
+			writeJSONError(w, http.StatusNotFound, "Inventory not found", err)
+			return
+		}
 		writeJSONError(w, http.StatusInternalServerError, "Failed to delete inventory", err)
 		return
 	}
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"logistics-backend/internal/domain/invite"
+	"logistics-backend/internal/domain/user"
+	middleware "logistics-backend/internal/middleware"
 	usecase "logistics-backend/internal/usecase/invite"
 	"net/http"
 
@@ -20,26 +23,41 @@ func NewInviteHandler(uc *usecase.UseCase) *InviteHandler {
 
 // CreateMember godoc
 // @Summary Create a new invite
-// @Description Create a new invite for a user
+// @Security JWT
+// @Description Invites an email address to join with the given role. The invite link is emailed to the invitee and expires after the configured TTL.
 // @Tags Invites
 // @Accept json
 // @Produce json
 // @Param invite body invite.CreateInviteRequest true "Invite payload"
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} handlers.ErrorResponse "Bad request"
+// @Failure 409 {object} handlers.ErrorResponse "Email already registered"
 // @Failure 500 {object} handlers.ErrorResponse "Internal server error"
 // @Router /invites/create [post]
 func (h *InviteHandler) CreateMember(w http.ResponseWriter, r *http.Request) {
 	var req invite.CreateInviteRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
 		writeJSONError(w, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
 
-	i := req.ToInvite()
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	i := req.ToInvite(adminID)
 
 	if err := h.UC.InviteMember(r.Context(), i); err != nil {
-		writeJSONError(w, http.StatusInternalServerError, "Failed to create invite", err)
+		switch {
+		case errors.Is(err, invite.ErrInvalidRole):
+			writeJSONError(w, http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, invite.ErrEmailTaken):
+			writeJSONError(w, http.StatusConflict, err.Error(), err)
+		default:
+			writeJSONError(w, http.StatusInternalServerError, "Failed to create invite", err)
+		}
 		return
 	}
 
@@ -49,22 +67,22 @@ func (h *InviteHandler) CreateMember(w http.ResponseWriter, r *http.Request) {
 		"id":         i.ID,
 		"email":      i.Email,
 		"role":       i.Role,
-		"token":      i.Token,
-		"expires_at": i.Token,
+		"expires_at": i.ExpiresAt,
 		"invited_by": i.InvitedBy,
 	})
 }
 
 // GetMemberByToken godoc
-// @Summary Get invite by token
-// @Description Fetch an invite using a token
-// @Tags Invites
+// @Summary Preview invite by token
+// @Description Shows the email and role an invite link was issued for, as long as it can still be accepted
+// @Tags public
 // @Produce json
 // @Param token query string true "Invite token"
-// @Success 200 {object} invite.Invite
-// @Failure 400 {string} handlers.ErrorResponse "Invalid Token"
-// @Failure 404 {string} handlers.ErrorResponse "Invite not found"
-// @Router /invites/by-token [get]
+// @Success 200 {object} invite.InvitePreview
+// @Failure 400 {object} handlers.ErrorResponse "Missing token"
+// @Failure 404 {object} handlers.ErrorResponse "Invite not found"
+// @Failure 410 {object} handlers.ErrorResponse "Invite expired or already accepted"
+// @Router /public/invites/by-token [get]
 func (h *InviteHandler) GetMemberByToken(w http.ResponseWriter, r *http.Request) {
 	token := r.URL.Query().Get("token")
 	if token == "" {
@@ -72,26 +90,69 @@ func (h *InviteHandler) GetMemberByToken(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	invite, err := h.UC.GetMemberByToken(r.Context(), token)
+	i, err := h.UC.GetMemberByToken(r.Context(), token)
 	if err != nil {
-		writeJSONError(w, http.StatusInternalServerError, "Invite not found", err)
+		writeInviteError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(invite)
+	json.NewEncoder(w).Encode(invite.InvitePreview{
+		Email:     i.Email,
+		Role:      i.Role,
+		ExpiresAt: i.ExpiresAt,
+	})
+}
+
+// AcceptInvite godoc
+// @Summary Accept an invite
+// @Description Creates the invited account with the invited role and consumes the invite. Driver invites also create the driver profile.
+// @Tags public
+// @Accept json
+// @Produce json
+// @Param body body invite.AcceptInviteRequest true "Invite token and account details"
+// @Success 201 {object} user.User
+// @Failure 400 {object} handlers.ErrorResponse "Invalid request or weak password"
+// @Failure 404 {object} handlers.ErrorResponse "Invite not found"
+// @Failure 409 {object} handlers.ErrorResponse "Email already registered"
+// @Failure 410 {object} handlers.ErrorResponse "Invite expired or already accepted"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /public/invites/accept [post]
+func (h *InviteHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
+	var req invite.AcceptInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" || req.FullName == "" {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	u, err := h.UC.AcceptInvite(r.Context(), &req)
+	if err != nil {
+		writeInviteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(u)
 }
 
 // ListPendingMembers godoc
 // @Summary List all pending invites
-// @Description Get all invites that are pending
+// @Security JWT
+// @Description Get the caller's invites that are neither accepted nor expired
 // @Tags Invites
 // @Produce json
 // @Success 200 {array} invite.Invite
 // @Failure 500 {object} handlers.ErrorResponse "Internal server error"
 // @Router /invites/all_invites [get]
 func (h *InviteHandler) ListPendingMembers(w http.ResponseWriter, r *http.Request) {
-	invites, err := h.UC.ListPendingMembers(r.Context())
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	invites, err := h.UC.ListPendingMembers(r.Context(), adminID)
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Could not fetch invites", err)
 		return
@@ -103,12 +164,14 @@ func (h *InviteHandler) ListPendingMembers(w http.ResponseWriter, r *http.Reques
 
 // DeleteMember godoc
 // @Summary Delete an invite
-// @Description Delete an invite by ID
+// @Security JWT
+// @Description Delete (revoke) one of the caller's invites by ID
 // @Tags Invites
 // @Produce json
 // @Param id path string true "Invite ID"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} handlers.ErrorResponse "Invalid invite ID"
+// @Failure 404 {object} handlers.ErrorResponse "Invite not found"
 // @Failure 500 {object} handlers.ErrorResponse "Internal server error"
 // @Router /invites/{id} [delete]
 func (h *InviteHandler) DeleteMember(w http.ResponseWriter, r *http.Request) {
@@ -120,7 +183,17 @@ func (h *InviteHandler) DeleteMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.UC.DeleteMember(r.Context(), inviteID); err != nil {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	if err := h.UC.DeleteMember(r.Context(), inviteID, adminID); err != nil {
+		if errors.Is(err, invite.ErrInviteNotFound) {
+			writeJSONError(w, http.StatusNotFound, "Invite not found", err)
+			return
+		}
 		writeJSONError(w, http.StatusInternalServerError, "Failed to delete invite", err)
 		return
 	}
@@ -129,3 +202,18 @@ func (h *InviteHandler) DeleteMember(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "New Invite deleted"})
 }
+
+func writeInviteError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, invite.ErrInviteNotFound):
+		writeJSONError(w, http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, invite.ErrInviteExpired), errors.Is(err, invite.ErrInviteAccepted):
+		writeJSONError(w, http.StatusGone, err.Error(), err)
+	case errors.Is(err, invite.ErrEmailTaken):
+		writeJSONError(w, http.StatusConflict, err.Error(), err)
+	case errors.Is(err, user.ErrWeakPassword), errors.Is(err, user.ErrInvalidPhone):
+		writeJSONError(w, http.StatusBadRequest, err.Error(), err)
+	default:
+		writeJSONError(w, http.StatusInternalServerError, "Failed to process invite", err)
+	}
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"encoding/json"
+	"logistics-backend/internal/utils"
+	"net/http"
+)
+
+type JWKSHandler struct {
+	Keys *utils.KeySet
+}
+
+func NewJWKSHandler(keys *utils.KeySet) *JWKSHandler {
+	return &JWKSHandler{Keys: keys}
+}
+
+// GetJWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys that access tokens are signed with, matched by the token's kid header. Includes retired keys whose tokens may still be valid.
+// @Tags public
+// @Produce json
+// @Success 200 {object} utils.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(h.Keys.JWKS())
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"logistics-backend/internal/domain/mfa"
+	"logistics-backend/internal/domain/user"
+	middleware "logistics-backend/internal/middleware"
+	usecase "logistics-backend/internal/usecase/mfa"
+	"net/http"
+)
+
+type MFAHandler struct {
+	UC *usecase.UseCase
+}
+
+func NewMFAHandler(uc *usecase.UseCase) *MFAHandler {
+	return &MFAHandler{UC: uc}
+}
+
+// Enroll godoc
+// @Summary Start two-factor enrollment
+// @Security JWT
+// @Description Creates a TOTP secret for the caller. Show the provisioning URI as a QR code, then confirm with a first code.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} mfa.Enrollment
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 409 {object} handlers.ErrorResponse "Already enabled"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /auth/mfa/enroll [post]
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	enrollment, err := h.UC.Enroll(r.Context(), userID)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(enrollment)
+}
+
+// Confirm godoc
+// @Summary Confirm two-factor enrollment
+// @Security JWT
+// @Description Enables 2FA once a code from the authenticator app is verified and returns single-use recovery codes
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body mfa.CodeRequest true "Authenticator code"
+// @Success 200 {object} mfa.RecoveryCodesResponse
+// @Failure 400 {object} handlers.ErrorResponse "Invalid code or not enrolled"
+// @Failure 409 {object} handlers.ErrorResponse "Already enabled"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /auth/mfa/confirm [post]
+func (h *MFAHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var req mfa.CodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	codes, err := h.UC.Confirm(r.Context(), userID, req.Code)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mfa.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication
+// @Security JWT
+// @Description Turns 2FA off after checking a current code. Not allowed for roles that require 2FA.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body mfa.CodeRequest true "Authenticator code"
+// @Success 200 {object} map[string]string "Disabled"
+// @Failure 400 {object} handlers.ErrorResponse "Invalid code or not enrolled"
+// @Failure 403 {object} handlers.ErrorResponse "Required by policy"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /auth/mfa/disable [post]
+func (h *MFAHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	userID, role, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var req mfa.CodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	if err := h.UC.Disable(r.Context(), userID, user.Role(role), req.Code); err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Security JWT
+// @Description Replaces every recovery code after checking a current authenticator code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body mfa.CodeRequest true "Authenticator code"
+// @Success 200 {object} mfa.RecoveryCodesResponse
+// @Failure 400 {object} handlers.ErrorResponse "Invalid code or not enrolled"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /auth/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var req mfa.CodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	codes, err := h.UC.RegenerateRecoveryCodes(r.Context(), userID, req.Code)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mfa.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func writeMFAError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, mfa.ErrInvalidCode), errors.Is(err, mfa.ErrNotEnrolled):
+		writeJSONError(w, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, mfa.ErrAlreadyEnabled):
+		writeJSONError(w, http.StatusConflict, err.Error(), err)
+	case errors.Is(err, mfa.ErrRequiredByPolicy):
+		writeJSONError(w, http.StatusForbidden, err.Error(), err)
+	default:
+		writeJSONError(w, http.StatusInternalServerError, "Two-factor authentication failed", err)
+	}
+}
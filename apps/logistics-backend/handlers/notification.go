@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"logistics-backend/internal/application"
 	"logistics-backend/internal/domain/notification"
+	middleware "logistics-backend/internal/middleware"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -21,19 +26,27 @@ func NewNotificationHandler(uc *application.OrderService) *NotificationHandler {
 // CreateNotification godoc
 // @Summary Create a new notification
 // @Security JWT
-// @Description Create a new notification with user_id, message, etc.
+// @Description Create a new notification with user_id, message, etc. for a user in the caller's store
 // @Tags notifications
 // @Accept  json
 // @Produce  json
 // @Param user body notification.CreateNotificationRequest true "User Input"
 // @Success 201 {object} notification.Notification
 // @Failure 400 {string} handlers.ErrorResponse "Invalid request"
+// @Failure 401 {string} handlers.ErrorResponse "Unauthorized"
+// @Failure 404 {string} handlers.ErrorResponse "User not found"
 // @Failure 500 {string} handlers.ErrorResponse "Failed to create notification"
 // @Router /notifications/create [post]
 func (h *NotificationHandler) CreateNotification(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
 	var req *notification.CreateNotificationRequest
 
-	err := json.NewDecoder(r.Body).Decode(&req)
+	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
 		return
@@ -41,7 +54,11 @@ func (h *NotificationHandler) CreateNotification(w http.ResponseWriter, r *http.
 
 	n := req.ToNotification()
 
-	if err := h.UC.Notifications.UseCase.CreateNotification(r.Context(), n); err != nil {
+	if err := h.UC.Notifications.UseCase.CreateNotificationFor(r.Context(), n, adminID); err != nil {
+		if errors.Is(err, notification.ErrRecipientNotFound) {
+			writeJSONError(w, http.StatusNotFound, "User not found", err)
+			return
+		}
 		writeJSONError(w, http.StatusInternalServerError, "Could not create notification", err)
 		return
 	}
@@ -60,7 +77,7 @@ func (h *NotificationHandler) CreateNotification(w http.ResponseWriter, r *http.
 // UpdateNotificationStatus godoc
 // @Summary Update a notification's status (e.g. mark as sent or read)
 // @Security JWT
-// @Description Update notification status by ID
+// @Description Update the status of a notification to a user in the caller's store
 // @Tags notifications
 // @Accept json
 // @Produce json
@@ -72,6 +89,12 @@ func (h *NotificationHandler) CreateNotification(w http.ResponseWriter, r *http.
 // @Failure 500 {object} handlers.ErrorResponse "Failed to update notification"
 // @Router /notifications/{id}/status [patch]
 func (h *NotificationHandler) UpdateNotificationStatus(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -85,7 +108,11 @@ func (h *NotificationHandler) UpdateNotificationStatus(w http.ResponseWriter, r
 		return
 	}
 
-	if err := h.UC.Notifications.UseCase.UpdateNotificationStatus(r.Context(), id, req.Status); err != nil {
+	if err := h.UC.Notifications.UseCase.UpdateNotificationStatus(r.Context(), id, adminID, req.Status); err != nil {
+		if errors.Is(err, notification.ErrNotificationNotFound) {
+			writeJSONError(w, http.StatusNotFound, "Notification not found", err)
+			return
+		}
 		writeJSONError(w, http.StatusInternalServerError, "Failed to update status", err)
 		return
 	}
@@ -96,16 +123,23 @@ func (h *NotificationHandler) UpdateNotificationStatus(w http.ResponseWriter, r
 }
 
 // ListPendingNotifications godoc
-// @Summary List all pending notifications
+// @Summary List pending notifications
 // @Security JWT
-// @Description Get a list of notifications not yet sent
+// @Description Get the notifications not yet sent to users in the caller's store. Event variables and messages rendered from them are left out, as they can hold single-use links.
 // @Tags notifications
 // @Produce json
 // @Success 200 {array} notification.Notification
+// @Failure 401 {object} handlers.ErrorResponse
 // @Failure 500 {object} handlers.ErrorResponse
 // @Router /notifications/all_pending_notifications [get]
 func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
-	n, err := h.UC.Notifications.UseCase.ListPendingNotifications(r.Context())
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	n, err := h.UC.Notifications.UseCase.ListPendingNotifications(r.Context(), adminID)
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Could not fetch notifications", err)
 		return
@@ -118,32 +152,29 @@ func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.R
 // ListUserNotifications godoc
 // @Summary List notifications by user
 // @Security JWT
-// @Description Get all notifications belonging to a user (optionally filtered by status)
+// @Description Get the caller's notifications with a delivery status (default sent). Prefer /notifications/inbox.
 // @Tags notifications
 // @Produce json
-// @Param id path string true "User ID"
-// @Param status query string false "Notification status (pending, sent, failed, read)"
+// @Param id path string true "User ID, must be the caller's"
+// @Param status query string false "Notification status (pending, sent, delivered, failed, dead, suppressed)"
 // @Success 200 {array} notification.Notification
 // @Failure 400 {object} handlers.ErrorResponse
+// @Failure 403 {object} handlers.ErrorResponse
 // @Failure 500 {object} handlers.ErrorResponse
 // @Router /notifications/all_my_notifications/{id} [get]
 func (h *NotificationHandler) ListUserNotifications(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "id")
-	userID, err := uuid.Parse(idStr)
-	if err != nil {
-		writeJSONError(w, http.StatusBadRequest, "Invalid user ID", nil)
+	userID, ok := h.callerMatches(w, r)
+	if !ok {
 		return
 	}
 
-	var n []*notification.Notification
-	statusStr := r.URL.Query().Get("status")
-	if statusStr != "" {
-		status := notification.NotificationStatus(statusStr)
-		n, err = h.UC.Notifications.UseCase.ListNotificationsByCustomer(r.Context(), userID, status)
-	} else {
-		n, err = h.UC.Notifications.UseCase.ListNotificationsByCustomer(r.Context(), userID, "pending")
+	status := notification.Sent
+	if s := r.URL.Query().Get("status"); s != "" {
+		status = notification.NotificationStatus(s)
 	}
 
+	n, err := h.UC.Notifications.UseCase.ListNotificationsByCustomer(r.Context(), userID, status)
+
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Could not fetch notifications", err)
 		return
@@ -156,16 +187,23 @@ func (h *NotificationHandler) ListUserNotifications(w http.ResponseWriter, r *ht
 // MarkAsRead godoc
 // @Summary Mark a single notification as read
 // @Security JWT
-// @Description Update notification status to "read" by ID
+// @Description Mark one of the caller's notifications as read
 // @Tags notifications
 // @Accept json
 // @Produce json
 // @Param id path string true "Notification ID"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} handlers.ErrorResponse "Invalid ID"
+// @Failure 404 {object} handlers.ErrorResponse "Notification not found"
 // @Failure 500 {object} handlers.ErrorResponse "Failed to update notification"
 // @Router /notifications/{id}/read [patch]
 func (h *NotificationHandler) MarkAsRead(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -173,7 +211,11 @@ func (h *NotificationHandler) MarkAsRead(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.UC.Notifications.UseCase.MarkAsRead(r.Context(), id); err != nil {
+	if err := h.UC.Notifications.UseCase.MarkAsRead(r.Context(), userID, id); err != nil {
+		if errors.Is(err, notification.ErrNotificationNotFound) {
+			writeJSONError(w, http.StatusNotFound, "Notification not found", err)
+			return
+		}
 		writeJSONError(w, http.StatusInternalServerError, "could not mark notification as read", err)
 		return
 	}
@@ -187,19 +229,18 @@ func (h *NotificationHandler) MarkAsRead(w http.ResponseWriter, r *http.Request)
 // MarkAllAsRead godoc
 // @Summary Mark all user notifications as read
 // @Security JWT
-// @Description Mark all unread notifications for a given user ID as "read"
+// @Description Mark all of the caller's unread notifications as read
 // @Tags notifications
 // @Produce json
-// @Param id path string true "User ID"
+// @Param id path string true "User ID, must be the caller's"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} handlers.ErrorResponse "Invalid user ID"
+// @Failure 403 {object} handlers.ErrorResponse "Not the caller's ID"
 // @Failure 500 {object} handlers.ErrorResponse "Failed to mark notifications as read"
 // @Router /notifications/mark_all_as_read/{id} [patch]
 func (h *NotificationHandler) MarkAllAsRead(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "id")
-	userID, err := uuid.Parse(idStr)
-	if err != nil {
-		writeJSONError(w, http.StatusBadRequest, "Invalid user ID", nil)
+	userID, ok := h.callerMatches(w, r)
+	if !ok {
 		return
 	}
 
@@ -213,3 +254,382 @@ func (h *NotificationHandler) MarkAllAsRead(w http.ResponseWriter, r *http.Reque
 		"message": "All notifications marked as read",
 	})
 }
+
+// ListInbox godoc
+// @Summary List the caller's inbox
+// @Security JWT
+// @Description Cursor-paginated, newest first. Pass next_cursor from the previous page as cursor. Archived notifications only show with status=archived.
+// @Tags notifications
+// @Produce json
+// @Param status query string false "unread, read or archived"
+// @Param type query string false "Channel (system, email, sms, push)"
+// @Param limit query int false "Page size, default 20, at most 100"
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} notification.InboxPage
+// @Failure 400 {object} handlers.ErrorResponse "Invalid filter or cursor"
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /notifications/inbox [get]
+func (h *NotificationHandler) ListInbox(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := notification.InboxFilter{
+		State: notification.InboxState(q.Get("status")),
+		Type:  notification.NotificationType(q.Get("type")),
+	}
+	if limit, err := strconv.Atoi(q.Get("limit")); err == nil {
+		filter.Limit = limit
+	}
+	if c := q.Get("cursor"); c != "" {
+		if filter.Cursor, err = notification.DecodeInboxCursor(c); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "Invalid cursor", err)
+			return
+		}
+	}
+
+	page, err := h.UC.Notifications.UseCase.ListInbox(r.Context(), userID, filter)
+	if err != nil {
+		if errors.Is(err, notification.ErrInvalidInboxFilter) {
+			writeJSONError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch notifications", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// UnreadCount godoc
+// @Summary Count the caller's unread notifications
+// @Security JWT
+// @Description Unread, unarchived notifications for the inbox badge
+// @Tags notifications
+// @Produce json
+// @Param type query string false "Channel (system, email, sms, push); all when omitted"
+// @Success 200 {object} map[string]int
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /notifications/inbox/unread-count [get]
+func (h *NotificationHandler) UnreadCount(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	count, err := h.UC.Notifications.UseCase.CountUnread(r.Context(), userID, notification.NotificationType(r.URL.Query().Get("type")))
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not count notifications", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"unread": count})
+}
+
+// MarkRead godoc
+// @Summary Mark several of the caller's notifications as read
+// @Security JWT
+// @Description IDs that are not the caller's are ignored; updated is how many were marked
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param ids body notification.IDsRequest true "Notification IDs"
+// @Success 200 {object} map[string]int64
+// @Failure 400 {object} handlers.ErrorResponse "Invalid request"
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /notifications/inbox/read [post]
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	h.bulk(w, r, h.UC.Notifications.UseCase.MarkRead)
+}
+
+// Archive godoc
+// @Summary Archive several of the caller's notifications
+// @Security JWT
+// @Description Archived notifications leave the inbox and the unread count; list them with status=archived. IDs that are not the caller's are ignored.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param ids body notification.IDsRequest true "Notification IDs"
+// @Success 200 {object} map[string]int64
+// @Failure 400 {object} handlers.ErrorResponse "Invalid request"
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /notifications/inbox/archive [post]
+func (h *NotificationHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	h.bulk(w, r, h.UC.Notifications.UseCase.Archive)
+}
+
+// DeleteNotification godoc
+// @Summary Delete one of the caller's notifications
+// @Security JWT
+// @Tags notifications
+// @Param id path string true "Notification ID"
+// @Success 204
+// @Failure 400 {object} handlers.ErrorResponse "Invalid ID"
+// @Failure 404 {object} handlers.ErrorResponse "Notification not found"
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /notifications/{id} [delete]
+func (h *NotificationHandler) DeleteNotification(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid notification ID", err)
+		return
+	}
+
+	if err := h.UC.Notifications.UseCase.DeleteNotification(r.Context(), userID, id); err != nil {
+		if errors.Is(err, notification.ErrNotificationNotFound) {
+			writeJSONError(w, http.StatusNotFound, "Notification not found", err)
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, "Could not delete notification", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) bulk(w http.ResponseWriter, r *http.Request, apply func(context.Context, uuid.UUID, []uuid.UUID) (int64, error)) {
+	userID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var req notification.IDsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if len(req.IDs) > notification.MaxInboxLimit {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("At most %d ids per request", notification.MaxInboxLimit), nil)
+		return
+	}
+
+	updated, err := apply(r.Context(), userID, req.IDs)
+	if err != nil {
+		if errors.Is(err, notification.ErrNoIDs) {
+			writeJSONError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, "Could not update notifications", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{"updated": updated})
+}
+
+// callerMatches checks the {id} path parameter is the caller's own user ID.
+func (h *NotificationHandler) callerMatches(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	callerID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid user ID", nil)
+		return uuid.Nil, false
+	}
+	if userID != callerID {
+		writeJSONError(w, http.StatusForbidden, "Forbidden", nil)
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// ListTemplates godoc
+// @Summary List notification templates
+// @Security JWT
+// @Description Lists the effective template for the admin's users for every event, locale and channel; the admin's overrides are flagged
+// @Tags notifications
+// @Produce json
+// @Success 200 {array} notification.Template
+// @Failure 401 {object} handlers.ErrorResponse
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /notifications/templates [get]
+func (h *NotificationHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	templates, err := h.UC.Notifications.UseCase.ListTemplates(r.Context(), adminID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch templates", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+// SaveTemplate godoc
+// @Summary Override a notification template
+// @Security JWT
+// @Description Replaces the built-in template for an event, locale and channel in notifications to the admin's own users. Templates use Go template syntax and may only reference the event's variables.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param event path string true "Event, e.g. order.created"
+// @Param locale path string true "Locale (en or sw)"
+// @Param channel path string true "Channel (email, sms, push or system)"
+// @Param template body notification.SaveTemplateRequest true "Template"
+// @Success 200 {object} notification.Template
+// @Failure 400 {object} handlers.ErrorResponse "Invalid template"
+// @Failure 500 {object} handlers.ErrorResponse "Failed to save template"
+// @Router /notifications/templates/{event}/{locale}/{channel} [put]
+func (h *NotificationHandler) SaveTemplate(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var req notification.SaveTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	t := req.ToTemplate(templateParams(r))
+	if err := h.UC.Notifications.UseCase.SaveTemplate(r.Context(), t, adminID); err != nil {
+		switch {
+		case errors.Is(err, notification.ErrUnknownEvent):
+			writeJSONError(w, http.StatusBadRequest, "Unknown event", err)
+		case errors.Is(err, notification.ErrUnsupportedLocale):
+			writeJSONError(w, http.StatusBadRequest, "Unsupported locale", err)
+		case errors.Is(err, notification.ErrInvalidTemplate):
+			writeJSONError(w, http.StatusBadRequest, err.Error(), err)
+		default:
+			writeJSONError(w, http.StatusInternalServerError, "Failed to save template", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// DeleteTemplate godoc
+// @Summary Remove a notification template override
+// @Security JWT
+// @Description Deletes the admin override so the built-in template is used again
+// @Tags notifications
+// @Param event path string true "Event, e.g. order.created"
+// @Param locale path string true "Locale (en or sw)"
+// @Param channel path string true "Channel (email, sms, push or system)"
+// @Success 204
+// @Failure 401 {object} handlers.ErrorResponse
+// @Failure 404 {object} handlers.ErrorResponse "No override for this template"
+// @Failure 500 {object} handlers.ErrorResponse "Failed to delete template"
+// @Router /notifications/templates/{event}/{locale}/{channel} [delete]
+func (h *NotificationHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	event, locale, channel := templateParams(r)
+	if err := h.UC.Notifications.UseCase.DeleteTemplate(r.Context(), event, locale, channel, adminID); err != nil {
+		if errors.Is(err, notification.ErrTemplateNotFound) {
+			writeJSONError(w, http.StatusNotFound, "No override for this template", err)
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, "Failed to delete template", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func templateParams(r *http.Request) (notification.Event, string, notification.NotificationType) {
+	return notification.Event(chi.URLParam(r, "event")),
+		chi.URLParam(r, "locale"),
+		notification.NotificationType(chi.URLParam(r, "channel"))
+}
+
+// GetPreferences godoc
+// @Summary Get the caller's notification preferences
+// @Security JWT
+// @Description Lists the channels turned on or off per event (event "*" applies to all events) and the quiet hours
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} notification.Settings
+// @Failure 500 {object} handlers.ErrorResponse
+// @Router /notifications/preferences [get]
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	settings, err := h.UC.Notifications.UseCase.GetPreferences(r.Context(), userID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch notification preferences", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// SavePreferences godoc
+// @Summary Replace the caller's notification preferences
+// @Security JWT
+// @Description Turns channels on or off per event and sets quiet hours ("22:00" to "07:00" in an IANA timezone) during which email, SMS and push wait. Security notifications cannot be turned off and ignore quiet hours.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param settings body notification.Settings true "Preferences"
+// @Success 200 {object} notification.Settings
+// @Failure 400 {object} handlers.ErrorResponse "Invalid preferences"
+// @Failure 500 {object} handlers.ErrorResponse "Failed to save preferences"
+// @Router /notifications/preferences [put]
+func (h *NotificationHandler) SavePreferences(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var settings notification.Settings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if settings.Preferences == nil {
+		settings.Preferences = []notification.Preference{}
+	}
+
+	if err := h.UC.Notifications.UseCase.SavePreferences(r.Context(), userID, &settings); err != nil {
+		switch {
+		case errors.Is(err, notification.ErrUnknownEvent),
+			errors.Is(err, notification.ErrInvalidPreference),
+			errors.Is(err, notification.ErrMandatoryEvent):
+			writeJSONError(w, http.StatusBadRequest, err.Error(), err)
+		default:
+			writeJSONError(w, http.StatusInternalServerError, "Failed to save notification preferences", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
@@ -10,6 +10,8 @@ import (
 
 	"logistics-backend/internal/application"
 	"logistics-backend/internal/domain/order"
+	"logistics-backend/internal/domain/user"
+	middleware "logistics-backend/internal/middleware"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -33,6 +35,7 @@ func NewOrderHandler(uc *application.OrderService) *OrderHandler {
 // @Param order body order.CreateOrderRequest true "Order input"
 // @Success 201 {object} order.Order
 // @Failure 400 {string} handlers.ErrorResponse "Bad request"
+// @Failure 404 {string} handlers.ErrorResponse "Inventory not found"
 // @Failure 500 {string} handlers.ErrorResponse "Internal server error"
 // @Router /orders/create [post]
 func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
@@ -42,20 +45,29 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
 	o := req.ToOrder()
+	o.AdminID = adminID
 
-	if err := h.UC.Orders.UseCase.CreateOrder(r.Context(), o); err != nil {
+	if err := h.UC.Orders.UseCase.CreateOrder(r.Context(), o, keyStore(r)); err != nil {
 
 		switch {
 		case errors.Is(err, order.ErrorOutOfStock):
 			writeJSONError(w, http.StatusConflict, "Product is out of stock", err)
-			return
 		case errors.Is(err, order.ErrorInvalidQuantity):
 			writeJSONError(w, http.StatusConflict, "Invalid Product Quantity", err)
+		case errors.Is(err, order.ErrorInventoryNotFound):
+			writeJSONError(w, http.StatusNotFound, "Inventory not found", err)
 		default:
 			log.Printf("CreateOrder unexpected error: %v", err)
 			writeJSONError(w, http.StatusInternalServerError, "Could not create order", err)
 		}
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -92,7 +104,35 @@ func (h *OrderHandler) GetOrderByID(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, http.StatusBadRequest, "Invalid order ID", nil)
 		return
 	}
-	o, err := h.UC.Orders.UseCase.GetOrder(r.Context(), id)
+	callerID, role, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var o *order.Order
+	switch role {
+	case string(user.Admin):
+		o, err = h.UC.Orders.UseCase.GetOrderForAdmin(r.Context(), id, callerID, keyStore(r))
+	case string(user.Customer):
+		o, err = h.UC.Orders.UseCase.GetOrder(r.Context(), id)
+		// customers only ever see their own orders
+		if err == nil && o.CustomerID != callerID {
+			err = order.ErrorNotFound
+		}
+	case string(user.Driver):
+		// drivers only see orders they were given a delivery for
+		var held bool
+		held, err = h.UC.Deliveries.UseCase.DriverHoldsOrder(r.Context(), callerID, id)
+		if err == nil && !held {
+			err = order.ErrorNotFound
+		}
+		if err == nil {
+			o, err = h.UC.Orders.UseCase.GetOrder(r.Context(), id)
+		}
+	default:
+		err = order.ErrorNotFound
+	}
 	if err != nil {
 		writeJSONError(w, http.StatusNotFound, "Order not found", err)
 		return
@@ -111,6 +151,7 @@ func (h *OrderHandler) GetOrderByID(w http.ResponseWriter, r *http.Request) {
 // @Param customer_id path string true "Customer ID"
 // @Success 200 {object} []order.Order
 // @Failure 400 {string} handlers.ErrorResponse "Invalid Customer ID"
+// @Failure 403 {string} handlers.ErrorResponse "Forbidden"
 // @Failure 404 {string} handlers.ErrorResponse "Not found"
 // @Router /orders/by-customer/{customer_id} [get]
 func (h *OrderHandler) GetOrderByCustomer(w http.ResponseWriter, r *http.Request) {
@@ -121,7 +162,22 @@ func (h *OrderHandler) GetOrderByCustomer(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	o, err := h.UC.Orders.UseCase.GetOrderByCustomer(r.Context(), customerID)
+	callerID, role, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var o []*order.Order
+	switch {
+	case role == string(user.Admin):
+		o, err = h.UC.Orders.UseCase.GetAdminOrdersByCustomer(r.Context(), customerID, callerID, keyStore(r))
+	case role == string(user.Customer) && customerID == callerID:
+		o, err = h.UC.Orders.UseCase.GetOrderByCustomer(r.Context(), customerID)
+	default:
+		writeJSONError(w, http.StatusForbidden, "Forbidden", nil)
+		return
+	}
 	if err != nil {
 		writeJSONError(w, http.StatusNotFound, "No orders found", err)
 		return
@@ -165,6 +221,17 @@ func (h *OrderHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	if _, err := h.UC.Orders.UseCase.GetOrderForAdmin(r.Context(), orderID, adminID, nil); err != nil {
+		writeJSONError(w, http.StatusNotFound, "Order not found", err)
+		return
+	}
+
 	if err := h.UC.Orders.UseCase.UpdateOrder(r.Context(), orderID, column, req.Value); err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Failed to update order", err)
 		return
@@ -180,14 +247,20 @@ func (h *OrderHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 // ListOrders godoc
 // @Summary List all orders
 // @Security JWT
-// @Description Get a list of all orders
+// @Description Get a list of the authenticated store owner's orders; API keys only get their store's
 // @Tags orders
 // @Produce  json
 // @Success 200 {array} order.Order
 // @Failure 500 {object} handlers.ErrorResponse "Internal server error"
 // @Router /orders/all_orders [get]
 func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
-	orders, err := h.UC.Orders.UseCase.ListOrders(r.Context())
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	orders, err := h.UC.Orders.UseCase.ListOrders(r.Context(), adminID, keyStore(r))
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Could not fetch orders", err)
 		return
@@ -207,6 +280,7 @@ func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
 // @Param id path string true "Order ID"
 // @Success 200 {object} map[string]string "Order deleted"
 // @Failure 400 {object} handlers.ErrorResponse "Invalid order ID"
+// @Failure 404 {object} handlers.ErrorResponse "Order not found"
 // @Failure 500 {object} handlers.ErrorResponse "Internal server error"
 // @Router /orders/{id} [delete]
 func (h *OrderHandler) DeleteOrder(w http.ResponseWriter, r *http.Request) {
@@ -217,7 +291,17 @@ func (h *OrderHandler) DeleteOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.UC.Orders.UseCase.DeleteOrder(r.Context(), orderID); err != nil {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	if err := h.UC.Orders.UseCase.DeleteOrder(r.Context(), orderID, adminID); err != nil {
+		if errors.Is(err, order.ErrorNotFound) {
+			writeJSONError(w, http.StatusNotFound, "Order not found", err)
+			return
+		}
 		writeJSONError(w, http.StatusInternalServerError, "Failed to delete order", err)
 		return
 	}
@@ -238,7 +322,13 @@ func (h *OrderHandler) DeleteOrder(w http.ResponseWriter, r *http.Request) {
 // @Failure      500  {object}  ErrorResponse "Failed to fetch customers or inventories"
 // @Router       /orders/form-data [get]
 func (h *OrderHandler) GetOrderFormData(w http.ResponseWriter, r *http.Request) {
-	data, err := h.UC.GetCustomersAndInventories(r.Context())
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	data, err := h.UC.GetCustomersAndInventories(r.Context(), adminID)
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Failed to fetch form data", err)
 		return
@@ -256,7 +346,13 @@ func (h *OrderHandler) GetOrderFormData(w http.ResponseWriter, r *http.Request)
 // @Router /orders/assign [post]
 func (h *OrderHandler) AutoAssignOrders(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	assignments, err := h.UC.OrderAssignment(ctx, 5000) // 5km radius
+	adminID, err := middleware.GetAdminIDFromContext(ctx)
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	assignments, err := h.UC.OrderAssignment(ctx, adminID, 5000) // 5km radius
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Assignment failed", err)
 		return
@@ -2,47 +2,64 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"log"
 	"logistics-backend/internal/domain/payment"
+	"logistics-backend/internal/domain/user"
+	middleware "logistics-backend/internal/middleware"
 	usecase "logistics-backend/internal/usecase/payment"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
+// PaymentCallbackParser authenticates and decodes a payment gateway's result callback.
+type PaymentCallbackParser interface {
+	ParseCallback(r *http.Request) (*payment.Result, error)
+}
+
 type PaymentHandler struct {
-	PH *usecase.UseCase
+	PH        *usecase.UseCase
+	Callbacks map[payment.PaymentMethod]PaymentCallbackParser // configured gateways only
 }
 
-func NewPaymentHandler(ph *usecase.UseCase) *PaymentHandler {
-	return &PaymentHandler{PH: ph}
+func NewPaymentHandler(ph *usecase.UseCase, callbacks map[payment.PaymentMethod]PaymentCallbackParser) *PaymentHandler {
+	return &PaymentHandler{PH: ph, Callbacks: callbacks}
 }
 
 // CreatePayment godoc
 // @Summary Create new payment
 // @Security JWT
-// @Description Register new payment with order_id, amount, etc.
+// @Description Records a payment taken outside the gateways for one of the caller's pending orders. The amount is the order's total.
 // @Tags payments
 // @Accept  json
 // @Produce  json
 // @Param user body payment.CreatePaymentRequest true "User Input"
 // @Success 201 {object} payment.Payment
 // @Failure 400 {string} handlers.ErrorResponse "Invalid request"
+// @Failure 404 {string} handlers.ErrorResponse "Order not found"
+// @Failure 409 {string} handlers.ErrorResponse "Order already paid, payment in progress or order not payable"
 // @Failure 500 {string} handlers.ErrorResponse "Failed to create payment"
 // @Router /payments/create [post]
 func (ph *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
-	var req *payment.CreatePaymentRequest
-
-	err := json.NewDecoder(r.Body).Decode(&req)
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
 	if err != nil {
-		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
 		return
 	}
 
-	p := req.ToPayment()
+	var req payment.CreatePaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.OrderID == uuid.Nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
 
-	if err := ph.PH.CreatePayment(r.Context(), p); err != nil {
-		writeJSONError(w, http.StatusInternalServerError, "Could not create payment", err)
+	p, err := ph.PH.CreatePayment(r.Context(), adminID, &req)
+	if err != nil {
+		writePaymentError(w, err)
 		return
 	}
 
@@ -58,10 +75,120 @@ func (ph *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// InitiatePayment godoc
+// @Summary Pay for an order
+// @Security JWT
+// @Description Starts paying for one of the caller's pending orders through a gateway, charging the order's total. For mobile_money the phone receives an M-Pesa prompt; the payment completes or fails once the customer responds, and the customer is notified either way. For stripe the initiation carries the PaymentIntent client secret to confirm with Stripe.js; asking again while that payment is pending returns it again.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param body body payment.InitiatePaymentRequest true "Order, method and phone"
+// @Success 202 {object} payment.InitiatePaymentResponse
+// @Failure 400 {object} handlers.ErrorResponse "Invalid request or phone number"
+// @Failure 404 {object} handlers.ErrorResponse "Order not found"
+// @Failure 409 {object} handlers.ErrorResponse "Order already paid, payment in progress or order not payable"
+// @Failure 502 {object} handlers.ErrorResponse "Gateway rejected the payment"
+// @Failure 503 {object} handlers.ErrorResponse "Payment method not available"
+// @Router /payments/initiate [post]
+func (ph *PaymentHandler) InitiatePayment(w http.ResponseWriter, r *http.Request) {
+	customerID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var req payment.InitiatePaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.OrderID == uuid.Nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	p, started, err := ph.PH.InitiatePayment(r.Context(), customerID, &req)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(payment.InitiatePaymentResponse{Payment: p, Initiation: started})
+}
+
+// MpesaCallback godoc
+// @Summary M-Pesa STK Push result callback
+// @Description Called by Safaricom Daraja with the outcome of an STK Push, authenticated by the ?token= on the configured callback URL. Always acknowledged unless the token is wrong, since Daraja does not retry.
+// @Tags public
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]any "Accepted"
+// @Failure 403 {object} handlers.ErrorResponse "Invalid token"
+// @Failure 404 {object} handlers.ErrorResponse "M-Pesa not configured"
+// @Router /public/payments/mpesa/callback [post]
+func (ph *PaymentHandler) MpesaCallback(w http.ResponseWriter, r *http.Request) {
+	parser := ph.Callbacks[payment.MethodMobileMoney]
+	if parser == nil {
+		writeJSONError(w, http.StatusNotFound, "M-Pesa is not configured", nil)
+		return
+	}
+
+	res, err := parser.ParseCallback(r)
+	if err != nil {
+		writeJSONError(w, http.StatusForbidden, "Invalid callback", err)
+		return
+	}
+
+	if err := ph.PH.HandleResult(r.Context(), payment.MethodMobileMoney, res); err != nil {
+		log.Printf("mpesa callback %s: %v", res.ProviderRef, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"ResultCode": 0, "ResultDesc": "Accepted"})
+}
+
+// StripeWebhook godoc
+// @Summary Stripe webhook
+// @Description Receives Stripe events, verified with the Stripe-Signature header. payment_intent.succeeded completes the payment and payment_intent.canceled fails it; other events are acknowledged and ignored. Repeated events change nothing.
+// @Tags public
+// @Accept json
+// @Success 204 "Event accepted"
+// @Failure 400 {object} handlers.ErrorResponse "Invalid signature"
+// @Failure 404 {object} handlers.ErrorResponse "Stripe not configured"
+// @Failure 500 {object} handlers.ErrorResponse "Event could not be applied; Stripe retries it"
+// @Router /public/payments/stripe/webhook [post]
+func (ph *PaymentHandler) StripeWebhook(w http.ResponseWriter, r *http.Request) {
+	parser := ph.Callbacks[payment.MethodStripe]
+	if parser == nil {
+		writeJSONError(w, http.StatusNotFound, "Stripe is not configured", nil)
+		return
+	}
+
+	res, err := parser.ParseCallback(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid webhook", err)
+		return
+	}
+
+	// intents created outside this API and outcomes the payment can no longer take are
+	// acknowledged so Stripe stops retrying them
+	if res != nil {
+		err := ph.PH.HandleResult(r.Context(), payment.MethodStripe, res)
+		switch {
+		case err == nil:
+		case errors.Is(err, payment.ErrPaymentNotFound), errors.Is(err, payment.ErrInvalidTransition):
+			log.Printf("stripe webhook %s: %v", res.ProviderRef, err)
+		default:
+			writeJSONError(w, http.StatusInternalServerError, "Failed to apply webhook", err)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // GetPaymentByID godoc
 // @Summary Get payment by ID
 // @Security JWT
-// @Description Fetch a single payment using payment ID
+// @Description Fetch a single payment using payment ID; store owners see their orders' payments and customers their own
 // @Tags payments
 // @Produce json
 // @Param id path string true "Payment ID"
@@ -76,8 +203,13 @@ func (ph *PaymentHandler) GetPaymentByID(w http.ResponseWriter, r *http.Request)
 		writeJSONError(w, http.StatusBadRequest, "Invalid payment ID", nil)
 		return
 	}
+	callerID, role, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
 
-	p, err := ph.PH.GetPaymentByID(r.Context(), paymentID)
+	p, err := ph.PH.GetPaymentFor(r.Context(), paymentID, callerID, user.Role(role))
 	if err != nil {
 		writeJSONError(w, http.StatusNotFound, "No payment found", err)
 		return
@@ -90,7 +222,7 @@ func (ph *PaymentHandler) GetPaymentByID(w http.ResponseWriter, r *http.Request)
 // GetPaymentByOrder godoc
 // @Summary Get payment by Order ID
 // @Security JWT
-// @Description Fetch payment(s) using Order ID
+// @Description Fetch the latest payment of an order the caller owns or placed
 // @Tags payments
 // @Produce json
 // @Param order_id path string true "Order ID"
@@ -105,8 +237,13 @@ func (ph *PaymentHandler) GetPaymentByOrderID(w http.ResponseWriter, r *http.Req
 		writeJSONError(w, http.StatusBadRequest, "Invalid order ID", nil)
 		return
 	}
+	callerID, role, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
 
-	p, err := ph.PH.GetPaymentByOrderID(r.Context(), orderID)
+	p, err := ph.PH.GetPaymentByOrderID(r.Context(), orderID, callerID, user.Role(role))
 	if err != nil {
 		writeJSONError(w, http.StatusNotFound, "No payment found", err)
 		return
@@ -119,13 +256,20 @@ func (ph *PaymentHandler) GetPaymentByOrderID(w http.ResponseWriter, r *http.Req
 // ListPayments godoc
 // @Summary List all payments
 // @Security JWT
-// @Description Get a list of all payments
+// @Description Get a list of the payments for the caller's orders
 // @Tags payments
 // @Produce  json
 // @Success 200 {array} payment.Payment
+// @Failure 401 {object} handlers.ErrorResponse
 // @Router /payments/all_payments [get]
 func (ph *PaymentHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
-	payments, err := ph.PH.ListPayments(r.Context())
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	payments, err := ph.PH.ListPayments(r.Context(), adminID)
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Could not fetch payments", err)
 		return
@@ -134,3 +278,251 @@ func (ph *PaymentHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(payments)
 }
+
+// AuthorizePayment godoc
+// @Summary Authorize a payment
+// @Security JWT
+// @Description Records that the gateway holds the funds for a pending payment, optionally with the gateway's reference.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param body body payment.AuthorizeRequest false "Gateway reference"
+// @Success 200 {object} payment.Payment
+// @Failure 400 {object} handlers.ErrorResponse "Invalid request"
+// @Failure 404 {object} handlers.ErrorResponse "Payment not found"
+// @Failure 409 {object} handlers.ErrorResponse "Payment cannot make this transition"
+// @Router /payments/{id}/authorize [post]
+func (ph *PaymentHandler) AuthorizePayment(w http.ResponseWriter, r *http.Request) {
+	id, _, ok := ph.ownedPayment(w, r)
+	if !ok {
+		return
+	}
+
+	var req payment.AuthorizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	p, err := ph.PH.Authorize(r.Context(), id, req.ProviderRef)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// CapturePayment godoc
+// @Summary Capture a payment
+// @Security JWT
+// @Description Takes the funds of a pending or authorized payment. Leave amount out to capture the full payment; a smaller amount captures only that much.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param body body payment.CaptureRequest false "Amount to capture in cents"
+// @Success 200 {object} payment.Payment
+// @Failure 400 {object} handlers.ErrorResponse "Invalid amount"
+// @Failure 404 {object} handlers.ErrorResponse "Payment not found"
+// @Failure 409 {object} handlers.ErrorResponse "Payment cannot make this transition"
+// @Router /payments/{id}/capture [post]
+func (ph *PaymentHandler) CapturePayment(w http.ResponseWriter, r *http.Request) {
+	id, _, ok := ph.ownedPayment(w, r)
+	if !ok {
+		return
+	}
+
+	var req payment.CaptureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	p, err := ph.PH.Capture(r.Context(), id, req.Amount)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// CompletePayment godoc
+// @Summary Complete a payment
+// @Security JWT
+// @Description Marks the payment as settled. A payment that was never captured is captured in full.
+// @Tags payments
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} payment.Payment
+// @Failure 404 {object} handlers.ErrorResponse "Payment not found"
+// @Failure 409 {object} handlers.ErrorResponse "Payment cannot make this transition"
+// @Router /payments/{id}/complete [post]
+func (ph *PaymentHandler) CompletePayment(w http.ResponseWriter, r *http.Request) {
+	id, _, ok := ph.ownedPayment(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := ph.PH.Complete(r.Context(), id)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// FailPayment godoc
+// @Summary Fail a payment
+// @Security JWT
+// @Description Marks a pending or authorized payment as failed. The order is cancelled if it is still pending.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param body body payment.FailRequest true "Failure reason"
+// @Success 200 {object} payment.Payment
+// @Failure 400 {object} handlers.ErrorResponse "Invalid request"
+// @Failure 404 {object} handlers.ErrorResponse "Payment not found"
+// @Failure 409 {object} handlers.ErrorResponse "Payment cannot make this transition"
+// @Router /payments/{id}/fail [post]
+func (ph *PaymentHandler) FailPayment(w http.ResponseWriter, r *http.Request) {
+	id, _, ok := ph.ownedPayment(w, r)
+	if !ok {
+		return
+	}
+
+	var req payment.FailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Reason) == "" {
+		writeJSONError(w, http.StatusBadRequest, "A failure reason is required", nil)
+		return
+	}
+
+	p, err := ph.PH.Fail(r.Context(), id, strings.TrimSpace(req.Reason))
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// RefundPayment godoc
+// @Summary Refund a payment
+// @Security JWT
+// @Description Refunds part or all of a captured payment. Leave amount out to refund everything not yet refunded. Stripe payments are refunded through Stripe first; other methods are only recorded. Refunds never exceed the captured amount; a full refund cancels the order unless it is already in transit or delivered.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param body body payment.RefundRequest false "Amount in cents and reason"
+// @Success 201 {object} payment.Refund
+// @Failure 400 {object} handlers.ErrorResponse "Invalid amount"
+// @Failure 404 {object} handlers.ErrorResponse "Payment not found"
+// @Failure 409 {object} handlers.ErrorResponse "Payment cannot be refunded"
+// @Failure 422 {object} handlers.ErrorResponse "Refund exceeds the captured amount"
+// @Failure 502 {object} handlers.ErrorResponse "Gateway rejected the refund"
+// @Router /payments/{id}/refunds [post]
+func (ph *PaymentHandler) RefundPayment(w http.ResponseWriter, r *http.Request) {
+	id, adminID, ok := ph.ownedPayment(w, r)
+	if !ok {
+		return
+	}
+
+	var req payment.RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	rf, err := ph.PH.Refund(r.Context(), id, req.Amount, req.Reason, &adminID)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rf)
+}
+
+// ListRefunds godoc
+// @Summary List a payment's refunds
+// @Security JWT
+// @Description Returns the payment's refunds, oldest first.
+// @Tags payments
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {array} payment.Refund
+// @Failure 404 {object} handlers.ErrorResponse "Payment not found"
+// @Router /payments/{id}/refunds [get]
+func (ph *PaymentHandler) ListRefunds(w http.ResponseWriter, r *http.Request) {
+	id, _, ok := ph.ownedPayment(w, r)
+	if !ok {
+		return
+	}
+
+	refunds, err := ph.PH.ListRefunds(r.Context(), id)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch refunds", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(refunds)
+}
+
+// ownedPayment reads the payment ID and the caller and checks the payment's order belongs to
+// the caller, writing the error response if not.
+func (ph *PaymentHandler) ownedPayment(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid payment ID", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	if _, err := ph.PH.GetPaymentForAdmin(r.Context(), id, adminID); err != nil {
+		writePaymentError(w, err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, adminID, true
+}
+
+func writePaymentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, payment.ErrInvalidAmount), errors.Is(err, payment.ErrInvalidPhone),
+		errors.Is(err, payment.ErrInvalidReportRange):
+		writeJSONError(w, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, payment.ErrPaymentNotFound), errors.Is(err, payment.ErrOrderNotFound),
+		errors.Is(err, payment.ErrDeliveryNotFound), errors.Is(err, payment.ErrRemittanceNotFound):
+		writeJSONError(w, http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, payment.ErrInvalidTransition), errors.Is(err, payment.ErrOrderNotPayable),
+		errors.Is(err, payment.ErrOrderAlreadyPaid), errors.Is(err, payment.ErrPaymentInProgress),
+		errors.Is(err, payment.ErrDeliveryNotCollectable), errors.Is(err, payment.ErrCashAlreadyCollected),
+		errors.Is(err, payment.ErrRemittanceConfirmed):
+		writeJSONError(w, http.StatusConflict, err.Error(), err)
+	case errors.Is(err, payment.ErrRefundExceedsCaptured), errors.Is(err, payment.ErrCashAmountMismatch),
+		errors.Is(err, payment.ErrExceedsCashInHand):
+		writeJSONError(w, http.StatusUnprocessableEntity, err.Error(), err)
+	case errors.Is(err, payment.ErrProvider):
+		writeJSONError(w, http.StatusBadGateway, err.Error(), err)
+	case errors.Is(err, payment.ErrMethodUnavailable):
+		writeJSONError(w, http.StatusServiceUnavailable, err.Error(), err)
+	default:
+		writeJSONError(w, http.StatusInternalServerError, "Payment request failed", err)
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"logistics-backend/internal/domain/notification"
+	notificationUsecase "logistics-backend/internal/usecase/notification"
+	"net/http"
+)
+
+// DeliveryReportParser authenticates and decodes an SMS gateway's receipt callback.
+type DeliveryReportParser interface {
+	ParseDeliveryReport(r *http.Request) (*notification.DeliveryReport, error)
+}
+
+type SMSReportHandler struct {
+	UC      *notificationUsecase.UseCase
+	Reports DeliveryReportParser // nil when no SMS gateway is configured
+}
+
+func NewSMSReportHandler(uc *notificationUsecase.UseCase, reports DeliveryReportParser) *SMSReportHandler {
+	return &SMSReportHandler{UC: uc, Reports: reports}
+}
+
+// DeliveryReport godoc
+// @Summary SMS delivery report callback
+// @Description Called by the SMS gateway when a message is delivered or fails. Africa's Talking authenticates with ?token=, Twilio with X-Twilio-Signature.
+// @Tags public
+// @Accept x-www-form-urlencoded
+// @Success 204 "Report accepted"
+// @Failure 403 {object} handlers.ErrorResponse "Invalid signature or token"
+// @Failure 404 {object} handlers.ErrorResponse "No SMS gateway configured"
+// @Router /public/sms/delivery-reports [post]
+func (h *SMSReportHandler) DeliveryReport(w http.ResponseWriter, r *http.Request) {
+	if h.Reports == nil {
+		writeJSONError(w, http.StatusNotFound, "No SMS gateway configured", nil)
+		return
+	}
+
+	report, err := h.Reports.ParseDeliveryReport(r)
+	if err != nil {
+		writeJSONError(w, http.StatusForbidden, "Invalid delivery report", err)
+		return
+	}
+
+	// intermediate states and receipts for messages we no longer know are acknowledged so the
+	// gateway stops retrying them
+	if report != nil {
+		if err := h.UC.HandleDeliveryReport(r.Context(), report); err != nil && !errors.Is(err, notification.ErrNotificationNotFound) {
+			log.Printf("sms delivery report %s: %v", report.ProviderRef, err)
+			writeJSONError(w, http.StatusInternalServerError, "Failed to apply delivery report", err)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"logistics-backend/internal/domain/store"
+	"logistics-backend/internal/domain/user"
+	middleware "logistics-backend/internal/middleware"
 	usecase "logistics-backend/internal/usecase/store"
 	"net/http"
 	"strings"
@@ -39,10 +42,19 @@ func (h *StoreHandler) CreateStore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	// stores are always owned by the authenticated admin, never by a body-supplied id
+	req.OwnerID = adminID
 	s := req.ToStore()
 
 	if err := h.UC.CreateStore(r.Context(), s); err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Could not create order", err)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -79,7 +91,18 @@ func (h *StoreHandler) GetStoreByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s, err := h.UC.GetStoreByID(r.Context(), id)
+	callerID, role, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var s *store.Store
+	if role == string(user.Admin) {
+		s, err = h.UC.GetOwnedStore(r.Context(), id, callerID)
+	} else {
+		s, err = h.UC.GetStoreByID(r.Context(), id)
+	}
 	if err != nil {
 		writeJSONError(w, http.StatusNotFound, "Store not found", err)
 		return
@@ -150,7 +173,17 @@ func (h *StoreHandler) UpdateStore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.UC.UpdateStore(r.Context(), storeID, column, req.Value); err != nil {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	if err := h.UC.UpdateStore(r.Context(), storeID, adminID, column, req.Value); err != nil {
+		if errors.Is(err, store.ErrStoreNotFound) {
+			writeJSONError(w, http.StatusNotFound, "Store not found", err)
+			return
+		}
 		writeJSONError(w, http.StatusInternalServerError, "Failed to update store", err)
 		return
 	}
@@ -162,6 +195,33 @@ func (h *StoreHandler) UpdateStore(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ListMyStores godoc
+// @Summary List my stores
+// @Security JWT
+// @Description Returns every store owned by the authenticated admin
+// @Tags stores
+// @Produce json
+// @Success 200 {array} store.Store
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /stores/mine [get]
+func (h *StoreHandler) ListMyStores(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	stores, err := h.UC.ListOwnerStores(r.Context(), adminID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch stores", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stores)
+}
+
 // GetPublicStores godoc
 // @Summary List all public stores
 // @Description Returns all stores marked as public
@@ -191,6 +251,7 @@ func (h *StoreHandler) GetPublicStores(w http.ResponseWriter, r *http.Request) {
 // @Param id path string true "Store ID"
 // @Success 200 {object} map[string]string "Store deleted"
 // @Failure 400 {object} handlers.ErrorResponse "Invalid store ID"
+// @Failure 404 {object} handlers.ErrorResponse "Store not found"
 // @Failure 500 {object} handlers.ErrorResponse "Failed to delete store"
 // @Router /stores/{id} [delete]
 func (h *StoreHandler) DeleteStore(w http.ResponseWriter, r *http.Request) {
@@ -201,7 +262,17 @@ func (h *StoreHandler) DeleteStore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.UC.DeleteStore(r.Context(), storeID); err != nil {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	if err := h.UC.DeleteStore(r.Context(), storeID, adminID); err != nil {
+		if errors.Is(err, store.ErrStoreNotFound) {
+			writeJSONError(w, http.StatusNotFound, "Store not found", err)
+			return
+		}
 		writeJSONError(w, http.StatusInternalServerError, "Failed to delete store", err)
 		return
 	}
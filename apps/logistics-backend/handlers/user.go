@@ -2,23 +2,36 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"net"
 	"net/http"
 	"net/url"
-	"os"
+	"strconv"
 	"time"
 
 	"logistics-backend/internal/application"
+	"logistics-backend/internal/domain/lockout"
+	"logistics-backend/internal/domain/mfa"
+	"logistics-backend/internal/domain/notification"
+	"logistics-backend/internal/domain/session"
 	"logistics-backend/internal/domain/user"
+	middleware "logistics-backend/internal/middleware"
+	lockoutusecase "logistics-backend/internal/usecase/lockout"
+	mfausecase "logistics-backend/internal/usecase/mfa"
+	sessionusecase "logistics-backend/internal/usecase/session"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
 )
 
 type UserHandler struct {
-	UC *application.OrderService
+	UC       *application.OrderService
+	Sessions *sessionusecase.UseCase
+	Lockout  *lockoutusecase.UseCase
+	MFA      *mfausecase.UseCase
 }
 
 // ErrorResponse is a generic error model for API responses.
@@ -26,10 +39,11 @@ type UserHandler struct {
 type ErrorResponse struct {
 	Error  string `json:"error" example:"Invalid request"`                               // user-friendly message
 	Detail string `json:"detail,omitempty" example:"validation failed on field 'email'"` // optional internal error
+	Code   string `json:"code,omitempty" example:"account_unverified"`                   // machine readable reason, when clients must react to it
 }
 
-func NewUserHandler(uc *application.OrderService) *UserHandler {
-	return &UserHandler{UC: uc}
+func NewUserHandler(uc *application.OrderService, sessions *sessionusecase.UseCase, lockout *lockoutusecase.UseCase, mfa *mfausecase.UseCase) *UserHandler {
+	return &UserHandler{UC: uc, Sessions: sessions, Lockout: lockout, MFA: mfa}
 }
 
 func writeJSONError(w http.ResponseWriter, status int, message string, internalErr error) {
@@ -50,9 +64,19 @@ func writeJSONError(w http.ResponseWriter, status int, message string, internalE
 	}
 }
 
+// writeJSONErrorCode is writeJSONError for failures clients are expected to branch on.
+func writeJSONErrorCode(w http.ResponseWriter, status int, message, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(ErrorResponse{Error: message, Code: code}); err != nil {
+		log.Printf("failed to write error response: %v", err)
+	}
+}
+
 // CreateUser godoc
 // @Summary Create a new user
-// @Description Register a new user with name, email, etc.
+// @Description Register a new user with name, email, etc. The account stays pending until the emailed verification link is confirmed.
 // @Tags public
 // @Accept  json
 // @Produce  json
@@ -78,6 +102,10 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	u := req.ToUser()
 
 	if err := h.UC.Users.UseCase.RegisterUser(r.Context(), u); err != nil {
+		if errors.Is(err, user.ErrInvalidPhone) {
+			writeJSONError(w, http.StatusBadRequest, "Invalid phone number", err)
+			return
+		}
 		log.Printf("failed to create user: %v", err)
 		writeJSONError(w, http.StatusInternalServerError, "Failed to create user", err)
 		return
@@ -88,7 +116,6 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]any{
 		"id":         u.ID,
 		"fullName":   u.FullName,
-		"password":   u.PasswordHash,
 		"email":      u.Email,
 		"role":       u.Role,
 		"status":     u.Status,
@@ -100,7 +127,7 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 // UpdateUserProfile godoc
 // @Summary Update user phone number
-// @Description Updates the phone number of a user (commonly used by a driver after initial registration)
+// @Description Updates the phone number of the caller (commonly a driver after initial registration), or of a user in the calling store owner's tenant
 // @Tags users
 // @Security JWT
 // @Accept json
@@ -120,6 +147,23 @@ func (h *UserHandler) UpdateUserProfile(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// users change their own phone; a store owner may change those of accounts they provisioned
+	callerID, role, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+	if userID != callerID {
+		if role != string(user.Admin) {
+			writeJSONError(w, http.StatusNotFound, "User not found", nil)
+			return
+		}
+		if _, err := h.UC.Users.UseCase.GetUserOwnedBy(r.Context(), userID, callerID); err != nil {
+			writeJSONError(w, http.StatusNotFound, "User not found", err)
+			return
+		}
+	}
+
 	var req user.UpdateDriverUserProfileRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err)
@@ -127,6 +171,10 @@ func (h *UserHandler) UpdateUserProfile(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err := h.UC.Users.UseCase.UpdateUserProfile(r.Context(), userID, &req); err != nil {
+		if errors.Is(err, user.ErrInvalidPhone) {
+			writeJSONError(w, http.StatusBadRequest, "Invalid phone number", err)
+			return
+		}
 		writeJSONError(w, http.StatusInternalServerError, "Failed to update user profile", err)
 		return
 	}
@@ -137,6 +185,78 @@ func (h *UserHandler) UpdateUserProfile(w http.ResponseWriter, r *http.Request)
 
 }
 
+// SetDeviceToken godoc
+// @Summary Register the caller's push notification device
+// @Description Stores the device token push notifications are sent to; an empty token turns push off
+// @Tags users
+// @Security JWT
+// @Accept json
+// @Produce json
+// @Param body body user.DeviceTokenRequest true "Device token"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} handlers.ErrorResponse "Bad request"
+// @Failure 500 {object} handlers.ErrorResponse "Server error"
+// @Router /users/me/device-token [put]
+func (h *UserHandler) SetDeviceToken(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var req user.DeviceTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.UC.Users.UseCase.SetDeviceToken(r.Context(), userID, req.Token); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Failed to update device token", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Device token updated"})
+}
+
+// SetLocale godoc
+// @Summary Set the caller's notification language
+// @Description Notifications are rendered in this locale, falling back to English where no translation exists
+// @Tags users
+// @Security JWT
+// @Accept json
+// @Produce json
+// @Param body body user.LocaleRequest true "Locale (en or sw)"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} handlers.ErrorResponse "Bad request"
+// @Failure 500 {object} handlers.ErrorResponse "Server error"
+// @Router /users/me/locale [put]
+func (h *UserHandler) SetLocale(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var req user.LocaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := h.UC.Users.UseCase.SetLocale(r.Context(), userID, req.Locale); err != nil {
+		if errors.Is(err, notification.ErrUnsupportedLocale) {
+			writeJSONError(w, http.StatusBadRequest, "Unsupported locale", err)
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, "Failed to update locale", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Locale updated"})
+}
+
 // UpdateUser godoc
 // @Summary Update a specific user field
 // @Description Updates a user's specific field (e.g., FullName, Email) based on user ID
@@ -148,6 +268,7 @@ func (h *UserHandler) UpdateUserProfile(w http.ResponseWriter, r *http.Request)
 // @Param data body user.UpdateUserRequest true "Field and value to update"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} handlers.ErrorResponse "Invalid user ID or request body"
+// @Failure 404 {object} handlers.ErrorResponse "User not found"
 // @Failure 500 {object} handlers.ErrorResponse "Internal server error"
 // @Router /users/{id}/update [put]
 func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
@@ -158,13 +279,28 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
 	var req user.UpdateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
 
+	if _, err := h.UC.Users.UseCase.GetUserOwnedBy(r.Context(), userID, adminID); err != nil {
+		writeJSONError(w, http.StatusNotFound, "User not found", err)
+		return
+	}
+
 	if err := h.UC.Users.UseCase.UpdateUser(r.Context(), userID, &req); err != nil {
+		if errors.Is(err, user.ErrInvalidPhone) {
+			writeJSONError(w, http.StatusBadRequest, "Invalid phone number", err)
+			return
+		}
 		writeJSONError(w, http.StatusInternalServerError, "Failed to update user", err)
 		return
 	}
@@ -196,7 +332,13 @@ func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	u, err := h.UC.Users.UseCase.GetUserByID(r.Context(), id)
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	u, err := h.UC.Users.UseCase.GetUserForOwner(r.Context(), id, adminID)
 	if err != nil {
 		writeJSONError(w, http.StatusNotFound, "User not found", err)
 		return
@@ -225,12 +367,23 @@ func (h *UserHandler) GetUserByEmail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
 	u, err := h.UC.Users.UseCase.GetUserByEmail(r.Context(), email)
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "User not found", err)
 		return
 	}
 
+	if _, err := h.UC.Users.UseCase.GetUserForOwner(r.Context(), u.ID, adminID); err != nil {
+		writeJSONError(w, http.StatusNotFound, "User not found", err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(u)
 }
@@ -238,13 +391,19 @@ func (h *UserHandler) GetUserByEmail(w http.ResponseWriter, r *http.Request) {
 // ListUsers godoc
 // @Summary List all users
 // @Security JWT
-// @Description Get a list of all registered users
+// @Description Get a list of the users belonging to the authenticated store owner
 // @Tags users
 // @Produce  json
 // @Success 200 {array} user.User
 // @Router /users/all_users [get]
 func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := h.UC.Users.UseCase.ListUsers(r.Context())
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	users, err := h.UC.Users.UseCase.ListUsers(r.Context(), adminID)
 	if err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Could not fetch users", err)
 		return
@@ -256,14 +415,16 @@ func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 
 // LoginUser godoc
 // @Summary Login user
-// @Description Authenticates a user using email and password and returns a JWT token.
+// @Description Authenticates a user using email and password and returns a short-lived JWT access token and a refresh token.
 // @Tags public
 // @Accept  json
 // @Produce  json
 // @Param user body user.LoginRequest true "User login credentials"
-// @Success 200 {object} user.LoginResponse
+// @Success 200 {object} user.LoginResponse "Tokens, or an mfa.ChallengeResponse when two-factor authentication is enabled"
 // @Failure 400 {string} handlers.ErrorResponse "Invalid request"
 // @Failure 401 {string} handlers.ErrorResponse "Invalid credentials"
+// @Failure 403 {string} handlers.ErrorResponse "Account unverified, suspended or inactive (see code)"
+// @Failure 429 {string} handlers.ErrorResponse "Account or client temporarily locked after repeated failures (see code and Retry-After)"
 // @Failure 500 {string} handlers.ErrorResponse "Internal server error"
 // @Router /public/login [post]
 func (h *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
@@ -274,54 +435,159 @@ func (h *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ip := clientIP(r)
+	if retryAfter, err := h.Lockout.Check(r.Context(), req.Email, ip); err != nil {
+		writeLockoutError(w, err, retryAfter)
+		return
+	}
+
 	u, err := h.UC.Users.UseCase.GetUserByEmail(r.Context(), req.Email)
 	if err != nil || !u.ComparePassword(req.Password) {
+		var userID *uuid.UUID
+		if err == nil {
+			userID = &u.ID
+		}
+
+		retryAfter, lockErr := h.Lockout.RecordFailure(r.Context(), req.Email, ip, userID)
+		if lockErr != nil {
+			writeLockoutError(w, lockErr, retryAfter)
+			return
+		}
+
 		writeJSONError(w, http.StatusUnauthorized, "Invalid credentials", err)
 		return
 	}
 
-	// Update last login
-	reqUpdate := &user.UpdateUserRequest{
-		Column: "last_login",
-		Value:  time.Now(),
+	if err := u.CheckStatus(); err != nil {
+		writeJSONErrorCode(w, http.StatusForbidden, err.Error(), user.StatusCode(err))
+		return
 	}
-	if err := h.UC.Users.UseCase.UpdateUser(r.Context(), u.ID, reqUpdate); err != nil {
-		log.Printf("failed to update last login for user %s: %v", u.ID, err)
+
+	// With 2FA on, the password only earns a challenge that VerifyLoginMFA exchanges for tokens;
+	// the failure count is only cleared once the second factor is right too
+	if u.MFAEnabled {
+		challenge, err := h.MFA.StartChallenge(r.Context(), u.ID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "Failed to start two-factor challenge", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(challenge)
+		return
+	}
+
+	if err := h.Lockout.RecordSuccess(r.Context(), req.Email); err != nil {
+		log.Printf("failed to reset login failures for user %s: %v", u.ID, err)
+	}
+
+	h.completeLogin(w, r, u)
+}
+
+// VerifyLoginMFA godoc
+// @Summary Complete a two-factor login
+// @Description Exchanges the challenge token returned by login, plus a TOTP code or a recovery code, for an access and refresh token
+// @Tags public
+// @Accept  json
+// @Produce  json
+// @Param body body mfa.VerifyLoginRequest true "Challenge token and code"
+// @Success 200 {object} user.LoginResponse
+// @Failure 400 {object} handlers.ErrorResponse "Invalid request"
+// @Failure 401 {object} handlers.ErrorResponse "Invalid code or expired challenge"
+// @Failure 403 {object} handlers.ErrorResponse "Account unverified, suspended or inactive (see code)"
+// @Failure 429 {object} handlers.ErrorResponse "Account or client temporarily locked after repeated failures (see code and Retry-After)"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /public/login/mfa [post]
+func (h *UserHandler) VerifyLoginMFA(w http.ResponseWriter, r *http.Request) {
+	var req mfa.VerifyLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChallengeToken == "" || (req.Code == "" && req.RecoveryCode == "") {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	userID, err := h.MFA.ChallengeUser(r.Context(), req.ChallengeToken)
+	if err != nil {
+		if errors.Is(err, mfa.ErrInvalidChallenge) {
+			writeJSONError(w, http.StatusUnauthorized, err.Error(), err)
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, "Failed to verify code", err)
+		return
+	}
+
+	u, err := h.UC.Users.UseCase.GetUserByID(r.Context(), userID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch user", err)
+		return
 	}
 
-	// Load the JWT secret from env
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		writeJSONError(w, http.StatusInternalServerError, "JWT secret not configured", err)
+	// wrong codes count towards the same lockout as wrong passwords, so new challenges from the
+	// password step do not give unlimited guesses
+	ip := clientIP(r)
+	if retryAfter, err := h.Lockout.Check(r.Context(), u.Email, ip); err != nil {
+		writeLockoutError(w, err, retryAfter)
 		return
 	}
 
-	// Create the token
-	claims := jwt.MapClaims{
-		"iss":   "my-client",   // Kong
-		"sub":   u.ID.String(), // subject
-		"email": u.Email,
-		"role":  u.Role, // custom claim
-		"name":  u.FullName,
-		"exp":   time.Now().Add(time.Hour * 24).Unix(), // expires in 24h
+	if _, err := h.MFA.VerifyChallenge(r.Context(), &req); err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) {
+			if retryAfter, lockErr := h.Lockout.RecordFailure(r.Context(), u.Email, ip, &u.ID); lockErr != nil {
+				writeLockoutError(w, lockErr, retryAfter)
+				return
+			}
+		}
+
+		switch {
+		case errors.Is(err, mfa.ErrInvalidCode), errors.Is(err, mfa.ErrInvalidChallenge), errors.Is(err, mfa.ErrNotEnrolled):
+			writeJSONError(w, http.StatusUnauthorized, err.Error(), err)
+		default:
+			writeJSONError(w, http.StatusInternalServerError, "Failed to verify code", err)
+		}
+		return
+	}
+
+	if err := h.Lockout.RecordSuccess(r.Context(), u.Email); err != nil {
+		log.Printf("failed to reset login failures for user %s: %v", u.ID, err)
+	}
+
+	// the account may have been suspended since the password step
+	if err := u.CheckStatus(); err != nil {
+		writeJSONErrorCode(w, http.StatusForbidden, err.Error(), user.StatusCode(err))
+		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	h.completeLogin(w, r, u)
+}
+
+// completeLogin records the login and hands out a new session for a fully authenticated user.
+func (h *UserHandler) completeLogin(w http.ResponseWriter, r *http.Request, u *user.User) {
+	// Update last login
+	reqUpdate := &user.UpdateUserRequest{
+		Column: "last_login",
+		Value:  time.Now(),
+	}
+	if err := h.UC.Users.UseCase.UpdateUser(r.Context(), u.ID, reqUpdate); err != nil {
+		log.Printf("failed to update last login for user %s: %v", u.ID, err)
+	}
 
-	// Sign it using the secret
-	signedToken, err := token.SignedString([]byte(jwtSecret))
+	// Issue a short-lived access token plus a rotating refresh token
+	tokens, err := h.Sessions.IssueTokens(r.Context(), u)
 	if err != nil {
-		writeJSONError(w, http.StatusInternalServerError, "Failed to sign token", err)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to issue tokens", err)
 		return
 	}
 
-	// Return the token in the response
+	// Return the tokens in the response
 	response := user.LoginResponse{
-		ID:       u.ID.String(),
-		FullName: u.FullName,
-		Email:    u.Email,
-		Role:     string(u.Role),
-		Token:    signedToken,
+		ID:                    u.ID.String(),
+		FullName:              u.FullName,
+		Email:                 u.Email,
+		Role:                  string(u.Role),
+		Token:                 tokens.AccessToken,
+		RefreshToken:          tokens.RefreshToken,
+		ExpiresAt:             tokens.ExpiresAt,
+		MustChangePassword:    u.Must_change_password,
+		MFAEnrollmentRequired: !u.MFAEnabled && h.MFA.Requires(u.Role),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -338,6 +604,7 @@ func (h *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 // @Param id path string true "User ID"
 // @Success 200 {object} map[string]string "User profile deleted"
 // @Failure 400 {object} handlers.ErrorResponse "Invalid user ID"
+// @Failure 404 {object} handlers.ErrorResponse "User not found"
 // @Failure 500 {object} handlers.ErrorResponse "Internal server error"
 // @Router /users/{id} [delete]
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
@@ -349,7 +616,18 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.UC.Users.UseCase.DeleteUser(r.Context(), userID); err != nil {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	if _, err := h.UC.Users.UseCase.GetUserOwnedBy(r.Context(), userID, adminID); err != nil {
+		writeJSONError(w, http.StatusNotFound, "User not found", err)
+		return
+	}
+
+	if err := h.UC.Users.UseCase.DeleteUser(r.Context(), userID, adminID); err != nil {
 		writeJSONError(w, http.StatusInternalServerError, "Failed to delete user", err)
 		return
 	}
@@ -358,3 +636,348 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "User profile deleted"})
 }
+
+// RefreshToken godoc
+// @Summary Refresh access token
+// @Description Exchanges a refresh token for a new access token and a new refresh token. The presented refresh token is revoked; reusing it revokes the whole session.
+// @Tags public
+// @Accept  json
+// @Produce  json
+// @Param body body session.RefreshRequest true "Refresh token"
+// @Success 200 {object} session.TokenPair
+// @Failure 400 {object} handlers.ErrorResponse "Invalid request"
+// @Failure 401 {object} handlers.ErrorResponse "Invalid, expired or reused refresh token"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /public/refresh [post]
+func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req session.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	tokens, err := h.Sessions.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, session.ErrUserInactive):
+			writeJSONErrorCode(w, http.StatusUnauthorized, err.Error(), user.StatusCode(err))
+		case errors.Is(err, session.ErrInvalidRefreshToken),
+			errors.Is(err, session.ErrRefreshTokenReused):
+			writeJSONError(w, http.StatusUnauthorized, err.Error(), err)
+		default:
+			writeJSONError(w, http.StatusInternalServerError, "Failed to refresh token", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// Logout godoc
+// @Summary Logout
+// @Security JWT
+// @Description Revokes the access token used for this request and, if provided, the refresh token session
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param body body session.LogoutRequest false "Refresh token to revoke"
+// @Success 200 {object} map[string]string "Logged out"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /auth/logout [post]
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	// body is optional
+	var req session.LogoutRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	jti, expiresAt := middleware.GetTokenFromContext(r.Context())
+	if err := h.Sessions.Logout(r.Context(), userID, jti, expiresAt, req.RefreshToken); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Failed to logout", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Logged out"})
+}
+
+// RevokeUserSessions godoc
+// @Summary Revoke all sessions of a user
+// @Security JWT
+// @Description Immediately invalidates every access and refresh token issued to the user
+// @Tags users
+// @Produce  json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]string "Sessions revoked"
+// @Failure 400 {object} handlers.ErrorResponse "Invalid user ID"
+// @Failure 404 {object} handlers.ErrorResponse "User not found"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /users/{id}/revoke_sessions [post]
+func (h *UserHandler) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	userID, err := uuid.Parse(idStr)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid user ID", nil)
+		return
+	}
+
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	if _, err := h.UC.Users.UseCase.GetUserOwnedBy(r.Context(), userID, adminID); err != nil {
+		writeJSONError(w, http.StatusNotFound, "User not found", err)
+		return
+	}
+
+	if err := h.Sessions.RevokeAllSessions(r.Context(), userID); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Failed to revoke sessions", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": fmt.Sprintf("all sessions of user %s revoked", userID),
+	})
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Emails a single-use password reset link. Always answers 202 so it cannot be used to discover accounts.
+// @Tags public
+// @Accept  json
+// @Produce  json
+// @Param body body user.ForgotPasswordRequest true "Account email"
+// @Success 202 {object} map[string]string "Reset link sent if the account exists"
+// @Failure 400 {object} handlers.ErrorResponse "Invalid request"
+// @Router /public/password/forgot [post]
+func (h *UserHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req user.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	if err := h.UC.Users.UseCase.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		log.Printf("password reset request failed: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "If an account exists for this email, a reset link has been sent",
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Sets a new password using an emailed reset token. The token is single-use and every existing session is revoked.
+// @Tags public
+// @Accept  json
+// @Produce  json
+// @Param body body user.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} map[string]string "Password reset"
+// @Failure 400 {object} handlers.ErrorResponse "Invalid request, token or password"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /public/password/reset [post]
+func (h *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req user.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	if err := h.UC.Users.UseCase.ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
+		switch {
+		case errors.Is(err, user.ErrInvalidResetToken), errors.Is(err, user.ErrWeakPassword):
+			writeJSONError(w, http.StatusBadRequest, err.Error(), err)
+		default:
+			writeJSONError(w, http.StatusInternalServerError, "Failed to reset password", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password has been reset, please log in again"})
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Security JWT
+// @Description Changes the authenticated user's password and clears a forced password change. Other sessions are revoked and a fresh token pair is returned.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param body body user.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} session.TokenPair
+// @Failure 400 {object} handlers.ErrorResponse "Invalid request or password"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized or wrong current password"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /auth/change-password [post]
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var req user.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	u, err := h.UC.Users.UseCase.ChangePassword(r.Context(), userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrInvalidPassword):
+			writeJSONError(w, http.StatusUnauthorized, err.Error(), err)
+		case errors.Is(err, user.ErrWeakPassword), errors.Is(err, user.ErrPasswordUnchanged):
+			writeJSONError(w, http.StatusBadRequest, err.Error(), err)
+		default:
+			writeJSONError(w, http.StatusInternalServerError, "Failed to change password", err)
+		}
+		return
+	}
+
+	// the old tokens were just revoked, hand out a fresh session
+	tokens, err := h.Sessions.IssueTokens(r.Context(), u)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Failed to issue tokens", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Confirms the email address with the emailed verification token and activates the pending account
+// @Tags public
+// @Accept  json
+// @Produce  json
+// @Param body body user.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} map[string]string "Email verified"
+// @Failure 400 {object} handlers.ErrorResponse "Invalid or expired token"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /public/verify-email [post]
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req user.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	if err := h.UC.Users.UseCase.VerifyEmail(r.Context(), req.Token); err != nil {
+		if errors.Is(err, user.ErrInvalidVerificationToken) {
+			writeJSONError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, "Failed to verify email", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Email verified, you can now log in"})
+}
+
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description Mails a new verification link to a pending account. Always answers 202 so it cannot be used to discover accounts.
+// @Tags public
+// @Accept  json
+// @Produce  json
+// @Param body body user.ResendVerificationRequest true "Account email"
+// @Success 202 {object} map[string]string "Verification link sent if the account is pending"
+// @Failure 400 {object} handlers.ErrorResponse "Invalid request"
+// @Router /public/verify-email/resend [post]
+func (h *UserHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req user.ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
+		return
+	}
+
+	if err := h.UC.Users.UseCase.ResendVerification(r.Context(), req.Email); err != nil {
+		log.Printf("resend verification failed: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "If the account is awaiting verification, a new link has been sent",
+	})
+}
+
+// UnlockUser godoc
+// @Summary Unlock a locked account
+// @Security JWT
+// @Description Lifts a temporary lockout caused by repeated failed logins
+// @Tags users
+// @Produce  json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]string "Account unlocked"
+// @Failure 400 {object} handlers.ErrorResponse "Invalid user ID"
+// @Failure 404 {object} handlers.ErrorResponse "User not found"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /users/{id}/unlock [post]
+func (h *UserHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	userID, err := uuid.Parse(idStr)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid user ID", nil)
+		return
+	}
+
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	u, err := h.UC.Users.UseCase.GetUserOwnedBy(r.Context(), userID, adminID)
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, "User not found", err)
+		return
+	}
+
+	if err := h.Lockout.Unlock(r.Context(), u.Email); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Failed to unlock user", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": fmt.Sprintf("user %s unlocked", userID),
+	})
+}
+
+func writeLockoutError(w http.ResponseWriter, err error, retryAfter time.Duration) {
+	code := lockout.CodeTooManyAttempts
+	if errors.Is(err, lockout.ErrAccountLocked) {
+		code = lockout.CodeAccountLocked
+	} else if !errors.Is(err, lockout.ErrTooManyAttempts) {
+		writeJSONError(w, http.StatusInternalServerError, "Failed to check login attempts", err)
+		return
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	writeJSONErrorCode(w, http.StatusTooManyRequests, err.Error(), code)
+}
+
+// clientIP is the caller's address; behind Kong, the RealIP middleware has already applied X-Forwarded-For.
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
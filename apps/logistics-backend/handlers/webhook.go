@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"logistics-backend/internal/domain/webhook"
+	middleware "logistics-backend/internal/middleware"
+	usecase "logistics-backend/internal/usecase/webhook"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type WebhookHandler struct {
+	UC *usecase.UseCase
+}
+
+func NewWebhookHandler(uc *usecase.UseCase) *WebhookHandler {
+	return &WebhookHandler{UC: uc}
+}
+
+// CreateWebhook godoc
+// @Summary Subscribe to store events
+// @Security JWT
+// @Description Sends the chosen events of one of the caller's stores (order.created, order.assigned, order.picked_up, order.delivered, payment.completed) to an https endpoint. Each delivery is signed: X-Webhook-Signature is "sha256=" and the hex HMAC-SHA256 of "<X-Webhook-Timestamp>.<body>" under the secret, which is only returned here and on rotation.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body webhook.CreateSubscriptionRequest true "Subscription payload"
+// @Success 201 {object} webhook.SubscriptionWithSecret
+// @Failure 400 {object} handlers.ErrorResponse "Invalid url or events"
+// @Failure 404 {object} handlers.ErrorResponse "Store not found"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req webhook.CreateSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.StoreID == uuid.Nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	s := req.ToSubscription(adminID)
+	secret, err := h.UC.CreateSubscription(r.Context(), s)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook.SubscriptionWithSecret{Subscription: s, Secret: secret})
+}
+
+// ListWebhooks godoc
+// @Summary List webhook subscriptions
+// @Security JWT
+// @Description Get the caller's webhook subscriptions across their stores, including disabled ones
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {array} webhook.Subscription
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	subs, err := h.UC.ListSubscriptions(r.Context(), adminID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Could not fetch webhooks", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subs)
+}
+
+// GetWebhook godoc
+// @Summary Get a webhook subscription
+// @Security JWT
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} webhook.Subscription
+// @Failure 400 {object} handlers.ErrorResponse "Invalid ID"
+// @Failure 404 {object} handlers.ErrorResponse "Subscription not found"
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, adminID, ok := webhookParams(w, r)
+	if !ok {
+		return
+	}
+
+	s, err := h.UC.GetSubscription(r.Context(), id, adminID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+// UpdateWebhook godoc
+// @Summary Update a webhook subscription
+// @Security JWT
+// @Description Changes the url, the events or whether the subscription is active. Setting active to true re-enables a subscription that was disabled after repeated failures; its queued deliveries are then retried.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param webhook body webhook.UpdateSubscriptionRequest true "Fields to change"
+// @Success 200 {object} webhook.Subscription
+// @Failure 400 {object} handlers.ErrorResponse "Invalid url or events"
+// @Failure 404 {object} handlers.ErrorResponse "Subscription not found"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, adminID, ok := webhookParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req webhook.UpdateSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	s, err := h.UC.UpdateSubscription(r.Context(), id, adminID, &req)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+// RotateWebhookSecret godoc
+// @Summary Rotate a webhook signing secret
+// @Security JWT
+// @Description Replaces the signing secret; deliveries sent from now on are signed with the new one
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} webhook.SubscriptionWithSecret
+// @Failure 404 {object} handlers.ErrorResponse "Subscription not found"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /webhooks/{id}/rotate-secret [post]
+func (h *WebhookHandler) RotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	id, adminID, ok := webhookParams(w, r)
+	if !ok {
+		return
+	}
+
+	s, secret, err := h.UC.RotateSecret(r.Context(), id, adminID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhook.SubscriptionWithSecret{Subscription: s, Secret: secret})
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook subscription
+// @Security JWT
+// @Description Deletes the subscription together with its delivery log
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} handlers.ErrorResponse "Subscription not found"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, adminID, ok := webhookParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.UC.DeleteSubscription(r.Context(), id, adminID); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Webhook deleted"})
+}
+
+// ListWebhookDeliveries godoc
+// @Summary List webhook deliveries
+// @Security JWT
+// @Description Get the subscription's delivery log, newest first, with the status code, body (first 4 KB) and duration of each delivery's latest attempt
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param limit query int false "Limit number of items (default 50, max 100)"
+// @Param offset query int false "Offset for pagination"
+// @Success 200 {array} webhook.Delivery
+// @Failure 404 {object} handlers.ErrorResponse "Subscription not found"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, adminID, ok := webhookParams(w, r)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+	limit = min(limit, 100)
+
+	offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	deliveries, err := h.UC.ListDeliveries(r.Context(), id, adminID, limit, offset)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// RedeliverWebhook godoc
+// @Summary Redeliver a webhook event
+// @Security JWT
+// @Description Queues the delivery's event to be sent again. The redelivery keeps the event ID, so receivers can recognise it as a duplicate.
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param deliveryID path string true "Delivery ID"
+// @Success 202 {object} webhook.Delivery
+// @Failure 404 {object} handlers.ErrorResponse "Subscription or delivery not found"
+// @Failure 409 {object} handlers.ErrorResponse "Subscription is disabled"
+// @Failure 500 {object} handlers.ErrorResponse "Internal server error"
+// @Router /webhooks/{id}/deliveries/{deliveryID}/redeliver [post]
+func (h *WebhookHandler) RedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	id, adminID, ok := webhookParams(w, r)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := uuid.Parse(chi.URLParam(r, "deliveryID"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid delivery ID", nil)
+		return
+	}
+
+	d, err := h.UC.Redeliver(r.Context(), id, deliveryID, adminID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(d)
+}
+
+// webhookParams reads the subscription ID and the caller, writing the error response if either is missing.
+func webhookParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid ID", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	adminID, err := middleware.GetAdminIDFromContext(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, adminID, true
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, webhook.ErrInvalidURL), errors.Is(err, webhook.ErrNoEvents), errors.Is(err, webhook.ErrInvalidEvent):
+		writeJSONError(w, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, webhook.ErrStoreNotOwned), errors.Is(err, webhook.ErrSubscriptionNotFound), errors.Is(err, webhook.ErrDeliveryNotFound):
+		writeJSONError(w, http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, webhook.ErrSubscriptionDisabled):
+		writeJSONError(w, http.StatusConflict, err.Error(), err)
+	default:
+		writeJSONError(w, http.StatusInternalServerError, "Webhook request failed", err)
+	}
+}
@@ -21,8 +21,12 @@ func (a *UseCaseAdapter) UpdateInventory(ctx context.Context, inventoryId uuid.U
 	return a.UseCase.UpdateInventory(ctx, inventoryId, column, value)
 }
 
-func (a *UseCaseAdapter) GetAllInventories(ctx context.Context) ([]order.Inventory, error) {
-	invs, err := a.UseCase.GetAllInventories(ctx) // returns []inventory.AllInventory
+func (a *UseCaseAdapter) RestockInventory(ctx context.Context, inventoryId uuid.UUID, quantity int) error {
+	return a.UseCase.RestockTx(ctx, inventoryId, quantity)
+}
+
+func (a *UseCaseAdapter) GetAllInventories(ctx context.Context, ownerID uuid.UUID) ([]order.Inventory, error) {
+	invs, err := a.UseCase.GetAllInventories(ctx, ownerID) // returns []inventory.AllInventory
 	if err != nil {
 		return nil, err
 	}
@@ -1,9 +1,16 @@
 package notificationadapter
 
 import (
+	"context"
+	"logistics-backend/internal/domain/notification"
 	notificationusecase "logistics-backend/internal/usecase/notification"
 )
 
 type UseCaseAdapter struct {
 	UseCase *notificationusecase.UseCase
 }
+
+// Create lets other domains raise notifications through the notification use case.
+func (a *UseCaseAdapter) Create(ctx context.Context, n *notification.Notification) error {
+	return a.UseCase.CreateNotification(ctx, n)
+}
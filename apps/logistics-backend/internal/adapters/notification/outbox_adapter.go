@@ -0,0 +1,18 @@
+package notificationadapter
+
+import (
+	"context"
+	"logistics-backend/internal/domain/notification"
+	"logistics-backend/internal/domain/outbox"
+	outboxusecase "logistics-backend/internal/usecase/outbox"
+)
+
+type OutboxAdapter struct {
+	Outbox *outboxusecase.UseCase
+}
+
+// Create queues the notification in the caller's transaction; the outbox dispatcher
+// stores it once that transaction has committed.
+func (a *OutboxAdapter) Create(ctx context.Context, n *notification.Notification) error {
+	return a.Outbox.Enqueue(ctx, outbox.TopicNotificationCreate, n)
+}
@@ -4,14 +4,16 @@ import (
 	"context"
 	"logistics-backend/internal/domain/order"
 	userusecase "logistics-backend/internal/usecase/user"
+
+	"github.com/google/uuid"
 )
 
 type UseCaseAdapter struct {
 	UseCase *userusecase.UseCase
 }
 
-func (a *UseCaseAdapter) GetAllCustomers(ctx context.Context) ([]order.Customer, error) {
-	custs, err := a.UseCase.GetAllCustomers(ctx) // returns []user.AllCustomers
+func (a *UseCaseAdapter) GetAllCustomers(ctx context.Context, ownerID uuid.UUID) ([]order.Customer, error) {
+	custs, err := a.UseCase.GetAllCustomers(ctx, ownerID) // returns []user.AllCustomers
 	if err != nil {
 		return nil, err
 	}
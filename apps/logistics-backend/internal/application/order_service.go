@@ -13,8 +13,8 @@ import (
 	useradapter "logistics-backend/internal/adapters/user"
 	"sort"
 
+	"logistics-backend/internal/domain/delivery"
 	"logistics-backend/internal/domain/driver"
-	"logistics-backend/internal/domain/notification"
 	order "logistics-backend/internal/domain/order"
 
 	"github.com/google/uuid"
@@ -91,13 +91,14 @@ func (s *OrderService) GetOrderWithDriver(ctx context.Context, orderID uuid.UUID
 	}{Order: order, Delivery: delivery, Driver: driver}, nil
 }
 
-func (s *OrderService) GetCustomersAndInventories(ctx context.Context) (any, error) {
-	customers, err := s.Users.GetAllCustomers(ctx)
+// GetCustomersAndInventories returns the order form dropdowns scoped to one store owner.
+func (s *OrderService) GetCustomersAndInventories(ctx context.Context, adminID uuid.UUID) (any, error) {
+	customers, err := s.Users.GetAllCustomers(ctx, adminID)
 	if err != nil {
 		return nil, fmt.Errorf("fetch customers: %w", err)
 	}
 
-	inventories, err := s.Inventories.GetAllInventories(ctx)
+	inventories, err := s.Inventories.GetAllInventories(ctx, adminID)
 	if err != nil {
 		return nil, fmt.Errorf("fetch inventories: %w", err)
 	}
@@ -111,9 +112,9 @@ func (s *OrderService) GetCustomersAndInventories(ctx context.Context) (any, err
 	}, nil
 }
 
-func (s *OrderService) OrderAssignment(ctx context.Context, maxDistance float64) ([]Assignment, error) {
-	// 1. Fetch all pending orders
-	allOrders, err := s.Orders.UseCase.ListOrders(ctx)
+func (s *OrderService) OrderAssignment(ctx context.Context, adminID uuid.UUID, maxDistance float64) ([]Assignment, error) {
+	// 1. Fetch the admin's pending orders
+	allOrders, err := s.Orders.UseCase.ListOrders(ctx, adminID, nil)
 	if err != nil {
 		return nil, fmt.Errorf("fetch all orders failed: %w", err)
 	}
@@ -174,25 +175,7 @@ func (s *OrderService) OrderAssignment(ctx context.Context, maxDistance float64)
 				continue
 			}
 
-			// Create a notification for both driver and customer
-			msgCustomer := fmt.Sprintf("Your order %s has been assigned to driver %s", o.ID.String(), designatedDriver.FullName)
-			msgDriver := fmt.Sprintf("You have been assigned a new delivery: order %s", o.ID.String())
-
-			// Customer notification
-			_ = s.Notifications.UseCase.CreateNotification(ctx, &notification.Notification{
-				UserID:  o.CustomerID,
-				Message: msgCustomer,
-				Type:    notification.System, // or SMS later
-				Status:  notification.Pending,
-			})
-
-			// Driver notification
-			_ = s.Notifications.UseCase.CreateNotification(ctx, &notification.Notification{
-				UserID:  designatedDriver.ID,
-				Message: msgDriver,
-				Type:    notification.System,
-				Status:  notification.Pending,
-			})
+			// The customer and driver are notified by the order.assigned event subscribers
 
 			// ✅ TODO (Future optimization):
 			// Consider grouping or batching assignments by proximity and destination direction.
@@ -225,6 +208,31 @@ func (s *OrderService) OrderAssignment(ctx context.Context, maxDistance float64)
 	return assignments, nil
 }
 
+// GetDriverRoute returns every stop the driver still has to make, whenever it was assigned:
+// parcels already picked up first, then assigned deliveries still to be collected.
+func (s *OrderService) GetDriverRoute(ctx context.Context, driverID uuid.UUID) ([]delivery.RouteStop, error) {
+	active, err := s.Deliveries.UseCase.ListDriverActiveDeliveries(ctx, driverID)
+	if err != nil {
+		return nil, fmt.Errorf("fetch active deliveries failed: %w", err)
+	}
+
+	offers, err := s.Deliveries.UseCase.ListDriverOffers(ctx, driverID)
+	if err != nil {
+		return nil, fmt.Errorf("fetch assigned deliveries failed: %w", err)
+	}
+
+	stops := make([]delivery.RouteStop, 0, len(active)+len(offers))
+	for _, d := range append(active, offers...) {
+		o, err := s.Orders.GetOrderByID(ctx, d.OrderID)
+		if err != nil {
+			return nil, fmt.Errorf("fetch order %s failed: %w", d.OrderID, err)
+		}
+		stops = append(stops, delivery.RouteStop{Delivery: d, Order: o})
+	}
+
+	return stops, nil
+}
+
 func filterPendingOrders(orders []*order.Order) []*order.Order {
 	var pending []*order.Order
 	for _, o := range orders {
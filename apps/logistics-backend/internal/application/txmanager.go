@@ -15,8 +15,9 @@ type SQLTxManager struct {
 	db *sqlx.DB
 }
 
-// Unexported key type for context safety
+// Unexported key types for context safety
 type txCtxKey struct{}
+type afterCommitKey struct{}
 
 func NewTxManager(db *sqlx.DB) *SQLTxManager {
 	return &SQLTxManager{db: db}
@@ -28,8 +29,9 @@ func (m *SQLTxManager) Do(ctx context.Context, fn func(ctx context.Context) erro
 		return err
 	}
 
-	// Store the transaction in a new ctx
-	txCtx := context.WithValue(ctx, txCtxKey{}, tx)
+	// Store the transaction, and the hooks to run once it commits, in a new ctx
+	hooks := new([]func(context.Context))
+	txCtx := context.WithValue(context.WithValue(ctx, txCtxKey{}, tx), afterCommitKey{}, hooks)
 
 	// Run the business logic
 	if err := fn(txCtx); err != nil {
@@ -39,7 +41,24 @@ func (m *SQLTxManager) Do(ctx context.Context, fn func(ctx context.Context) erro
 		return err
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, hook := range *hooks {
+		hook(ctx)
+	}
+	return nil
+}
+
+// AfterCommit runs fn once the transaction carried by ctx has committed, with the context
+// Do was called with. Outside a transaction fn runs straight away; after a rollback never.
+func (m *SQLTxManager) AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if hooks, ok := ctx.Value(afterCommitKey{}).(*[]func(context.Context)); ok {
+		*hooks = append(*hooks, fn)
+		return
+	}
+	fn(ctx)
 }
 
 func GetTx(ctx context.Context) *sqlx.Tx {
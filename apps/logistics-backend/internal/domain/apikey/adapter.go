@@ -0,0 +1,12 @@
+package apikey
+
+import (
+	"context"
+	"logistics-backend/internal/domain/store"
+
+	"github.com/google/uuid"
+)
+
+type StoreReader interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*store.Store, error)
+}
@@ -0,0 +1,12 @@
+package apikey
+
+import "errors"
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrInvalidAPIKey  = errors.New("invalid, expired or revoked api key")
+	ErrInvalidScope   = errors.New("scope is not available to api keys")
+	ErrNoScopes       = errors.New("an api key needs at least one scope")
+	ErrStoreNotOwned  = errors.New("store not found or not owned by caller")
+	ErrStoreMismatch  = errors.New("api key is not valid for this store")
+)
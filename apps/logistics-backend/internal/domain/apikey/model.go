@@ -0,0 +1,57 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// KeyPrefix starts every API key, so keys are recognisable in headers and secret scanners.
+const KeyPrefix = "lk_"
+
+// AllowedScopes are the only permissions a key can be granted; everything else
+// (user management, payments, drivers, ...) stays behind a user session.
+var AllowedScopes = []string{
+	"orders:create", "orders:read", "orders:list",
+	"inventories:read", "inventories:write",
+	"stores:read",
+}
+
+func IsAllowedScope(scope string) bool {
+	for _, s := range AllowedScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey lets an integration act for a store owner, limited to its scopes.
+// The full key is lk_<prefix>_<secret> and is only shown once, on creation.
+type APIKey struct {
+	ID         uuid.UUID      `db:"id" json:"id"`
+	StoreID    uuid.UUID      `db:"store_id" json:"store_id"`
+	OwnerID    uuid.UUID      `db:"owner_id" json:"owner_id"`
+	Name       string         `db:"name" json:"name"`
+	Prefix     string         `db:"prefix" json:"prefix"`
+	KeyHash    string         `db:"key_hash" json:"-"`
+	Scopes     pq.StringArray `db:"scopes" json:"scopes"` // permission names, see AllowedScopes
+	LastUsedAt *time.Time     `db:"last_used_at" json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time     `db:"expires_at" json:"expires_at,omitempty"`
+	RevokedAt  *time.Time     `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
+
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
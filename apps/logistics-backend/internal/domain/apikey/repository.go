@@ -0,0 +1,15 @@
+package apikey
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	Create(ctx context.Context, k *APIKey) error                           // POST
+	GetByPrefix(ctx context.Context, prefix string) (*APIKey, error)       // GET
+	ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*APIKey, error) // GET
+	Revoke(ctx context.Context, id, ownerID uuid.UUID) error               // PATCH
+	TouchLastUsed(ctx context.Context, id uuid.UUID) error                 // PATCH, at most once a minute
+}
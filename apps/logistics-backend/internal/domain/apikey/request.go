@@ -0,0 +1,30 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateAPIKeyRequest struct {
+	StoreID   uuid.UUID  `json:"store_id" binding:"required"`
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // never expires when omitted
+}
+
+func (r *CreateAPIKeyRequest) ToAPIKey(ownerID uuid.UUID) *APIKey {
+	return &APIKey{
+		StoreID:   r.StoreID,
+		OwnerID:   ownerID,
+		Name:      r.Name,
+		Scopes:    r.Scopes,
+		ExpiresAt: r.ExpiresAt,
+	}
+}
+
+// CreateAPIKeyResponse carries the only copy of the full key the server ever hands out.
+type CreateAPIKeyResponse struct {
+	*APIKey
+	Key string `json:"key"`
+}
@@ -3,7 +3,7 @@ package delivery
 import (
 	"context"
 	"logistics-backend/internal/domain/driver"
-	"logistics-backend/internal/domain/notification"
+	"logistics-backend/internal/domain/event"
 	"logistics-backend/internal/domain/order"
 
 	"github.com/google/uuid"
@@ -21,6 +21,7 @@ type DriverReader interface {
 	UpdateDriverAvailability(ctx context.Context, driverID uuid.UUID, column string, value bool) error
 }
 
-type NotificationReader interface {
-	Create(ctx context.Context, n *notification.Notification) error
+// Publishes the domain's events once the transaction commits.
+type EventPublisher interface {
+	Publish(ctx context.Context, events ...event.Event) error
 }
@@ -2,4 +2,7 @@ package delivery
 
 import "errors"
 
-var ErrorNoPendingOrder = errors.New("no pending orders")
+var (
+	ErrorNoPendingOrder = errors.New("no pending orders")
+	ErrorNotFound       = errors.New("delivery not found")
+)
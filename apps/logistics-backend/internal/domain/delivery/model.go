@@ -3,6 +3,9 @@ package delivery
 import (
 	"time"
 
+	"logistics-backend/internal/domain/money"
+	"logistics-backend/internal/domain/order"
+
 	"github.com/google/uuid"
 )
 
@@ -26,3 +29,24 @@ type Delivery struct {
 }
 
 // *time.Time can hold both a timestamp and a nil value.
+
+// RouteStop pairs a driver's delivery with the order it fulfils.
+type RouteStop struct {
+	Delivery *Delivery    `json:"delivery"`
+	Order    *order.Order `json:"order"`
+}
+
+type PeriodEarnings struct {
+	Deliveries int         `json:"deliveries"`
+	Amount     money.Money `json:"amount"`
+}
+
+// EarningsSummary is computed from completed deliveries at the flat per-delivery payout rate.
+type EarningsSummary struct {
+	DriverID  uuid.UUID      `json:"driver_id"`
+	Rate      money.Money    `json:"rate"`
+	Today     PeriodEarnings `json:"today"`
+	ThisWeek  PeriodEarnings `json:"this_week"`
+	ThisMonth PeriodEarnings `json:"this_month"`
+	AllTime   PeriodEarnings `json:"all_time"`
+}
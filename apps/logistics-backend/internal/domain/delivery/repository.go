@@ -2,6 +2,7 @@ package delivery
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -9,10 +10,13 @@ import (
 type Repository interface {
 	Create(ctx context.Context, delivery *Delivery) error                             // POST method to create delivery from orders.
 	GetByID(ctx context.Context, id uuid.UUID) (*Delivery, error)                     // GET method for fetching delivery by id
-	List(ctx context.Context) ([]*Delivery, error)                                    // GET method to fetch all deliveries
+	ListByAdmin(ctx context.Context, adminID uuid.UUID) ([]*Delivery, error)          // GET method to fetch all deliveries of one store owner's orders
 	Update(ctx context.Context, deliveryID uuid.UUID, column string, value any) error // PUT generic method to update specified column value in orders table
 	Accept(ctx context.Context, d *Delivery) error                                    // PATCH method for driver to accept delivery.
 	Delete(ctx context.Context, id uuid.UUID) error                                   // DELETE method to remove delivery by ID
 
 	ListByStatus(ctx context.Context, statuses []DeliveryStatus) ([]*Delivery, error)
+	ListByDriver(ctx context.Context, driverID uuid.UUID, statuses []DeliveryStatus, limit, offset int) ([]*Delivery, error)
+	CountDelivered(ctx context.Context, driverID uuid.UUID, since time.Time) (int, error)
+	HeldByDriver(ctx context.Context, orderID, driverID uuid.UUID) (bool, error)
 }
@@ -0,0 +1,18 @@
+package document
+
+import (
+	"context"
+	"logistics-backend/internal/domain/notification"
+
+	"github.com/google/uuid"
+)
+
+type NotificationReader interface {
+	Create(ctx context.Context, n *notification.Notification) error
+}
+
+// DriverTenants tells whether a driver works within a store owner's tenant, or was provisioned by them.
+type DriverTenants interface {
+	BelongsToOwner(ctx context.Context, driverID, ownerID uuid.UUID) (bool, error)
+	OwnedBy(ctx context.Context, driverID, ownerID uuid.UUID) (bool, error)
+}
@@ -0,0 +1,12 @@
+package document
+
+import "errors"
+
+var (
+	ErrInvalidType     = errors.New("invalid document type")
+	ErrInvalidDates    = errors.New("expiry date must be after issue date")
+	ErrMissingFile     = errors.New("missing document file")
+	ErrInvalidStatus   = errors.New("invalid verification status")
+	ErrAlreadyVerified = errors.New("document already verified")
+	ErrNotFound        = errors.New("document not found")
+)
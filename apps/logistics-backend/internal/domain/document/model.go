@@ -0,0 +1,55 @@
+package document
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DocumentType string
+type VerificationStatus string
+
+const (
+	Licence           DocumentType = "licence"
+	Insurance         DocumentType = "insurance"
+	VehicleInspection DocumentType = "vehicle_inspection"
+)
+
+const (
+	Pending  VerificationStatus = "pending"
+	Verified VerificationStatus = "verified"
+	Rejected VerificationStatus = "rejected"
+)
+
+// RequiredTypes lists the documents checked before dispatch; a driver whose document of one of
+// these types has expired or been rejected is not dispatched until a valid one replaces it.
+var RequiredTypes = []DocumentType{Licence, Insurance, VehicleInspection}
+
+// driver compliance document (licence, insurance, inspection)
+type Document struct {
+	ID          uuid.UUID          `db:"id" json:"id"`
+	DriverID    uuid.UUID          `db:"driver_id" json:"driver_id"`
+	Type        DocumentType       `db:"type" json:"type"`
+	Number      string             `db:"number" json:"number"`
+	IssuedAt    time.Time          `db:"issued_at" json:"issued_at"`
+	ExpiresAt   time.Time          `db:"expires_at" json:"expires_at"`
+	FilePath    string             `db:"file_path" json:"file_path"`
+	Status      VerificationStatus `db:"status" json:"status"`
+	VerifiedBy  *uuid.UUID         `db:"verified_by" json:"verified_by,omitempty"`
+	VerifiedAt  *time.Time         `db:"verified_at" json:"verified_at,omitempty"`
+	LastAlertAt *time.Time         `db:"last_alert_at" json:"last_alert_at,omitempty"`
+	CreatedAt   time.Time          `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `db:"updated_at" json:"updated_at"`
+}
+
+func (d *Document) IsExpired(now time.Time) bool {
+	return !d.ExpiresAt.After(now)
+}
+
+func (t DocumentType) IsValid() bool {
+	switch t {
+	case Licence, Insurance, VehicleInspection:
+		return true
+	}
+	return false
+}
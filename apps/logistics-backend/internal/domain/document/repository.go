@@ -0,0 +1,29 @@
+package document
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	Create(ctx context.Context, d *Document) error                                                         // POST
+	GetByID(ctx context.Context, id uuid.UUID) (*Document, error)                                          // GET
+	ListByDriver(ctx context.Context, driverID uuid.UUID) ([]*Document, error)                             // GET
+	UpdateStatus(ctx context.Context, id uuid.UUID, status VerificationStatus, verifiedBy uuid.UUID) error // PUT
+	Delete(ctx context.Context, id uuid.UUID) error                                                        // DELETE
+
+	ListExpiring(ctx context.Context, before time.Time, alertedBefore time.Time) ([]*Document, error)
+	ListExpiringForOwner(ctx context.Context, ownerID uuid.UUID, before time.Time) ([]*Document, error)
+	MarkAlerted(ctx context.Context, id uuid.UUID) error
+}
+
+// BlobStorage keeps the uploaded document files.
+// The local filesystem implementation can later be swapped for S3/GCS.
+type BlobStorage interface {
+	Save(ctx context.Context, name string, r io.Reader) (string, error)
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+	Delete(ctx context.Context, path string) error
+}
@@ -0,0 +1,30 @@
+package document
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UploadDocumentRequest struct {
+	DriverID  uuid.UUID    `json:"driver_id" binding:"required"`
+	Type      DocumentType `json:"type" binding:"required"`
+	Number    string       `json:"number" binding:"required"`
+	IssuedAt  time.Time    `json:"issued_at" binding:"required"`
+	ExpiresAt time.Time    `json:"expires_at" binding:"required"`
+}
+
+type VerifyDocumentRequest struct {
+	Status VerificationStatus `json:"status" binding:"required,oneof=verified rejected"`
+}
+
+func (r *UploadDocumentRequest) ToDocument() *Document {
+	return &Document{
+		DriverID:  r.DriverID,
+		Type:      r.Type,
+		Number:    r.Number,
+		IssuedAt:  r.IssuedAt,
+		ExpiresAt: r.ExpiresAt,
+		Status:    Pending,
+	}
+}
@@ -3,5 +3,6 @@ package driver
 import "errors"
 
 var (
-	ErrMissingUserID = errors.New("missing driver ID")
+	ErrMissingUserID  = errors.New("missing driver ID")
+	ErrDriverNotFound = errors.New("driver not found")
 )
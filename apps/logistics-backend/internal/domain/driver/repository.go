@@ -11,7 +11,9 @@ type Repository interface {
 	Create(ctx context.Context, driver *Driver) error                                                          // POST
 	GetByID(ctx context.Context, id uuid.UUID) (*Driver, error)                                                // GET
 	GetByEmail(ctx context.Context, email string) (*Driver, error)                                             // GET
-	List(ctx context.Context) ([]*Driver, error)                                                               // GET all drivers
+	ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*Driver, error)                                     // GET all drivers of one store owner
+	BelongsToOwner(ctx context.Context, driverID, ownerID uuid.UUID) (bool, error)                             // GET tenant membership check
+	OwnedBy(ctx context.Context, driverID, ownerID uuid.UUID) (bool, error)                                    // GET provisioning owner check
 	UpdateColumn(ctx context.Context, driverID uuid.UUID, column string, value any) error                      // PATCH method for specific driver details update
 	UpdateProfile(ctx context.Context, id uuid.UUID, vehicleInfo string, currentLocation postgis.PointS) error // PUT method for driver details to be updated after registration
 	Delete(ctx context.Context, id uuid.UUID) error                                                            // DELETE
@@ -0,0 +1,32 @@
+package event
+
+import "context"
+
+// Publisher raises events from a use case. Inside a transaction nothing reaches subscribers
+// unless that transaction commits.
+type Publisher interface {
+	Publish(ctx context.Context, events ...Event) error
+}
+
+// Handler reacts to one event. Async handlers may see an event more than once and use the
+// envelope ID to make repeats harmless.
+type Handler interface {
+	Handle(ctx context.Context, env *Envelope) error
+}
+
+type HandlerFunc func(ctx context.Context, env *Envelope) error
+
+func (f HandlerFunc) Handle(ctx context.Context, env *Envelope) error {
+	return f(ctx, env)
+}
+
+// Queue stores async deliveries in the publishing transaction (the outbox).
+type Queue interface {
+	Enqueue(ctx context.Context, topic string, payload any) error
+}
+
+// Committer runs fn once the transaction carried by ctx has committed, or straight away
+// when ctx carries none.
+type Committer interface {
+	AfterCommit(ctx context.Context, fn func(ctx context.Context))
+}
@@ -0,0 +1,8 @@
+package event
+
+import "errors"
+
+var (
+	ErrUnknownEvent      = errors.New("unknown domain event")
+	ErrUnknownSubscriber = errors.New("no async subscriber registered for domain event")
+)
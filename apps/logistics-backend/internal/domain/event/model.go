@@ -0,0 +1,189 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Name identifies a kind of domain event; subscribers pick the names they react to.
+type Name string
+
+const (
+	NameOrderCreated      Name = "order.created"
+	NameOrderAssigned     Name = "order.assigned"
+	NameDeliveryAccepted  Name = "delivery.accepted"
+	NameDeliveryUpdated   Name = "delivery.updated"
+	NameDeliveryCompleted Name = "delivery.completed"
+	NameInventoryCreated  Name = "inventory.created"
+	NameInventoryUpdated  Name = "inventory.updated"
+	NameInventoryDeleted  Name = "inventory.deleted"
+	NameStockLow          Name = "inventory.stock_low"
+	NamePaymentCompleted  Name = "payment.completed"
+	NamePaymentFailed     Name = "payment.failed"
+	NamePaymentRefunded   Name = "payment.refunded"
+
+	// All subscribes a handler to every event.
+	All Name = "*"
+)
+
+// Event is something that happened in a use case. Events are plain values that survive a
+// JSON round trip, so async subscribers receive exactly what was published.
+type Event interface {
+	EventName() Name
+}
+
+// Envelope wraps a published event. ID is the same for every subscriber and every retry, so
+// subscribers use it to make repeats harmless.
+type Envelope struct {
+	ID         uuid.UUID `json:"id"`
+	Name       Name      `json:"name"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Event      Event     `json:"-"`
+}
+
+type OrderCreated struct {
+	OrderID         uuid.UUID `json:"order_id"`
+	StoreID         uuid.UUID `json:"store_id"`
+	AdminID         uuid.UUID `json:"admin_id"`
+	CustomerID      uuid.UUID `json:"customer_id"`
+	InventoryID     uuid.UUID `json:"inventory_id"`
+	Quantity        int       `json:"quantity"`
+	PickupAddress   string    `json:"pickup_address"`
+	DeliveryAddress string    `json:"delivery_address"`
+}
+
+type OrderAssigned struct {
+	OrderID    uuid.UUID `json:"order_id"`
+	CustomerID uuid.UUID `json:"customer_id"`
+	DriverID   uuid.UUID `json:"driver_id"`
+}
+
+// DeliveryAccepted is raised when a driver accepts an order and picks it up.
+type DeliveryAccepted struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+	OrderID    uuid.UUID `json:"order_id"`
+	CustomerID uuid.UUID `json:"customer_id"`
+	DriverID   uuid.UUID `json:"driver_id"`
+	DriverName string    `json:"driver_name"`
+}
+
+type DeliveryUpdated struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+	OrderID    uuid.UUID `json:"order_id"`
+	DriverID   uuid.UUID `json:"driver_id"`
+	Field      string    `json:"field"`
+}
+
+type DeliveryCompleted struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+	OrderID    uuid.UUID `json:"order_id"`
+	DriverID   uuid.UUID `json:"driver_id"`
+}
+
+type InventoryCreated struct {
+	InventoryID uuid.UUID `json:"inventory_id"`
+	StoreID     uuid.UUID `json:"store_id"`
+	OwnerID     uuid.UUID `json:"owner_id"`
+	Item        string    `json:"item"`
+	Stock       int       `json:"stock"`
+}
+
+type InventoryUpdated struct {
+	InventoryID uuid.UUID `json:"inventory_id"`
+	StoreID     uuid.UUID `json:"store_id"`
+	OwnerID     uuid.UUID `json:"owner_id"`
+	Item        string    `json:"item"`
+	Field       string    `json:"field"`
+}
+
+type InventoryDeleted struct {
+	InventoryID uuid.UUID `json:"inventory_id"`
+	StoreID     uuid.UUID `json:"store_id"`
+	OwnerID     uuid.UUID `json:"owner_id"`
+	Item        string    `json:"item"`
+}
+
+// StockLow is raised when an inventory's stock drops to or below the low stock threshold.
+type StockLow struct {
+	InventoryID uuid.UUID `json:"inventory_id"`
+	StoreID     uuid.UUID `json:"store_id"`
+	OwnerID     uuid.UUID `json:"owner_id"`
+	Item        string    `json:"item"`
+	Stock       int       `json:"stock"`
+}
+
+type PaymentCompleted struct {
+	PaymentID  uuid.UUID `json:"payment_id"`
+	OrderID    uuid.UUID `json:"order_id"`
+	CustomerID uuid.UUID `json:"customer_id"`
+	Amount     int64     `json:"amount"`
+	Currency   string    `json:"currency"`
+	Method     string    `json:"method"`
+	Receipt    string    `json:"receipt,omitempty"`
+}
+
+type PaymentFailed struct {
+	PaymentID  uuid.UUID `json:"payment_id"`
+	OrderID    uuid.UUID `json:"order_id"`
+	CustomerID uuid.UUID `json:"customer_id"`
+	Reason     string    `json:"reason"`
+}
+
+// PaymentRefunded is raised for every refund; Full is set once nothing captured is left.
+type PaymentRefunded struct {
+	PaymentID uuid.UUID `json:"payment_id"`
+	OrderID   uuid.UUID `json:"order_id"`
+	RefundID  uuid.UUID `json:"refund_id"`
+	Amount    int64     `json:"amount"`
+	Currency  string    `json:"currency"`
+	Full      bool      `json:"full"`
+}
+
+func (OrderCreated) EventName() Name      { return NameOrderCreated }
+func (OrderAssigned) EventName() Name     { return NameOrderAssigned }
+func (DeliveryAccepted) EventName() Name  { return NameDeliveryAccepted }
+func (DeliveryUpdated) EventName() Name   { return NameDeliveryUpdated }
+func (DeliveryCompleted) EventName() Name { return NameDeliveryCompleted }
+func (InventoryCreated) EventName() Name  { return NameInventoryCreated }
+func (InventoryUpdated) EventName() Name  { return NameInventoryUpdated }
+func (InventoryDeleted) EventName() Name  { return NameInventoryDeleted }
+func (StockLow) EventName() Name          { return NameStockLow }
+func (PaymentCompleted) EventName() Name  { return NamePaymentCompleted }
+func (PaymentFailed) EventName() Name     { return NamePaymentFailed }
+func (PaymentRefunded) EventName() Name   { return NamePaymentRefunded }
+
+// decoders turn a queued event back into its type, keyed by name.
+var decoders = map[Name]func([]byte) (Event, error){
+	NameOrderCreated:      decode[OrderCreated],
+	NameOrderAssigned:     decode[OrderAssigned],
+	NameDeliveryAccepted:  decode[DeliveryAccepted],
+	NameDeliveryUpdated:   decode[DeliveryUpdated],
+	NameDeliveryCompleted: decode[DeliveryCompleted],
+	NameInventoryCreated:  decode[InventoryCreated],
+	NameInventoryUpdated:  decode[InventoryUpdated],
+	NameInventoryDeleted:  decode[InventoryDeleted],
+	NameStockLow:          decode[StockLow],
+	NamePaymentCompleted:  decode[PaymentCompleted],
+	NamePaymentFailed:     decode[PaymentFailed],
+	NamePaymentRefunded:   decode[PaymentRefunded],
+}
+
+// Decode rebuilds an event of the given name from its JSON.
+func Decode(name Name, data []byte) (Event, error) {
+	d, ok := decoders[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEvent, name)
+	}
+	return d(data)
+}
+
+func decode[E Event](data []byte) (Event, error) {
+	var e E
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("decode %s event: %w", e.EventName(), err)
+	}
+	return e, nil
+}
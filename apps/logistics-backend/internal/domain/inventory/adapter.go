@@ -2,16 +2,17 @@ package inventory
 
 import (
 	"context"
-	"logistics-backend/internal/domain/notification"
+	"logistics-backend/internal/domain/event"
 	"logistics-backend/internal/domain/store"
 
 	"github.com/google/uuid"
 )
 
-type NotificationReader interface {
-	Create(ctx context.Context, n *notification.Notification) error
-}
-
 type StoreReader interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*store.Store, error)
 }
+
+// Publishes the domain's events once the transaction commits.
+type EventPublisher interface {
+	Publish(ctx context.Context, events ...event.Event) error
+}
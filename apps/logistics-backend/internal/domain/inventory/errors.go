@@ -0,0 +1,7 @@
+package inventory
+
+import "errors"
+
+var (
+	ErrInventoryNotFound = errors.New("inventory not found")
+)
@@ -7,16 +7,18 @@ import (
 )
 
 type Repository interface {
-	Create(ctx context.Context, inventory *Inventory) error            // POST method for creating new inventory.
-	GetByID(ctx context.Context, id uuid.UUID) (*Inventory, error)     // GET method for fetching inventory by id.
-	GetByName(ctx context.Context, name string) (*Inventory, error)    // GET method for fetching inventory by name.
-	List(ctx context.Context, limit, offset int) ([]*Inventory, error) // GET method for fetching all inventories - slice.
-	GetAllInventories(ctx context.Context) ([]AllInventory, error)     // GET all inv ID, Name & AdminID without pagination.
-	Delete(ctx context.Context, id uuid.UUID) error                    // DELETE method to remove inventory by id.
+	Create(ctx context.Context, inventory *Inventory) error                                                          // POST method for creating new inventory.
+	GetByID(ctx context.Context, id uuid.UUID) (*Inventory, error)                                                   // GET method for fetching inventory by id.
+	GetByName(ctx context.Context, name string, ownerID, storeID *uuid.UUID) (*Inventory, error)                     // GET method for fetching inventory by name, optionally within an owner's stores or one store.
+	List(ctx context.Context, limit, offset int) ([]*Inventory, error)                                               // GET method for fetching all inventories - slice.
+	ListByOwner(ctx context.Context, ownerID uuid.UUID, storeID *uuid.UUID, limit, offset int) ([]*Inventory, error) // GET inventories across the owner's stores, or one of them.
+	GetAllInventories(ctx context.Context, ownerID uuid.UUID) ([]AllInventory, error)                                // GET all of the owner's inv ID, Name & AdminID without pagination.
+	Delete(ctx context.Context, id uuid.UUID) error                                                                  // DELETE method to remove inventory by id.
 
-	GetByCategory(ctx context.Context, category string) ([]*Inventory, error)       // GET method for fetching inventories(slice) by category.
-	ListCategories(ctx context.Context) ([]string, error)                           // GET method for fetching all categories in inventories table.
-	UpdateColumn(ctx context.Context, id uuid.UUID, column string, value any) error // PUT method for updating table column values.
+	GetByCategory(ctx context.Context, category string, ownerID, storeID *uuid.UUID) ([]*Inventory, error) // GET method for fetching inventories(slice) by category, optionally within an owner's stores or one store.
+	ListCategories(ctx context.Context) ([]string, error)                                                  // GET method for fetching all categories in inventories table.
+	UpdateColumn(ctx context.Context, id uuid.UUID, column string, value any) error                        // PUT method for updating table column values.
+	AddStock(ctx context.Context, id uuid.UUID, quantity int) error                                        // PATCH stock in place, so concurrent changes are not lost.
 
 	GetByStoreID(ctx context.Context, storeID uuid.UUID) ([]*Inventory, error)
 }
@@ -5,7 +5,7 @@ import (
 )
 
 type CreateInventoryRequest struct {
-	AdminID       uuid.UUID `json:"admin_id"`                    // Foreign key
+	StoreID       uuid.UUID `json:"store_id" binding:"required"` // Foreign key, must be owned by the caller
 	Category      string    `json:"category" binding:"required"` // e.g. “Dairy”
 	Stock         int       `json:"stock" binding:"required"`
 	PriceAmount   int64     `json:"price_amount" binding:"required"`
@@ -18,6 +18,7 @@ type CreateInventoryRequest struct {
 
 func (r *CreateInventoryRequest) ToInventory() *Inventory {
 	return &Inventory{
+		StoreID:       r.StoreID,
 		Category:      r.Category,
 		Stock:         r.Stock,
 		PriceAmount:   r.PriceAmount,
@@ -0,0 +1,17 @@
+package invite
+
+import (
+	"context"
+	"logistics-backend/internal/domain/notification"
+	"logistics-backend/internal/domain/user"
+)
+
+// User use case, so accepting an invite provisions the account (and driver row) in the invite's transaction.
+type UserProvisioner interface {
+	GetUserByEmail(ctx context.Context, email string) (*user.User, error)
+	CreateAccount(ctx context.Context, u *user.User) error
+}
+
+type NotificationWriter interface {
+	Create(ctx context.Context, n *notification.Notification) error
+}
@@ -0,0 +1,11 @@
+package invite
+
+import "errors"
+
+var (
+	ErrInviteNotFound = errors.New("invite not found")
+	ErrInviteExpired  = errors.New("invite has expired")
+	ErrInviteAccepted = errors.New("invite has already been accepted")
+	ErrInvalidRole    = errors.New("role cannot be invited")
+	ErrEmailTaken     = errors.New("an account with this email already exists")
+)
@@ -17,11 +17,30 @@ const (
 
 // new user invite via invite link
 type Invite struct {
-	ID        uuid.UUID `db:"id" json:"id"`
-	Email     string    `db:"email" json:"email"`
-	Role      Role      `db:"role" json:"role"`
-	Token     string    `db:"token" json:"token"`
-	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
-	InvitedBy uuid.UUID `db:"invited_by" json:"invited_by"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	ID             uuid.UUID  `db:"id" json:"id"`
+	Email          string     `db:"email" json:"email"`
+	Role           Role       `db:"role" json:"role"`
+	TokenHash      string     `db:"token_hash" json:"-"` // the raw token only ever leaves the server in the invite email
+	ExpiresAt      time.Time  `db:"expires_at" json:"expires_at"`
+	InvitedBy      uuid.UUID  `db:"invited_by" json:"invited_by"`
+	AcceptedAt     *time.Time `db:"accepted_at" json:"accepted_at,omitempty"`
+	AcceptedUserID *uuid.UUID `db:"accepted_user_id" json:"accepted_user_id,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+}
+
+func (i *Invite) IsExpired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+func (i *Invite) IsAccepted() bool {
+	return i.AcceptedAt != nil
+}
+
+// CanInvite reports whether accounts of the role may be created through an invite.
+func (r Role) CanInvite() bool {
+	switch r {
+	case Admin, Driver, Customer:
+		return true
+	}
+	return false
 }
@@ -8,8 +8,9 @@ import (
 
 // Invite = pending entry, just an email + role + token + expiration.
 type Repository interface {
-	Create(ctx context.Context, invite *Invite) error              // POST
-	GetByToken(ctx context.Context, token string) (*Invite, error) // GET
-	ListPending(ctx context.Context) ([]*Invite, error)            // GET
-	Delete(ctx context.Context, id uuid.UUID) error                // DELETE
+	Create(ctx context.Context, invite *Invite) error                        // POST
+	GetByTokenHash(ctx context.Context, tokenHash string) (*Invite, error)   // GET
+	ListPending(ctx context.Context, invitedBy uuid.UUID) ([]*Invite, error) // GET
+	MarkAccepted(ctx context.Context, id uuid.UUID, userID uuid.UUID) error  // PATCH, fails if already accepted
+	Delete(ctx context.Context, id uuid.UUID, invitedBy uuid.UUID) error     // DELETE
 }
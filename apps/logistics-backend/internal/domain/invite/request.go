@@ -1,27 +1,36 @@
 package invite
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type CreateInviteRequest struct {
-	ID        uuid.UUID `json:"id" binding:"required"`
-	Email     string    `json:"email" binding:"required"`
-	Role      Role      `json:"role" binding:"required"`
-	Token     string    `json:"token" binding:"required"`
-	ExpiresAt time.Time `json:"expires_at" binding:"required"`
-	InvitedBy uuid.UUID `json:"invited_by" binding:"required"`
+	Email string `json:"email" binding:"required,email"`
+	Role  Role   `json:"role" binding:"required,oneof=admin driver customer"`
 }
 
-func (r *CreateInviteRequest) ToInvite() *Invite {
+// ToInvite builds the invite; the token and expiry are filled in by the use case.
+func (r *CreateInviteRequest) ToInvite(invitedBy uuid.UUID) *Invite {
 	return &Invite{
-		ID:        r.ID,
-		Email:     r.Email,
+		Email:     strings.ToLower(strings.TrimSpace(r.Email)),
 		Role:      r.Role,
-		Token:     r.Token,
-		ExpiresAt: r.ExpiresAt,
-		InvitedBy: r.InvitedBy,
+		InvitedBy: invitedBy,
 	}
 }
+
+type AcceptInviteRequest struct {
+	Token    string `json:"token" binding:"required"`
+	FullName string `json:"fullName" binding:"required"`
+	Password string `json:"password" binding:"required"` //raw password from client
+	Phone    string `json:"phone" binding:"required"`
+}
+
+// InvitePreview is what the public accept page may learn about an invite before accepting it.
+type InvitePreview struct {
+	Email     string    `json:"email"`
+	Role      Role      `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,10 @@
+package lockout
+
+import (
+	"context"
+	"logistics-backend/internal/domain/notification"
+)
+
+type NotificationWriter interface {
+	Create(ctx context.Context, n *notification.Notification) error
+}
@@ -0,0 +1,14 @@
+package lockout
+
+import "errors"
+
+// Machine readable codes returned to clients that are being throttled.
+const (
+	CodeAccountLocked   = "account_locked"
+	CodeTooManyAttempts = "too_many_attempts"
+)
+
+var (
+	ErrAccountLocked   = errors.New("account temporarily locked after too many failed logins")
+	ErrTooManyAttempts = errors.New("too many failed logins from this address")
+)
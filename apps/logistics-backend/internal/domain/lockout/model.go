@@ -0,0 +1,23 @@
+package lockout
+
+import "time"
+
+type Scope string
+
+const (
+	Account Scope = "account" // keyed by the lower-cased login email, known or not
+	IP      Scope = "ip"      // keyed by the client IP
+)
+
+// Failures counts recent failed logins for one account or client IP.
+type Failures struct {
+	Scope        Scope      `db:"scope" json:"scope"`
+	Key          string     `db:"key" json:"key"`
+	Count        int        `db:"failures" json:"failures"`
+	LastFailedAt time.Time  `db:"last_failed_at" json:"last_failed_at"`
+	LockedUntil  *time.Time `db:"locked_until" json:"locked_until,omitempty"`
+}
+
+func (f *Failures) IsLocked(now time.Time) bool {
+	return f.LockedUntil != nil && now.Before(*f.LockedUntil)
+}
@@ -0,0 +1,14 @@
+package lockout
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Get(ctx context.Context, scope Scope, key string) (*Failures, error)                                 // GET
+	RecordFailure(ctx context.Context, scope Scope, key string, window time.Duration) (*Failures, error) // POST/PATCH, restarts the count once the window has passed
+	Lock(ctx context.Context, scope Scope, key string, until time.Time) error                            // PATCH, also restarts the count
+	Reset(ctx context.Context, scope Scope, key string) error                                            // DELETE
+	DeleteStale(ctx context.Context, before time.Time) (int64, error)                                    // DELETE unlocked rows last failed before the cutoff
+}
@@ -0,0 +1,17 @@
+package mfa
+
+import (
+	"context"
+	"logistics-backend/internal/domain/notification"
+	"logistics-backend/internal/domain/user"
+
+	"github.com/google/uuid"
+)
+
+type UserReader interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*user.User, error)
+}
+
+type NotificationWriter interface {
+	Create(ctx context.Context, n *notification.Notification) error
+}
@@ -0,0 +1,11 @@
+package mfa
+
+import "errors"
+
+var (
+	ErrAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrNotEnrolled      = errors.New("two-factor authentication is not set up")
+	ErrInvalidCode      = errors.New("invalid authentication code")
+	ErrInvalidChallenge = errors.New("invalid or expired login challenge")
+	ErrRequiredByPolicy = errors.New("two-factor authentication is required for this role")
+)
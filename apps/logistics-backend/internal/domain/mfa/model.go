@@ -0,0 +1,58 @@
+package mfa
+
+import (
+	"time"
+
+	"logistics-backend/internal/domain/user"
+
+	"github.com/google/uuid"
+)
+
+// TOTP is a user's authenticator secret; it only protects logins once confirmed.
+type TOTP struct {
+	UserID       uuid.UUID  `db:"user_id" json:"user_id"`
+	Secret       string     `db:"secret" json:"-"`
+	ConfirmedAt  *time.Time `db:"confirmed_at" json:"confirmed_at,omitempty"`
+	LastUsedStep int64      `db:"last_used_step" json:"-"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
+
+func (t *TOTP) IsConfirmed() bool {
+	return t.ConfirmedAt != nil
+}
+
+// Challenge is handed out by LoginUser after a correct password, in place of tokens,
+// and exchanged for tokens together with a TOTP or recovery code.
+type Challenge struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	UserID    uuid.UUID  `db:"user_id" json:"user_id"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	Attempts  int        `db:"attempts" json:"attempts"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+func (c *Challenge) IsUsable(now time.Time, maxAttempts int) bool {
+	return c.UsedAt == nil && now.Before(c.ExpiresAt) && c.Attempts < maxAttempts
+}
+
+// Enrollment is shown once, so the user can add the secret to an authenticator app.
+type Enrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // render as a QR code
+}
+
+// Policy lists the roles that must have 2FA enabled.
+type Policy struct {
+	RequiredRoles []user.Role
+}
+
+func (p Policy) Requires(role user.Role) bool {
+	for _, r := range p.RequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
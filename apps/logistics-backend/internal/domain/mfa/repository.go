@@ -0,0 +1,24 @@
+package mfa
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	UpsertTOTP(ctx context.Context, t *TOTP) error                        // POST, replaces an unconfirmed secret
+	GetTOTP(ctx context.Context, userID uuid.UUID) (*TOTP, error)         // GET
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64) error  // PATCH
+	UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error  // PATCH, fails for a step at or before the last used one
+	DeleteTOTP(ctx context.Context, userID uuid.UUID) error               // DELETE, also drops recovery codes
+	SetEnabled(ctx context.Context, userID uuid.UUID, enabled bool) error // PATCH users.mfa_enabled
+
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes []string) error // DELETE + POST
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) error          // PATCH, fails if unknown or used
+
+	CreateChallenge(ctx context.Context, c *Challenge) error                      // POST
+	GetChallengeByHash(ctx context.Context, tokenHash string) (*Challenge, error) // GET
+	IncrementChallengeAttempts(ctx context.Context, id uuid.UUID) error           // PATCH
+	MarkChallengeUsed(ctx context.Context, id uuid.UUID) error                    // PATCH, fails if already used
+}
@@ -0,0 +1,24 @@
+package mfa
+
+import "time"
+
+type CodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// VerifyLoginRequest completes a login with either a TOTP code or a recovery code.
+type VerifyLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code,omitempty"`
+	RecoveryCode   string `json:"recovery_code,omitempty"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"` // shown once, store them somewhere safe
+}
+
+type ChallengeResponse struct {
+	MFARequired    bool      `json:"mfa_required"`
+	ChallengeToken string    `json:"challenge_token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
@@ -0,0 +1,14 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// User contact details, so notifications reach the user's real email, phone or device, and
+// tenant membership, so admins only notify users within their own store.
+type ContactReader interface {
+	GetContact(ctx context.Context, userID uuid.UUID) (*Contact, error)
+	BelongsToOwner(ctx context.Context, userID, ownerID uuid.UUID) (bool, error)
+}
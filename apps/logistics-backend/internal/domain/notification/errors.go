@@ -0,0 +1,21 @@
+package notification
+
+import "errors"
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrRecipientNotFound    = errors.New("recipient not found")
+	ErrNoAddress            = errors.New("recipient has no address for this channel")
+	ErrChannelUnavailable   = errors.New("no sender configured for this channel")
+	ErrUnknownEvent         = errors.New("unknown notification event")
+	ErrUnsupportedLocale    = errors.New("unsupported locale")
+	ErrInvalidTemplate      = errors.New("invalid notification template")
+	ErrTemplateNotFound     = errors.New("notification template not found")
+	ErrInvalidPreference    = errors.New("invalid notification preference")
+	ErrMandatoryEvent       = errors.New("security notifications cannot be turned off")
+	ErrInvalidInboxFilter   = errors.New("invalid inbox filter")
+	ErrInvalidCursor        = errors.New("invalid cursor")
+	ErrNoIDs                = errors.New("no notification ids given")
+	ErrMuted                = errors.New("recipient turned this channel off")             // permanent, not retried
+	ErrUndeliverable        = errors.New("message cannot be delivered to this recipient") // permanent, not retried
+)
@@ -0,0 +1,97 @@
+package notification
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InboxState filters the inbox by what the user did with a notification.
+type InboxState string
+
+const (
+	Unread   InboxState = "unread"
+	Seen     InboxState = "read"
+	Archived InboxState = "archived"
+)
+
+const (
+	DefaultInboxLimit = 20
+	MaxInboxLimit     = 100
+)
+
+// InboxFilter selects a page of the user's inbox. Archived notifications are only listed when
+// State is Archived; an empty Type lists every channel.
+type InboxFilter struct {
+	State  InboxState
+	Type   NotificationType
+	Limit  int
+	Cursor *InboxCursor
+}
+
+// InboxCursor points at the last notification of the previous page; pages run newest first.
+type InboxCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+type InboxPage struct {
+	Items      []*Notification `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+func (f *InboxFilter) Validate() error {
+	switch f.State {
+	case "", Unread, Seen, Archived:
+	default:
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidInboxFilter, f.State)
+	}
+	switch f.Type {
+	case "", System, Email, SMS, Push:
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidInboxFilter, f.Type)
+	}
+
+	if f.Limit <= 0 {
+		f.Limit = DefaultInboxLimit
+	}
+	f.Limit = min(f.Limit, MaxInboxLimit)
+	return nil
+}
+
+// Encode makes the cursor opaque to clients.
+func (c *InboxCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeInboxCursor(s string) (*InboxCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &InboxCursor{CreatedAt: createdAt, ID: uid}, nil
+}
+
+// IDsRequest names notifications in the caller's inbox for a bulk action.
+type IDsRequest struct {
+	IDs []uuid.UUID `json:"ids"`
+}
@@ -15,19 +15,56 @@ const (
 	Push   NotificationType = "push"
 	System NotificationType = "system" // for in-app or placeholder
 
-	Pending NotificationStatus = "pending"
-	Sent    NotificationStatus = "sent"
-	Failed  NotificationStatus = "failed"
-	Read    NotificationStatus = "read"
+	Pending    NotificationStatus = "pending"
+	Sent       NotificationStatus = "sent"
+	Delivered  NotificationStatus = "delivered"  // receipt from the carrier, SMS only
+	Failed     NotificationStatus = "failed"     // last attempt failed, retried at NextAttemptAt
+	Dead       NotificationStatus = "dead"       // gave up after the maximum number of attempts
+	Suppressed NotificationStatus = "suppressed" // not sent, the recipient turned the channel off after it was queued
+	Read       NotificationStatus = "read"       // legacy; reads are recorded in ReadAt and leave the delivery status alone
 )
 
 type Notification struct {
 	ID        uuid.UUID          `db:"id" json:"id"`
-	UserID    uuid.UUID          `db:"user_id" json:"user_id"`
+	UserID    uuid.UUID          `db:"user_id" json:"user_id"`               // zero for recipient-only notifications
+	Recipient *string            `db:"recipient" json:"recipient,omitempty"` // overrides the user's own address, e.g. for invitees
 	Message   string             `db:"message" json:"message"`
 	Type      NotificationType   `db:"type" json:"type"`
 	Status    NotificationStatus `db:"status" json:"status"`
 	SentAt    time.Time          `db:"sent_at" json:"sent_at"`
 	UpdatedAt time.Time          `db:"updated_at" json:"updated_at"`
 	CreatedAt time.Time          `db:"created_at" json:"created_at"`
+
+	Attempts      int        `db:"attempts" json:"attempts"`
+	NextAttemptAt *time.Time `db:"next_attempt_at" json:"next_attempt_at,omitempty"`
+	LastError     *string    `db:"last_error" json:"last_error,omitempty"`
+	ProviderRef   *string    `db:"provider_ref" json:"provider_ref,omitempty"` // gateway message ID, matched by delivery receipts
+
+	// Inbox state, set by the user
+	ReadAt     *time.Time `db:"read_at" json:"read_at,omitempty"`
+	ArchivedAt *time.Time `db:"archived_at" json:"archived_at,omitempty"`
+
+	// Event and Data render the message per channel and locale; empty for free-text notifications
+	Event Event        `db:"event" json:"event,omitempty"`
+	Data  TemplateData `db:"data" json:"data,omitempty"`
+}
+
+// DeliveryReport is a gateway's receipt for a sent message. Status is Delivered or Failed;
+// intermediate states (queued, buffered) are not reported.
+type DeliveryReport struct {
+	ProviderRef string
+	Status      NotificationStatus
+	Reason      string
+}
+
+// Contact is where a user can be reached on each channel; empty fields are unreachable.
+type Contact struct {
+	Name        string  `db:"full_name"`
+	Email       string  `db:"email"`
+	Phone       string  `db:"phone"`
+	DeviceToken *string `db:"device_token"`
+	Locale      string  `db:"locale"`
+
+	// Tenant is the store owner whose template overrides apply; nil for users outside any store
+	Tenant *uuid.UUID `db:"tenant_id"`
 }
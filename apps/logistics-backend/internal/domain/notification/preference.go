@@ -0,0 +1,189 @@
+package notification
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// AllEvents in a preference applies it to every event without a preference of its own.
+const AllEvents Event = "*"
+
+// DefaultTimezone is used for quiet hours saved without a timezone.
+const DefaultTimezone = "Africa/Nairobi"
+
+// Channels a user can turn on or off, in the order notifications fan out to them.
+var Channels = []NotificationType{System, Email, SMS, Push}
+
+// mandatory events concern account security or carry a time-limited link; they are always sent
+// on the channel their producer chose and are not held back by quiet hours.
+var mandatory = []Event{
+	EventEmailVerification,
+	EventPasswordResetRequested,
+	EventPasswordReset,
+	EventPasswordChanged,
+	EventAccountLocked,
+	EventMFAEnabled,
+	EventMFADisabled,
+	EventMFARecoveryCodeUsed,
+	EventInviteSent,
+}
+
+func (e Event) Mandatory() bool {
+	return slices.Contains(mandatory, e)
+}
+
+// Preference turns one channel on or off for an event, or for AllEvents.
+type Preference struct {
+	Event   Event            `db:"event" json:"event"`
+	Channel NotificationType `db:"channel" json:"channel"`
+	Enabled bool             `db:"enabled" json:"enabled"`
+}
+
+// QuietHours hold back notifications outside the in-app inbox between Start and End ("15:04")
+// in the user's timezone. A window may cross midnight, e.g. 22:00 to 07:00.
+type QuietHours struct {
+	Start    string `db:"start_time" json:"start"`
+	End      string `db:"end_time" json:"end"`
+	Timezone string `db:"timezone" json:"timezone"`
+}
+
+// Settings are a user's notification preferences.
+type Settings struct {
+	Preferences []Preference `json:"preferences"`
+	QuietHours  *QuietHours  `json:"quiet_hours,omitempty"`
+}
+
+// lookup returns the user's choice for a channel, preferring the event's own preference over AllEvents.
+func (s *Settings) lookup(event Event, channel NotificationType) (enabled, set bool) {
+	for _, e := range []Event{event, AllEvents} {
+		for _, p := range s.Preferences {
+			if p.Event == e && p.Channel == channel {
+				return p.Enabled, true
+			}
+		}
+	}
+	return false, false
+}
+
+// Channels returns the channels an event goes out on for this user: the producer's channel
+// unless turned off, plus any channel the user turned on. Free-text notifications and
+// mandatory events always keep the producer's channel.
+func (s *Settings) Channels(event Event, def NotificationType) []NotificationType {
+	if event == "" {
+		return []NotificationType{def}
+	}
+
+	var channels []NotificationType
+	for _, ch := range append([]NotificationType{def}, Channels...) {
+		if slices.Contains(channels, ch) {
+			continue
+		}
+		enabled, set := s.lookup(event, ch)
+		if !set {
+			enabled = ch == def
+		}
+		if enabled || (ch == def && event.Mandatory()) {
+			channels = append(channels, ch)
+		}
+	}
+	return channels
+}
+
+// Muted reports whether the user has since turned off the channel a queued notification is for.
+func (s *Settings) Muted(n *Notification) bool {
+	if n.Event == "" || n.Event.Mandatory() || n.Recipient != nil {
+		return false
+	}
+	enabled, set := s.lookup(n.Event, n.Type)
+	return set && !enabled
+}
+
+// Validate checks the preferences refer to known events and channels, leave mandatory events
+// alone and that the quiet hours parse.
+func (s *Settings) Validate() error {
+	seen := make(map[Preference]bool, len(s.Preferences))
+	for _, p := range s.Preferences {
+		if _, ok := EventVars[p.Event]; !ok && p.Event != AllEvents {
+			return fmt.Errorf("%w: %s", ErrUnknownEvent, p.Event)
+		}
+		if !slices.Contains(Channels, p.Channel) {
+			return fmt.Errorf("%w: unknown channel %q", ErrInvalidPreference, p.Channel)
+		}
+		if p.Event.Mandatory() && !p.Enabled {
+			return fmt.Errorf("%w: %s", ErrMandatoryEvent, p.Event)
+		}
+		key := Preference{Event: p.Event, Channel: p.Channel}
+		if seen[key] {
+			return fmt.Errorf("%w: %s/%s listed twice", ErrInvalidPreference, p.Event, p.Channel)
+		}
+		seen[key] = true
+	}
+
+	if q := s.QuietHours; q != nil {
+		if q.Timezone == "" {
+			q.Timezone = DefaultTimezone
+		}
+		start, errStart := parseClock(q.Start)
+		end, errEnd := parseClock(q.End)
+		if errStart != nil || errEnd != nil {
+			return fmt.Errorf("%w: quiet hours must be HH:MM", ErrInvalidPreference)
+		}
+		if start == end {
+			return fmt.Errorf("%w: quiet hours start and end at the same time", ErrInvalidPreference)
+		}
+		if _, err := time.LoadLocation(q.Timezone); err != nil {
+			return fmt.Errorf("%w: unknown timezone %q", ErrInvalidPreference, q.Timezone)
+		}
+		q.Start, q.End = formatClock(start), formatClock(end)
+	}
+	return nil
+}
+
+// Until reports whether now falls within the quiet hours and, if so, when they end.
+func (q *QuietHours) Until(now time.Time) (time.Time, bool) {
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return time.Time{}, false
+	}
+	start, errStart := parseClock(q.Start)
+	end, errEnd := parseClock(q.End)
+	if errStart != nil || errEnd != nil || start == end {
+		return time.Time{}, false
+	}
+
+	local := now.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	at := local.Sub(midnight)
+
+	var quiet bool
+	if start < end {
+		quiet = at >= start && at < end
+	} else { // crosses midnight
+		quiet = at >= start || at < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := midnight.Add(end)
+	if at >= end {
+		until = midnight.AddDate(0, 0, 1).Add(end)
+	}
+	return until, true
+}
+
+// parseClock accepts "15:04" and the "15:04:05" Postgres returns for TIME columns.
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		if t, err = time.Parse("15:04:05", s); err != nil {
+			return 0, err
+		}
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -2,52 +2,124 @@ package notification
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Repository interface {
 	Create(ctx context.Context, notification *Notification) error
-	UpdateStatus(ctx context.Context, id uuid.UUID, status NotificationStatus) error
-	ListPending(ctx context.Context) ([]*Notification, error)
+	CreateIfAbsent(ctx context.Context, notification *Notification) error                     // keyed by ID, for outbox redelivery
+	UpdateStatus(ctx context.Context, id, ownerID uuid.UUID, status NotificationStatus) error // within the owner's tenant
+	ListPending(ctx context.Context, ownerID uuid.UUID) ([]*Notification, error)              // tenant's queue, without event data
 	ListByUserAndStatus(ctx context.Context, userID uuid.UUID, status NotificationStatus) ([]*Notification, error)
 	UpdateAllAsRead(ctx context.Context, userID uuid.UUID) error
+
+	// Inbox, always scoped to the owner; bulk actions return how many of ids were the user's
+	ListInbox(ctx context.Context, userID uuid.UUID, filter InboxFilter) ([]*Notification, error)
+	CountUnread(ctx context.Context, userID uuid.UUID, typ NotificationType) (int, error)
+	MarkRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error)
+	Archive(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error)
+	Delete(ctx context.Context, userID uuid.UUID, id uuid.UUID) error
+
+	// Delivery worker
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*Notification, error)
+	MarkSent(ctx context.Context, id uuid.UUID, providerRef *string) error
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string, retryAt time.Time, dead bool) error
+	MarkSuppressed(ctx context.Context, id uuid.UUID, reason string) error
+	Defer(ctx context.Context, id uuid.UUID, until time.Time) error // back to pending without using up an attempt
+
+	// Delivery receipts
+	GetByProviderRef(ctx context.Context, providerRef string) (*Notification, error)
+	MarkDelivered(ctx context.Context, id uuid.UUID) error
+}
+
+// Template overrides edited by admins; built-in templates live in code.
+type TemplateRepository interface {
+	ListOverrides(ctx context.Context, ownerID uuid.UUID) ([]*Template, error)                                         // GET
+	ListOverridesForEvent(ctx context.Context, ownerID uuid.UUID, event Event) ([]*Template, error)                    // GET
+	UpsertOverride(ctx context.Context, t *Template) error                                                             // PUT
+	DeleteOverride(ctx context.Context, ownerID uuid.UUID, event Event, locale string, channel NotificationType) error // DELETE
+}
+
+// Per-user preferences; users without any get the producers' defaults.
+type PreferenceRepository interface {
+	GetSettings(ctx context.Context, userID uuid.UUID) (*Settings, error)            // GET
+	ReplaceSettings(ctx context.Context, userID uuid.UUID, settings *Settings) error // PUT, run in a transaction
 }
 
 // Sender defines a generic interface for sending notifications.
 // Concrete implementations (Twilio, SendGrid, Firebase, etc.)
 // will satisfy this interface.
+// Send delivers msg, the notification rendered for its channel, and returns the provider's
+// message ID when the channel reports delivery receipts.
 type Sender interface {
-	Send(ctx context.Context, n *Notification) error
+	Send(ctx context.Context, n *Notification, to *Contact, msg *Message) (providerRef string, err error)
 }
 
+// EmailSender sends a multipart text and HTML email; an empty html is derived from text.
 type EmailSender interface {
-	SendEmail(ctx context.Context, to string, subject string, body string) error
+	SendEmail(ctx context.Context, to string, subject string, text string, html string) error
 }
 
+// SMSSender returns the gateway's message ID, which its delivery receipts refer to.
 type SMSSender interface {
-	SendSMS(ctx context.Context, phone string, message string) error
+	SendSMS(ctx context.Context, phone string, message string) (string, error)
 }
 
 type PushSender interface {
 	SendPush(ctx context.Context, deviceToken string, title string, message string) error
 }
 
+// MultiChannelSender routes a notification to the sender for its type.
+// Channels without a sender fail with ErrChannelUnavailable.
 type MultiChannelSender struct {
 	emailSender EmailSender
 	smsSender   SMSSender
 	pushSender  PushSender
 }
 
-func (s *MultiChannelSender) Send(ctx context.Context, n *Notification) error {
+func NewMultiChannelSender(email EmailSender, sms SMSSender, push PushSender) *MultiChannelSender {
+	return &MultiChannelSender{emailSender: email, smsSender: sms, pushSender: push}
+}
+
+func (s *MultiChannelSender) Send(ctx context.Context, n *Notification, to *Contact, msg *Message) (string, error) {
 	switch n.Type {
 	case Email:
-		return s.emailSender.SendEmail(ctx, n.UserID.String(), "Notification", n.Message)
+		if s.emailSender == nil {
+			return "", ErrChannelUnavailable
+		}
+		addr := address(n, to.Email)
+		if addr == "" {
+			return "", ErrNoAddress
+		}
+		return "", s.emailSender.SendEmail(ctx, addr, msg.Subject, msg.Text, msg.HTML)
 	case SMS:
-		return s.smsSender.SendSMS(ctx, n.UserID.String(), n.Message)
+		if s.smsSender == nil {
+			return "", ErrChannelUnavailable
+		}
+		addr := address(n, to.Phone)
+		if addr == "" {
+			return "", ErrNoAddress
+		}
+		return s.smsSender.SendSMS(ctx, addr, msg.Text)
 	case Push:
-		return s.pushSender.SendPush(ctx, n.UserID.String(), "Notification", n.Message)
+		if s.pushSender == nil {
+			return "", ErrChannelUnavailable
+		}
+		if to.DeviceToken == nil || *to.DeviceToken == "" {
+			return "", ErrNoAddress
+		}
+		return "", s.pushSender.SendPush(ctx, *to.DeviceToken, msg.Subject, msg.Text)
 	default:
-		return nil // or mark as system
+		return "", nil // system notifications only live in the in-app inbox
+	}
+}
+
+// address prefers the notification's own recipient (e.g. an invitee's email) over the user's.
+func address(n *Notification, own string) string {
+	if n.Recipient != nil && *n.Recipient != "" {
+		return *n.Recipient
 	}
+	return own
 }
@@ -16,6 +16,24 @@ type UpdateNotificationStatusRequest struct {
 	Status NotificationStatus `json:"status"`
 }
 
+// SaveTemplateRequest overrides the template for one event, locale and channel.
+type SaveTemplateRequest struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	HTML    string `json:"html,omitempty"`
+}
+
+func (r *SaveTemplateRequest) ToTemplate(event Event, locale string, channel NotificationType) *Template {
+	return &Template{
+		Event:   event,
+		Locale:  locale,
+		Channel: channel,
+		Subject: r.Subject,
+		Body:    r.Body,
+		HTML:    r.HTML,
+	}
+}
+
 func (r *CreateNotificationRequest) ToNotification() *Notification {
 	return &Notification{
 		UserID:  r.UserID,
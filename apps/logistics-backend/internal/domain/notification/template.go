@@ -0,0 +1,178 @@
+package notification
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event names what happened; each event has its own set of templates.
+type Event string
+
+const (
+	EventUserRegistered         Event = "user.registered"
+	EventUserProfileUpdated     Event = "user.profile_updated"
+	EventUserAccountUpdated     Event = "user.account_updated"
+	EventUserDeleted            Event = "user.deleted"
+	EventEmailVerification      Event = "user.email_verification"
+	EventEmailVerified          Event = "user.email_verified"
+	EventPasswordResetRequested Event = "user.password_reset_requested"
+	EventPasswordReset          Event = "user.password_reset"
+	EventPasswordChanged        Event = "user.password_changed"
+	EventAccountLocked          Event = "user.account_locked"
+
+	EventMFAEnabled          Event = "mfa.enabled"
+	EventMFADisabled         Event = "mfa.disabled"
+	EventMFARecoveryCodeUsed Event = "mfa.recovery_code_used"
+
+	EventInviteSent     Event = "invite.sent"
+	EventInviteAccepted Event = "invite.accepted"
+
+	EventDriverRegistered          Event = "driver.registered"
+	EventDriverProfileUpdated      Event = "driver.profile_updated"
+	EventDriverAccountUpdated      Event = "driver.account_updated"
+	EventDriverAvailabilityChanged Event = "driver.availability_changed"
+	EventDriverDeleted             Event = "driver.deleted"
+
+	EventDocumentUploaded Event = "document.uploaded"
+	EventDocumentReviewed Event = "document.reviewed"
+	EventDocumentExpiring Event = "document.expiring"
+	EventDocumentExpired  Event = "document.expired"
+
+	EventOrderCreated        Event = "order.created"
+	EventOrderAssigned       Event = "order.assigned"
+	EventOrderAssignedDriver Event = "order.assigned_driver"
+
+	EventDeliveryUpdated   Event = "delivery.updated"
+	EventDeliveryInTransit Event = "delivery.in_transit"
+	EventDeliveryAccepted  Event = "delivery.accepted"
+
+	EventInventoryCreated  Event = "inventory.created"
+	EventInventoryUpdated  Event = "inventory.updated"
+	EventInventoryDeleted  Event = "inventory.deleted"
+	EventInventoryLowStock Event = "inventory.low_stock"
+
+	EventPaymentReceived Event = "payment.received"
+	EventPaymentFailed   Event = "payment.failed"
+
+	EventWebhookDisabled Event = "webhook.disabled"
+)
+
+// EventVars lists the variables each event supplies. Templates may only use these, which is
+// checked for the built-in templates at startup and for overrides when they are saved.
+var EventVars = map[Event][]string{
+	EventUserRegistered:         {"name", "role"},
+	EventUserProfileUpdated:     {},
+	EventUserAccountUpdated:     {"field"},
+	EventUserDeleted:            {"name"},
+	EventEmailVerification:      {"name", "link", "ttl"},
+	EventEmailVerified:          {},
+	EventPasswordResetRequested: {"link", "ttl"},
+	EventPasswordReset:          {},
+	EventPasswordChanged:        {},
+	EventAccountLocked:          {"until", "attempts"},
+
+	EventMFAEnabled:          {},
+	EventMFADisabled:         {},
+	EventMFARecoveryCodeUsed: {},
+
+	EventInviteSent:     {"role", "expires", "link"},
+	EventInviteAccepted: {"name", "role"},
+
+	EventDriverRegistered:          {"name"},
+	EventDriverProfileUpdated:      {},
+	EventDriverAccountUpdated:      {"field"},
+	EventDriverAvailabilityChanged: {"status"},
+	EventDriverDeleted:             {},
+
+	EventDocumentUploaded: {"document"},
+	EventDocumentReviewed: {"document", "status"},
+	EventDocumentExpiring: {"document", "number", "days", "date"},
+	EventDocumentExpired:  {"document", "number", "date"},
+
+	EventOrderCreated:        {"order_id"},
+	EventOrderAssigned:       {"order_id", "driver_name"},
+	EventOrderAssignedDriver: {"order_id"},
+
+	EventDeliveryUpdated:   {"order_id", "field"},
+	EventDeliveryInTransit: {"order_id", "driver_name"},
+	EventDeliveryAccepted:  {"order_id"},
+
+	EventInventoryCreated:  {"item", "stock"},
+	EventInventoryUpdated:  {"item", "field"},
+	EventInventoryDeleted:  {"item"},
+	EventInventoryLowStock: {"item", "stock"},
+
+	EventPaymentReceived: {"order_id", "amount", "receipt"},
+	EventPaymentFailed:   {"order_id", "reason"},
+
+	EventWebhookDisabled: {"url", "failures"},
+}
+
+// Supported locales; DefaultLocale is the fallback and must have a template for every event.
+const (
+	English = "en"
+	Swahili = "sw"
+
+	DefaultLocale = English
+)
+
+var Locales = []string{English, Swahili}
+
+func IsSupportedLocale(locale string) bool {
+	return slices.Contains(Locales, locale)
+}
+
+// TemplateData holds an event's variables; stored as JSONB with the notification so every
+// channel can be rendered from it later.
+type TemplateData map[string]string
+
+func (d TemplateData) Value() (driver.Value, error) {
+	if d == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(d)
+}
+
+func (d *TemplateData) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*d = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, d)
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	default:
+		return fmt.Errorf("cannot scan %T into TemplateData", src)
+	}
+}
+
+// Template is the text for one event, locale and channel. Templates for the System channel are
+// the in-app text and the fallback for channels without their own variant. Subject doubles as
+// the push title; HTML is only used for email.
+type Template struct {
+	Event   Event            `db:"event" json:"event"`
+	Locale  string           `db:"locale" json:"locale"`
+	Channel NotificationType `db:"channel" json:"channel"`
+	Subject string           `db:"subject" json:"subject"`
+	Body    string           `db:"body" json:"body"`
+	HTML    string           `db:"html" json:"html,omitempty"`
+
+	// set on database overrides only
+	OwnerID   uuid.UUID  `db:"owner_id" json:"-"`
+	UpdatedBy *uuid.UUID `db:"updated_by" json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `db:"updated_at" json:"updated_at,omitempty"`
+	Override  bool       `db:"-" json:"override"`
+}
+
+// Message is a notification rendered for one channel.
+type Message struct {
+	Subject string
+	Text    string
+	HTML    string // email only; empty lets the sender derive it from Text
+}
@@ -3,8 +3,8 @@ package order
 import (
 	"context"
 	"logistics-backend/internal/domain/driver"
+	"logistics-backend/internal/domain/event"
 	"logistics-backend/internal/domain/inventory"
-	"logistics-backend/internal/domain/notification"
 	"logistics-backend/internal/domain/store"
 
 	"github.com/cridenour/go-postgis"
@@ -17,22 +17,24 @@ import (
 type InventoryReader interface {
 	GetInventoryByID(ctx context.Context, id uuid.UUID) (*inventory.Inventory, error)
 	UpdateInventory(ctx context.Context, inventoryId uuid.UUID, column string, value any) error
-	GetAllInventories(ctx context.Context) ([]Inventory, error)
+	RestockInventory(ctx context.Context, inventoryId uuid.UUID, quantity int) error // joins the caller's transaction
+	GetAllInventories(ctx context.Context, ownerID uuid.UUID) ([]Inventory, error)
 }
 
 // Access the user domain usecase method for getting users of role customers.
 type CustomerReader interface {
-	GetAllCustomers(ctx context.Context) ([]Customer, error)
+	GetAllCustomers(ctx context.Context, ownerID uuid.UUID) ([]Customer, error)
 }
 
 type DriverReader interface {
 	GetNearestDriver(ctx context.Context, pickup postgis.PointS, maxDistance float64) (*driver.Driver, error)
 }
 
-type NotificationReader interface {
-	Create(ctx context.Context, n *notification.Notification) error
-}
-
 type StoreReader interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*store.Store, error)
 }
+
+// Publishes the domain's events once the transaction commits.
+type EventPublisher interface {
+	Publish(ctx context.Context, events ...event.Event) error
+}
@@ -6,4 +6,6 @@ var (
 	ErrorOutOfStock           = errors.New("product out of stock")
 	ErrorInvalidQuantity      = errors.New("invalid quantity")
 	ErrorQuantityExceedsStock = errors.New("ordered quantity exceeds available stock")
+	ErrorNotFound             = errors.New("order not found")
+	ErrorInventoryNotFound    = errors.New("inventory not found")
 )
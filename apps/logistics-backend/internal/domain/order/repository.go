@@ -8,12 +8,13 @@ import (
 )
 
 type Repository interface {
-	Create(ctx context.Context, order *Order) error                                // POST method to create order.
-	GetByID(ctx context.Context, id uuid.UUID) (*Order, error)                     // GET method for fetching order by id.
-	ListByCustomer(ctx context.Context, customerID uuid.UUID) ([]*Order, error)    // GET method for fetching all orders by customer id.
-	Update(ctx context.Context, orderID uuid.UUID, column string, value any) error // PATCH method to update specified column value in orders table.
-	List(ctx context.Context) ([]*Order, error)                                    // GET method for fetching all orders
-	Delete(ctx context.Context, id uuid.UUID) error                                // DELETE method for removing order by id
+	Create(ctx context.Context, order *Order) error                                           // POST method to create order.
+	GetByID(ctx context.Context, id uuid.UUID) (*Order, error)                                // GET method for fetching order by id.
+	ListByCustomer(ctx context.Context, customerID uuid.UUID) ([]*Order, error)               // GET method for fetching all orders by customer id.
+	Update(ctx context.Context, orderID uuid.UUID, column string, value any) error            // PATCH method to update specified column value in orders table.
+	ListByAdmin(ctx context.Context, adminID uuid.UUID, storeID *uuid.UUID) ([]*Order, error) // GET method for fetching all orders of one store owner, or of one of their stores
+	Delete(ctx context.Context, id uuid.UUID) error                                           // DELETE method for removing order by id
+	GetForUpdate(ctx context.Context, id uuid.UUID) (*Order, error)                           // GET the order and lock it until the transaction ends
 
 	GetPickupPoint(ctx context.Context, orderID uuid.UUID) (postgis.PointS, error)
 	GetDeliveryPoint(ctx context.Context, orderID uuid.UUID) (postgis.PointS, error)
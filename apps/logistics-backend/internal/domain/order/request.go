@@ -6,7 +6,7 @@ import (
 )
 
 type CreateOrderRequest struct {
-	AdminID     uuid.UUID `json:"admin_id" binding:"required"`
+	AdminID     uuid.UUID `json:"admin_id"` // ignored on create, set from the authenticated admin
 	Quantity    int       `json:"quantity" binding:"required"`
 	InventoryID uuid.UUID `json:"inventory_id" binding:"required"`
 	CustomerID  uuid.UUID `json:"customer_id" binding:"required"`
@@ -0,0 +1,20 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Handler carries out one topic's messages. A message may be handed over more than once
+// (e.g. the process dies before it is marked dispatched), so handlers use the message ID
+// to make repeats harmless.
+type Handler interface {
+	Handle(ctx context.Context, id uuid.UUID, payload []byte) error
+}
+
+type HandlerFunc func(ctx context.Context, id uuid.UUID, payload []byte) error
+
+func (f HandlerFunc) Handle(ctx context.Context, id uuid.UUID, payload []byte) error {
+	return f(ctx, id, payload)
+}
@@ -0,0 +1,7 @@
+package outbox
+
+import "errors"
+
+var (
+	ErrNoHandler = errors.New("no handler registered for outbox topic")
+)
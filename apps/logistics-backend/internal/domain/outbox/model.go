@@ -0,0 +1,35 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	Pending    Status = "pending"
+	Dispatched Status = "dispatched"
+	Dead       Status = "dead" // gave up after the maximum number of attempts
+)
+
+// Topics name what a message asks for; each has one registered handler.
+const (
+	TopicNotificationCreate = "notification.create"
+	TopicDomainEvent        = "event.dispatch" // one async subscriber's copy of a domain event
+)
+
+// Message is a side effect recorded together with the business write that caused it.
+type Message struct {
+	ID            uuid.UUID       `db:"id" json:"id"`
+	Topic         string          `db:"topic" json:"topic"`
+	Payload       json.RawMessage `db:"payload" json:"payload"`
+	Status        Status          `db:"status" json:"status"`
+	Attempts      int             `db:"attempts" json:"attempts"`
+	NextAttemptAt time.Time       `db:"next_attempt_at" json:"next_attempt_at"`
+	LastError     *string         `db:"last_error" json:"last_error,omitempty"`
+	DispatchedAt  *time.Time      `db:"dispatched_at" json:"dispatched_at,omitempty"`
+	CreatedAt     time.Time       `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	Add(ctx context.Context, m *Message) error                                                       // POST, joins the transaction in ctx
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*Message, error)                // PATCH, hides the claimed messages for the lease
+	MarkDispatched(ctx context.Context, id uuid.UUID) error                                          // PATCH
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string, retryAt time.Time, dead bool) error // PATCH
+	DeleteDispatched(ctx context.Context, before time.Time) (int64, error)                           // DELETE
+}
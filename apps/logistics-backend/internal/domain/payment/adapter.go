@@ -0,0 +1,47 @@
+package payment
+
+import (
+	"context"
+	"logistics-backend/internal/domain/delivery"
+	"logistics-backend/internal/domain/event"
+	"logistics-backend/internal/domain/inventory"
+	"logistics-backend/internal/domain/order"
+
+	"github.com/google/uuid"
+)
+
+type OrderReader interface {
+	GetOrderByID(ctx context.Context, id uuid.UUID) (*order.Order, error)
+}
+
+type DeliveryReader interface {
+	GetDeliveryByID(ctx context.Context, id uuid.UUID) (*delivery.Delivery, error)
+}
+
+type InventoryReader interface {
+	GetInventoryByID(ctx context.Context, id uuid.UUID) (*inventory.Inventory, error)
+}
+
+// Provider collects payments of one method through an external gateway. Initiate only starts
+// the payment; the gateway reports the outcome later through its callback.
+type Provider interface {
+	Method() PaymentMethod
+	Initiate(ctx context.Context, c *Charge) (*Initiation, error)
+}
+
+// Resumer is a Provider whose pending payments the customer can pick up again, e.g. to retry
+// a declined card on the same Stripe PaymentIntent.
+type Resumer interface {
+	Resume(ctx context.Context, p *Payment) (*Initiation, error)
+}
+
+// Refunder is a Provider that returns money through its gateway; it returns the gateway's
+// refund ID. Refunds of other methods are only recorded.
+type Refunder interface {
+	Refund(ctx context.Context, p *Payment, r *Refund) (string, error)
+}
+
+// Publishes the domain's events once the transaction commits.
+type EventPublisher interface {
+	Publish(ctx context.Context, events ...event.Event) error
+}
@@ -0,0 +1,82 @@
+package payment
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CashReportTimezone is the default day boundary for cash reports; a driver's shift is one
+// calendar day there.
+const CashReportTimezone = "Africa/Nairobi"
+
+type RemittanceStatus string
+
+const (
+	RemittancePending   RemittanceStatus = "pending"
+	RemittanceConfirmed RemittanceStatus = "confirmed"
+)
+
+// CashCollection is cash a driver took from the customer for a cash-on-delivery payment. The
+// cash belongs to the order's store owner (AdminID) until the driver hands it in.
+type CashCollection struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	PaymentID   uuid.UUID `db:"payment_id" json:"payment_id"`
+	OrderID     uuid.UUID `db:"order_id" json:"order_id"`
+	DeliveryID  uuid.UUID `db:"delivery_id" json:"delivery_id"`
+	DriverID    uuid.UUID `db:"driver_id" json:"driver_id"`
+	AdminID     uuid.UUID `db:"admin_id" json:"admin_id"`
+	Amount      int64     `db:"amount" json:"amount"`
+	Currency    string    `db:"currency" json:"currency"`
+	CollectedAt time.Time `db:"collected_at" json:"collected_at"`
+}
+
+// CashRemittance is cash a driver hands in to a store owner. The driver declares the amount
+// and the owner confirms what they counted; any difference is a shortfall.
+type CashRemittance struct {
+	ID             uuid.UUID        `db:"id" json:"id"`
+	DriverID       uuid.UUID        `db:"driver_id" json:"driver_id"`
+	AdminID        uuid.UUID        `db:"admin_id" json:"admin_id"`
+	Currency       string           `db:"currency" json:"currency"`
+	DeclaredAmount int64            `db:"declared_amount" json:"declared_amount"`
+	ReceivedAmount *int64           `db:"received_amount" json:"received_amount,omitempty"`
+	Status         RemittanceStatus `db:"status" json:"status"`
+	Note           *string          `db:"note" json:"note,omitempty"`
+	DeclaredAt     time.Time        `db:"declared_at" json:"declared_at"`
+	ConfirmedAt    *time.Time       `db:"confirmed_at" json:"confirmed_at,omitempty"`
+	ConfirmedBy    *uuid.UUID       `db:"confirmed_by" json:"confirmed_by,omitempty"`
+}
+
+// CashBalance is the cash a driver holds for one store owner in one currency. Only confirmed
+// remittances reduce it; Pending is declared but not yet counted.
+type CashBalance struct {
+	DriverID  uuid.UUID `db:"driver_id" json:"driver_id"`
+	AdminID   uuid.UUID `db:"admin_id" json:"admin_id"`
+	Currency  string    `db:"currency" json:"currency"`
+	Collected int64     `db:"collected" json:"collected"`
+	Remitted  int64     `db:"remitted" json:"remitted"`
+	Pending   int64     `db:"pending" json:"pending"`
+	InHand    int64     `db:"-" json:"in_hand"`
+}
+
+// CashDay is one driver's cash for one day. Remittances count on the day they were declared.
+type CashDay struct {
+	Day         string    `db:"day" json:"day"` // YYYY-MM-DD in the report's time zone
+	DriverID    uuid.UUID `db:"driver_id" json:"driver_id"`
+	Currency    string    `db:"currency" json:"currency"`
+	Collections int       `db:"collections" json:"collections"`
+	Collected   int64     `db:"collected" json:"collected"`
+	Declared    int64     `db:"declared" json:"declared"`
+	Received    int64     `db:"received" json:"received"`
+	Unconfirmed int64     `db:"unconfirmed" json:"unconfirmed"` // declared, not yet counted
+	Shortfall   int64     `db:"shortfall" json:"shortfall"`     // declared minus counted on confirmed handovers
+	Unremitted  int64     `db:"-" json:"unremitted"`            // collected minus counted; carried into later days
+}
+
+// CashReportQuery selects the days [From, To) of one store owner's cash, optionally for one driver.
+type CashReportQuery struct {
+	AdminID  uuid.UUID
+	DriverID *uuid.UUID
+	From, To time.Time
+	Location *time.Location
+}
@@ -0,0 +1,26 @@
+package payment
+
+import "errors"
+
+var (
+	ErrPaymentNotFound       = errors.New("payment not found")
+	ErrInvalidTransition     = errors.New("payment cannot make this transition")
+	ErrInvalidAmount         = errors.New("amount must be positive and within the payment amount")
+	ErrRefundExceedsCaptured = errors.New("refund exceeds the captured amount not yet refunded")
+	ErrMethodUnavailable     = errors.New("payment method is not available")
+	ErrOrderNotFound         = errors.New("order not found")
+	ErrOrderNotPayable       = errors.New("order can no longer be paid for")
+	ErrOrderAlreadyPaid      = errors.New("order has already been paid for")
+	ErrPaymentInProgress     = errors.New("a payment for this order is already in progress")
+	ErrInvalidPhone          = errors.New("invalid phone number")
+	ErrProvider              = errors.New("payment gateway rejected the request")
+
+	ErrDeliveryNotFound       = errors.New("delivery not found")
+	ErrDeliveryNotCollectable = errors.New("cash can only be collected for a delivery that has been picked up")
+	ErrCashAlreadyCollected   = errors.New("cash has already been collected for this order")
+	ErrCashAmountMismatch     = errors.New("collected amount does not match the amount due")
+	ErrRemittanceNotFound     = errors.New("remittance not found")
+	ErrRemittanceConfirmed    = errors.New("remittance has already been confirmed")
+	ErrExceedsCashInHand      = errors.New("amount exceeds the cash the driver holds")
+	ErrInvalidReportRange     = errors.New("report needs from and to dates (YYYY-MM-DD), from not after to, at most 92 days, and a valid timezone")
+)
@@ -15,17 +15,70 @@ const (
 	MethodMobileMoney    PaymentMethod = "mobile_money"
 	MethodCashOnDelivery PaymentMethod = "cash_on_delivery"
 
-	StatusPending   PaymentStatus = "pending"
-	StatusCompleted PaymentStatus = "completed"
-	StatusFailed    PaymentStatus = "failed"
+	StatusPending           PaymentStatus = "pending"
+	StatusAuthorized        PaymentStatus = "authorized" // funds held, not yet taken
+	StatusCaptured          PaymentStatus = "captured"   // funds taken, waiting for settlement
+	StatusCompleted         PaymentStatus = "completed"
+	StatusFailed            PaymentStatus = "failed"
+	StatusPartiallyRefunded PaymentStatus = "partially_refunded"
+	StatusRefunded          PaymentStatus = "refunded"
 )
 
+// Payment amounts are in the currency's minor unit (cents). CapturedAmount is what was
+// actually taken, which may be less than Amount; refunds never exceed it.
 type Payment struct {
-	ID       uuid.UUID     `db:"id" json:"id"`
-	OrderID  uuid.UUID     `db:"order_id" json:"order_id"`
-	Amount   int64         `db:"amount" json:"amount"`
-	Currency string        `db:"currency" json:"currency"`
-	Method   PaymentMethod `db:"method" json:"method"`
-	Status   PaymentStatus `db:"status" json:"status"`
-	PaidAt   time.Time     `db:"paid_at" json:"paid_at"`
+	ID             uuid.UUID     `db:"id" json:"id"`
+	OrderID        uuid.UUID     `db:"order_id" json:"order_id"`
+	Amount         int64         `db:"amount" json:"amount"`
+	Currency       string        `db:"currency" json:"currency"`
+	Method         PaymentMethod `db:"method" json:"method"`
+	Status         PaymentStatus `db:"status" json:"status"`
+	CapturedAmount int64         `db:"captured_amount" json:"captured_amount"`
+	RefundedAmount int64         `db:"refunded_amount" json:"refunded_amount"`
+	ProviderRef    *string       `db:"provider_ref" json:"provider_ref,omitempty"` // the gateway's ID for the payment
+	Receipt        *string       `db:"receipt" json:"receipt,omitempty"`           // the receipt number the gateway gave the customer
+	FailureReason  *string       `db:"failure_reason" json:"failure_reason,omitempty"`
+	AuthorizedAt   *time.Time    `db:"authorized_at" json:"authorized_at,omitempty"`
+	CapturedAt     *time.Time    `db:"captured_at" json:"captured_at,omitempty"`
+	PaidAt         *time.Time    `db:"paid_at" json:"paid_at,omitempty"` // when the payment completed
+	FailedAt       *time.Time    `db:"failed_at" json:"failed_at,omitempty"`
+	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+// Refund returns part or all of a payment's captured amount.
+type Refund struct {
+	ID          uuid.UUID  `db:"id" json:"id"`
+	PaymentID   uuid.UUID  `db:"payment_id" json:"payment_id"`
+	Amount      int64      `db:"amount" json:"amount"`
+	Currency    string     `db:"currency" json:"currency"`
+	Reason      *string    `db:"reason" json:"reason,omitempty"`
+	ProviderRef *string    `db:"provider_ref" json:"provider_ref,omitempty"`
+	CreatedBy   *uuid.UUID `db:"created_by" json:"created_by,omitempty"` // nil when the gateway reported it
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
+
+// Charge asks a gateway to collect a payment from the customer.
+type Charge struct {
+	PaymentID   uuid.UUID
+	Amount      int64
+	Currency    string
+	Phone       string // E.164, for gateways that prompt the customer's phone
+	Reference   string // shown to the customer, e.g. the order number
+	Description string
+}
+
+// Initiation is the gateway's acknowledgement of a charge; the outcome follows in a callback.
+type Initiation struct {
+	ProviderRef     string `json:"provider_ref"`
+	CustomerMessage string `json:"customer_message,omitempty"`
+	ClientSecret    string `json:"client_secret,omitempty"` // lets the client confirm a card payment, e.g. with Stripe.js
+}
+
+// Result is a gateway's final word on a payment, read from its callback.
+type Result struct {
+	ProviderRef string
+	Succeeded   bool
+	Reason      string // why it failed
+	Receipt     string // the gateway's receipt number when it succeeded
 }
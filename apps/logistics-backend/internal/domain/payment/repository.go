@@ -10,6 +10,34 @@ type Repository interface {
 	Create(ctx context.Context, payment *Payment) error
 	GetByID(cxt context.Context, id uuid.UUID) (*Payment, error)
 	GetByOrder(ctx context.Context, id uuid.UUID) (*Payment, error)
-	List(ctx context.Context) ([]*Payment, error)
+	ListByAdmin(ctx context.Context, adminID uuid.UUID) ([]*Payment, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+
+	// Transitions; GetForUpdate locks the payment until the transaction ends
+	GetForUpdate(ctx context.Context, id uuid.UUID) (*Payment, error)
+	Save(ctx context.Context, p *Payment) error // PUT status, amounts, provider ref and timestamps
+
+	// Gateways
+	GetByProviderRef(ctx context.Context, method PaymentMethod, ref string) (*Payment, error)
+	LockOrder(ctx context.Context, orderID uuid.UUID) error // serialises payment attempts for one order
+
+	// Refunds
+	CreateRefund(ctx context.Context, r *Refund) error
+	ListRefunds(ctx context.Context, paymentID uuid.UUID) ([]*Refund, error)
+}
+
+// CashRepository keeps cash-on-delivery collections and drivers' remittances.
+type CashRepository interface {
+	CreateCollection(ctx context.Context, c *CashCollection) error
+	ListCollections(ctx context.Context, driverID uuid.UUID, limit, offset int) ([]*CashCollection, error)
+
+	LockDriver(ctx context.Context, driverID uuid.UUID) error // serialises balance checks for one driver
+	CreateRemittance(ctx context.Context, r *CashRemittance) error
+	GetRemittanceForUpdate(ctx context.Context, id uuid.UUID) (*CashRemittance, error)
+	ConfirmRemittance(ctx context.Context, r *CashRemittance) error
+	ListRemittances(ctx context.Context, driverID, adminID *uuid.UUID, status *RemittanceStatus, limit, offset int) ([]*CashRemittance, error)
+
+	// Reports; nil IDs mean every driver or store owner
+	Balances(ctx context.Context, driverID, adminID *uuid.UUID) ([]*CashBalance, error)
+	DailyReport(ctx context.Context, q CashReportQuery) ([]*CashDay, error)
 }
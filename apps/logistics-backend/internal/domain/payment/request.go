@@ -4,20 +4,59 @@ import (
 	"github.com/google/uuid"
 )
 
+// CreatePaymentRequest records a payment taken outside the gateways; the amount is the order's total.
 type CreatePaymentRequest struct {
-	OrderID  uuid.UUID     `json:"order_id"`
-	Amount   int64         `json:"amount"`
-	Currency string        `json:"currency"`
-	Method   PaymentMethod `json:"method"`
-	Status   PaymentStatus `json:"status"`
-}
-
-func (r *CreatePaymentRequest) ToPayment() *Payment {
-	return &Payment{
-		OrderID:  r.OrderID,
-		Amount:   r.Amount,
-		Currency: r.Currency,
-		Method:   r.Method,
-		Status:   StatusPending,
-	}
+	OrderID uuid.UUID     `json:"order_id"`
+	Method  PaymentMethod `json:"method"`
+}
+
+type AuthorizeRequest struct {
+	ProviderRef *string `json:"provider_ref,omitempty"`
+}
+
+// CaptureRequest takes part of an authorized payment; leave amount out to capture all of it.
+type CaptureRequest struct {
+	Amount int64 `json:"amount,omitempty"`
+}
+
+type FailRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RefundRequest returns part of a payment; leave amount out to refund everything still captured.
+type RefundRequest struct {
+	Amount int64   `json:"amount,omitempty"`
+	Reason *string `json:"reason,omitempty"`
+}
+
+// InitiatePaymentRequest starts paying for an order through a gateway. Mobile money needs the
+// phone number that receives the payment prompt.
+type InitiatePaymentRequest struct {
+	OrderID uuid.UUID     `json:"order_id"`
+	Method  PaymentMethod `json:"method"`
+	Phone   string        `json:"phone,omitempty"`
+}
+
+type InitiatePaymentResponse struct {
+	Payment    *Payment    `json:"payment"`
+	Initiation *Initiation `json:"initiation"`
+}
+
+// CollectCashRequest records the cash a driver took at delivery; it must match the amount due.
+type CollectCashRequest struct {
+	Amount int64 `json:"amount"`
+}
+
+// DeclareRemittanceRequest is a driver handing cash in to the store owner.
+type DeclareRemittanceRequest struct {
+	AdminID  uuid.UUID `json:"admin_id"`
+	Amount   int64     `json:"amount"`
+	Currency string    `json:"currency,omitempty"` // defaults to KES
+	Note     *string   `json:"note,omitempty"`
+}
+
+// ConfirmRemittanceRequest records what the store owner counted.
+type ConfirmRemittanceRequest struct {
+	ReceivedAmount int64   `json:"received_amount"`
+	Note           *string `json:"note,omitempty"`
 }
@@ -0,0 +1,120 @@
+package payment
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// transitions lists the statuses a payment may move to from each status. Failed and
+// refunded payments are final.
+var transitions = map[PaymentStatus][]PaymentStatus{
+	StatusPending:           {StatusAuthorized, StatusCaptured, StatusCompleted, StatusFailed},
+	StatusAuthorized:        {StatusCaptured, StatusCompleted, StatusFailed}, // failing an authorization voids it
+	StatusCaptured:          {StatusCompleted, StatusPartiallyRefunded, StatusRefunded},
+	StatusCompleted:         {StatusPartiallyRefunded, StatusRefunded},
+	StatusPartiallyRefunded: {StatusPartiallyRefunded, StatusRefunded},
+}
+
+func (s PaymentStatus) CanBecome(next PaymentStatus) bool {
+	return slices.Contains(transitions[s], next)
+}
+
+func (p *Payment) moveTo(next PaymentStatus) error {
+	if !p.Status.CanBecome(next) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, p.Status, next)
+	}
+	p.Status = next
+	return nil
+}
+
+// Refundable is the captured amount not refunded yet.
+func (p *Payment) Refundable() int64 {
+	return p.CapturedAmount - p.RefundedAmount
+}
+
+// Authorize records that the gateway holds the funds.
+func (p *Payment) Authorize(providerRef *string, now time.Time) error {
+	if err := p.moveTo(StatusAuthorized); err != nil {
+		return err
+	}
+	if providerRef != nil {
+		p.ProviderRef = providerRef
+	}
+	p.AuthorizedAt = &now
+	return nil
+}
+
+// Capture takes amount, or the whole payment when amount is 0.
+func (p *Payment) Capture(amount int64, now time.Time) error {
+	if amount == 0 {
+		amount = p.Amount
+	}
+	if amount < 0 || amount > p.Amount {
+		return ErrInvalidAmount
+	}
+	if err := p.moveTo(StatusCaptured); err != nil {
+		return err
+	}
+	p.CapturedAmount = amount
+	p.CapturedAt = &now
+	return nil
+}
+
+// Complete marks the payment as settled. Methods without a separate capture step, such as
+// mobile money and cash on delivery, complete straight from pending and capture in full.
+func (p *Payment) Complete(now time.Time) error {
+	captured := p.Status == StatusCaptured
+	if err := p.moveTo(StatusCompleted); err != nil {
+		return err
+	}
+	if !captured {
+		p.CapturedAmount = p.Amount
+		p.CapturedAt = &now
+	}
+	p.PaidAt = &now
+	return nil
+}
+
+func (p *Payment) Fail(reason string, now time.Time) error {
+	if err := p.moveTo(StatusFailed); err != nil {
+		return err
+	}
+	p.FailureReason = &reason
+	p.FailedAt = &now
+	return nil
+}
+
+// Refund returns amount, or everything still refundable when amount is 0, and reports the
+// refund to record. The payment is refunded once nothing captured is left.
+func (p *Payment) Refund(amount int64, reason *string, createdBy *uuid.UUID) (*Refund, error) {
+	if amount == 0 {
+		amount = p.Refundable()
+	}
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if amount > p.Refundable() {
+		return nil, ErrRefundExceedsCaptured
+	}
+
+	next := StatusPartiallyRefunded
+	if amount == p.Refundable() {
+		next = StatusRefunded
+	}
+	if err := p.moveTo(next); err != nil {
+		return nil, err
+	}
+	p.RefundedAmount += amount
+
+	return &Refund{
+		ID:        uuid.New(),
+		PaymentID: p.ID,
+		Amount:    amount,
+		Currency:  p.Currency,
+		Reason:    reason,
+		CreatedBy: createdBy,
+	}, nil
+}
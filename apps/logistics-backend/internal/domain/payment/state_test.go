@@ -0,0 +1,119 @@
+package payment
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTransitions(t *testing.T) {
+	now := time.Now()
+	steps := map[string]func(p *Payment) error{
+		"authorize": func(p *Payment) error { return p.Authorize(nil, now) },
+		"capture":   func(p *Payment) error { return p.Capture(0, now) },
+		"complete":  func(p *Payment) error { return p.Complete(now) },
+		"fail":      func(p *Payment) error { return p.Fail("declined", now) },
+		"refund":    func(p *Payment) error { _, err := p.Refund(100, nil, nil); return err },
+	}
+
+	// statuses each step may start from; anything else is an invalid transition
+	allowed := map[string][]PaymentStatus{
+		"authorize": {StatusPending},
+		"capture":   {StatusPending, StatusAuthorized},
+		"complete":  {StatusPending, StatusAuthorized, StatusCaptured},
+		"fail":      {StatusPending, StatusAuthorized},
+		"refund":    {StatusCaptured, StatusCompleted, StatusPartiallyRefunded},
+	}
+
+	statuses := []PaymentStatus{
+		StatusPending, StatusAuthorized, StatusCaptured, StatusCompleted,
+		StatusFailed, StatusPartiallyRefunded, StatusRefunded,
+	}
+	for name, step := range steps {
+		for _, from := range statuses {
+			// amounts that pass every check, so only the status decides
+			p := &Payment{Amount: 1000, CapturedAmount: 1000, RefundedAmount: 200, Status: from}
+
+			err := step(p)
+			want := false
+			for _, s := range allowed[name] {
+				want = want || s == from
+			}
+			if want && err != nil {
+				t.Errorf("%s from %s: %v", name, from, err)
+			}
+			if !want && !errors.Is(err, ErrInvalidTransition) {
+				t.Errorf("%s from %s: err = %v, want ErrInvalidTransition", name, from, err)
+			}
+		}
+	}
+}
+
+func TestCompleteCapturesInFull(t *testing.T) {
+	p := &Payment{Amount: 1000, Status: StatusPending}
+	if err := p.Complete(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if p.CapturedAmount != 1000 || p.PaidAt == nil {
+		t.Errorf("captured %d, paid at %v; want 1000 and a time", p.CapturedAmount, p.PaidAt)
+	}
+
+	p = &Payment{Amount: 1000, Status: StatusPending}
+	if err := p.Capture(600, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Complete(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if p.CapturedAmount != 600 {
+		t.Errorf("captured %d, want the partial capture 600 kept", p.CapturedAmount)
+	}
+}
+
+func TestCaptureLimits(t *testing.T) {
+	for _, amount := range []int64{-1, 1001} {
+		p := &Payment{Amount: 1000, Status: StatusAuthorized}
+		if err := p.Capture(amount, time.Now()); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("capture %d: err = %v, want ErrInvalidAmount", amount, err)
+		}
+		if p.Status != StatusAuthorized {
+			t.Errorf("capture %d: status = %s, want it unchanged", amount, p.Status)
+		}
+	}
+}
+
+func TestRefundLimits(t *testing.T) {
+	p := &Payment{Amount: 1000, CapturedAmount: 800, Status: StatusCaptured, Currency: "KES"}
+
+	if _, err := p.Refund(-5, nil, nil); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("negative refund: err = %v, want ErrInvalidAmount", err)
+	}
+	if _, err := p.Refund(801, nil, nil); !errors.Is(err, ErrRefundExceedsCaptured) {
+		t.Errorf("refund above captured: err = %v, want ErrRefundExceedsCaptured", err)
+	}
+
+	rf, err := p.Refund(300, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rf.Amount != 300 || rf.Currency != "KES" || p.Status != StatusPartiallyRefunded || p.Refundable() != 500 {
+		t.Errorf("after 300: refund %d %s, status %s, refundable %d", rf.Amount, rf.Currency, p.Status, p.Refundable())
+	}
+
+	if _, err := p.Refund(501, nil, nil); !errors.Is(err, ErrRefundExceedsCaptured) {
+		t.Errorf("refund above what is left: err = %v, want ErrRefundExceedsCaptured", err)
+	}
+
+	// no amount refunds the rest
+	rf, err = p.Refund(0, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rf.Amount != 500 || p.Status != StatusRefunded || p.Refundable() != 0 {
+		t.Errorf("refund of the rest: refund %d, status %s, refundable %d", rf.Amount, p.Status, p.Refundable())
+	}
+
+	if _, err := p.Refund(0, nil, nil); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("refund after full refund: err = %v, want ErrInvalidAmount", err)
+	}
+}
@@ -0,0 +1,22 @@
+package session
+
+import (
+	"context"
+	"logistics-backend/internal/domain/user"
+
+	"github.com/google/uuid"
+)
+
+type UserReader interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*user.User, error)
+}
+
+// MFA policy, so tokens of roles that require 2FA are restricted until it is set up.
+type MFAPolicy interface {
+	Requires(role user.Role) bool
+}
+
+// Access token signing, so the usecase does not depend on how keys are loaded or rotated.
+type TokenSigner interface {
+	SignAccessToken(claims map[string]any) (string, error)
+}
@@ -0,0 +1,15 @@
+package session
+
+import "errors"
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrTokenRevoked        = errors.New("token has been revoked")
+	ErrUserInactive        = errors.New("user account is not active")
+
+	// ErrPasswordChangeRequired is returned for otherwise valid tokens of users who must change their password.
+	ErrPasswordChangeRequired = errors.New("password change required")
+	// ErrMFAEnrollmentRequired is returned for otherwise valid tokens of users whose role requires 2FA they have not set up.
+	ErrMFAEnrollmentRequired = errors.New("two-factor authentication setup required")
+)
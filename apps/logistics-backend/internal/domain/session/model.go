@@ -0,0 +1,35 @@
+package session
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is one link in a rotation chain; the raw token is only ever handed to the client.
+type RefreshToken struct {
+	ID         uuid.UUID  `db:"id" json:"id"`
+	UserID     uuid.UUID  `db:"user_id" json:"user_id"`
+	TokenHash  string     `db:"token_hash" json:"-"`
+	FamilyID   uuid.UUID  `db:"family_id" json:"family_id"` // shared by every token rotated from the same login
+	ExpiresAt  time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	ReplacedBy *uuid.UUID `db:"replaced_by" json:"replaced_by,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// TokenPair is returned on login and on every refresh.
+type TokenPair struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresAt    time.Time `json:"expires_at"` // access token expiry
+}
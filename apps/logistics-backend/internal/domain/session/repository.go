@@ -0,0 +1,22 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	Create(ctx context.Context, t *RefreshToken) error                      // POST
+	GetByHash(ctx context.Context, tokenHash string) (*RefreshToken, error) // GET
+	Revoke(ctx context.Context, id uuid.UUID, replacedBy *uuid.UUID) error  // PATCH single token, optionally recording its successor
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error             // PATCH every token of a rotation chain
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error           // PATCH every token of a user
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)     // DELETE expired refresh tokens and revoked access tokens
+
+	RevokeAccessToken(ctx context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error // POST revocation list entry
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)                             // GET
+
+	BumpTokenVersion(ctx context.Context, userID uuid.UUID) (int, error) // PATCH users.token_version + 1
+}
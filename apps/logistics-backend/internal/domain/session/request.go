@@ -0,0 +1,9 @@
+package session
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"` // optional, also ends the refresh chain when given
+}
@@ -0,0 +1,7 @@
+package store
+
+import "errors"
+
+var (
+	ErrStoreNotFound = errors.New("store not found")
+)
@@ -11,6 +11,7 @@ type Repository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*Store, error)
 	GetBySlug(ctx context.Context, slug string) (*Store, error)
 	GetByOwner(ctx context.Context, ownerID uuid.UUID) (*Store, error)
+	ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*Store, error)
 	Update(ctx context.Context, storeID uuid.UUID, column string, value any) error
 	ListPublic(ctx context.Context) ([]*Store, error)
 	Delete(ctx context.Context, id uuid.UUID) error
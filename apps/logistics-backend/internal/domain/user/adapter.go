@@ -4,12 +4,19 @@ import (
 	"context"
 	"logistics-backend/internal/domain/driver"
 	"logistics-backend/internal/domain/notification"
+
+	"github.com/google/uuid"
 )
 
 type DriverReader interface {
 	RegisterDriver(ctx context.Context, d *driver.Driver) error
 }
 
+// Session use case, so credential changes can end existing sessions in the same transaction.
+type SessionRevoker interface {
+	RevokeAllSessionsTx(ctx context.Context, userID uuid.UUID) error
+}
+
 type NotificationReader interface {
 	Create(ctx context.Context, n *notification.Notification) error
 }
@@ -0,0 +1,37 @@
+package user
+
+import "errors"
+
+// Machine readable codes returned to clients for accounts that may not sign in.
+const (
+	CodeAccountUnverified = "account_unverified"
+	CodeAccountSuspended  = "account_suspended"
+	CodeAccountInactive   = "account_inactive"
+)
+
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrWeakPassword      = errors.New("password must be at least 8 characters")
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+	ErrInvalidPassword   = errors.New("current password is incorrect")
+	ErrPasswordUnchanged = errors.New("new password must differ from the current one")
+	ErrInvalidPhone      = errors.New("phone number is not valid")
+
+	ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+	ErrAccountUnverified        = errors.New("email address has not been verified")
+	ErrAccountSuspended         = errors.New("account is suspended")
+	ErrAccountInactive          = errors.New("account is inactive")
+)
+
+// StatusCode maps an account status error from User.CheckStatus to its client code, or "" for other errors.
+func StatusCode(err error) string {
+	switch {
+	case errors.Is(err, ErrAccountUnverified):
+		return CodeAccountUnverified
+	case errors.Is(err, ErrAccountSuspended):
+		return CodeAccountSuspended
+	case errors.Is(err, ErrAccountInactive):
+		return CodeAccountInactive
+	}
+	return ""
+}
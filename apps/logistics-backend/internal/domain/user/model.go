@@ -34,7 +34,12 @@ type User struct {
 	Slug                 string     `db:"slug" json:"slug"` // adminSlug used in public route
 	Must_change_password bool       `db:"must_change_password" json:"must_change_password"`
 	Status               UserStatus `db:"status" json:"status"`
+	OwnerID              *uuid.UUID `db:"owner_id" json:"owner_id,omitempty"`     // store owner (admin) the account belongs to
+	InvitedBy            *uuid.UUID `db:"invited_by" json:"invited_by,omitempty"` // set for accounts created from an invite
+	EmailVerifiedAt      *time.Time `db:"email_verified_at" json:"email_verified_at,omitempty"`
 	LastLogin            *time.Time `db:"last_login" json:"last_login,omitempty"`
+	TokenVersion         int        `db:"token_version" json:"-"` // bumped to revoke every issued access token
+	MFAEnabled           bool       `db:"mfa_enabled" json:"mfa_enabled"`
 	CreatedAt            time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt            time.Time  `db:"updated_at" json:"updated_at"`
 }
@@ -44,7 +49,56 @@ func (u *User) ComparePassword(password string) bool {
 	return err == nil
 }
 
+// CheckStatus returns why the account may not sign in, or nil for active accounts.
+func (u *User) CheckStatus() error {
+	switch u.Status {
+	case Active:
+		return nil
+	case Pending:
+		return ErrAccountUnverified
+	case Suspended:
+		return ErrAccountSuspended
+	default:
+		return ErrAccountInactive
+	}
+}
+
 type AllCustomers struct {
 	ID   uuid.UUID `db:"id" json:"id"`
 	Name string    `db:"full_name" json:"name"`
 }
+
+// PasswordReset is a single-use token mailed to a user who forgot their password.
+type PasswordReset struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	UserID    uuid.UUID  `db:"user_id" json:"user_id"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+func (p *PasswordReset) IsUsable(now time.Time) bool {
+	return p.UsedAt == nil && now.Before(p.ExpiresAt)
+}
+
+// EmailVerification is a single-use token mailed to a self-registered user to activate the account.
+type EmailVerification struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	UserID    uuid.UUID  `db:"user_id" json:"user_id"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+func (v *EmailVerification) IsUsable(now time.Time) bool {
+	return v.UsedAt == nil && now.Before(v.ExpiresAt)
+}
+
+const MinPasswordLength = 8
+
+func HashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hashed), err
+}
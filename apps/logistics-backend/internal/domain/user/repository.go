@@ -8,12 +8,26 @@ import (
 
 // User = actual onboarded account in the system.
 type Repository interface {
-	Create(ctx context.Context, user *User) error                                      // POST
-	GetByID(ctx context.Context, id uuid.UUID) (*User, error)                          // GET
-	GetByEmail(ctx context.Context, email string) (*User, error)                       // GET
-	List(ctx context.Context) ([]*User, error)                                         // GET
-	GetAllCustomers(ctx context.Context) ([]AllCustomers, error)                       // GET
-	UpdateColum(ctx context.Context, userID uuid.UUID, column string, value any) error // PATCH
-	UpdateProfile(ctx context.Context, id uuid.UUID, phone string) error               // PUT
-	Delete(ctx context.Context, id uuid.UUID) error                                    // DELETE
+	Create(ctx context.Context, user *User) error                                                 // POST
+	GetByID(ctx context.Context, id uuid.UUID) (*User, error)                                     // GET
+	GetByEmail(ctx context.Context, email string) (*User, error)                                  // GET
+	ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*User, error)                          // GET
+	GetAllCustomers(ctx context.Context, ownerID uuid.UUID) ([]AllCustomers, error)               // GET
+	BelongsToOwner(ctx context.Context, userID, ownerID uuid.UUID) (bool, error)                  // GET tenant membership check
+	OwnedBy(ctx context.Context, userID, ownerID uuid.UUID) (bool, error)                         // GET provisioning owner check
+	UpdateColum(ctx context.Context, userID uuid.UUID, column string, value any) error            // PATCH
+	UpdateProfile(ctx context.Context, id uuid.UUID, phone string) error                          // PUT
+	Delete(ctx context.Context, id uuid.UUID) error                                               // DELETE
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string, mustChange bool) error // PATCH
+	UpdateDeviceToken(ctx context.Context, id uuid.UUID, token *string) error                     // PUT, nil clears it
+	UpdateLocale(ctx context.Context, id uuid.UUID, locale string) error                          // PUT
+
+	CreatePasswordReset(ctx context.Context, p *PasswordReset) error                      // POST
+	GetPasswordResetByHash(ctx context.Context, tokenHash string) (*PasswordReset, error) // GET
+	MarkPasswordResetUsed(ctx context.Context, id uuid.UUID) error                        // PATCH, fails if already used
+
+	CreateEmailVerification(ctx context.Context, v *EmailVerification) error                      // POST
+	GetEmailVerificationByHash(ctx context.Context, tokenHash string) (*EmailVerification, error) // GET
+	MarkEmailVerificationUsed(ctx context.Context, id uuid.UUID) error                            // PATCH, fails if already used
+	MarkEmailVerified(ctx context.Context, id uuid.UUID) error                                    // PATCH, also activates pending accounts
 }
@@ -1,6 +1,8 @@
 package user
 
 import (
+	"time"
+
 	generate "logistics-backend/internal/utils"
 
 	"github.com/google/uuid"
@@ -35,7 +37,7 @@ func (r *CreateUserRequest) ToUser() *User {
 		Role:         r.Role,
 		Phone:        r.Phone,
 		Slug:         baseSlug + "-" + uniqueSuffix,
-		Status:       Active,
+		Status:       Pending, // activated once the email address is verified
 	}
 }
 
@@ -45,9 +47,45 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	ID       string `json:"id"`
-	FullName string `json:"fullName"`
-	Email    string `json:"email"`
-	Role     string `json:"role"`
-	Token    string `json:"token,omitempty"`
+	ID                    string    `json:"id"`
+	FullName              string    `json:"fullName"`
+	Email                 string    `json:"email"`
+	Role                  string    `json:"role"`
+	Token                 string    `json:"token,omitempty"` // access token
+	RefreshToken          string    `json:"refresh_token,omitempty"`
+	ExpiresAt             time.Time `json:"expires_at"`              // access token expiry
+	MustChangePassword    bool      `json:"must_change_password"`    // every route but change-password is blocked until set
+	MFAEnrollmentRequired bool      `json:"mfa_enrollment_required"` // the role requires 2FA; every route but /auth is blocked until enrolled
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// DeviceTokenRequest registers the device that push notifications go to; an empty token clears it.
+type DeviceTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// LocaleRequest picks the language notifications are written in, e.g. "en" or "sw".
+type LocaleRequest struct {
+	Locale string `json:"locale"`
 }
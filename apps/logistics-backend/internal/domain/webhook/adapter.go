@@ -0,0 +1,18 @@
+package webhook
+
+import (
+	"context"
+	"logistics-backend/internal/domain/notification"
+	"logistics-backend/internal/domain/store"
+
+	"github.com/google/uuid"
+)
+
+type StoreReader interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*store.Store, error)
+}
+
+// Tells the store owner when one of their endpoints is disabled.
+type NotificationWriter interface {
+	Create(ctx context.Context, n *notification.Notification) error
+}
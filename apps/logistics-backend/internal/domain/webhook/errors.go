@@ -0,0 +1,14 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrInvalidURL           = errors.New("webhook url must be an absolute https url")
+	ErrNoEvents             = errors.New("a webhook subscription needs at least one event type")
+	ErrInvalidEvent         = errors.New("unknown webhook event type")
+	ErrStoreNotOwned        = errors.New("store not found or not owned by caller")
+	ErrSubscriptionDisabled = errors.New("webhook subscription is disabled")
+	ErrBlockedAddress       = errors.New("webhook url resolves to a private or local address")
+)
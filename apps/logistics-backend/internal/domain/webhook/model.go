@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// EventType names what happened to a store's order; subscriptions pick the types they receive.
+type EventType string
+
+const (
+	EventOrderCreated     EventType = "order.created"
+	EventOrderAssigned    EventType = "order.assigned"
+	EventOrderPickedUp    EventType = "order.picked_up"
+	EventOrderDelivered   EventType = "order.delivered"
+	EventPaymentCompleted EventType = "payment.completed"
+)
+
+var EventTypes = []EventType{EventOrderCreated, EventOrderAssigned, EventOrderPickedUp, EventOrderDelivered, EventPaymentCompleted}
+
+func IsEventType(t string) bool {
+	return slices.Contains(EventTypes, EventType(t))
+}
+
+// SecretPrefix starts every signing secret, so secrets are recognisable in configs and secret scanners.
+const SecretPrefix = "whsec_"
+
+// Headers sent with every delivery. The signature is the hex HMAC-SHA256 of "<timestamp>.<body>"
+// under the subscription's secret, so receivers can reject forged and replayed requests.
+const (
+	HeaderEventID   = "X-Webhook-Id"
+	HeaderEventType = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Subscription sends a store's events to an endpoint of the owner's. It is disabled after too
+// many failed attempts in a row and has to be re-enabled by the owner.
+type Subscription struct {
+	ID                  uuid.UUID      `db:"id" json:"id"`
+	StoreID             uuid.UUID      `db:"store_id" json:"store_id"`
+	OwnerID             uuid.UUID      `db:"owner_id" json:"owner_id"`
+	URL                 string         `db:"url" json:"url"`
+	Events              pq.StringArray `db:"events" json:"events"`
+	Secret              string         `db:"secret" json:"-"`
+	Active              bool           `db:"active" json:"active"`
+	ConsecutiveFailures int            `db:"consecutive_failures" json:"consecutive_failures"`
+	DisabledAt          *time.Time     `db:"disabled_at" json:"disabled_at,omitempty"`
+	DisabledReason      *string        `db:"disabled_reason" json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// Event is the JSON body of a delivery. ID stays the same across retries and redeliveries so
+// receivers can drop duplicates.
+type Event struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      EventType       `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	StoreID   uuid.UUID       `json:"store_id"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type DeliveryStatus string
+
+const (
+	Pending   DeliveryStatus = "pending"
+	Succeeded DeliveryStatus = "succeeded"
+	Failed    DeliveryStatus = "failed" // last attempt failed, retried at NextAttemptAt
+	Dead      DeliveryStatus = "dead"   // gave up after the maximum number of attempts
+)
+
+// Delivery is one event sent to one subscription, and the log of its latest attempt.
+type Delivery struct {
+	ID             uuid.UUID       `db:"id" json:"id"`
+	SubscriptionID uuid.UUID       `db:"subscription_id" json:"subscription_id"`
+	EventID        uuid.UUID       `db:"event_id" json:"event_id"`
+	EventType      EventType       `db:"event_type" json:"event_type"`
+	Payload        json.RawMessage `db:"payload" json:"payload"`
+	Status         DeliveryStatus  `db:"status" json:"status"`
+	Attempts       int             `db:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time       `db:"next_attempt_at" json:"next_attempt_at"`
+	ResponseCode   *int            `db:"response_code" json:"response_code,omitempty"`
+	ResponseBody   *string         `db:"response_body" json:"response_body,omitempty"` // truncated
+	LastError      *string         `db:"last_error" json:"last_error,omitempty"`
+	DurationMS     *int            `db:"duration_ms" json:"duration_ms,omitempty"`
+	DeliveredAt    *time.Time      `db:"delivered_at" json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// Response is what an endpoint answered to a delivery.
+type Response struct {
+	StatusCode int
+	Body       string
+	Duration   time.Duration
+}
+
+func (r *Response) OK() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// Sign returns the signature header value for a body sent at timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
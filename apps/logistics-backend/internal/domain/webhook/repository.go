@@ -0,0 +1,38 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	// Subscriptions
+	Create(ctx context.Context, s *Subscription) error                                               // POST
+	GetByID(ctx context.Context, id uuid.UUID) (*Subscription, error)                                // GET
+	ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*Subscription, error)                     // GET
+	Update(ctx context.Context, s *Subscription) error                                               // PUT url, events, secret and state
+	Delete(ctx context.Context, id, ownerID uuid.UUID) error                                         // DELETE
+	ListActiveForEvent(ctx context.Context, storeID uuid.UUID, t EventType) ([]*Subscription, error) // GET
+	StoreIDForOrder(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error)                       // GET, via the order's inventory
+
+	// Failure tracking; RecordFailure disables the subscription once it reaches disableAfter
+	ResetFailures(ctx context.Context, id uuid.UUID) error
+	RecordFailure(ctx context.Context, id uuid.UUID, disableAfter int, reason string) (disabled bool, err error)
+
+	// Deliveries
+	CreateDelivery(ctx context.Context, d *Delivery) error // keyed by ID, repeats are ignored
+	GetDelivery(ctx context.Context, id uuid.UUID) (*Delivery, error)
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit, offset int) ([]*Delivery, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*Delivery, error) // active subscriptions only
+	SaveAttempt(ctx context.Context, d *Delivery) error
+	DeleteDeliveries(ctx context.Context, before time.Time) (int64, error) // finished deliveries only
+}
+
+// Poster sends a delivery over HTTP. A non-2xx answer is a Response, not an error; errors are
+// for requests that got no answer at all.
+type Poster interface {
+	Post(ctx context.Context, url string, header http.Header, body []byte) (*Response, error)
+}
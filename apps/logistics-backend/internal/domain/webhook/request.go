@@ -0,0 +1,33 @@
+package webhook
+
+import "github.com/google/uuid"
+
+type CreateSubscriptionRequest struct {
+	StoreID uuid.UUID `json:"store_id" binding:"required"`
+	URL     string    `json:"url" binding:"required"`
+	Events  []string  `json:"events" binding:"required"`
+}
+
+func (r *CreateSubscriptionRequest) ToSubscription(ownerID uuid.UUID) *Subscription {
+	return &Subscription{
+		StoreID: r.StoreID,
+		OwnerID: ownerID,
+		URL:     r.URL,
+		Events:  r.Events,
+		Active:  true,
+	}
+}
+
+// UpdateSubscriptionRequest changes the fields that are set. Setting active re-enables a
+// subscription that was disabled after failing.
+type UpdateSubscriptionRequest struct {
+	URL    *string  `json:"url,omitempty"`
+	Events []string `json:"events,omitempty"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+// SubscriptionWithSecret carries the signing secret, which is only shown on creation and rotation.
+type SubscriptionWithSecret struct {
+	*Subscription
+	Secret string `json:"secret"`
+}
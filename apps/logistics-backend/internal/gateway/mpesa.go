@@ -0,0 +1,285 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"logistics-backend/internal/domain/payment"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MpesaLiveURL    = "https://api.safaricom.co.ke"
+	MpesaSandboxURL = "https://sandbox.safaricom.co.ke"
+
+	MpesaPayBill  = "CustomerPayBillOnline"
+	MpesaBuyGoods = "CustomerBuyGoodsOnline"
+)
+
+var ErrInvalidCallback = errors.New("payment callback could not be authenticated")
+
+// Daraja timestamps and passwords use Nairobi time.
+var eat = time.FixedZone("EAT", 3*60*60)
+
+// Mpesa collects mobile money payments through Safaricom's Daraja STK Push (Lipa na M-Pesa
+// Online): the customer gets a prompt on their phone and Daraja posts the outcome to
+// CallbackURL once they enter their PIN, cancel or let it time out.
+type Mpesa struct {
+	BaseURL         string // MpesaLiveURL, MpesaSandboxURL or a local mock
+	ConsumerKey     string
+	ConsumerSecret  string
+	ShortCode       string // paybill or till number
+	PassKey         string
+	TransactionType string // MpesaPayBill (default) or MpesaBuyGoods
+
+	// CallbackURL receives the results; Daraja does not sign them, so CallbackToken is added
+	// as ?token= and checked on every callback.
+	CallbackURL   string
+	CallbackToken string
+
+	Client *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+type mpesaError struct {
+	RequestID    string `json:"requestId"`
+	ErrorCode    string `json:"errorCode"`
+	ErrorMessage string `json:"errorMessage"`
+}
+
+type stkPushRequest struct {
+	BusinessShortCode string `json:"BusinessShortCode"`
+	Password          string `json:"Password"`
+	Timestamp         string `json:"Timestamp"`
+	TransactionType   string `json:"TransactionType"`
+	Amount            int64  `json:"Amount"`
+	PartyA            string `json:"PartyA"`
+	PartyB            string `json:"PartyB"`
+	PhoneNumber       string `json:"PhoneNumber"`
+	CallBackURL       string `json:"CallBackURL"`
+	AccountReference  string `json:"AccountReference"`
+	TransactionDesc   string `json:"TransactionDesc"`
+}
+
+type stkPushResponse struct {
+	MerchantRequestID   string `json:"MerchantRequestID"`
+	CheckoutRequestID   string `json:"CheckoutRequestID"`
+	ResponseCode        string `json:"ResponseCode"`
+	ResponseDescription string `json:"ResponseDescription"`
+	CustomerMessage     string `json:"CustomerMessage"`
+}
+
+type stkCallback struct {
+	Body struct {
+		StkCallback struct {
+			MerchantRequestID string `json:"MerchantRequestID"`
+			CheckoutRequestID string `json:"CheckoutRequestID"`
+			ResultCode        int    `json:"ResultCode"`
+			ResultDesc        string `json:"ResultDesc"`
+			CallbackMetadata  struct {
+				Item []struct {
+					Name  string `json:"Name"`
+					Value any    `json:"Value"`
+				} `json:"Item"`
+			} `json:"CallbackMetadata"`
+		} `json:"stkCallback"`
+	} `json:"Body"`
+}
+
+func (m *Mpesa) Method() payment.PaymentMethod {
+	return payment.MethodMobileMoney
+}
+
+// Initiate sends the STK Push prompt and returns its CheckoutRequestID, which the callback
+// carries. M-Pesa only moves whole shillings in KES.
+func (m *Mpesa) Initiate(ctx context.Context, c *payment.Charge) (*payment.Initiation, error) {
+	if c.Currency != "KES" || c.Amount%100 != 0 {
+		return nil, fmt.Errorf("%w: m-pesa takes whole KES amounts only, got %d %s cents", payment.ErrProvider, c.Amount, c.Currency)
+	}
+
+	callback, err := url.Parse(m.CallbackURL)
+	if err != nil {
+		return nil, fmt.Errorf("mpesa: invalid callback url: %w", err)
+	}
+	q := callback.Query()
+	q.Set("token", m.CallbackToken)
+	callback.RawQuery = q.Encode()
+
+	txType := m.TransactionType
+	if txType == "" {
+		txType = MpesaPayBill
+	}
+	phone := strings.TrimPrefix(c.Phone, "+")
+	timestamp := time.Now().In(eat).Format("20060102150405")
+
+	body, err := json.Marshal(stkPushRequest{
+		BusinessShortCode: m.ShortCode,
+		Password:          base64.StdEncoding.EncodeToString([]byte(m.ShortCode + m.PassKey + timestamp)),
+		Timestamp:         timestamp,
+		TransactionType:   txType,
+		Amount:            c.Amount / 100,
+		PartyA:            phone,
+		PartyB:            m.ShortCode,
+		PhoneNumber:       phone,
+		CallBackURL:       callback.String(),
+		AccountReference:  truncate(c.Reference, 12),
+		TransactionDesc:   truncate(c.Description, 13),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res stkPushResponse
+	if err := m.post(ctx, "/mpesa/stkpush/v1/processrequest", body, &res); err != nil {
+		return nil, err
+	}
+	if res.ResponseCode != "0" || res.CheckoutRequestID == "" {
+		return nil, fmt.Errorf("%w: mpesa: %s (%s)", payment.ErrProvider, res.ResponseDescription, res.ResponseCode)
+	}
+
+	return &payment.Initiation{ProviderRef: res.CheckoutRequestID, CustomerMessage: res.CustomerMessage}, nil
+}
+
+// ParseCallback authenticates and decodes an STK Push result. Result code 0 is a payment;
+// anything else (1032 cancelled, 1037 timed out, 1 insufficient funds, ...) is a failure.
+func (m *Mpesa) ParseCallback(r *http.Request) (*payment.Result, error) {
+	token := r.URL.Query().Get("token")
+	if m.CallbackToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(m.CallbackToken)) != 1 {
+		return nil, ErrInvalidCallback
+	}
+
+	var cb stkCallback
+	if err := json.NewDecoder(r.Body).Decode(&cb); err != nil {
+		return nil, fmt.Errorf("mpesa: decode callback: %w", err)
+	}
+	stk := cb.Body.StkCallback
+	if stk.CheckoutRequestID == "" {
+		return nil, fmt.Errorf("mpesa: callback without a checkout request id")
+	}
+
+	res := &payment.Result{ProviderRef: stk.CheckoutRequestID, Succeeded: stk.ResultCode == 0}
+	if !res.Succeeded {
+		res.Reason = stk.ResultDesc
+		return res, nil
+	}
+	for _, item := range stk.CallbackMetadata.Item {
+		if item.Name == "MpesaReceiptNumber" {
+			res.Receipt, _ = item.Value.(string)
+		}
+	}
+	return res, nil
+}
+
+func (m *Mpesa) post(ctx context.Context, path string, body []byte, out any) error {
+	for attempt := 0; ; attempt++ {
+		token, err := m.accessToken(ctx)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(m.BaseURL, "/")+path, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		res, err := m.client().Do(req)
+		if err != nil {
+			return fmt.Errorf("mpesa: %w", err)
+		}
+
+		// a token revoked before its expiry is fetched again once
+		if res.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			res.Body.Close()
+			m.mu.Lock()
+			m.token = ""
+			m.mu.Unlock()
+			continue
+		}
+
+		defer res.Body.Close()
+		if res.StatusCode >= 300 {
+			var e mpesaError
+			if json.NewDecoder(res.Body).Decode(&e) == nil && e.ErrorMessage != "" {
+				if res.StatusCode < 500 {
+					return fmt.Errorf("%w: mpesa: %s (%s)", payment.ErrProvider, e.ErrorMessage, e.ErrorCode)
+				}
+				return fmt.Errorf("mpesa: %s (%s)", e.ErrorMessage, e.ErrorCode)
+			}
+			return fmt.Errorf("mpesa: unexpected status %s", res.Status)
+		}
+
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			return fmt.Errorf("mpesa: decode response: %w", err)
+		}
+		return nil
+	}
+}
+
+// accessToken returns the cached OAuth token, fetching a new one a minute before it expires.
+func (m *Mpesa) accessToken(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.token != "" && time.Now().Before(m.tokenExpiry) {
+		return m.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(m.BaseURL, "/")+"/oauth/v1/generate?grant_type=client_credentials", nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(m.ConsumerKey, m.ConsumerSecret)
+
+	res, err := m.client().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("mpesa: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return "", fmt.Errorf("mpesa: could not authenticate: %s", res.Status)
+	}
+
+	var body struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresIn   json.Number `json:"expires_in"` // a string in Daraja's responses
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("mpesa: decode token: %w", err)
+	}
+	ttl, err := body.ExpiresIn.Int64()
+	if err != nil || body.AccessToken == "" {
+		return "", fmt.Errorf("mpesa: malformed token response")
+	}
+
+	m.token = body.AccessToken
+	m.tokenExpiry = time.Now().Add(time.Duration(ttl)*time.Second - time.Minute)
+	return m.token, nil
+}
+
+func (m *Mpesa) client() *http.Client {
+	if m.Client != nil {
+		return m.Client
+	}
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
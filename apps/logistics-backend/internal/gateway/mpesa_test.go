@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"logistics-backend/internal/domain/payment"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// mockDaraja mimics the two Daraja endpoints Mpesa uses.
+func mockDaraja(t *testing.T, tokens *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/v1/generate":
+			user, pass, _ := r.BasicAuth()
+			if user != "key" || pass != "secret" || r.URL.Query().Get("grant_type") != "client_credentials" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			*tokens++
+			w.Write([]byte(`{"access_token":"tok","expires_in":"3599"}`))
+
+		case "/mpesa/stkpush/v1/processrequest":
+			if r.Header.Get("Authorization") != "Bearer tok" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var req stkPushRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatal(err)
+			}
+			if want := base64.StdEncoding.EncodeToString([]byte("174379" + "pass" + req.Timestamp)); req.Password != want {
+				t.Errorf("password = %s, want %s", req.Password, want)
+			}
+			if req.PhoneNumber == "254700000000" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"requestId":"1-1","errorCode":"400.002.02","errorMessage":"Bad Request - Invalid PhoneNumber"}`))
+				return
+			}
+			if req.Amount != 150 || req.PartyA != "254712345678" || req.TransactionType != MpesaPayBill || !strings.Contains(req.CallBackURL, "token=cb-secret") {
+				t.Errorf("request = %+v", req)
+			}
+			w.Write([]byte(`{"MerchantRequestID":"29115-34620561-1","CheckoutRequestID":"ws_CO_191220191020363925","ResponseCode":"0","ResponseDescription":"Success. Request accepted for processing","CustomerMessage":"Success. Request accepted for processing"}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestMpesaInitiate(t *testing.T) {
+	var tokens int
+	srv := mockDaraja(t, &tokens)
+	defer srv.Close()
+
+	m := &Mpesa{
+		BaseURL: srv.URL, ConsumerKey: "key", ConsumerSecret: "secret", ShortCode: "174379", PassKey: "pass",
+		CallbackURL: "https://api.example.com/api/public/payments/mpesa/callback", CallbackToken: "cb-secret",
+	}
+	charge := &payment.Charge{PaymentID: uuid.New(), Amount: 15000, Currency: "KES", Phone: "+254712345678", Reference: "a1b2c3d4", Description: "Order a1b2c3d4"}
+
+	started, err := m.Initiate(context.Background(), charge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if started.ProviderRef != "ws_CO_191220191020363925" {
+		t.Errorf("provider ref = %q", started.ProviderRef)
+	}
+
+	// the token is cached between calls
+	if _, err := m.Initiate(context.Background(), charge); err != nil || tokens != 1 {
+		t.Errorf("second initiate: err = %v, tokens fetched = %d", err, tokens)
+	}
+
+	bad := *charge
+	bad.Phone = "+254700000000"
+	if _, err := m.Initiate(context.Background(), &bad); !errors.Is(err, payment.ErrProvider) {
+		t.Errorf("invalid phone: err = %v", err)
+	}
+
+	cents := *charge
+	cents.Amount = 15050
+	if _, err := m.Initiate(context.Background(), &cents); !errors.Is(err, payment.ErrProvider) {
+		t.Errorf("fractional amount: err = %v", err)
+	}
+}
+
+func TestMpesaCallback(t *testing.T) {
+	m := &Mpesa{CallbackToken: "cb-secret"}
+
+	post := func(token, body string) *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/api/public/payments/mpesa/callback?token="+token, strings.NewReader(body))
+	}
+	paid := `{"Body":{"stkCallback":{"MerchantRequestID":"29115-34620561-1","CheckoutRequestID":"ws_CO_191220191020363925","ResultCode":0,"ResultDesc":"The service request is processed successfully.","CallbackMetadata":{"Item":[{"Name":"Amount","Value":150.00},{"Name":"MpesaReceiptNumber","Value":"NLJ7RT61SV"},{"Name":"Balance"},{"Name":"TransactionDate","Value":20191219102115},{"Name":"PhoneNumber","Value":254712345678}]}}}}`
+	cancelled := `{"Body":{"stkCallback":{"MerchantRequestID":"29115-34620561-1","CheckoutRequestID":"ws_CO_191220191020363925","ResultCode":1032,"ResultDesc":"Request cancelled by user"}}}`
+
+	if _, err := m.ParseCallback(post("forged", paid)); err != ErrInvalidCallback {
+		t.Errorf("forged token: err = %v", err)
+	}
+
+	res, err := m.ParseCallback(post("cb-secret", paid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Succeeded || res.ProviderRef != "ws_CO_191220191020363925" || res.Receipt != "NLJ7RT61SV" {
+		t.Errorf("paid result = %+v", res)
+	}
+
+	res, err = m.ParseCallback(post("cb-secret", cancelled))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Succeeded || res.Reason != "Request cancelled by user" {
+		t.Errorf("cancelled result = %+v", res)
+	}
+}
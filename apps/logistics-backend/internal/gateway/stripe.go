@@ -0,0 +1,241 @@
+package gateway
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"logistics-backend/internal/domain/payment"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const StripeURL = "https://api.stripe.com"
+
+// Stripe takes card payments with PaymentIntents: the client confirms the intent with its
+// client secret (Stripe.js) and Stripe reports the outcome to the webhook endpoint, signing
+// every event with the endpoint's secret.
+type Stripe struct {
+	BaseURL       string // StripeURL or a local stub
+	SecretKey     string
+	WebhookSecret string        // whsec_..., from the webhook endpoint's settings
+	Tolerance     time.Duration // how old a signed event may be; 0 means 5 minutes
+
+	Client *http.Client
+}
+
+type stripeIntent struct {
+	ID                 string `json:"id"`
+	ClientSecret       string `json:"client_secret"`
+	Status             string `json:"status"`
+	LatestCharge       string `json:"latest_charge"`
+	CancellationReason string `json:"cancellation_reason"`
+}
+
+type stripeRefund struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type stripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+type stripeError struct {
+	Error struct {
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (s *Stripe) Method() payment.PaymentMethod {
+	return payment.MethodStripe
+}
+
+// Initiate creates a PaymentIntent for the charge. The payment ID is the idempotency key, so
+// a retried request gets the same intent back.
+func (s *Stripe) Initiate(ctx context.Context, c *payment.Charge) (*payment.Initiation, error) {
+	form := url.Values{
+		"amount":                             {strconv.FormatInt(c.Amount, 10)},
+		"currency":                           {strings.ToLower(c.Currency)},
+		"description":                        {c.Description},
+		"automatic_payment_methods[enabled]": {"true"},
+		"metadata[payment_id]":               {c.PaymentID.String()},
+		"metadata[reference]":                {c.Reference},
+	}
+
+	var pi stripeIntent
+	if err := s.do(ctx, http.MethodPost, "/v1/payment_intents", form, "pi-"+c.PaymentID.String(), &pi); err != nil {
+		return nil, err
+	}
+	return &payment.Initiation{ProviderRef: pi.ID, ClientSecret: pi.ClientSecret}, nil
+}
+
+// Resume returns the client secret of the payment's intent again, so the customer can retry
+// after a declined card or a closed page.
+func (s *Stripe) Resume(ctx context.Context, p *payment.Payment) (*payment.Initiation, error) {
+	var pi stripeIntent
+	if err := s.do(ctx, http.MethodGet, "/v1/payment_intents/"+url.PathEscape(*p.ProviderRef), nil, "", &pi); err != nil {
+		return nil, err
+	}
+	return &payment.Initiation{ProviderRef: pi.ID, ClientSecret: pi.ClientSecret}, nil
+}
+
+// Refund refunds part of the payment's intent, keyed by the refund ID.
+func (s *Stripe) Refund(ctx context.Context, p *payment.Payment, r *payment.Refund) (string, error) {
+	form := url.Values{
+		"payment_intent":      {*p.ProviderRef},
+		"amount":              {strconv.FormatInt(r.Amount, 10)},
+		"metadata[refund_id]": {r.ID.String()},
+	}
+	if r.Reason != nil {
+		form.Set("metadata[reason]", *r.Reason)
+	}
+
+	var re stripeRefund
+	if err := s.do(ctx, http.MethodPost, "/v1/refunds", form, "re-"+r.ID.String(), &re); err != nil {
+		return "", err
+	}
+	if re.Status == "failed" || re.Status == "canceled" {
+		return "", fmt.Errorf("%w: stripe: refund %s %s", payment.ErrProvider, re.ID, re.Status)
+	}
+	return re.ID, nil
+}
+
+// ParseCallback verifies the Stripe-Signature header and reads the event. Only final outcomes
+// are returned: payment_intent.payment_failed leaves the intent open for another attempt, so
+// it and every other event type give a nil result.
+func (s *Stripe) ParseCallback(r *http.Request) (*payment.Result, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if !s.verify(r.Header.Get("Stripe-Signature"), body, time.Now()) {
+		return nil, ErrInvalidCallback
+	}
+
+	var evt stripeEvent
+	if err := json.Unmarshal(body, &evt); err != nil {
+		return nil, fmt.Errorf("stripe: decode event: %w", err)
+	}
+
+	var pi stripeIntent
+	switch evt.Type {
+	case "payment_intent.succeeded", "payment_intent.canceled":
+		if err := json.Unmarshal(evt.Data.Object, &pi); err != nil {
+			return nil, fmt.Errorf("stripe: decode %s: %w", evt.Type, err)
+		}
+	default:
+		return nil, nil
+	}
+
+	if evt.Type == "payment_intent.succeeded" {
+		return &payment.Result{ProviderRef: pi.ID, Succeeded: true, Receipt: pi.LatestCharge}, nil
+	}
+	reason := "payment cancelled"
+	if pi.CancellationReason != "" {
+		reason += ": " + pi.CancellationReason
+	}
+	return &payment.Result{ProviderRef: pi.ID, Reason: reason}, nil
+}
+
+// verify checks header's v1 signatures, the hex HMAC-SHA256 of "<t>.<body>" under the webhook
+// secret, and that t is within the tolerance of now.
+func (s *Stripe) verify(header string, body []byte, now time.Time) bool {
+	if s.WebhookSecret == "" {
+		return false
+	}
+
+	var (
+		ts   int64
+		sigs []string
+	)
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts, _ = strconv.ParseInt(v, 10, 64)
+		case "v1":
+			sigs = append(sigs, v)
+		}
+	}
+
+	tolerance := s.Tolerance
+	if tolerance == 0 {
+		tolerance = 5 * time.Minute
+	}
+	if ts == 0 || now.Sub(time.Unix(ts, 0)).Abs() > tolerance {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.WebhookSecret))
+	fmt.Fprintf(mac, "%d.", ts)
+	mac.Write(body)
+	want := mac.Sum(nil)
+
+	for _, sig := range sigs {
+		if got, err := hex.DecodeString(sig); err == nil && hmac.Equal(got, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Stripe) do(ctx context.Context, method, path string, form url.Values, idempotencyKey string, out any) error {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(s.BaseURL, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.SecretKey, "")
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	res, err := s.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("stripe: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		var e stripeError
+		if json.NewDecoder(res.Body).Decode(&e) == nil && e.Error.Message != "" {
+			if res.StatusCode < 500 && res.StatusCode != http.StatusTooManyRequests {
+				return fmt.Errorf("%w: stripe: %s (%s)", payment.ErrProvider, e.Error.Message, e.Error.Type)
+			}
+			return fmt.Errorf("stripe: %s (%s)", e.Error.Message, e.Error.Type)
+		}
+		return fmt.Errorf("stripe: unexpected status %s", res.Status)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("stripe: decode response: %w", err)
+	}
+	return nil
+}
+
+func (s *Stripe) client() *http.Client {
+	if s.Client != nil {
+		return s.Client
+	}
+	return &http.Client{Timeout: 30 * time.Second}
+}
@@ -0,0 +1,163 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"logistics-backend/internal/domain/payment"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", "stripe", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// stubStripe answers the Stripe API calls with recorded responses.
+func stubStripe(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if key, _, _ := r.BasicAuth(); key != "sk_test_123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"message":"Invalid API Key provided","type":"invalid_request_error"}}`))
+			return
+		}
+		r.ParseForm()
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/payment_intents":
+			if r.PostForm.Get("amount") == "100" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write(fixture(t, "error_amount_too_small.json"))
+				return
+			}
+			if r.PostForm.Get("currency") != "kes" || r.Header.Get("Idempotency-Key") == "" || r.PostForm.Get("metadata[payment_id]") == "" {
+				t.Errorf("create intent: form = %v, idempotency key = %q", r.PostForm, r.Header.Get("Idempotency-Key"))
+			}
+			w.Write(fixture(t, "payment_intent.json"))
+
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/payment_intents/pi_3PzK2LJx9sQ1aB0c1Xy2Zw3V":
+			w.Write(fixture(t, "payment_intent.json"))
+
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/refunds":
+			if r.PostForm.Get("payment_intent") != "pi_3PzK2LJx9sQ1aB0c1Xy2Zw3V" || r.PostForm.Get("amount") != "100000" {
+				t.Errorf("refund: form = %v", r.PostForm)
+			}
+			w.Write(fixture(t, "refund.json"))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"message":"Unrecognized request URL","type":"invalid_request_error"}}`))
+		}
+	}))
+}
+
+func TestStripePaymentIntents(t *testing.T) {
+	srv := stubStripe(t)
+	defer srv.Close()
+	s := &Stripe{BaseURL: srv.URL, SecretKey: "sk_test_123"}
+
+	charge := &payment.Charge{PaymentID: uuid.New(), Amount: 250000, Currency: "KES", Reference: "3f2a9c1e", Description: "Order 3f2a9c1e"}
+	started, err := s.Initiate(context.Background(), charge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if started.ProviderRef != "pi_3PzK2LJx9sQ1aB0c1Xy2Zw3V" || started.ClientSecret == "" {
+		t.Errorf("initiation = %+v", started)
+	}
+
+	ref := started.ProviderRef
+	resumed, err := s.Resume(context.Background(), &payment.Payment{ProviderRef: &ref})
+	if err != nil || resumed.ClientSecret != started.ClientSecret {
+		t.Errorf("resume = %+v, %v", resumed, err)
+	}
+
+	small := *charge
+	small.Amount = 100
+	if _, err := s.Initiate(context.Background(), &small); !errors.Is(err, payment.ErrProvider) {
+		t.Errorf("rejected intent: err = %v", err)
+	}
+
+	refundID, err := s.Refund(context.Background(), &payment.Payment{ProviderRef: &ref}, &payment.Refund{ID: uuid.New(), Amount: 100000})
+	if err != nil || refundID != "re_3PzK2LJx9sQ1aB0c1Ab2Cd3E" {
+		t.Errorf("refund = %q, %v", refundID, err)
+	}
+}
+
+func sign(secret string, ts time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", ts.Unix())
+	mac.Write(body)
+	return "t=" + strconv.FormatInt(ts.Unix(), 10) + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestStripeWebhook(t *testing.T) {
+	s := &Stripe{WebhookSecret: "whsec_test"}
+
+	post := func(name, sig string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/api/public/payments/stripe/webhook", bytes.NewReader(fixture(t, name)))
+		r.Header.Set("Stripe-Signature", sig)
+		return r
+	}
+	signed := func(name string) *http.Request {
+		return post(name, sign("whsec_test", time.Now(), fixture(t, name)))
+	}
+
+	res, err := s.ParseCallback(signed("event_payment_intent_succeeded.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Succeeded || res.ProviderRef != "pi_3PzK2LJx9sQ1aB0c1Xy2Zw3V" || res.Receipt != "ch_3PzK2LJx9sQ1aB0c1Kl6Mn7O" {
+		t.Errorf("succeeded = %+v", res)
+	}
+
+	res, err = s.ParseCallback(signed("event_payment_intent_canceled.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Succeeded || res.Reason != "payment cancelled: abandoned" {
+		t.Errorf("canceled = %+v", res)
+	}
+
+	// a declined card leaves the intent open for another attempt
+	if res, err := s.ParseCallback(signed("event_payment_intent_payment_failed.json")); res != nil || err != nil {
+		t.Errorf("payment_failed = %+v, %v", res, err)
+	}
+
+	body := fixture(t, "event_payment_intent_succeeded.json")
+	for name, sig := range map[string]string{
+		"wrong secret": sign("whsec_other", time.Now(), body),
+		"too old":      sign("whsec_test", time.Now().Add(-10*time.Minute), body),
+		"missing":      "",
+		"malformed":    "t=abc,v1=zz",
+	} {
+		if _, err := s.ParseCallback(post("event_payment_intent_succeeded.json", sig)); err != ErrInvalidCallback {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+
+	// several v1 signatures are sent while the endpoint secret is being rolled
+	now := time.Now()
+	_, current, _ := strings.Cut(sign("whsec_test", now, body), ",")
+	both := sign("whsec_old", now, body) + "," + current
+	if _, err := s.ParseCallback(post("event_payment_intent_succeeded.json", both)); err != nil {
+		t.Errorf("rolled secret: err = %v", err)
+	}
+}
@@ -2,10 +2,15 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"log"
+	"logistics-backend/internal/domain/apikey"
+	"logistics-backend/internal/domain/session"
+	"logistics-backend/internal/domain/user"
 	"net/http"
-	"os"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -14,57 +19,166 @@ import (
 type contextKey string
 
 const (
-	ContextUserID contextKey = "userID"
-	ContextRole   contextKey = "role"
+	ContextUserID      contextKey = "userID"
+	ContextRole        contextKey = "role"
+	ContextTokenID     contextKey = "tokenID"
+	ContextTokenExpiry contextKey = "tokenExpiry"
+	ContextMustChange  contextKey = "mustChangePassword"
+	ContextMustEnroll  contextKey = "mustEnrollMFA"
+	ContextScopes      contextKey = "apiKeyScopes"
+	ContextStoreID     contextKey = "apiKeyStoreID"
 )
 
-func JWTAuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer") {
-			http.Error(w, "Missing or invalid Authorization header", http.StatusUnauthorized)
-			return
-		}
+// TokenValidator decides whether a signature-valid access token is still honoured,
+// i.e. it was not revoked and carries the user's current token version.
+type TokenValidator interface {
+	ValidateAccessToken(ctx context.Context, userID uuid.UUID, jti string, version int) error
+}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+// APIKeyValidator resolves a raw API key to the active, store-scoped key it belongs to.
+type APIKeyValidator interface {
+	AuthenticateAPIKey(ctx context.Context, raw string) (*apikey.APIKey, error)
+}
 
-		jwtSecret := os.Getenv("JWT_SECRET")
-		if jwtSecret == "" {
-			http.Error(w, "Server misconfigured (no JWT_SECRET)", http.StatusInternalServerError)
-			return
-		}
+// TokenVerifier resolves the public key a signed access token is checked against, by its kid.
+type TokenVerifier interface {
+	Keyfunc(t *jwt.Token) (any, error)
+	Methods() []string
+}
+
+// JWTAuthMiddleware authenticates a user access token, or an API key sent as X-API-Key
+// or as a Bearer token starting with lk_.
+func JWTAuthMiddleware(verifier TokenVerifier, tokens TokenValidator, keys APIKeyValidator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if raw := apiKeyFromRequest(r); raw != "" {
+				authenticateAPIKey(w, r, next, keys, raw)
+				return
+			}
+
+			if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer") {
+				http.Error(w, "Missing or invalid Authorization header", http.StatusUnauthorized)
+				return
+			}
+
+			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+
+			token, err := jwt.Parse(tokenString, verifier.Keyfunc, jwt.WithValidMethods(verifier.Methods()))
+
+			if err != nil || !token.Valid {
+				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+
+			claims, ok := token.Claims.(jwt.MapClaims)
+			if !ok {
+				http.Error(w, "Invalid token claims", http.StatusUnauthorized)
+				return
+			}
+
+			// Extract user ID, role and token version from claims
+			userID, ok1 := claims["sub"].(string)
+			role, ok2 := claims["role"].(string)
+			version, ok3 := claims["ver"].(float64)
+			if !ok1 || !ok2 || !ok3 {
+				http.Error(w, "Missing token claims", http.StatusUnauthorized)
+				return
+			}
+			jti, _ := claims["jti"].(string)
+
+			uid, err := uuid.Parse(userID)
+			if err != nil {
+				http.Error(w, "Invalid token subject", http.StatusUnauthorized)
+				return
+			}
 
-		token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-			// Check the signing method is HMAC (HS256)
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
+			// Reject revoked tokens and tokens issued before a "revoke all sessions"
+			// Users that must change their password or set up 2FA are let through here
+			// and stopped by RequirePasswordChanged / RequireMFAEnrolled
+			mustChange, mustEnroll := false, false
+			if err := tokens.ValidateAccessToken(r.Context(), uid, jti, int(version)); err != nil {
+				switch {
+				case user.StatusCode(err) != "":
+					writeAccountError(w, err, user.StatusCode(err))
+					return
+				case errors.Is(err, session.ErrPasswordChangeRequired):
+					mustChange = true
+				case errors.Is(err, session.ErrMFAEnrollmentRequired):
+					mustEnroll = true
+				default:
+					http.Error(w, "Token has been revoked", http.StatusUnauthorized)
+					return
+				}
 			}
-			return []byte(jwtSecret), nil
+
+			var expiresAt time.Time
+			if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+				expiresAt = exp.Time
+			}
+
+			// Add to context
+			ctx := context.WithValue(r.Context(), ContextUserID, userID)
+			ctx = context.WithValue(ctx, ContextRole, role)
+			ctx = context.WithValue(ctx, ContextTokenID, jti)
+			ctx = context.WithValue(ctx, ContextTokenExpiry, expiresAt)
+			ctx = context.WithValue(ctx, ContextMustChange, mustChange)
+			ctx = context.WithValue(ctx, ContextMustEnroll, mustEnroll)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
+	}
+}
 
-		if err != nil || !token.Valid {
-			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
-			return
+// authenticateAPIKey lets the request act as the key's owner, limited to the key's scopes
+// (enforced by RequirePermission) and to the key's store.
+func authenticateAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, keys APIKeyValidator, raw string) {
+	k, err := keys.AuthenticateAPIKey(r.Context(), raw)
+	if err != nil {
+		if !errors.Is(err, apikey.ErrInvalidAPIKey) {
+			log.Printf("api key authentication failed: %v", err)
 		}
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			http.Error(w, "Invalid token claims", http.StatusUnauthorized)
-			return
-		}
+	// store owners are admins, see RolePermissions[user.Admin]
+	ctx := context.WithValue(r.Context(), ContextUserID, k.OwnerID.String())
+	ctx = context.WithValue(ctx, ContextRole, string(user.Admin))
+	ctx = context.WithValue(ctx, ContextScopes, []string(k.Scopes))
+	ctx = context.WithValue(ctx, ContextStoreID, k.StoreID)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
 
-		// Extract user ID and role from claims
-		userID, ok1 := claims["sub"].(string)
-		role, ok2 := claims["role"].(string)
-		if !ok1 || !ok2 {
-			http.Error(w, "Missing token claims", http.StatusUnauthorized)
+func apiKeyFromRequest(r *http.Request) string {
+	if raw := r.Header.Get("X-API-Key"); raw != "" {
+		return raw
+	}
+	if raw := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "); strings.HasPrefix(raw, apikey.KeyPrefix) {
+		return raw
+	}
+	return ""
+}
+
+// GetAPIKeyStoreFromContext returns the store an API key is bound to; ok is false for user sessions.
+func GetAPIKeyStoreFromContext(ctx context.Context) (uuid.UUID, bool) {
+	storeID, ok := ctx.Value(ContextStoreID).(uuid.UUID)
+	return storeID, ok
+}
+
+// RequireUserSession rejects API keys on routes that only make sense for a signed-in user,
+// such as logout, password changes and 2FA.
+func RequireUserSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, isKey := GetAPIKeyStoreFromContext(r.Context()); isKey {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error":  "Forbidden",
+				"detail": "this route requires a user session, not an API key",
+			})
 			return
 		}
-
-		// Add to context
-		ctx := context.WithValue(r.Context(), ContextUserID, userID)
-		ctx = context.WithValue(ctx, ContextRole, role)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r)
 	})
 }
 
@@ -95,3 +209,72 @@ func GetDriverIDFromContext(ctx context.Context) (uuid.UUID, error) {
 
 	return uuid.Parse(idStr)
 }
+
+// GetUserFromContext returns the authenticated user's ID and role, whatever the role.
+func GetUserFromContext(ctx context.Context) (uuid.UUID, string, error) {
+	role, ok := ctx.Value(ContextRole).(string)
+	if !ok {
+		return uuid.Nil, "", errors.New("missing role in context")
+	}
+
+	idStr, ok := ctx.Value(ContextUserID).(string)
+	if !ok {
+		return uuid.Nil, "", errors.New("missing user ID in context")
+	}
+
+	id, err := uuid.Parse(idStr)
+	return id, role, err
+}
+
+// GetTokenFromContext returns the jti and expiry of the access token that authenticated the request.
+func GetTokenFromContext(ctx context.Context) (string, time.Time) {
+	jti, _ := ctx.Value(ContextTokenID).(string)
+	exp, _ := ctx.Value(ContextTokenExpiry).(time.Time)
+	return jti, exp
+}
+
+// RequirePasswordChanged blocks users that still have to change their password; mount it on
+// every protected route except the ones needed to actually change it.
+func RequirePasswordChanged(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if mustChange, _ := r.Context().Value(ContextMustChange).(bool); mustChange {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error":  "Password change required",
+				"detail": "change your password via /api/auth/change-password before continuing",
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireMFAEnrolled blocks users whose role requires 2FA until they have set it up; mount it on
+// every protected route except the enrollment ones.
+func RequireMFAEnrolled(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if mustEnroll, _ := r.Context().Value(ContextMustEnroll).(bool); mustEnroll {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error":  "Two-factor authentication required",
+				"detail": "set up two-factor authentication via /api/auth/mfa/enroll before continuing",
+				"code":   "mfa_enrollment_required",
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeAccountError rejects tokens of accounts that are unverified, suspended or inactive,
+// with a code clients can branch on.
+func writeAccountError(w http.ResponseWriter, err error, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": err.Error(),
+		"code":  code,
+	})
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"logistics-backend/internal/domain/user"
+)
+
+// Permission is a single action a role may perform on the API.
+type Permission string
+
+const (
+	PermUsersList   Permission = "users:list"
+	PermUsersRead   Permission = "users:read"
+	PermUsersWrite  Permission = "users:write"
+	PermUsersDelete Permission = "users:delete"
+	PermUsersSelf   Permission = "users:self"
+
+	PermInvitesManage Permission = "invites:manage"
+
+	PermAPIKeysManage  Permission = "apikeys:manage"
+	PermWebhooksManage Permission = "webhooks:manage"
+
+	PermOrdersList   Permission = "orders:list"
+	PermOrdersRead   Permission = "orders:read"
+	PermOrdersCreate Permission = "orders:create"
+	PermOrdersWrite  Permission = "orders:write"
+	PermOrdersDelete Permission = "orders:delete"
+	PermOrdersAssign Permission = "orders:assign"
+
+	PermInventoriesRead  Permission = "inventories:read"
+	PermInventoriesWrite Permission = "inventories:write"
+
+	PermDriversList    Permission = "drivers:list"
+	PermDriversRead    Permission = "drivers:read"
+	PermDriversWrite   Permission = "drivers:write"
+	PermDriversProfile Permission = "drivers:profile"
+	PermDriversSelf    Permission = "drivers:self"
+
+	PermDocumentsUpload Permission = "documents:upload"
+	PermDocumentsRead   Permission = "documents:read"
+	PermDocumentsVerify Permission = "documents:verify"
+
+	PermDeliveriesList   Permission = "deliveries:list"
+	PermDeliveriesRead   Permission = "deliveries:read"
+	PermDeliveriesWrite  Permission = "deliveries:write"
+	PermDeliveriesAccept Permission = "deliveries:accept"
+	PermDeliveriesDelete Permission = "deliveries:delete"
+
+	PermPaymentsCreate Permission = "payments:create"
+	PermPaymentsRead   Permission = "payments:read"
+	PermPaymentsList   Permission = "payments:list"
+	PermPaymentsManage Permission = "payments:manage"
+
+	PermFeedbackCreate Permission = "feedback:create"
+	PermFeedbackRead   Permission = "feedback:read"
+
+	PermNotificationsRead   Permission = "notifications:read"
+	PermNotificationsManage Permission = "notifications:manage"
+
+	PermStoresRead  Permission = "stores:read"
+	PermStoresWrite Permission = "stores:write"
+)
+
+// RolePermissions maps each role to the permissions it holds.
+// Admins hold every permission except the driver-only self-service ones.
+var RolePermissions = map[user.Role][]Permission{
+	user.Admin: {
+		PermUsersList, PermUsersRead, PermUsersWrite, PermUsersDelete, PermUsersSelf,
+		PermInvitesManage,
+		PermAPIKeysManage, PermWebhooksManage,
+		PermOrdersList, PermOrdersRead, PermOrdersCreate, PermOrdersWrite, PermOrdersDelete, PermOrdersAssign,
+		PermInventoriesRead, PermInventoriesWrite,
+		PermDriversList, PermDriversRead, PermDriversWrite, PermDriversProfile,
+		PermDocumentsUpload, PermDocumentsRead, PermDocumentsVerify,
+		PermDeliveriesList, PermDeliveriesRead, PermDeliveriesWrite, PermDeliveriesDelete,
+		PermPaymentsCreate, PermPaymentsRead, PermPaymentsList, PermPaymentsManage,
+		PermFeedbackCreate, PermFeedbackRead,
+		PermNotificationsRead, PermNotificationsManage,
+		PermStoresRead, PermStoresWrite,
+	},
+	user.Driver: {
+		PermUsersSelf,
+		PermOrdersRead,
+		PermInventoriesRead,
+		PermDriversProfile, PermDriversSelf,
+		PermDocumentsUpload, PermDocumentsRead,
+		PermDeliveriesRead, PermDeliveriesWrite, PermDeliveriesAccept,
+		PermNotificationsRead,
+		PermStoresRead,
+	},
+	user.Customer: {
+		PermUsersSelf,
+		PermOrdersRead,
+		PermInventoriesRead,
+		PermPaymentsCreate, PermPaymentsRead,
+		PermFeedbackCreate,
+		PermNotificationsRead,
+		PermStoresRead,
+	},
+	user.Guest: {
+		PermInventoriesRead,
+		PermStoresRead,
+	},
+}
+
+var rolePermissionSet = buildPermissionSet(RolePermissions)
+
+func buildPermissionSet(rp map[user.Role][]Permission) map[user.Role]map[Permission]bool {
+	set := make(map[user.Role]map[Permission]bool, len(rp))
+	for role, perms := range rp {
+		set[role] = make(map[Permission]bool, len(perms))
+		for _, p := range perms {
+			set[role][p] = true
+		}
+	}
+	return set
+}
+
+// HasPermission reports whether the role holds the permission.
+func HasPermission(role user.Role, p Permission) bool {
+	return rolePermissionSet[role][p]
+}
+
+// RequirePermission only lets the request through when the role placed in the
+// context by JWTAuthMiddleware holds the permission, otherwise it responds 403.
+// Requests made with an API key additionally need the permission among the key's scopes.
+func RequirePermission(p Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, _ := r.Context().Value(ContextRole).(string)
+			if !HasPermission(user.Role(role), p) {
+				writeForbidden(w, p)
+				return
+			}
+			if scopes, isKey := r.Context().Value(ContextScopes).([]string); isKey && !slices.Contains(scopes, string(p)) {
+				writeForbidden(w, p)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeForbidden(w http.ResponseWriter, p Permission) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error":  "Forbidden",
+		"detail": "missing permission " + string(p),
+	})
+}
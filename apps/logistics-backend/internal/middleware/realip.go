@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RealIP replaces r.RemoteAddr with the client address a trusted proxy reports. Kong appends the
+// address it saw to X-Forwarded-For, so only the right-most hop is taken, and only on requests
+// that came from one of proxies; anything else a client puts in the header is ignored.
+func RealIP(proxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip, ok := forwardedFor(r, proxies); ok {
+				r.RemoteAddr = ip.String()
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func forwardedFor(r *http.Request, proxies []netip.Prefix) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	peer, err := netip.ParseAddr(host)
+	if err != nil || !trusted(peer.Unmap(), proxies) {
+		return netip.Addr{}, false
+	}
+
+	hops := r.Header.Values("X-Forwarded-For")
+	if len(hops) == 0 {
+		return netip.Addr{}, false
+	}
+	last := hops[len(hops)-1]
+	if i := strings.LastIndexByte(last, ','); i >= 0 {
+		last = last[i+1:]
+	}
+
+	ip, err := netip.ParseAddr(strings.TrimSpace(last))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
+
+func trusted(ip netip.Addr, proxies []netip.Prefix) bool {
+	for _, p := range proxies {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseProxies reads a comma separated list of proxy addresses or CIDR ranges.
+func ParseProxies(s string) ([]netip.Prefix, error) {
+	var proxies []netip.Prefix
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+
+		if !strings.Contains(f, "/") {
+			ip, err := netip.ParseAddr(f)
+			if err != nil {
+				return nil, fmt.Errorf("invalid proxy address %q: %w", f, err)
+			}
+			proxies = append(proxies, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+			continue
+		}
+
+		p, err := netip.ParsePrefix(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy range %q: %w", f, err)
+		}
+		proxies = append(proxies, p.Masked())
+	}
+	return proxies, nil
+}
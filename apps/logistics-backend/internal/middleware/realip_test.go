@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	proxies, err := ParseProxies("172.28.0.10, 10.1.0.0/16")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{"direct client keeps its address", "203.0.113.7:5000", []string{"1.2.3.4"}, "203.0.113.7:5000"},
+		{"trusted proxy without header", "172.28.0.10:5000", nil, "172.28.0.10:5000"},
+		{"trusted proxy", "172.28.0.10:5000", []string{"198.51.100.4"}, "198.51.100.4"},
+		{"spoofed hops are skipped", "172.28.0.10:5000", []string{"1.2.3.4, 198.51.100.4"}, "198.51.100.4"},
+		{"last of repeated headers", "10.1.2.3:5000", []string{"1.2.3.4", "198.51.100.4"}, "198.51.100.4"},
+		{"garbage hop is ignored", "172.28.0.10:5000", []string{"1.2.3.4, not-an-ip"}, "172.28.0.10:5000"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got string
+			h := RealIP(proxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = c.remoteAddr
+			for _, v := range c.forwarded {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != c.want {
+				t.Errorf("RemoteAddr = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseProxiesRejectsGarbage(t *testing.T) {
+	if _, err := ParseProxies("172.28.0.10,kong"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
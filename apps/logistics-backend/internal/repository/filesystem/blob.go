@@ -0,0 +1,70 @@
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// LocalBlobStorage stores uploaded files under a base directory on local disk.
+type LocalBlobStorage struct {
+	baseDir string
+}
+
+func NewLocalBlobStorage(baseDir string) (*LocalBlobStorage, error) {
+	if err := os.MkdirAll(baseDir, 0o750); err != nil {
+		return nil, fmt.Errorf("create blob dir: %w", err)
+	}
+	return &LocalBlobStorage{baseDir: baseDir}, nil
+}
+
+// Save writes the file under a random name (keeping the extension) and returns
+// the path relative to the base directory.
+func (s *LocalBlobStorage) Save(ctx context.Context, name string, r io.Reader) (string, error) {
+	rel := uuid.New().String() + strings.ToLower(filepath.Ext(name))
+
+	f, err := os.OpenFile(filepath.Join(s.baseDir, rel), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o640)
+	if err != nil {
+		return "", fmt.Errorf("create blob: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return "", fmt.Errorf("write blob: %w", err)
+	}
+
+	return rel, nil
+}
+
+func (s *LocalBlobStorage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	full, err := s.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(full)
+}
+
+func (s *LocalBlobStorage) Delete(ctx context.Context, path string) error {
+	full, err := s.resolve(path)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(full); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("delete blob: %w", err)
+	}
+	return nil
+}
+
+// resolve keeps paths inside the base directory.
+func (s *LocalBlobStorage) resolve(path string) (string, error) {
+	full := filepath.Join(s.baseDir, filepath.Clean("/"+path))
+	if !strings.HasPrefix(full, filepath.Clean(s.baseDir)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("invalid blob path: %s", path)
+	}
+	return full, nil
+}
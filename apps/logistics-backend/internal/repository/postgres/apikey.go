@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"logistics-backend/internal/application"
+	"logistics-backend/internal/domain/apikey"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type APIKeyRepository struct {
+	exec sqlx.ExtContext
+}
+
+func NewAPIKeyRepository(db *sqlx.DB) *APIKeyRepository {
+	return &APIKeyRepository{exec: db}
+}
+
+func (r *APIKeyRepository) execFromCtx(ctx context.Context) sqlx.ExtContext {
+	if tx := application.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.exec
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, k *apikey.APIKey) error {
+	query := `
+		INSERT INTO api_keys (store_id, owner_id, name, prefix, key_hash, scopes, expires_at)
+		VALUES (:store_id, :owner_id, :name, :prefix, :key_hash, :scopes, :expires_at)
+		RETURNING id, created_at
+	`
+	rows, err := sqlx.NamedQueryContext(ctx, r.execFromCtx(ctx), query, k)
+	if err != nil {
+		return fmt.Errorf("insert api key: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&k.ID, &k.CreatedAt); err != nil {
+			return fmt.Errorf("scanning new api key id: %w", err)
+		}
+	} else {
+		return fmt.Errorf("no id returned after scan")
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*apikey.APIKey, error) {
+	query := `
+		SELECT id, store_id, owner_id, name, prefix, key_hash, scopes, last_used_at, expires_at, revoked_at, created_at
+		FROM api_keys
+		WHERE prefix = $1
+	`
+
+	var k apikey.APIKey
+	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &k, query, prefix)
+	return &k, err
+}
+
+func (r *APIKeyRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*apikey.APIKey, error) {
+	query := `
+		SELECT id, store_id, owner_id, name, prefix, key_hash, scopes, last_used_at, expires_at, revoked_at, created_at
+		FROM api_keys
+		WHERE owner_id = $1
+		ORDER BY created_at DESC
+	`
+
+	var keys []*apikey.APIKey
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &keys, query, ownerID)
+	return keys, err
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, id, ownerID uuid.UUID) error {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = NOW()
+		WHERE id = $1 AND owner_id = $2 AND revoked_at IS NULL
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, id, ownerID)
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return apikey.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	// skip the write when the key was already used within the last minute,
+	// so a busy integration does not update the row on every request
+	query := `
+		UPDATE api_keys
+		SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("touch api key: %w", err)
+	}
+
+	return nil
+}
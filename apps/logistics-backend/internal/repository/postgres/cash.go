@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"logistics-backend/internal/application"
+	"logistics-backend/internal/domain/payment"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	cashCollectionColumns = `id, payment_id, order_id, delivery_id, driver_id, admin_id, amount, currency, collected_at`
+	cashRemittanceColumns = `id, driver_id, admin_id, currency, declared_amount, received_amount, status, note, declared_at, confirmed_at, confirmed_by`
+)
+
+type CashRepository struct {
+	exec sqlx.ExtContext
+}
+
+func NewCashRepository(db *sqlx.DB) *CashRepository {
+	return &CashRepository{exec: db}
+}
+
+func (r *CashRepository) execFromCtx(ctx context.Context) sqlx.ExtContext {
+	if tx := application.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.exec
+}
+
+func (r *CashRepository) CreateCollection(ctx context.Context, c *payment.CashCollection) error {
+	query := `
+		INSERT INTO cash_collections (payment_id, order_id, delivery_id, driver_id, admin_id, amount, currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, collected_at
+	`
+
+	err := r.execFromCtx(ctx).QueryRowxContext(ctx, query, c.PaymentID, c.OrderID, c.DeliveryID, c.DriverID, c.AdminID, c.Amount, c.Currency).
+		Scan(&c.ID, &c.CollectedAt)
+	if err != nil {
+		return fmt.Errorf("insert cash collection: %w", err)
+	}
+	return nil
+}
+
+func (r *CashRepository) ListCollections(ctx context.Context, driverID uuid.UUID, limit, offset int) ([]*payment.CashCollection, error) {
+	query := `
+		SELECT ` + cashCollectionColumns + `
+		FROM cash_collections
+		WHERE driver_id = $1
+		ORDER BY collected_at DESC
+		LIMIT NULLIF($2, 0) OFFSET $3
+	`
+
+	var collections []*payment.CashCollection
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &collections, query, driverID, limit, offset)
+	return collections, err
+}
+
+// LockDriver locks the driver row so a driver's remittances are checked against their balance one at a time.
+func (r *CashRepository) LockDriver(ctx context.Context, driverID uuid.UUID) error {
+	var id uuid.UUID
+	query := `SELECT id FROM drivers WHERE id = $1 FOR UPDATE`
+	return sqlx.GetContext(ctx, r.execFromCtx(ctx), &id, query, driverID)
+}
+
+func (r *CashRepository) CreateRemittance(ctx context.Context, rm *payment.CashRemittance) error {
+	query := `
+		INSERT INTO cash_remittances (driver_id, admin_id, currency, declared_amount, status, note)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, declared_at
+	`
+
+	err := r.execFromCtx(ctx).QueryRowxContext(ctx, query, rm.DriverID, rm.AdminID, rm.Currency, rm.DeclaredAmount, rm.Status, rm.Note).
+		Scan(&rm.ID, &rm.DeclaredAt)
+	if err != nil {
+		return fmt.Errorf("insert cash remittance: %w", err)
+	}
+	return nil
+}
+
+func (r *CashRepository) GetRemittanceForUpdate(ctx context.Context, id uuid.UUID) (*payment.CashRemittance, error) {
+	query := `SELECT ` + cashRemittanceColumns + ` FROM cash_remittances WHERE id = $1 FOR UPDATE`
+
+	var rm payment.CashRemittance
+	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &rm, query, id)
+	return &rm, err
+}
+
+func (r *CashRepository) ConfirmRemittance(ctx context.Context, rm *payment.CashRemittance) error {
+	query := `
+		UPDATE cash_remittances
+		SET received_amount = $2, status = $3, note = $4, confirmed_at = $5, confirmed_by = $6
+		WHERE id = $1
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, rm.ID, rm.ReceivedAmount, rm.Status, rm.Note, rm.ConfirmedAt, rm.ConfirmedBy)
+	if err != nil {
+		return fmt.Errorf("confirm cash remittance: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return payment.ErrRemittanceNotFound
+	}
+	return nil
+}
+
+func (r *CashRepository) ListRemittances(ctx context.Context, driverID, adminID *uuid.UUID, status *payment.RemittanceStatus, limit, offset int) ([]*payment.CashRemittance, error) {
+	query := `
+		SELECT ` + cashRemittanceColumns + `
+		FROM cash_remittances
+		WHERE ($1::uuid IS NULL OR driver_id = $1)
+		  AND ($2::uuid IS NULL OR admin_id = $2)
+		  AND ($3::text IS NULL OR status = $3)
+		ORDER BY declared_at DESC
+		LIMIT NULLIF($4, 0) OFFSET $5
+	`
+
+	var remittances []*payment.CashRemittance
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &remittances, query, driverID, adminID, status, limit, offset)
+	return remittances, err
+}
+
+// Balances sums collections and remittances per driver, store owner and currency. InHand is
+// left to the caller.
+func (r *CashRepository) Balances(ctx context.Context, driverID, adminID *uuid.UUID) ([]*payment.CashBalance, error) {
+	query := `
+		WITH c AS (
+			SELECT driver_id, admin_id, currency, SUM(amount) AS collected
+			FROM cash_collections
+			WHERE ($1::uuid IS NULL OR driver_id = $1) AND ($2::uuid IS NULL OR admin_id = $2)
+			GROUP BY driver_id, admin_id, currency
+		), r AS (
+			SELECT driver_id, admin_id, currency,
+				COALESCE(SUM(received_amount) FILTER (WHERE status = 'confirmed'), 0) AS remitted,
+				COALESCE(SUM(declared_amount) FILTER (WHERE status = 'pending'), 0) AS pending
+			FROM cash_remittances
+			WHERE ($1::uuid IS NULL OR driver_id = $1) AND ($2::uuid IS NULL OR admin_id = $2)
+			GROUP BY driver_id, admin_id, currency
+		)
+		SELECT driver_id, admin_id, currency,
+			COALESCE(c.collected, 0) AS collected,
+			COALESCE(r.remitted, 0) AS remitted,
+			COALESCE(r.pending, 0) AS pending
+		FROM c FULL JOIN r USING (driver_id, admin_id, currency)
+		ORDER BY driver_id, admin_id, currency
+	`
+
+	var balances []*payment.CashBalance
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &balances, query, driverID, adminID)
+	return balances, err
+}
+
+// DailyReport groups one store owner's collections and remittances by local day, driver and
+// currency. Remittances fall on the day they were declared.
+func (r *CashRepository) DailyReport(ctx context.Context, q payment.CashReportQuery) ([]*payment.CashDay, error) {
+	query := `
+		WITH c AS (
+			SELECT (collected_at AT TIME ZONE $2::text)::date AS day, driver_id, currency,
+				COUNT(*) AS collections, SUM(amount) AS collected
+			FROM cash_collections
+			WHERE admin_id = $1 AND collected_at >= $3 AND collected_at < $4
+			  AND ($5::uuid IS NULL OR driver_id = $5)
+			GROUP BY 1, driver_id, currency
+		), r AS (
+			SELECT (declared_at AT TIME ZONE $2::text)::date AS day, driver_id, currency,
+				SUM(declared_amount) AS declared,
+				COALESCE(SUM(received_amount), 0) AS received,
+				COALESCE(SUM(declared_amount) FILTER (WHERE status = 'pending'), 0) AS unconfirmed,
+				COALESCE(SUM(declared_amount - received_amount) FILTER (WHERE status = 'confirmed'), 0) AS shortfall
+			FROM cash_remittances
+			WHERE admin_id = $1 AND declared_at >= $3 AND declared_at < $4
+			  AND ($5::uuid IS NULL OR driver_id = $5)
+			GROUP BY 1, driver_id, currency
+		)
+		SELECT to_char(day, 'YYYY-MM-DD') AS day, driver_id, currency,
+			COALESCE(c.collections, 0) AS collections,
+			COALESCE(c.collected, 0) AS collected,
+			COALESCE(r.declared, 0) AS declared,
+			COALESCE(r.received, 0) AS received,
+			COALESCE(r.unconfirmed, 0) AS unconfirmed,
+			COALESCE(r.shortfall, 0) AS shortfall
+		FROM c FULL JOIN r USING (day, driver_id, currency)
+		ORDER BY day, driver_id, currency
+	`
+
+	var days []*payment.CashDay
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &days, query, q.AdminID, q.Location.String(), q.From, q.To, q.DriverID)
+	return days, err
+}
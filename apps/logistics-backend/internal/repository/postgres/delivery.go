@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"logistics-backend/internal/domain/delivery"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -123,14 +124,16 @@ func (r *DeliveryRepository) Accept(ctx context.Context, d *delivery.Delivery) e
 	return nil
 }
 
-func (r *DeliveryRepository) List(ctx context.Context) ([]*delivery.Delivery, error) {
+func (r *DeliveryRepository) ListByAdmin(ctx context.Context, adminID uuid.UUID) ([]*delivery.Delivery, error) {
 	query := `
-		SELECT id, order_id, driver_id, assigned_at, picked_up_at, delivered_at, status 
-		FROM deliveries
+		SELECT d.id, d.order_id, d.driver_id, d.assigned_at, d.picked_up_at, d.delivered_at, d.status
+		FROM deliveries d
+		JOIN orders o ON o.id = d.order_id
+		WHERE o.admin_id = $1
 	`
 	var deliveries []*delivery.Delivery
 
-	err := sqlx.SelectContext(ctx, r.exec, &deliveries, query)
+	err := sqlx.SelectContext(ctx, r.exec, &deliveries, query, adminID)
 	return deliveries, err
 }
 
@@ -146,6 +149,46 @@ func (r *DeliveryRepository) ListByStatus(ctx context.Context, statuses []delive
 	return deliveries, err
 }
 
+func (r *DeliveryRepository) ListByDriver(ctx context.Context, driverID uuid.UUID, statuses []delivery.DeliveryStatus, limit, offset int) ([]*delivery.Delivery, error) {
+	query := `
+		SELECT id, order_id, driver_id, assigned_at, picked_up_at, delivered_at, status 
+		FROM deliveries
+		WHERE driver_id = $1 AND status = ANY($2)
+		ORDER BY assigned_at DESC
+		LIMIT NULLIF($3, 0) OFFSET $4
+	`
+	var deliveries []*delivery.Delivery
+
+	err := sqlx.SelectContext(ctx, r.exec, &deliveries, query, driverID, pq.Array(statuses), limit, offset)
+	return deliveries, err
+}
+
+func (r *DeliveryRepository) CountDelivered(ctx context.Context, driverID uuid.UUID, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM deliveries
+		WHERE driver_id = $1 AND status = 'delivered' AND delivered_at >= $2
+	`
+	var count int
+
+	err := sqlx.GetContext(ctx, r.exec, &count, query, driverID, since)
+	return count, err
+}
+
+// HeldByDriver reports whether the order has a delivery assigned to the driver, in any status.
+func (r *DeliveryRepository) HeldByDriver(ctx context.Context, orderID, driverID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM deliveries
+			WHERE order_id = $1 AND driver_id = $2
+		)
+	`
+	var ok bool
+
+	err := sqlx.GetContext(ctx, r.exec, &ok, query, orderID, driverID)
+	return ok, err
+}
+
 func (r *DeliveryRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `
 		DELETE FROM deliveries 
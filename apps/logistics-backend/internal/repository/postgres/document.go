@@ -0,0 +1,204 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"logistics-backend/internal/application"
+	"logistics-backend/internal/domain/document"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type DocumentRepository struct {
+	exec sqlx.ExtContext
+}
+
+func NewDocumentRepository(db *sqlx.DB) *DocumentRepository {
+	return &DocumentRepository{exec: db}
+}
+
+func (r *DocumentRepository) execFromCtx(ctx context.Context) sqlx.ExtContext {
+	if tx := application.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.exec
+}
+
+func (r *DocumentRepository) Create(ctx context.Context, d *document.Document) error {
+	query := `
+		INSERT INTO driver_documents (driver_id, type, number, issued_at, expires_at, file_path, status)
+		VALUES (:driver_id, :type, :number, :issued_at, :expires_at, :file_path, :status)
+		RETURNING id, created_at, updated_at
+	`
+	rows, err := sqlx.NamedQueryContext(ctx, r.execFromCtx(ctx), query, d)
+	if err != nil {
+		return fmt.Errorf("insert driver document: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return fmt.Errorf("scanning new driver document id: %w", err)
+		}
+	} else {
+		return fmt.Errorf("no id returned after scan")
+	}
+
+	return nil
+}
+
+func (r *DocumentRepository) GetByID(ctx context.Context, id uuid.UUID) (*document.Document, error) {
+	query := `
+		SELECT id, driver_id, type, number, issued_at, expires_at, file_path, status,
+			verified_by, verified_at, last_alert_at, created_at, updated_at
+		FROM driver_documents
+		WHERE id = $1
+	`
+
+	var d document.Document
+	if err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &d, query, id); err != nil {
+		return nil, fmt.Errorf("get driver document by id: %w", err)
+	}
+
+	return &d, nil
+}
+
+func (r *DocumentRepository) ListByDriver(ctx context.Context, driverID uuid.UUID) ([]*document.Document, error) {
+	query := `
+		SELECT id, driver_id, type, number, issued_at, expires_at, file_path, status,
+			verified_by, verified_at, last_alert_at, created_at, updated_at
+		FROM driver_documents
+		WHERE driver_id = $1
+		ORDER BY expires_at ASC
+	`
+
+	var docs []*document.Document
+	if err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &docs, query, driverID); err != nil {
+		return nil, fmt.Errorf("list driver documents: %w", err)
+	}
+
+	return docs, nil
+}
+
+func (r *DocumentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status document.VerificationStatus, verifiedBy uuid.UUID) error {
+	query := `
+		UPDATE driver_documents
+		SET status = :status, verified_by = :verified_by, verified_at = NOW(), updated_at = NOW()
+		WHERE id = :id
+	`
+
+	args := map[string]interface{}{
+		"status":      status,
+		"verified_by": verifiedBy,
+		"id":          id,
+	}
+
+	res, err := sqlx.NamedExecContext(ctx, r.execFromCtx(ctx), query, args)
+	if err != nil {
+		return fmt.Errorf("update driver document status: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("no driver document found with id %s", id)
+	}
+
+	return nil
+}
+
+// ListExpiringForOwner returns the unrenewed documents expiring before the given date of
+// drivers working within the owner's tenant.
+func (r *DocumentRepository) ListExpiringForOwner(ctx context.Context, ownerID uuid.UUID, before time.Time) ([]*document.Document, error) {
+	query := fmt.Sprintf(`
+		SELECT id, driver_id, type, number, issued_at, expires_at, file_path, status,
+			verified_by, verified_at, last_alert_at, created_at, updated_at
+		FROM driver_documents
+		WHERE expires_at <= $2
+		AND driver_id IN (SELECT u.id FROM users u WHERE %s)
+		AND NOT EXISTS (
+			SELECT 1 FROM driver_documents renewed
+			WHERE renewed.driver_id = driver_documents.driver_id
+			AND renewed.type = driver_documents.type
+			AND renewed.expires_at > driver_documents.expires_at
+			AND renewed.status <> 'rejected'
+		)
+		ORDER BY expires_at ASC
+	`, tenantMemberPredicate)
+
+	var docs []*document.Document
+	if err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &docs, query, ownerID, before); err != nil {
+		return nil, fmt.Errorf("list expiring driver documents: %w", err)
+	}
+
+	return docs, nil
+}
+
+// ListExpiring returns documents expiring before the given date that have not been
+// renewed and whose last alert (if any) is older than alertedBefore.
+func (r *DocumentRepository) ListExpiring(ctx context.Context, before time.Time, alertedBefore time.Time) ([]*document.Document, error) {
+	query := `
+		SELECT id, driver_id, type, number, issued_at, expires_at, file_path, status,
+			verified_by, verified_at, last_alert_at, created_at, updated_at
+		FROM driver_documents
+		WHERE expires_at <= $1
+		AND (last_alert_at IS NULL OR last_alert_at < $2)
+		AND NOT EXISTS (
+			SELECT 1 FROM driver_documents renewed
+			WHERE renewed.driver_id = driver_documents.driver_id
+			AND renewed.type = driver_documents.type
+			AND renewed.expires_at > driver_documents.expires_at
+			AND renewed.status <> 'rejected'
+		)
+		ORDER BY expires_at ASC
+	`
+
+	var docs []*document.Document
+	if err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &docs, query, before, alertedBefore); err != nil {
+		return nil, fmt.Errorf("list expiring driver documents: %w", err)
+	}
+
+	return docs, nil
+}
+
+func (r *DocumentRepository) MarkAlerted(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE driver_documents
+		SET last_alert_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("mark driver document alerted: %w", err)
+	}
+
+	return nil
+}
+
+func (r *DocumentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `
+		DELETE FROM driver_documents
+		WHERE id = $1
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete driver document: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("could not verify driver document deletion: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("driver document already deleted or invalid")
+	}
+
+	return nil
+}
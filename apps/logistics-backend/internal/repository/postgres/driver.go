@@ -4,13 +4,41 @@ import (
 	"context"
 	"fmt"
 	"logistics-backend/internal/application"
+	"logistics-backend/internal/domain/document"
 	"logistics-backend/internal/domain/driver"
+	"strings"
 
 	"github.com/cridenour/go-postgis"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
+// compliantDriverPredicate matches drivers (the drivers table) that may be dispatched: those
+// without an expired or rejected document of a type in document.RequiredTypes, unless a
+// replacement of that type is still valid (verified or pending review, unexpired). Drivers who
+// have not uploaded a document yet stay dispatchable, so enforcement starts with the first upload.
+var compliantDriverPredicate = fmt.Sprintf(`NOT EXISTS (
+	SELECT 1 FROM driver_documents dd
+	WHERE dd.driver_id = drivers.id
+	AND dd.type = ANY(ARRAY['%s']::text[])
+	AND (dd.status = 'rejected' OR dd.expires_at <= CURRENT_DATE)
+	AND NOT EXISTS (
+		SELECT 1 FROM driver_documents valid
+		WHERE valid.driver_id = dd.driver_id
+		AND valid.type = dd.type
+		AND valid.status <> 'rejected'
+		AND valid.expires_at > CURRENT_DATE
+	)
+)`, strings.Join(requiredDocumentTypes(), "', '"))
+
+func requiredDocumentTypes() []string {
+	types := make([]string, len(document.RequiredTypes))
+	for i, t := range document.RequiredTypes {
+		types[i] = string(t)
+	}
+	return types
+}
+
 type DriverRepository struct {
 	exec sqlx.ExtContext
 }
@@ -147,23 +175,56 @@ func (r *DriverRepository) GetByEmail(ctx context.Context, email string) (*drive
 	return &d, err
 }
 
-func (r *DriverRepository) List(ctx context.Context) ([]*driver.Driver, error) {
-	query := `
-		SELECT id, full_name, email, vehicle_info, current_location, available, created_at 
-		FROM drivers
-	`
+func (r *DriverRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*driver.Driver, error) {
+	query := fmt.Sprintf(`
+		SELECT d.id, d.full_name, d.email, d.vehicle_info, d.current_location, d.available, d.created_at
+		FROM drivers d
+		JOIN users u ON u.id = d.id
+		WHERE %s
+	`, tenantMemberPredicate)
 
 	var drivers []*driver.Driver
-	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &drivers, query)
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &drivers, query, ownerID)
 	return drivers, err
 }
 
-func (r *DriverRepository) ListAvailableDrivers(ctx context.Context, available bool) ([]*driver.Driver, error) {
+func (r *DriverRepository) BelongsToOwner(ctx context.Context, driverID, ownerID uuid.UUID) (bool, error) {
+	query := fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1 FROM drivers d
+			JOIN users u ON u.id = d.id
+			WHERE d.id = $2 AND %s
+		)
+	`, tenantMemberPredicate)
+
+	var ok bool
+	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &ok, query, ownerID, driverID)
+	return ok, err
+}
+
+// OwnedBy reports whether ownerID provisioned the driver's account. Unlike BelongsToOwner it does
+// not count drivers the owner merely shares with other tenants.
+func (r *DriverRepository) OwnedBy(ctx context.Context, driverID, ownerID uuid.UUID) (bool, error) {
 	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM drivers d
+			JOIN users u ON u.id = d.id
+			WHERE d.id = $1 AND u.owner_id = $2
+		)
+	`
+
+	var ok bool
+	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &ok, query, driverID, ownerID)
+	return ok, err
+}
+
+func (r *DriverRepository) ListAvailableDrivers(ctx context.Context, available bool) ([]*driver.Driver, error) {
+	query := fmt.Sprintf(`
 		SELECT id, full_name, email, vehicle_info, current_location, available, created_at 
 		FROM drivers
 		WHERE available = $1
-	`
+		AND %s
+	`, compliantDriverPredicate)
 
 	var drivers []*driver.Driver
 	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &drivers, query, available)
@@ -193,14 +254,15 @@ func (r *DriverRepository) Delete(ctx context.Context, id uuid.UUID) error {
 }
 
 func (r *DriverRepository) GetNearestDriver(ctx context.Context, pickup postgis.PointS, maxDistance float64) (*driver.Driver, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, full_name, current_location, ST_Distance(current_location, $1) AS dist
 		FROM drivers
 		WHERE available = true
 		AND ST_DWithin(current_location, $1, $2)
+		AND %s
 		ORDER BY current_location <-> $1
 		LIMIT 1
-	`
+	`, compliantDriverPredicate)
 
 	var d driver.Driver
 	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &d, query, pickup, maxDistance)
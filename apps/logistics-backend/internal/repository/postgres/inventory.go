@@ -68,20 +68,52 @@ func (r *InventoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*inven
 
 }
 
-func (r *InventoryRepository) GetByName(ctx context.Context, name string) (*inventory.Inventory, error) {
+// GetByName returns the newest inventory named name, which is its category now that inventories
+// have no name of their own. A non-nil ownerID or storeID limits the search to that owner's stores
+// or to that store.
+func (r *InventoryRepository) GetByName(ctx context.Context, name string, ownerID, storeID *uuid.UUID) (*inventory.Inventory, error) {
 	query := `
-		SELECT id, admin_id, name, category, stock, price_amount, price_currency, images, unit, packaging, description, location, slug
-		FROM inventories
-		WHERE name = $1
+		SELECT i.id, i.store_id, i.category, i.stock, i.price_amount, i.price_currency, i.images, i.unit,
+		       i.packaging, i.description, i.created_at, i.updated_at
+		FROM inventories i
+		JOIN stores s ON s.id = i.store_id
+		WHERE i.category = $1
+		AND ($2::uuid IS NULL OR s.owner_id = $2)
+		AND ($3::uuid IS NULL OR i.store_id = $3)
+		ORDER BY i.created_at DESC
+		LIMIT 1
 	`
 	var i inventory.Inventory
-	if err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &i, query, name); err != nil {
+	if err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &i, query, name, ownerID, storeID); err != nil {
 		return nil, fmt.Errorf("get inventory by name: %w", err)
 	}
 
 	return &i, nil
 }
 
+func (r *InventoryRepository) AddStock(ctx context.Context, inventoryID uuid.UUID, quantity int) error {
+	query := `
+		UPDATE inventories SET stock = stock + $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, inventoryID, quantity)
+	if err != nil {
+		return fmt.Errorf("add inventory stock: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("no inventory found with id %s", inventoryID)
+	}
+
+	return nil
+}
+
 func (r *InventoryRepository) UpdateColumn(ctx context.Context, inventoryID uuid.UUID, column string, value any) error {
 	// Whitelist column names
 	allowed := map[string]bool{
@@ -124,21 +156,25 @@ func (r *InventoryRepository) UpdateColumn(ctx context.Context, inventoryID uuid
 	return nil
 }
 
-func (r *InventoryRepository) GetByCategory(ctx context.Context, category string) ([]*inventory.Inventory, error) {
+// GetByCategory lists the inventories in category; a non-nil ownerID or storeID narrows it to that
+// owner's stores or to that store.
+func (r *InventoryRepository) GetByCategory(ctx context.Context, category string, ownerID, storeID *uuid.UUID) ([]*inventory.Inventory, error) {
 	query := `
-		SELECT id, admin_id, name, category, stock, price_amount, price_currency, images, unit, 
-			packaging, description, location, slug, created_at, updated_at
-		FROM inventories
-		WHERE category = $1
+		SELECT i.id, i.store_id, i.category, i.stock, i.price_amount, i.price_currency, i.images, i.unit,
+		       i.packaging, i.description, i.created_at, i.updated_at
+		FROM inventories i
+		JOIN stores s ON s.id = i.store_id
+		WHERE i.category = $1
+		AND ($2::uuid IS NULL OR s.owner_id = $2)
+		AND ($3::uuid IS NULL OR i.store_id = $3)
 	`
 
 	var inventories []*inventory.Inventory
-	if err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &inventories, query, category); err != nil {
+	if err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &inventories, query, category, ownerID, storeID); err != nil {
 		return nil, fmt.Errorf("get inventories by category: %w", err)
 	}
 
 	return inventories, nil
-
 }
 
 func (r *InventoryRepository) GetByStoreID(ctx context.Context, storeID uuid.UUID) ([]*inventory.Inventory, error) {
@@ -182,6 +218,22 @@ func (r *InventoryRepository) List(ctx context.Context, limit, offset int) ([]*i
 	return inventories, err
 }
 
+// ListByOwner lists the inventories of ownerID's stores; a non-nil storeID narrows it to that store.
+func (r *InventoryRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID, storeID *uuid.UUID, limit, offset int) ([]*inventory.Inventory, error) {
+	query := `
+		SELECT i.id, i.store_id, i.category, i.stock, i.price_amount, i.price_currency, i.images, i.unit,
+		       i.packaging, i.description, i.created_at, i.updated_at
+		FROM inventories i
+		JOIN stores s ON s.id = i.store_id
+		WHERE s.owner_id = $1 AND ($2::uuid IS NULL OR i.store_id = $2)
+		ORDER BY i.created_at DESC
+		LIMIT NULLIF($3, 0) OFFSET $4
+	`
+	var inventories []*inventory.Inventory
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &inventories, query, ownerID, storeID, limit, offset)
+	return inventories, err
+}
+
 func (r *InventoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `
 		DELETE FROM inventories 
@@ -205,13 +257,15 @@ func (r *InventoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (r *InventoryRepository) GetAllInventories(ctx context.Context) ([]inventory.AllInventory, error) {
+func (r *InventoryRepository) GetAllInventories(ctx context.Context, ownerID uuid.UUID) ([]inventory.AllInventory, error) {
 	query := `
-        SELECT id, name, admin_id, category
-        FROM inventories
-        ORDER BY name ASC
+        SELECT i.id, s.name, s.owner_id AS admin_id, i.category
+        FROM inventories i
+        JOIN stores s ON s.id = i.store_id
+        WHERE s.owner_id = $1
+        ORDER BY s.name ASC, i.category ASC
     `
 	var inventories []inventory.AllInventory
-	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &inventories, query)
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &inventories, query, ownerID)
 	return inventories, err
 }
@@ -27,8 +27,9 @@ func (r *InviteRepository) execFromCtx(ctx context.Context) sqlx.ExtContext {
 
 func (r *InviteRepository) Create(ctx context.Context, i *invite.Invite) error {
 	query := `
-		INSERT INTO invites (id, email, role, token, expires_at, invited_by, created_at)
-		VALUES (:id, :email, :role, :token, :expires_at, :invited_by, :created_at)
+		INSERT INTO invites (email, role, token_hash, expires_at, invited_by)
+		VALUES (:email, :role, :token_hash, :expires_at, :invited_by)
+		RETURNING id, created_at
 	`
 	rows, err := sqlx.NamedQueryContext(ctx, r.execFromCtx(ctx), query, i)
 	if err != nil {
@@ -38,7 +39,7 @@ func (r *InviteRepository) Create(ctx context.Context, i *invite.Invite) error {
 	defer rows.Close()
 
 	if rows.Next() {
-		if err := rows.Scan(&i.ID); err != nil {
+		if err := rows.Scan(&i.ID, &i.CreatedAt); err != nil {
 			return fmt.Errorf("scanning new invite id: %w", err)
 		}
 	} else {
@@ -48,35 +49,61 @@ func (r *InviteRepository) Create(ctx context.Context, i *invite.Invite) error {
 	return nil
 }
 
-func (r *InviteRepository) GetByToken(ctx context.Context, token string) (*invite.Invite, error) {
+func (r *InviteRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*invite.Invite, error) {
 	var i invite.Invite
 	query := `
-		SELECT id, email, role, token, expires_at, invited_by, created_at 
+		SELECT id, email, role, token_hash, expires_at, invited_by, accepted_at, accepted_user_id, created_at
 		FROM invites 
-		WHERE token = $1
+		WHERE token_hash = $1
 	`
-	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &i, query, token)
+	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &i, query, tokenHash)
 	return &i, err
 }
 
-func (r *InviteRepository) ListPending(ctx context.Context) ([]*invite.Invite, error) {
+func (r *InviteRepository) ListPending(ctx context.Context, invitedBy uuid.UUID) ([]*invite.Invite, error) {
 	var invites []*invite.Invite
 	query := `
-		SELECT id, email, role, token, expires_at, invited_by, created_at
-        FROM invites
-        WHERE expires_at > NOW()
+		SELECT id, email, role, token_hash, expires_at, invited_by, accepted_at, accepted_user_id, created_at
+		FROM invites
+		WHERE invited_by = $1 AND accepted_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC
 	`
-	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &invites, query)
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &invites, query, invitedBy)
 	return invites, err
 }
 
-func (r *InviteRepository) Delete(ctx context.Context, id uuid.UUID) error {
+func (r *InviteRepository) MarkAccepted(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	query := `
+		UPDATE invites
+		SET accepted_at = NOW(), accepted_user_id = $2
+		WHERE id = $1 AND accepted_at IS NULL
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("accept invite: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	// a concurrent accept got there first
+	if rows == 0 {
+		return invite.ErrInviteAccepted
+	}
+
+	return nil
+}
+
+func (r *InviteRepository) Delete(ctx context.Context, id uuid.UUID, invitedBy uuid.UUID) error {
 	query := `
 		DELETE FROM invites 
-		WHERE id = $1
+		WHERE id = $1 AND invited_by = $2
 	`
 
-	res, err := r.exec.ExecContext(ctx, query, id)
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, id, invitedBy)
 	if err != nil {
 		return fmt.Errorf("failed to delete invite: %w", err)
 	}
@@ -87,7 +114,7 @@ func (r *InviteRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("invite already deleted or invalid")
+		return invite.ErrInviteNotFound
 	}
 
 	return nil
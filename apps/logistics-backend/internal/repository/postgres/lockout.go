@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"logistics-backend/internal/application"
+	"logistics-backend/internal/domain/lockout"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type LockoutRepository struct {
+	exec sqlx.ExtContext
+}
+
+func NewLockoutRepository(db *sqlx.DB) *LockoutRepository {
+	return &LockoutRepository{exec: db}
+}
+
+func (r *LockoutRepository) execFromCtx(ctx context.Context) sqlx.ExtContext {
+	if tx := application.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.exec
+}
+
+func (r *LockoutRepository) Get(ctx context.Context, scope lockout.Scope, key string) (*lockout.Failures, error) {
+	query := `
+		SELECT scope, key, failures, last_failed_at, locked_until
+		FROM login_failures
+		WHERE scope = $1 AND key = $2
+	`
+
+	var f lockout.Failures
+	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &f, query, scope, key)
+	return &f, err
+}
+
+func (r *LockoutRepository) RecordFailure(ctx context.Context, scope lockout.Scope, key string, window time.Duration) (*lockout.Failures, error) {
+	query := `
+		INSERT INTO login_failures (scope, key, failures, last_failed_at)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (scope, key) DO UPDATE
+		SET failures = CASE
+		        WHEN login_failures.last_failed_at < NOW() - make_interval(secs => $3) THEN 1
+		        ELSE login_failures.failures + 1
+		    END,
+		    last_failed_at = NOW()
+		RETURNING scope, key, failures, last_failed_at, locked_until
+	`
+
+	var f lockout.Failures
+	if err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &f, query, scope, key, window.Seconds()); err != nil {
+		return nil, fmt.Errorf("record login failure: %w", err)
+	}
+
+	return &f, nil
+}
+
+func (r *LockoutRepository) Lock(ctx context.Context, scope lockout.Scope, key string, until time.Time) error {
+	query := `
+		UPDATE login_failures
+		SET locked_until = $3, failures = 0
+		WHERE scope = $1 AND key = $2
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, scope, key, until); err != nil {
+		return fmt.Errorf("lock login: %w", err)
+	}
+
+	return nil
+}
+
+func (r *LockoutRepository) Reset(ctx context.Context, scope lockout.Scope, key string) error {
+	query := `
+		DELETE FROM login_failures
+		WHERE scope = $1 AND key = $2
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, scope, key); err != nil {
+		return fmt.Errorf("reset login failures: %w", err)
+	}
+
+	return nil
+}
+
+func (r *LockoutRepository) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM login_failures
+		WHERE last_failed_at < $1 AND (locked_until IS NULL OR locked_until < NOW())
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete stale login failures: %w", err)
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,262 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"logistics-backend/internal/application"
+	"logistics-backend/internal/domain/mfa"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type MFARepository struct {
+	exec sqlx.ExtContext
+}
+
+func NewMFARepository(db *sqlx.DB) *MFARepository {
+	return &MFARepository{exec: db}
+}
+
+func (r *MFARepository) execFromCtx(ctx context.Context) sqlx.ExtContext {
+	if tx := application.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.exec
+}
+
+func (r *MFARepository) UpsertTOTP(ctx context.Context, t *mfa.TOTP) error {
+	query := `
+		INSERT INTO user_totp (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, confirmed_at = NULL, last_used_step = 0, created_at = NOW()
+		WHERE user_totp.confirmed_at IS NULL
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, t.UserID, t.Secret)
+	if err != nil {
+		return fmt.Errorf("upsert totp: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	// a confirmed authenticator is only replaced after disabling 2FA
+	if rows == 0 {
+		return mfa.ErrAlreadyEnabled
+	}
+
+	return nil
+}
+
+func (r *MFARepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*mfa.TOTP, error) {
+	query := `
+		SELECT user_id, secret, confirmed_at, last_used_step, created_at
+		FROM user_totp
+		WHERE user_id = $1
+	`
+
+	var t mfa.TOTP
+	if err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &t, query, userID); err != nil {
+		return nil, fmt.Errorf("get totp: %w", err)
+	}
+
+	return &t, nil
+}
+
+func (r *MFARepository) ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64) error {
+	query := `
+		UPDATE user_totp
+		SET confirmed_at = NOW(), last_used_step = $2
+		WHERE user_id = $1 AND confirmed_at IS NULL
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("confirm totp: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return mfa.ErrAlreadyEnabled
+	}
+
+	return nil
+}
+
+func (r *MFARepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	query := `
+		UPDATE user_totp
+		SET last_used_step = $2
+		WHERE user_id = $1 AND last_used_step < $2
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("use totp step: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	// the code was already used (replay)
+	if rows == 0 {
+		return mfa.ErrInvalidCode
+	}
+
+	return nil
+}
+
+func (r *MFARepository) DeleteTOTP(ctx context.Context, userID uuid.UUID) error {
+	for _, query := range []string{
+		`DELETE FROM mfa_recovery_codes WHERE user_id = $1`,
+		`DELETE FROM user_totp WHERE user_id = $1`,
+	} {
+		if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, userID); err != nil {
+			return fmt.Errorf("delete totp: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *MFARepository) SetEnabled(ctx context.Context, userID uuid.UUID, enabled bool) error {
+	query := `
+		UPDATE users
+		SET mfa_enabled = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, userID, enabled); err != nil {
+		return fmt.Errorf("set mfa enabled: %w", err)
+	}
+
+	return nil
+}
+
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes []string) error {
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("delete recovery codes: %w", err)
+	}
+
+	query := `
+		INSERT INTO mfa_recovery_codes (user_id, code_hash)
+		VALUES ($1, $2)
+	`
+	for _, h := range hashes {
+		if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, userID, h); err != nil {
+			return fmt.Errorf("insert recovery code: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) error {
+	query := `
+		UPDATE mfa_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, userID, hash)
+	if err != nil {
+		return fmt.Errorf("use recovery code: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return mfa.ErrInvalidCode
+	}
+
+	return nil
+}
+
+func (r *MFARepository) CreateChallenge(ctx context.Context, c *mfa.Challenge) error {
+	query := `
+		INSERT INTO mfa_challenges (user_id, token_hash, expires_at)
+		VALUES (:user_id, :token_hash, :expires_at)
+		RETURNING id
+	`
+	rows, err := sqlx.NamedQueryContext(ctx, r.execFromCtx(ctx), query, c)
+	if err != nil {
+		return fmt.Errorf("insert mfa challenge: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&c.ID); err != nil {
+			return fmt.Errorf("scanning new mfa challenge id: %w", err)
+		}
+	} else {
+		return fmt.Errorf("no id returned after scan")
+	}
+
+	return nil
+}
+
+func (r *MFARepository) GetChallengeByHash(ctx context.Context, tokenHash string) (*mfa.Challenge, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, attempts, used_at, created_at
+		FROM mfa_challenges
+		WHERE token_hash = $1
+	`
+
+	var c mfa.Challenge
+	if err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &c, query, tokenHash); err != nil {
+		return nil, fmt.Errorf("get mfa challenge: %w", err)
+	}
+
+	return &c, nil
+}
+
+func (r *MFARepository) IncrementChallengeAttempts(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE mfa_challenges
+		SET attempts = attempts + 1
+		WHERE id = $1
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("increment mfa challenge attempts: %w", err)
+	}
+
+	return nil
+}
+
+func (r *MFARepository) MarkChallengeUsed(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE mfa_challenges
+		SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("mark mfa challenge used: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return mfa.ErrInvalidChallenge
+	}
+
+	return nil
+}
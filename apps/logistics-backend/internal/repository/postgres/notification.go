@@ -2,12 +2,16 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"logistics-backend/internal/application"
 	"logistics-backend/internal/domain/notification"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type NotificationRepository struct {
@@ -25,10 +29,12 @@ func (r *NotificationRepository) execFromCtx(ctx context.Context) sqlx.ExtContex
 	return r.exec
 }
 
+// Create stores a notification; a zero UserID is stored as NULL for notifications addressed
+// only by their recipient.
 func (r *NotificationRepository) Create(ctx context.Context, n *notification.Notification) error {
 	query := `
-		INSERT INTO notifications (user_id, message, type, status, sent_at)
-		VALUES (:user_id, :message, :type, :status, :sent_at)
+		INSERT INTO notifications (user_id, recipient, message, type, status, sent_at, event, data)
+		VALUES (NULLIF(:user_id, CAST('00000000-0000-0000-0000-000000000000' AS uuid)), :recipient, :message, :type, :status, :sent_at, :event, :data)
 		RETURNING id
 	`
 	rows, err := sqlx.NamedQueryContext(ctx, r.execFromCtx(ctx), query, n)
@@ -48,19 +54,31 @@ func (r *NotificationRepository) Create(ctx context.Context, n *notification.Not
 	return nil
 }
 
-func (r *NotificationRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status notification.NotificationStatus) error {
+func (r *NotificationRepository) CreateIfAbsent(ctx context.Context, n *notification.Notification) error {
 	query := `
-		UPDATE notifications 
-		SET status = :status, updated_at = NOW() 
-		WHERE id = :id
+		INSERT INTO notifications (id, user_id, recipient, message, type, status, sent_at, event, data)
+		VALUES (:id, NULLIF(:user_id, CAST('00000000-0000-0000-0000-000000000000' AS uuid)), :recipient, :message, :type, :status, :sent_at, :event, :data)
+		ON CONFLICT (id) DO NOTHING
 	`
 
-	args := map[string]interface{}{
-		"status": status,
-		"id":     id,
+	if _, err := sqlx.NamedExecContext(ctx, r.execFromCtx(ctx), query, n); err != nil {
+		return fmt.Errorf("insert notification: %w", err)
 	}
 
-	res, err := sqlx.NamedExecContext(ctx, r.execFromCtx(ctx), query, args)
+	return nil
+}
+
+// UpdateStatus changes the status of a notification to a user within ownerID's tenant, the same
+// notifications ListPending shows the owner.
+func (r *NotificationRepository) UpdateStatus(ctx context.Context, id, ownerID uuid.UUID, status notification.NotificationStatus) error {
+	query := fmt.Sprintf(`
+		UPDATE notifications n
+		SET status = $3, updated_at = NOW()
+		FROM users u
+		WHERE n.id = $2 AND u.id = n.user_id AND %s
+	`, tenantMemberPredicate)
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, ownerID, id, status)
 	if err != nil {
 		return fmt.Errorf("update notification status: %w", err)
 	}
@@ -70,7 +88,7 @@ func (r *NotificationRepository) UpdateStatus(ctx context.Context, id uuid.UUID,
 		return fmt.Errorf("rows affected: %w", err)
 	}
 	if rows == 0 {
-		return fmt.Errorf("no notification found with id %s", id)
+		return notification.ErrNotificationNotFound
 	}
 
 	return nil
@@ -79,12 +97,11 @@ func (r *NotificationRepository) UpdateStatus(ctx context.Context, id uuid.UUID,
 func (r *NotificationRepository) UpdateAllAsRead(ctx context.Context, userID uuid.UUID) error {
 	query := `
 		UPDATE notifications
-		SET status = :status, updated_at = NOW()
-		WHERE user_id = :user_id AND status != :status
+		SET read_at = NOW(), updated_at = NOW()
+		WHERE user_id = :user_id AND recipient IS NULL AND read_at IS NULL
 	`
 
 	args := map[string]interface{}{
-		"status":  notification.Read,
 		"user_id": userID,
 	}
 
@@ -96,15 +113,19 @@ func (r *NotificationRepository) UpdateAllAsRead(ctx context.Context, userID uui
 	return nil
 }
 
-func (r *NotificationRepository) ListPending(ctx context.Context) ([]*notification.Notification, error) {
-	query := `
-		SELECT id, user_id, message, type, status, sent_at, created_at, updated_at
-		FROM notifications
-		WHERE status = 'pending'
-		ORDER BY created_at ASC
-	`
+// ListPending lists the queue of an owner's tenant for support. Event variables and the text
+// rendered from them carry single-use links, so only free-text messages are returned.
+func (r *NotificationRepository) ListPending(ctx context.Context, ownerID uuid.UUID) ([]*notification.Notification, error) {
+	query := fmt.Sprintf(`
+		SELECT n.id, n.user_id, n.recipient, CASE WHEN n.event = '' THEN n.message ELSE '' END AS message,
+		       n.type, n.status, n.sent_at, n.created_at, n.updated_at, n.attempts, n.next_attempt_at, n.last_error, n.provider_ref, n.read_at, n.archived_at, n.event
+		FROM notifications n
+		JOIN users u ON u.id = n.user_id
+		WHERE n.status = 'pending' AND %s
+		ORDER BY n.created_at ASC
+	`, tenantMemberPredicate)
 	var notifications []*notification.Notification
-	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &notifications, query)
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &notifications, query, ownerID)
 	if err != nil {
 		return nil, fmt.Errorf("list pending notifications: %w", err)
 	}
@@ -113,9 +134,9 @@ func (r *NotificationRepository) ListPending(ctx context.Context) ([]*notificati
 
 func (r *NotificationRepository) ListByUserAndStatus(ctx context.Context, userID uuid.UUID, status notification.NotificationStatus) ([]*notification.Notification, error) {
 	query := `
-		SELECT id, user_id, message, type, status, sent_at, created_at, updated_at
+		SELECT id, user_id, recipient, message, type, status, sent_at, created_at, updated_at, attempts, next_attempt_at, last_error, provider_ref, read_at, archived_at, event, data
 		FROM notifications
-		WHERE user_id = $1 AND status = $2
+		WHERE ` + inboxCondition + ` AND status = $2
 		ORDER BY created_at DESC
 	`
 	var notifications []*notification.Notification
@@ -125,3 +146,220 @@ func (r *NotificationRepository) ListByUserAndStatus(ctx context.Context, userID
 	}
 	return notifications, nil
 }
+
+func (r *NotificationRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*notification.Notification, error) {
+	// SKIP LOCKED lets several workers run side by side without sending the same notification;
+	// pushing next_attempt_at forward hides a claimed row until the lease runs out
+	query := `
+		UPDATE notifications
+		SET attempts = attempts + 1, next_attempt_at = NOW() + make_interval(secs => $2), updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE status IN ('pending', 'failed') AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, user_id, recipient, message, type, status, sent_at, created_at, updated_at, attempts, next_attempt_at, last_error, provider_ref, read_at, archived_at, event, data
+	`
+
+	var notifications []*notification.Notification
+	if err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &notifications, query, limit, lease.Seconds()); err != nil {
+		return nil, fmt.Errorf("claim due notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (r *NotificationRepository) MarkSent(ctx context.Context, id uuid.UUID, providerRef *string) error {
+	query := `
+		UPDATE notifications
+		SET status = 'sent', sent_at = NOW(), last_error = NULL, provider_ref = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, id, providerRef); err != nil {
+		return fmt.Errorf("mark notification sent: %w", err)
+	}
+
+	return nil
+}
+
+func (r *NotificationRepository) MarkFailed(ctx context.Context, id uuid.UUID, reason string, retryAt time.Time, dead bool) error {
+	query := `
+		UPDATE notifications
+		SET status = CASE WHEN $4 THEN 'dead' ELSE 'failed' END,
+		    last_error = $2, next_attempt_at = $3, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, id, reason, retryAt, dead); err != nil {
+		return fmt.Errorf("mark notification failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *NotificationRepository) MarkSuppressed(ctx context.Context, id uuid.UUID, reason string) error {
+	query := `
+		UPDATE notifications
+		SET status = 'suppressed', last_error = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, id, reason); err != nil {
+		return fmt.Errorf("mark notification suppressed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *NotificationRepository) Defer(ctx context.Context, id uuid.UUID, until time.Time) error {
+	// ClaimDue counted an attempt that never happened, so it is given back
+	query := `
+		UPDATE notifications
+		SET status = 'pending', attempts = GREATEST(attempts - 1, 0), next_attempt_at = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, id, until); err != nil {
+		return fmt.Errorf("defer notification: %w", err)
+	}
+
+	return nil
+}
+
+func (r *NotificationRepository) GetByProviderRef(ctx context.Context, providerRef string) (*notification.Notification, error) {
+	query := `
+		SELECT id, user_id, recipient, message, type, status, sent_at, created_at, updated_at, attempts, next_attempt_at, last_error, provider_ref, read_at, archived_at, event, data
+		FROM notifications
+		WHERE provider_ref = $1
+	`
+
+	var n notification.Notification
+	if err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &n, query, providerRef); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, notification.ErrNotificationNotFound
+		}
+		return nil, fmt.Errorf("get notification by provider ref: %w", err)
+	}
+
+	return &n, nil
+}
+
+func (r *NotificationRepository) MarkDelivered(ctx context.Context, id uuid.UUID) error {
+	// a receipt must not undo the user having read the notification
+	query := `
+		UPDATE notifications
+		SET status = 'delivered', updated_at = NOW()
+		WHERE id = $1 AND status = 'sent'
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("mark notification delivered: %w", err)
+	}
+
+	return nil
+}
+
+// inboxCondition keeps the inbox to the user's own notifications. Notifications addressed to
+// someone without an account (invite emails) have no user_id and never match; the recipient check
+// also keeps out any notification sent to another address on a user's behalf.
+const inboxCondition = `user_id = $1 AND recipient IS NULL`
+
+func (r *NotificationRepository) ListInbox(ctx context.Context, userID uuid.UUID, f notification.InboxFilter) ([]*notification.Notification, error) {
+	query := `
+		SELECT id, user_id, recipient, message, type, status, sent_at, created_at, updated_at, attempts, next_attempt_at, last_error, provider_ref, read_at, archived_at, event, data
+		FROM notifications
+		WHERE ` + inboxCondition
+	args := []any{userID}
+
+	switch f.State {
+	case notification.Unread:
+		query += ` AND read_at IS NULL AND archived_at IS NULL`
+	case notification.Seen:
+		query += ` AND read_at IS NOT NULL AND archived_at IS NULL`
+	case notification.Archived:
+		query += ` AND archived_at IS NOT NULL`
+	default:
+		query += ` AND archived_at IS NULL`
+	}
+	if f.Type != "" {
+		args = append(args, f.Type)
+		query += fmt.Sprintf(` AND type = $%d`, len(args))
+	}
+	if f.Cursor != nil {
+		args = append(args, f.Cursor.CreatedAt, f.Cursor.ID)
+		query += fmt.Sprintf(` AND (created_at, id) < ($%d, $%d)`, len(args)-1, len(args))
+	}
+	args = append(args, f.Limit)
+	query += fmt.Sprintf(` ORDER BY created_at DESC, id DESC LIMIT $%d`, len(args))
+
+	var notifications []*notification.Notification
+	if err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &notifications, query, args...); err != nil {
+		return nil, fmt.Errorf("list inbox: %w", err)
+	}
+	return notifications, nil
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID uuid.UUID, typ notification.NotificationType) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM notifications
+		WHERE ` + inboxCondition + ` AND read_at IS NULL AND archived_at IS NULL AND ($2 = '' OR type = $2)
+	`
+
+	var count int
+	if err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &count, query, userID, typ); err != nil {
+		return 0, fmt.Errorf("count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	// already read notifications keep their first read time but still count as the user's
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, NOW()), updated_at = NOW()
+		WHERE ` + inboxCondition + ` AND id = ANY($2)
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, userID, pq.Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("mark notifications read: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func (r *NotificationRepository) Archive(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	query := `
+		UPDATE notifications
+		SET archived_at = COALESCE(archived_at, NOW()), read_at = COALESCE(read_at, NOW()), updated_at = NOW()
+		WHERE ` + inboxCondition + ` AND id = ANY($2)
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, userID, pq.Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("archive notifications: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func (r *NotificationRepository) Delete(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
+	query := `DELETE FROM notifications WHERE ` + inboxCondition + ` AND id = $2`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, userID, id)
+	if err != nil {
+		return fmt.Errorf("delete notification: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if rows == 0 {
+		return notification.ErrNotificationNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"logistics-backend/internal/application"
+	"logistics-backend/internal/domain/notification"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type NotificationPreferenceRepository struct {
+	exec sqlx.ExtContext
+}
+
+func NewNotificationPreferenceRepository(db *sqlx.DB) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{exec: db}
+}
+
+func (r *NotificationPreferenceRepository) execFromCtx(ctx context.Context) sqlx.ExtContext {
+	if tx := application.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.exec
+}
+
+func (r *NotificationPreferenceRepository) GetSettings(ctx context.Context, userID uuid.UUID) (*notification.Settings, error) {
+	query := `
+		SELECT event, channel, enabled
+		FROM notification_preferences
+		WHERE user_id = $1
+		ORDER BY event, channel
+	`
+
+	s := &notification.Settings{Preferences: []notification.Preference{}}
+	if err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &s.Preferences, query, userID); err != nil {
+		return nil, fmt.Errorf("list notification preferences: %w", err)
+	}
+
+	query = `
+		SELECT to_char(start_time, 'HH24:MI') AS start_time, to_char(end_time, 'HH24:MI') AS end_time, timezone
+		FROM notification_quiet_hours
+		WHERE user_id = $1
+	`
+
+	var q notification.QuietHours
+	switch err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &q, query, userID); {
+	case err == nil:
+		s.QuietHours = &q
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("get quiet hours: %w", err)
+	}
+
+	return s, nil
+}
+
+func (r *NotificationPreferenceRepository) ReplaceSettings(ctx context.Context, userID uuid.UUID, s *notification.Settings) error {
+	exec := r.execFromCtx(ctx)
+
+	if _, err := exec.ExecContext(ctx, `DELETE FROM notification_preferences WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("clear notification preferences: %w", err)
+	}
+
+	for _, p := range s.Preferences {
+		query := `
+			INSERT INTO notification_preferences (user_id, event, channel, enabled)
+			VALUES ($1, $2, $3, $4)
+		`
+		if _, err := exec.ExecContext(ctx, query, userID, p.Event, p.Channel, p.Enabled); err != nil {
+			return fmt.Errorf("save notification preference: %w", err)
+		}
+	}
+
+	if s.QuietHours == nil {
+		if _, err := exec.ExecContext(ctx, `DELETE FROM notification_quiet_hours WHERE user_id = $1`, userID); err != nil {
+			return fmt.Errorf("clear quiet hours: %w", err)
+		}
+		return nil
+	}
+
+	query := `
+		INSERT INTO notification_quiet_hours (user_id, start_time, end_time, timezone)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET start_time = EXCLUDED.start_time, end_time = EXCLUDED.end_time,
+		    timezone = EXCLUDED.timezone, updated_at = NOW()
+	`
+	q := s.QuietHours
+	if _, err := exec.ExecContext(ctx, query, userID, q.Start, q.End, q.Timezone); err != nil {
+		return fmt.Errorf("save quiet hours: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"logistics-backend/internal/application"
+	"logistics-backend/internal/domain/notification"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type NotificationTemplateRepository struct {
+	exec sqlx.ExtContext
+}
+
+func NewNotificationTemplateRepository(db *sqlx.DB) *NotificationTemplateRepository {
+	return &NotificationTemplateRepository{exec: db}
+}
+
+func (r *NotificationTemplateRepository) execFromCtx(ctx context.Context) sqlx.ExtContext {
+	if tx := application.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.exec
+}
+
+func (r *NotificationTemplateRepository) ListOverrides(ctx context.Context, ownerID uuid.UUID) ([]*notification.Template, error) {
+	query := `
+		SELECT owner_id, event, locale, channel, subject, body, html, updated_by, updated_at
+		FROM notification_templates
+		WHERE owner_id = $1
+		ORDER BY event, locale, channel
+	`
+
+	var templates []*notification.Template
+	if err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &templates, query, ownerID); err != nil {
+		return nil, fmt.Errorf("list notification templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+func (r *NotificationTemplateRepository) ListOverridesForEvent(ctx context.Context, ownerID uuid.UUID, event notification.Event) ([]*notification.Template, error) {
+	query := `
+		SELECT owner_id, event, locale, channel, subject, body, html, updated_by, updated_at
+		FROM notification_templates
+		WHERE owner_id = $1 AND event = $2
+	`
+
+	var templates []*notification.Template
+	if err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &templates, query, ownerID, event); err != nil {
+		return nil, fmt.Errorf("list notification templates for %s: %w", event, err)
+	}
+
+	return templates, nil
+}
+
+func (r *NotificationTemplateRepository) UpsertOverride(ctx context.Context, t *notification.Template) error {
+	query := `
+		INSERT INTO notification_templates (owner_id, event, locale, channel, subject, body, html, updated_by)
+		VALUES (:owner_id, :event, :locale, :channel, :subject, :body, :html, :updated_by)
+		ON CONFLICT (owner_id, event, locale, channel) DO UPDATE
+		SET subject = EXCLUDED.subject, body = EXCLUDED.body, html = EXCLUDED.html,
+		    updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`
+
+	if _, err := sqlx.NamedExecContext(ctx, r.execFromCtx(ctx), query, t); err != nil {
+		return fmt.Errorf("save notification template: %w", err)
+	}
+
+	return nil
+}
+
+func (r *NotificationTemplateRepository) DeleteOverride(ctx context.Context, ownerID uuid.UUID, event notification.Event, locale string, channel notification.NotificationType) error {
+	query := `
+		DELETE FROM notification_templates
+		WHERE owner_id = $1 AND event = $2 AND locale = $3 AND channel = $4
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, ownerID, event, locale, channel)
+	if err != nil {
+		return fmt.Errorf("delete notification template: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if rows == 0 {
+		return notification.ErrTemplateNotFound
+	}
+
+	return nil
+}
@@ -74,6 +74,22 @@ func (r *OrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*order.Ord
 	return &o, nil
 }
 
+func (r *OrderRepository) GetForUpdate(ctx context.Context, id uuid.UUID) (*order.Order, error) {
+	query := `
+		SELECT id, user_id, admin_id, inventory_id, quantity, pickup_address, pickup_point, delivery_address, delivery_point, status, created_at, updated_at
+		FROM orders
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var o order.Order
+	if err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &o, query, id); err != nil {
+		return nil, fmt.Errorf("get order for update: %w", err)
+	}
+
+	return &o, nil
+}
+
 func (r *OrderRepository) ListByCustomer(ctx context.Context, customerID uuid.UUID) ([]*order.Order, error) {
 	query := `
 		SELECT id, user_id, admin_id, inventory_id, quantity, pickup_address, pickup_point, delivery_address, delivery_point, status, created_at, updated_at 
@@ -138,14 +154,17 @@ func (r *OrderRepository) Update(ctx context.Context, orderID uuid.UUID, column
 // 	return nil
 // }
 
-func (r *OrderRepository) List(ctx context.Context) ([]*order.Order, error) {
+// ListByAdmin lists a store owner's orders; a non-nil storeID keeps those for that store's inventory.
+func (r *OrderRepository) ListByAdmin(ctx context.Context, adminID uuid.UUID, storeID *uuid.UUID) ([]*order.Order, error) {
 	query := `
 		SELECT id, user_id, admin_id, inventory_id, quantity, pickup_address, delivery_address, status, created_at, updated_at, pickup_point, delivery_point
 		FROM orders
+		WHERE admin_id = $1
+		  AND ($2::uuid IS NULL OR inventory_id IN (SELECT id FROM inventories WHERE store_id = $2))
 	`
 
 	var orders []*order.Order
-	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &orders, query)
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &orders, query, adminID, storeID)
 	return orders, err
 }
 
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"logistics-backend/internal/application"
+	"logistics-backend/internal/domain/outbox"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type OutboxRepository struct {
+	exec sqlx.ExtContext
+}
+
+func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {
+	return &OutboxRepository{exec: db}
+}
+
+func (r *OutboxRepository) execFromCtx(ctx context.Context) sqlx.ExtContext {
+	if tx := application.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.exec
+}
+
+func (r *OutboxRepository) Add(ctx context.Context, m *outbox.Message) error {
+	query := `
+		INSERT INTO outbox_messages (topic, payload)
+		VALUES ($1, $2::jsonb)
+		RETURNING id, status, next_attempt_at, created_at
+	`
+
+	row := r.execFromCtx(ctx).QueryRowxContext(ctx, query, m.Topic, string(m.Payload))
+	if err := row.Scan(&m.ID, &m.Status, &m.NextAttemptAt, &m.CreatedAt); err != nil {
+		return fmt.Errorf("insert outbox message: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*outbox.Message, error) {
+	// SKIP LOCKED lets several dispatchers run side by side without handing out the same message
+	query := `
+		UPDATE outbox_messages
+		SET attempts = attempts + 1, next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM outbox_messages
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, topic, payload, status, attempts, next_attempt_at, last_error, dispatched_at, created_at
+	`
+
+	var msgs []*outbox.Message
+	if err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &msgs, query, limit, lease.Seconds()); err != nil {
+		return nil, fmt.Errorf("claim outbox messages: %w", err)
+	}
+
+	return msgs, nil
+}
+
+func (r *OutboxRepository) MarkDispatched(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE outbox_messages
+		SET status = 'dispatched', dispatched_at = NOW(), last_error = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("mark outbox message dispatched: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, reason string, retryAt time.Time, dead bool) error {
+	query := `
+		UPDATE outbox_messages
+		SET last_error = $2, next_attempt_at = $3,
+		    status = CASE WHEN $4 THEN 'dead' ELSE status END
+		WHERE id = $1
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, id, reason, retryAt, dead); err != nil {
+		return fmt.Errorf("mark outbox message failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) DeleteDispatched(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM outbox_messages
+		WHERE status = 'dispatched' AND dispatched_at < $1
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete dispatched outbox messages: %w", err)
+	}
+
+	return res.RowsAffected()
+}
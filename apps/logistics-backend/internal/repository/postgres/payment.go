@@ -10,6 +10,11 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+const (
+	paymentColumns       = `id, order_id, amount, currency, method, status, captured_amount, refunded_amount, provider_ref, receipt, failure_reason, authorized_at, captured_at, paid_at, failed_at, created_at, updated_at`
+	paymentRefundColumns = `id, payment_id, amount, currency, reason, provider_ref, created_by, created_at`
+)
+
 type PaymentRepository struct {
 	exec sqlx.ExtContext
 }
@@ -27,9 +32,9 @@ func (r *PaymentRepository) execFromCtx(ctx context.Context) sqlx.ExtContext {
 
 func (r *PaymentRepository) Create(ctx context.Context, p *payment.Payment) error {
 	query := `
-		INSERT INTO payments (order_id, amount, method, status)
-		VALUES (:order_id, :amount, :method, :status)
-		RETURNING id
+		INSERT INTO payments (order_id, amount, currency, method, status, provider_ref)
+		VALUES (:order_id, :amount, COALESCE(NULLIF(:currency, ''), 'KES'), :method, :status, :provider_ref)
+		RETURNING id, currency, created_at, updated_at
 	`
 
 	rows, err := sqlx.NamedQueryContext(ctx, r.execFromCtx(ctx), query, p)
@@ -39,7 +44,7 @@ func (r *PaymentRepository) Create(ctx context.Context, p *payment.Payment) erro
 	defer rows.Close()
 
 	if rows.Next() {
-		if err := rows.Scan(&p.ID); err != nil {
+		if err := rows.Scan(&p.ID, &p.Currency, &p.CreatedAt, &p.UpdatedAt); err != nil {
 			return fmt.Errorf("scanning new payment id: %w", err)
 		}
 	} else {
@@ -50,21 +55,44 @@ func (r *PaymentRepository) Create(ctx context.Context, p *payment.Payment) erro
 }
 
 func (r *PaymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*payment.Payment, error) {
-	query := `
-		SELECT id, order_id, amount, method, status, paid_at 
-		FROM payments 
-		WHERE id = $1
-	`
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE id = $1`
+
+	var p payment.Payment
+	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &p, query, id)
+	return &p, err
+}
+
+// GetForUpdate locks the payment row so concurrent transitions and refunds apply one at a time.
+func (r *PaymentRepository) GetForUpdate(ctx context.Context, id uuid.UUID) (*payment.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE id = $1 FOR UPDATE`
 
 	var p payment.Payment
 	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &p, query, id)
 	return &p, err
 }
 
+func (r *PaymentRepository) GetByProviderRef(ctx context.Context, method payment.PaymentMethod, ref string) (*payment.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE method = $1 AND provider_ref = $2`
+
+	var p payment.Payment
+	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &p, query, method, ref)
+	return &p, err
+}
+
+// LockOrder locks the order row until the transaction ends, so only one payment attempt for
+// the order is checked and created at a time.
+func (r *PaymentRepository) LockOrder(ctx context.Context, orderID uuid.UUID) error {
+	var id uuid.UUID
+	query := `SELECT id FROM orders WHERE id = $1 FOR UPDATE`
+	return sqlx.GetContext(ctx, r.execFromCtx(ctx), &id, query, orderID)
+}
+
 func (r *PaymentRepository) GetByOrder(ctx context.Context, orderID uuid.UUID) (*payment.Payment, error) {
 	query := `
-		SELECT id, order_id, amount, status, paid_at FROM payments 
+		SELECT ` + paymentColumns + ` FROM payments
 		WHERE order_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
 	`
 
 	var p payment.Payment
@@ -72,23 +100,50 @@ func (r *PaymentRepository) GetByOrder(ctx context.Context, orderID uuid.UUID) (
 	return &p, err
 }
 
-func (r *PaymentRepository) List(ctx context.Context) ([]*payment.Payment, error) {
+func (r *PaymentRepository) ListByAdmin(ctx context.Context, adminID uuid.UUID) ([]*payment.Payment, error) {
 	query := `
-		SELECT id, order_id, amount, status 
+		SELECT ` + paymentColumns + `
 		FROM payments
+		WHERE order_id IN (SELECT id FROM orders WHERE admin_id = $1)
+		ORDER BY created_at DESC
 	`
 
 	var payments []*payment.Payment
-	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &payments, query)
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &payments, query, adminID)
 	return payments, err
 }
 
+func (r *PaymentRepository) Save(ctx context.Context, p *payment.Payment) error {
+	query := `
+		UPDATE payments
+		SET status = :status, captured_amount = :captured_amount, refunded_amount = :refunded_amount,
+			provider_ref = :provider_ref, receipt = :receipt, failure_reason = :failure_reason, authorized_at = :authorized_at,
+			captured_at = :captured_at, paid_at = :paid_at, failed_at = :failed_at, updated_at = NOW()
+		WHERE id = :id
+	`
+
+	res, err := sqlx.NamedExecContext(ctx, r.execFromCtx(ctx), query, p)
+	if err != nil {
+		return fmt.Errorf("update payment: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if rows == 0 {
+		return payment.ErrPaymentNotFound
+	}
+
+	return nil
+}
+
 func (r *PaymentRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `
 		DELETE FROM payments
 		WHERE id = $1
 	`
-	res, err := r.exec.ExecContext(ctx, query, id)
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete payment: %w", err)
 	}
@@ -104,3 +159,35 @@ func (r *PaymentRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+func (r *PaymentRepository) CreateRefund(ctx context.Context, rf *payment.Refund) error {
+	query := `
+		INSERT INTO payment_refunds (id, payment_id, amount, currency, reason, provider_ref, created_by)
+		VALUES (:id, :payment_id, :amount, :currency, :reason, :provider_ref, :created_by)
+		RETURNING created_at
+	`
+
+	rows, err := sqlx.NamedQueryContext(ctx, r.execFromCtx(ctx), query, rf)
+	if err != nil {
+		return fmt.Errorf("insert payment refund: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return fmt.Errorf("no row returned after inserting payment refund")
+	}
+	return rows.Scan(&rf.CreatedAt)
+}
+
+func (r *PaymentRepository) ListRefunds(ctx context.Context, paymentID uuid.UUID) ([]*payment.Refund, error) {
+	query := `
+		SELECT ` + paymentRefundColumns + `
+		FROM payment_refunds
+		WHERE payment_id = $1
+		ORDER BY created_at
+	`
+
+	var refunds []*payment.Refund
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &refunds, query, paymentID)
+	return refunds, err
+}
@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"logistics-backend/internal/application"
+	"logistics-backend/internal/domain/session"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type SessionRepository struct {
+	exec sqlx.ExtContext
+}
+
+func NewSessionRepository(db *sqlx.DB) *SessionRepository {
+	return &SessionRepository{exec: db}
+}
+
+func (r *SessionRepository) execFromCtx(ctx context.Context) sqlx.ExtContext {
+	if tx := application.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.exec
+}
+
+func (r *SessionRepository) Create(ctx context.Context, t *session.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (user_id, token_hash, family_id, expires_at)
+		VALUES (:user_id, :token_hash, :family_id, :expires_at)
+		RETURNING id, created_at
+	`
+	rows, err := sqlx.NamedQueryContext(ctx, r.execFromCtx(ctx), query, t)
+	if err != nil {
+		return fmt.Errorf("insert refresh token: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&t.ID, &t.CreatedAt); err != nil {
+			return fmt.Errorf("scanning new refresh token id: %w", err)
+		}
+	} else {
+		return fmt.Errorf("no id returned after scan")
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) GetByHash(ctx context.Context, tokenHash string) (*session.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, family_id, expires_at, revoked_at, replaced_by, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+
+	var t session.RefreshToken
+	if err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &t, query, tokenHash); err != nil {
+		return nil, fmt.Errorf("get refresh token: %w", err)
+	}
+
+	return &t, nil
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, id uuid.UUID, replacedBy *uuid.UUID) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW(), replaced_by = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, id, replacedBy)
+	if err != nil {
+		return fmt.Errorf("revoke refresh token: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	// a concurrent refresh already rotated this token
+	if rows == 0 {
+		return session.ErrRefreshTokenReused
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, familyID); err != nil {
+		return fmt.Errorf("revoke refresh token family: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("revoke user refresh tokens: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+
+	for _, query := range []string{
+		`DELETE FROM refresh_tokens WHERE expires_at < $1`,
+		`DELETE FROM revoked_access_tokens WHERE expires_at < $1`,
+	} {
+		res, err := r.execFromCtx(ctx).ExecContext(ctx, query, before)
+		if err != nil {
+			return total, fmt.Errorf("delete expired tokens: %w", err)
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return total, fmt.Errorf("rows affected: %w", err)
+		}
+		total += rows
+	}
+
+	return total, nil
+}
+
+func (r *SessionRepository) RevokeAccessToken(ctx context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error {
+	query := `
+		INSERT INTO revoked_access_tokens (jti, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, jti, userID, expiresAt); err != nil {
+		return fmt.Errorf("revoke access token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM revoked_access_tokens WHERE jti = $1)
+	`
+
+	var revoked bool
+	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &revoked, query, jti)
+	return revoked, err
+}
+
+func (r *SessionRepository) BumpTokenVersion(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `
+		UPDATE users
+		SET token_version = token_version + 1, updated_at = NOW()
+		WHERE id = $1
+		RETURNING token_version
+	`
+
+	var version int
+	if err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &version, query, userID); err != nil {
+		return 0, fmt.Errorf("bump token version: %w", err)
+	}
+
+	return version, nil
+}
@@ -113,6 +113,18 @@ func (r *StoreRepository) Update(ctx context.Context, storeID uuid.UUID, column
 	return nil
 }
 
+func (r *StoreRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*store.Store, error) {
+	query := `
+		SELECT * FROM stores
+		WHERE owner_id = $1
+		ORDER BY created_at DESC
+	`
+
+	var stores []*store.Store
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &stores, query, ownerID)
+	return stores, err
+}
+
 func (r *StoreRepository) ListPublic(ctx context.Context) ([]*store.Store, error) {
 	query := `
 		SELECT * FROM stores 
@@ -4,12 +4,28 @@ import (
 	"context"
 	"fmt"
 	"logistics-backend/internal/application"
+	"logistics-backend/internal/domain/notification"
 	"logistics-backend/internal/domain/user"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
+// tenantMemberPredicate matches users (aliased u) that belong to the store owner bound to $1:
+// the owner themselves, accounts they provisioned, customers who ordered from them and
+// drivers who carried their orders. Customers and drivers can belong to several owners this
+// way, so it only gates reads; writes go through OwnedBy.
+const tenantMemberPredicate = `(
+	u.id = $1
+	OR u.owner_id = $1
+	OR EXISTS (SELECT 1 FROM orders o WHERE o.user_id = u.id AND o.admin_id = $1)
+	OR EXISTS (
+		SELECT 1 FROM deliveries d
+		JOIN orders o ON o.id = d.order_id
+		WHERE d.driver_id = u.id AND o.admin_id = $1
+	)
+)`
+
 type UserRepository struct {
 	exec sqlx.ExtContext
 }
@@ -27,8 +43,8 @@ func (r *UserRepository) execFromCtx(ctx context.Context) sqlx.ExtContext {
 
 func (r *UserRepository) Create(ctx context.Context, u *user.User) error {
 	query := `
-		INSERT INTO users (full_name, email, password_hash, role, status, phone, slug)
-		VALUES (:full_name, :email, :password_hash, :role, :status, :phone, :slug)
+		INSERT INTO users (full_name, email, password_hash, role, status, phone, slug, owner_id, invited_by, email_verified_at)
+		VALUES (:full_name, :email, :password_hash, :role, :status, :phone, :slug, :owner_id, :invited_by, :email_verified_at)
 		RETURNING id
 	`
 	rows, err := sqlx.NamedQueryContext(ctx, r.execFromCtx(ctx), query, u)
@@ -79,12 +95,13 @@ func (r *UserRepository) UpdateProfile(ctx context.Context, userID uuid.UUID, ph
 
 func (r *UserRepository) UpdateColum(ctx context.Context, userID uuid.UUID, column string, value any) error {
 	allowed := map[string]bool{
-		"full_name":  true,
-		"email":      true,
-		"phone":      true,
-		"role":       true,
-		"status":     true,
-		"last_login": true,
+		"full_name":            true,
+		"email":                true,
+		"phone":                true,
+		"role":                 true,
+		"status":               true,
+		"last_login":           true,
+		"must_change_password": true,
 	}
 
 	if !allowed[column] {
@@ -118,9 +135,186 @@ func (r *UserRepository) UpdateColum(ctx context.Context, userID uuid.UUID, colu
 	return nil
 }
 
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string, mustChange bool) error {
+	query := `
+		UPDATE users
+		SET password_hash = $2, must_change_password = $3, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, userID, passwordHash, mustChange)
+	if err != nil {
+		return fmt.Errorf("update user password: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("no user found with id %s", userID)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) CreatePasswordReset(ctx context.Context, p *user.PasswordReset) error {
+	query := `
+		INSERT INTO password_resets (user_id, token_hash, expires_at)
+		VALUES (:user_id, :token_hash, :expires_at)
+		RETURNING id
+	`
+	rows, err := sqlx.NamedQueryContext(ctx, r.execFromCtx(ctx), query, p)
+	if err != nil {
+		return fmt.Errorf("insert password reset: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&p.ID); err != nil {
+			return fmt.Errorf("scanning new password reset id: %w", err)
+		}
+	} else {
+		return fmt.Errorf("no id returned after scan")
+	}
+
+	return nil
+}
+
+func (r *UserRepository) GetPasswordResetByHash(ctx context.Context, tokenHash string) (*user.PasswordReset, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM password_resets
+		WHERE token_hash = $1
+	`
+
+	var p user.PasswordReset
+	if err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &p, query, tokenHash); err != nil {
+		return nil, fmt.Errorf("get password reset: %w", err)
+	}
+
+	return &p, nil
+}
+
+func (r *UserRepository) MarkPasswordResetUsed(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE password_resets
+		SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("mark password reset used: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return user.ErrInvalidResetToken
+	}
+
+	return nil
+}
+
+func (r *UserRepository) CreateEmailVerification(ctx context.Context, v *user.EmailVerification) error {
+	query := `
+		INSERT INTO email_verifications (user_id, token_hash, expires_at)
+		VALUES (:user_id, :token_hash, :expires_at)
+		RETURNING id
+	`
+	rows, err := sqlx.NamedQueryContext(ctx, r.execFromCtx(ctx), query, v)
+	if err != nil {
+		return fmt.Errorf("insert email verification: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&v.ID); err != nil {
+			return fmt.Errorf("scanning new email verification id: %w", err)
+		}
+	} else {
+		return fmt.Errorf("no id returned after scan")
+	}
+
+	return nil
+}
+
+func (r *UserRepository) GetEmailVerificationByHash(ctx context.Context, tokenHash string) (*user.EmailVerification, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM email_verifications
+		WHERE token_hash = $1
+	`
+
+	var v user.EmailVerification
+	if err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &v, query, tokenHash); err != nil {
+		return nil, fmt.Errorf("get email verification: %w", err)
+	}
+
+	return &v, nil
+}
+
+func (r *UserRepository) MarkEmailVerificationUsed(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE email_verifications
+		SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("mark email verification used: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return user.ErrInvalidVerificationToken
+	}
+
+	return nil
+}
+
+// MarkEmailVerified records the verification and activates the account if it was waiting for it;
+// suspended or inactive accounts keep their status.
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, NOW()),
+		    status = CASE WHEN status = 'pending' THEN 'active' ELSE status END,
+		    updated_at = NOW()
+		WHERE id = $1
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("mark email verified: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("no user found with id %s", userID)
+	}
+
+	return nil
+}
+
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*user.User, error) {
 	query := `
-		SELECT id, full_name, email, password_hash, role, status, last_login, phone, slug 
+		SELECT id, full_name, email, password_hash, role, status, last_login, phone, slug, owner_id, invited_by, email_verified_at, token_version, mfa_enabled,
+		       COALESCE(must_change_password, false) AS must_change_password
 		FROM users 
 		WHERE id = $1
 	`
@@ -130,9 +324,55 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*user.User,
 	return &u, err
 }
 
+// GetContact returns where the user can be reached, for notification delivery.
+func (r *UserRepository) GetContact(ctx context.Context, id uuid.UUID) (*notification.Contact, error) {
+	query := `
+		SELECT full_name, email, COALESCE(phone, '') AS phone, device_token, locale,
+		       CASE WHEN role = 'admin' THEN id ELSE owner_id END AS tenant_id
+		FROM users
+		WHERE id = $1
+	`
+
+	var c notification.Contact
+	if err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &c, query, id); err != nil {
+		return nil, fmt.Errorf("get user contact: %w", err)
+	}
+
+	return &c, nil
+}
+
+func (r *UserRepository) UpdateDeviceToken(ctx context.Context, id uuid.UUID, token *string) error {
+	query := `
+		UPDATE users
+		SET device_token = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, id, token); err != nil {
+		return fmt.Errorf("update device token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) UpdateLocale(ctx context.Context, id uuid.UUID, locale string) error {
+	query := `
+		UPDATE users
+		SET locale = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, id, locale); err != nil {
+		return fmt.Errorf("update locale: %w", err)
+	}
+
+	return nil
+}
+
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*user.User, error) {
 	query := `
-		SELECT id, full_name, email, password_hash, role, status, last_login, phone, slug 
+		SELECT id, full_name, email, password_hash, role, status, last_login, phone, slug, owner_id, invited_by, email_verified_at, token_version, mfa_enabled,
+		       COALESCE(must_change_password, false) AS must_change_password
 		FROM users 
 		WHERE email = $1
 	`
@@ -142,17 +382,43 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*user.Us
 	return &u, err
 }
 
-func (r *UserRepository) List(ctx context.Context) ([]*user.User, error) {
-	query := `
-		SELECT id, full_name, email, password_hash, role, status, last_login, phone, slug 
-		FROM users
-	`
+func (r *UserRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*user.User, error) {
+	query := fmt.Sprintf(`
+		SELECT u.id, u.full_name, u.email, u.password_hash, u.role, u.status, u.last_login, u.phone, u.slug, u.owner_id, u.invited_by, u.email_verified_at, u.token_version, u.mfa_enabled,
+		       COALESCE(u.must_change_password, false) AS must_change_password
+		FROM users u
+		WHERE %s
+		ORDER BY u.created_at DESC
+	`, tenantMemberPredicate)
 
 	var users []*user.User
-	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &users, query)
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &users, query, ownerID)
 	return users, err
 }
 
+func (r *UserRepository) BelongsToOwner(ctx context.Context, userID, ownerID uuid.UUID) (bool, error) {
+	query := fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1 FROM users u
+			WHERE u.id = $2 AND %s
+		)
+	`, tenantMemberPredicate)
+
+	var ok bool
+	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &ok, query, ownerID, userID)
+	return ok, err
+}
+
+// OwnedBy reports whether ownerID provisioned the user's account, the only tenant allowed to
+// change or remove it.
+func (r *UserRepository) OwnedBy(ctx context.Context, userID, ownerID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND owner_id = $2)`
+
+	var ok bool
+	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &ok, query, userID, ownerID)
+	return ok, err
+}
+
 func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `
 		DELETE FROM users 
@@ -176,14 +442,14 @@ func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 }
 
-func (r *UserRepository) GetAllCustomers(ctx context.Context) ([]user.AllCustomers, error) {
-	query := `
-        SELECT id, full_name
-        FROM users
-        WHERE role = 'customer'
-        ORDER BY full_name ASC
-    `
+func (r *UserRepository) GetAllCustomers(ctx context.Context, ownerID uuid.UUID) ([]user.AllCustomers, error) {
+	query := fmt.Sprintf(`
+        SELECT u.id, u.full_name
+        FROM users u
+        WHERE u.role = 'customer' AND %s
+        ORDER BY u.full_name ASC
+    `, tenantMemberPredicate)
 	var customers []user.AllCustomers
-	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &customers, query)
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &customers, query, ownerID)
 	return customers, err
 }
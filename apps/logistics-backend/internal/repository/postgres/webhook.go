@@ -0,0 +1,274 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"logistics-backend/internal/application"
+	"logistics-backend/internal/domain/webhook"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	webhookSubscriptionColumns = `id, store_id, owner_id, url, events, secret, active, consecutive_failures, disabled_at, disabled_reason, created_at, updated_at`
+	webhookDeliveryColumns     = `id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, response_code, response_body, last_error, duration_ms, delivered_at, created_at, updated_at`
+)
+
+type WebhookRepository struct {
+	exec sqlx.ExtContext
+}
+
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{exec: db}
+}
+
+func (r *WebhookRepository) execFromCtx(ctx context.Context) sqlx.ExtContext {
+	if tx := application.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.exec
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, s *webhook.Subscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (store_id, owner_id, url, events, secret, active)
+		VALUES (:store_id, :owner_id, :url, :events, :secret, :active)
+		RETURNING id, created_at, updated_at
+	`
+	rows, err := sqlx.NamedQueryContext(ctx, r.execFromCtx(ctx), query, s)
+	if err != nil {
+		return fmt.Errorf("insert webhook subscription: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return fmt.Errorf("scanning new webhook subscription id: %w", err)
+		}
+	} else {
+		return fmt.Errorf("no id returned after scan")
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, id uuid.UUID) (*webhook.Subscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+
+	var s webhook.Subscription
+	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &s, query, id)
+	return &s, err
+}
+
+func (r *WebhookRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*webhook.Subscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE owner_id = $1
+		ORDER BY created_at DESC
+	`
+
+	var subs []*webhook.Subscription
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &subs, query, ownerID)
+	return subs, err
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, s *webhook.Subscription) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = :url, events = :events, secret = :secret, active = :active,
+			consecutive_failures = :consecutive_failures, disabled_at = :disabled_at,
+			disabled_reason = :disabled_reason, updated_at = NOW()
+		WHERE id = :id AND owner_id = :owner_id
+	`
+
+	res, err := sqlx.NamedExecContext(ctx, r.execFromCtx(ctx), query, s)
+	if err != nil {
+		return fmt.Errorf("update webhook subscription: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if rows == 0 {
+		return webhook.ErrSubscriptionNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id, ownerID uuid.UUID) error {
+	query := `DELETE FROM webhook_subscriptions WHERE id = $1 AND owner_id = $2`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, id, ownerID)
+	if err != nil {
+		return fmt.Errorf("delete webhook subscription: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if rows == 0 {
+		return webhook.ErrSubscriptionNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) ListActiveForEvent(ctx context.Context, storeID uuid.UUID, t webhook.EventType) ([]*webhook.Subscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE store_id = $1 AND active AND $2 = ANY(events)
+	`
+
+	var subs []*webhook.Subscription
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &subs, query, storeID, string(t))
+	return subs, err
+}
+
+func (r *WebhookRepository) StoreIDForOrder(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error) {
+	query := `
+		SELECT i.store_id
+		FROM orders o
+		JOIN inventories i ON i.id = o.inventory_id
+		WHERE o.id = $1
+	`
+
+	var storeID uuid.UUID
+	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &storeID, query, orderID)
+	return storeID, err
+}
+
+func (r *WebhookRepository) ResetFailures(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET consecutive_failures = 0, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.execFromCtx(ctx).ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("reset webhook failures: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) RecordFailure(ctx context.Context, id uuid.UUID, disableAfter int, reason string) (bool, error) {
+	// only the update that crosses the threshold reports the subscription as disabled,
+	// so the owner is told once even with several workers failing at the same time
+	query := `
+		UPDATE webhook_subscriptions
+		SET consecutive_failures = consecutive_failures + 1,
+			active = CASE WHEN consecutive_failures + 1 >= $2 THEN FALSE ELSE active END,
+			disabled_at = CASE WHEN consecutive_failures + 1 >= $2 AND active THEN NOW() ELSE disabled_at END,
+			disabled_reason = CASE WHEN consecutive_failures + 1 >= $2 AND active THEN $3 ELSE disabled_reason END,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING active = FALSE AND consecutive_failures = $2
+	`
+
+	var disabled bool
+	if err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &disabled, query, id, disableAfter, reason); err != nil {
+		return false, fmt.Errorf("record webhook failure: %w", err)
+	}
+
+	return disabled, nil
+}
+
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, d *webhook.Delivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, status, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5::jsonb, $6, $7)
+		ON CONFLICT (id) DO NOTHING
+	`
+
+	_, err := r.execFromCtx(ctx).ExecContext(ctx, query,
+		d.ID, d.SubscriptionID, d.EventID, d.EventType, string(d.Payload), d.Status, d.NextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("insert webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id uuid.UUID) (*webhook.Delivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+
+	var d webhook.Delivery
+	err := sqlx.GetContext(ctx, r.execFromCtx(ctx), &d, query, id)
+	return &d, err
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit, offset int) ([]*webhook.Delivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3
+	`
+
+	var deliveries []*webhook.Delivery
+	err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &deliveries, query, subscriptionID, limit, offset)
+	return deliveries, err
+}
+
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*webhook.Delivery, error) {
+	// same lease scheme as the notification queue; deliveries of disabled subscriptions
+	// wait until the owner turns the subscription back on
+	query := `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1, next_attempt_at = NOW() + make_interval(secs => $2), updated_at = NOW()
+		WHERE id IN (
+			SELECT d.id FROM webhook_deliveries d
+			JOIN webhook_subscriptions s ON s.id = d.subscription_id
+			WHERE d.status IN ('pending', 'failed') AND d.next_attempt_at <= NOW() AND s.active
+			ORDER BY d.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns + `
+	`
+
+	var deliveries []*webhook.Delivery
+	if err := sqlx.SelectContext(ctx, r.execFromCtx(ctx), &deliveries, query, limit, lease.Seconds()); err != nil {
+		return nil, fmt.Errorf("claim due webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) SaveAttempt(ctx context.Context, d *webhook.Delivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = :status, next_attempt_at = :next_attempt_at, response_code = :response_code,
+			response_body = :response_body, last_error = :last_error, duration_ms = :duration_ms,
+			delivered_at = :delivered_at, updated_at = NOW()
+		WHERE id = :id
+	`
+
+	if _, err := sqlx.NamedExecContext(ctx, r.execFromCtx(ctx), query, d); err != nil {
+		return fmt.Errorf("save webhook attempt: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) DeleteDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM webhook_deliveries
+		WHERE status IN ('succeeded', 'dead') AND updated_at < $1
+	`
+
+	res, err := r.execFromCtx(ctx).ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete webhook deliveries: %w", err)
+	}
+
+	return res.RowsAffected()
+}
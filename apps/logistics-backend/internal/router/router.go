@@ -2,6 +2,7 @@ package router
 
 import (
 	"net/http"
+	"net/netip"
 
 	"github.com/go-chi/cors"
 
@@ -24,6 +25,16 @@ func NewRouter(
 	publicApiBaseUrl string,
 	c *handlers.InviteHandler,
 	s *handlers.StoreHandler,
+	dc *handlers.DocumentHandler,
+	mf *handlers.MFAHandler,
+	k *handlers.APIKeyHandler,
+	wh *handlers.WebhookHandler,
+	jw *handlers.JWKSHandler,
+	sr *handlers.SMSReportHandler,
+	verifier authMiddleware.TokenVerifier,
+	tokens authMiddleware.TokenValidator,
+	keys authMiddleware.APIKeyValidator,
+	proxies []netip.Prefix,
 ) http.Handler {
 	r := chi.NewRouter()
 
@@ -31,16 +42,20 @@ func NewRouter(
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Key"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
 
 	// Basic middleware
+	r.Use(authMiddleware.RealIP(proxies)) // client IPs from Kong's X-Forwarded-For, used for login throttling
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 
+	// Public keys for verifying access tokens (Kong and other services)
+	r.Get("/.well-known/jwks.json", jw.GetJWKS)
+
 	r.Route("/api", func(r chi.Router) {
 		// Swagger docs (this will now be served under /api/swagger)
 		r.Get("/swagger/*", httpSwagger.Handler(
@@ -52,6 +67,25 @@ func NewRouter(
 			// Public auth
 			r.Post("/create", u.CreateUser)
 			r.Post("/login", u.LoginUser)
+			r.Post("/login/mfa", u.VerifyLoginMFA)
+			r.Post("/refresh", u.RefreshToken)
+			r.Post("/password/forgot", u.ForgotPassword)
+			r.Post("/password/reset", u.ResetPassword)
+			r.Post("/verify-email", u.VerifyEmail)
+			r.Post("/verify-email/resend", u.ResendVerification)
+
+			// SMS gateway delivery receipts, authenticated by the gateway's token or signature
+			r.Post("/sms/delivery-reports", sr.DeliveryReport)
+
+			// Payment gateway result callbacks, authenticated by the callback URL's token or Stripe's signature
+			r.Post("/payments/mpesa/callback", p.MpesaCallback)
+			r.Post("/payments/stripe/webhook", p.StripeWebhook)
+
+			// Invite links
+			r.Route("/invites", func(r chi.Router) {
+				r.Get("/by-token", c.GetMemberByToken)
+				r.Post("/accept", c.AcceptInvite)
+			})
 
 			// Public store pages
 			r.Route("/stores", func(r chi.Router) {
@@ -61,104 +95,202 @@ func NewRouter(
 
 		// Protected Routes (auth required)
 		r.Group(func(r chi.Router) {
-			r.Use(authMiddleware.JWTAuthMiddleware)
-
-			// Users
-			r.Route("/users", func(r chi.Router) {
-				r.Get("/all_users", u.ListUsers)
-				r.Get("/by-id/{id}", u.GetUserByID)
-				r.Get("/by-email/{email}", u.GetUserByEmail)
-				r.Patch("/{id}/profile", u.UpdateUserProfile)
-				r.Put("/{id}/update", u.UpdateUser)
-				r.Delete("/{id}", u.DeleteUser)
-			})
+			r.Use(authMiddleware.JWTAuthMiddleware(verifier, tokens, keys))
 
-			// Invites
-			r.Route("/invites", func(r chi.Router) {
-				r.Post("/create", c.CreateMember)
-				r.Get("/by-token", c.GetMemberByToken)
-				r.Get("/all_invites", c.ListPendingMembers)
-				r.Delete("/{id}", c.DeleteMember)
-			})
+			// Every route declares the permission it requires; see middleware.RolePermissions.
+			can := authMiddleware.RequirePermission
 
-			// Orders
-			r.Route("/orders", func(r chi.Router) {
-				r.Post("/create", o.CreateOrder)
-				r.Get("/all_orders", o.ListOrders)
-				r.Get("/form-data", o.GetOrderFormData)
-				r.Post("/assign", o.AutoAssignOrders)
-				r.Get("/by-id/{id}", o.GetOrderByID)
-				r.Get("/by-customer/{customer_id}", o.GetOrderByCustomer)
-				r.Put("/{id}/update", o.UpdateOrder)
-				r.Delete("/{id}", o.DeleteOrder)
-			})
+			// Session (any authenticated role may end its own session, change its password and manage 2FA)
+			r.Route("/auth", func(r chi.Router) {
+				r.Use(authMiddleware.RequireUserSession)
 
-			// Inventories
-			r.Route("/inventories", func(r chi.Router) {
-				r.Post("/create", i.CreateInventory)
-				r.Get("/by-name", i.GetByInventoryName)
-				r.Get("/all_inventories", i.ListInventories)
-				r.Get("/by-category", i.GetInventoryByCategory)
-				r.Get("/categories", i.ListCategories)
-				r.Get("/by-id/{id}", i.GetByInventoryID)
-				r.Get("/by-store/{id}", i.GetInventoryByStore)
-				r.Delete("/{id}", i.DeleteInventory)
-			})
+				r.Post("/logout", u.Logout)
+				r.Post("/change-password", u.ChangePassword)
 
-			// Drivers
-			r.Route("/drivers", func(r chi.Router) {
-				r.Get("/all_drivers", d.ListDrivers)
-				r.Get("/by-id/{id}", d.GetDriverByID)
-				r.Get("/by-email/{email}", d.GetDriverByEmail)
-				r.Patch("/{id}/profile", d.UpdateDriverProfile)
-				r.Put("/{id}/update", d.UpdateDriver)
-				r.Delete("/{id}", d.DeleteDriver)
+				r.Route("/mfa", func(r chi.Router) {
+					r.Post("/enroll", mf.Enroll)
+					r.Post("/confirm", mf.Confirm)
+					r.Post("/disable", mf.Disable)
+					r.Post("/recovery-codes", mf.RegenerateRecoveryCodes)
+				})
 			})
 
-			// Deliveries
-			r.Route("/deliveries", func(r chi.Router) {
-				r.Get("/all_deliveries", e.ListDeliveries)
-				r.Get("/by-id/{id}", e.GetDeliveryByID)
-				r.Put("/{id}/update", e.UpdateDelivery)
-				r.Put("/{id}/accept", e.AcceptDelivery)
-				r.Delete("/{id}", e.DeleteDelivery)
-			})
+			// Everything else stays locked while the user must change their password or set up required 2FA
+			r.Group(func(r chi.Router) {
+				r.Use(authMiddleware.RequirePasswordChanged)
+				r.Use(authMiddleware.RequireMFAEnrolled)
 
-			// Payments
-			r.Route("/payments", func(r chi.Router) {
-				r.Post("/create", p.CreatePayment)
-				r.Get("/all_payments", p.ListPayments)
-				r.Get("/{id}", p.GetPaymentByID)
-				r.Get("/{order_id}", p.GetPaymentByOrderID)
-			})
+				// Users
+				r.Route("/users", func(r chi.Router) {
+					r.With(can(authMiddleware.PermUsersList)).Get("/all_users", u.ListUsers)
+					r.With(can(authMiddleware.PermUsersRead)).Get("/by-id/{id}", u.GetUserByID)
+					r.With(can(authMiddleware.PermUsersRead)).Get("/by-email/{email}", u.GetUserByEmail)
+					r.With(can(authMiddleware.PermUsersSelf)).Patch("/{id}/profile", u.UpdateUserProfile)
+					r.With(can(authMiddleware.PermUsersSelf)).Put("/me/device-token", u.SetDeviceToken)
+					r.With(can(authMiddleware.PermUsersSelf)).Put("/me/locale", u.SetLocale)
+					r.With(can(authMiddleware.PermUsersWrite)).Put("/{id}/update", u.UpdateUser)
+					r.With(can(authMiddleware.PermUsersWrite)).Post("/{id}/revoke_sessions", u.RevokeUserSessions)
+					r.With(can(authMiddleware.PermUsersWrite)).Post("/{id}/unlock", u.UnlockUser)
+					r.With(can(authMiddleware.PermUsersDelete)).Delete("/{id}", u.DeleteUser)
+				})
 
-			// Feedbacks
-			r.Route("/feedbacks", func(r chi.Router) {
-				r.Post("/create", f.CreateFeedback)
-				r.Get("/all_feedbacks", f.ListFeedback)
-				r.Get("/{id}", f.GetFeedbackByID)
-			})
+				// Invites
+				r.Route("/invites", func(r chi.Router) {
+					r.With(can(authMiddleware.PermInvitesManage)).Post("/create", c.CreateMember)
+					r.With(can(authMiddleware.PermInvitesManage)).Get("/all_invites", c.ListPendingMembers)
+					r.With(can(authMiddleware.PermInvitesManage)).Delete("/{id}", c.DeleteMember)
+				})
 
-			// Notifications
-			r.Route("/notifications", func(r chi.Router) {
-				r.Post("/create", n.CreateNotification)
-				r.Get("/all_pending_notifications", n.ListNotifications)
-				r.Get("/all_my_notifications/{id}", n.ListUserNotifications)
-				r.Put("/{id}/status", n.UpdateNotificationStatus)
-				r.Patch("/{id}/read", n.MarkAsRead)
-				r.Patch("/mark_all_as_read/{id}", n.MarkAllAsRead)
-			})
+				// API keys (store-scoped keys for integrations; keys themselves cannot manage keys)
+				r.Route("/api-keys", func(r chi.Router) {
+					r.With(can(authMiddleware.PermAPIKeysManage)).Post("/create", k.CreateAPIKey)
+					r.With(can(authMiddleware.PermAPIKeysManage)).Get("/all_api_keys", k.ListAPIKeys)
+					r.With(can(authMiddleware.PermAPIKeysManage)).Delete("/{id}", k.RevokeAPIKey)
+				})
 
-			// Stores
-			r.Route("/stores", func(r chi.Router) {
-				r.Post("/create", s.CreateStore)
-				r.Get("/by-slug", s.GetStoreBySlug)
-				r.Get("/public", s.GetPublicStores)
-				r.Get("/by-id/{id}", s.GetStoreByID)
-				r.Put("/{id}/update", s.UpdateStore)
-				r.Delete("/{id}", s.DeleteStore)
-			})
+				// Webhooks (signed store events sent to the owner's endpoints)
+				r.Route("/webhooks", func(r chi.Router) {
+					r.With(can(authMiddleware.PermWebhooksManage)).Post("/", wh.CreateWebhook)
+					r.With(can(authMiddleware.PermWebhooksManage)).Get("/", wh.ListWebhooks)
+					r.With(can(authMiddleware.PermWebhooksManage)).Get("/{id}", wh.GetWebhook)
+					r.With(can(authMiddleware.PermWebhooksManage)).Put("/{id}", wh.UpdateWebhook)
+					r.With(can(authMiddleware.PermWebhooksManage)).Delete("/{id}", wh.DeleteWebhook)
+					r.With(can(authMiddleware.PermWebhooksManage)).Post("/{id}/rotate-secret", wh.RotateWebhookSecret)
+					r.With(can(authMiddleware.PermWebhooksManage)).Get("/{id}/deliveries", wh.ListWebhookDeliveries)
+					r.With(can(authMiddleware.PermWebhooksManage)).Post("/{id}/deliveries/{deliveryID}/redeliver", wh.RedeliverWebhook)
+				})
+
+				// Orders
+				r.Route("/orders", func(r chi.Router) {
+					r.With(can(authMiddleware.PermOrdersCreate)).Post("/create", o.CreateOrder)
+					r.With(can(authMiddleware.PermOrdersList)).Get("/all_orders", o.ListOrders)
+					r.With(can(authMiddleware.PermOrdersCreate)).Get("/form-data", o.GetOrderFormData)
+					r.With(can(authMiddleware.PermOrdersAssign)).Post("/assign", o.AutoAssignOrders)
+					r.With(can(authMiddleware.PermOrdersRead)).Get("/by-id/{id}", o.GetOrderByID)
+					r.With(can(authMiddleware.PermOrdersRead)).Get("/by-customer/{customer_id}", o.GetOrderByCustomer)
+					r.With(can(authMiddleware.PermOrdersWrite)).Put("/{id}/update", o.UpdateOrder)
+					r.With(can(authMiddleware.PermOrdersDelete)).Delete("/{id}", o.DeleteOrder)
+				})
+
+				// Inventories
+				r.Route("/inventories", func(r chi.Router) {
+					r.With(can(authMiddleware.PermInventoriesWrite)).Post("/create", i.CreateInventory)
+					r.With(can(authMiddleware.PermInventoriesRead)).Get("/by-name", i.GetByInventoryName)
+					r.With(can(authMiddleware.PermInventoriesRead)).Get("/all_inventories", i.ListInventories)
+					r.With(can(authMiddleware.PermInventoriesRead)).Get("/by-category", i.GetInventoryByCategory)
+					r.With(can(authMiddleware.PermInventoriesRead)).Get("/categories", i.ListCategories)
+					r.With(can(authMiddleware.PermInventoriesRead)).Get("/by-id/{id}", i.GetByInventoryID)
+					r.With(can(authMiddleware.PermInventoriesRead)).Get("/by-store/{id}", i.GetInventoryByStore)
+					r.With(can(authMiddleware.PermInventoriesWrite)).Delete("/{id}", i.DeleteInventory)
+				})
+
+				// Drivers
+				r.Route("/drivers", func(r chi.Router) {
+					// Driver self-service (driver resolved from the token)
+					r.Route("/me", func(r chi.Router) {
+						r.Use(can(authMiddleware.PermDriversSelf))
+
+						r.Get("/deliveries/active", d.MyActiveDeliveries)
+						r.Get("/deliveries/history", d.MyDeliveryHistory)
+						r.Get("/offers", d.MyOffers)
+						r.Get("/route", d.MyRoute)
+						r.Get("/earnings", d.MyEarnings)
+
+						// Cash on delivery
+						r.Post("/deliveries/{id}/cash", p.CollectCash)
+						r.Get("/cash", p.MyCashBalance)
+						r.Get("/cash/collections", p.MyCashCollections)
+						r.Post("/cash/remittances", p.DeclareRemittance)
+						r.Get("/cash/remittances", p.MyRemittances)
+					})
 
+					r.With(can(authMiddleware.PermDriversList)).Get("/all_drivers", d.ListDrivers)
+					r.With(can(authMiddleware.PermDriversRead)).Get("/by-id/{id}", d.GetDriverByID)
+					r.With(can(authMiddleware.PermDriversRead)).Get("/by-email/{email}", d.GetDriverByEmail)
+					r.With(can(authMiddleware.PermDriversProfile)).Patch("/{id}/profile", d.UpdateDriverProfile)
+					r.With(can(authMiddleware.PermDriversWrite)).Put("/{id}/update", d.UpdateDriver)
+					r.With(can(authMiddleware.PermDriversWrite)).Delete("/{id}", d.DeleteDriver)
+					r.With(can(authMiddleware.PermDocumentsUpload)).Post("/{id}/documents", dc.UploadDocument)
+					r.With(can(authMiddleware.PermDocumentsRead)).Get("/{id}/documents", dc.ListDriverDocuments)
+				})
+
+				// Driver documents
+				r.Route("/documents", func(r chi.Router) {
+					r.With(can(authMiddleware.PermDocumentsVerify)).Get("/expiring", dc.ListExpiringDocuments)
+					r.With(can(authMiddleware.PermDocumentsRead)).Get("/by-id/{id}", dc.GetDocumentByID)
+					r.With(can(authMiddleware.PermDocumentsRead)).Get("/{id}/file", dc.DownloadDocument)
+					r.With(can(authMiddleware.PermDocumentsVerify)).Put("/{id}/verify", dc.VerifyDocument)
+					r.With(can(authMiddleware.PermDocumentsVerify)).Delete("/{id}", dc.DeleteDocument)
+				})
+
+				// Deliveries
+				r.Route("/deliveries", func(r chi.Router) {
+					r.With(can(authMiddleware.PermDeliveriesList)).Get("/all_deliveries", e.ListDeliveries)
+					r.With(can(authMiddleware.PermDeliveriesRead)).Get("/by-id/{id}", e.GetDeliveryByID)
+					r.With(can(authMiddleware.PermDeliveriesWrite)).Put("/{id}/update", e.UpdateDelivery)
+					r.With(can(authMiddleware.PermDeliveriesAccept)).Put("/{id}/accept", e.AcceptDelivery)
+					r.With(can(authMiddleware.PermDeliveriesDelete)).Delete("/{id}", e.DeleteDelivery)
+				})
+
+				// Payments
+				r.Route("/payments", func(r chi.Router) {
+					r.With(can(authMiddleware.PermPaymentsManage)).Post("/create", p.CreatePayment)
+					r.With(can(authMiddleware.PermPaymentsCreate)).Post("/initiate", p.InitiatePayment)
+					r.With(can(authMiddleware.PermPaymentsList)).Get("/all_payments", p.ListPayments)
+					r.With(can(authMiddleware.PermPaymentsRead)).Get("/{id}", p.GetPaymentByID)
+					r.With(can(authMiddleware.PermPaymentsRead)).Get("/{order_id}", p.GetPaymentByOrderID)
+					r.With(can(authMiddleware.PermPaymentsManage)).Post("/{id}/authorize", p.AuthorizePayment)
+					r.With(can(authMiddleware.PermPaymentsManage)).Post("/{id}/capture", p.CapturePayment)
+					r.With(can(authMiddleware.PermPaymentsManage)).Post("/{id}/complete", p.CompletePayment)
+					r.With(can(authMiddleware.PermPaymentsManage)).Post("/{id}/fail", p.FailPayment)
+					r.With(can(authMiddleware.PermPaymentsManage)).Post("/{id}/refunds", p.RefundPayment)
+					r.With(can(authMiddleware.PermPaymentsManage)).Get("/{id}/refunds", p.ListRefunds)
+
+					// Cash on delivery reconciliation
+					r.With(can(authMiddleware.PermPaymentsManage)).Get("/cash/balances", p.CashBalances)
+					r.With(can(authMiddleware.PermPaymentsManage)).Get("/cash/remittances", p.ListRemittances)
+					r.With(can(authMiddleware.PermPaymentsManage)).Post("/cash/remittances/{id}/confirm", p.ConfirmRemittance)
+					r.With(can(authMiddleware.PermPaymentsManage)).Get("/cash/report", p.CashReport)
+				})
+
+				// Feedbacks
+				r.Route("/feedbacks", func(r chi.Router) {
+					r.With(can(authMiddleware.PermFeedbackCreate)).Post("/create", f.CreateFeedback)
+					r.With(can(authMiddleware.PermFeedbackRead)).Get("/all_feedbacks", f.ListFeedback)
+					r.With(can(authMiddleware.PermFeedbackRead)).Get("/{id}", f.GetFeedbackByID)
+				})
+
+				// Notifications
+				r.Route("/notifications", func(r chi.Router) {
+					r.With(can(authMiddleware.PermNotificationsManage)).Post("/create", n.CreateNotification)
+					r.With(can(authMiddleware.PermNotificationsManage)).Get("/all_pending_notifications", n.ListNotifications)
+					r.With(can(authMiddleware.PermNotificationsRead)).Get("/inbox", n.ListInbox)
+					r.With(can(authMiddleware.PermNotificationsRead)).Get("/inbox/unread-count", n.UnreadCount)
+					r.With(can(authMiddleware.PermNotificationsRead)).Post("/inbox/read", n.MarkRead)
+					r.With(can(authMiddleware.PermNotificationsRead)).Post("/inbox/archive", n.Archive)
+					r.With(can(authMiddleware.PermNotificationsRead)).Get("/preferences", n.GetPreferences)
+					r.With(can(authMiddleware.PermNotificationsRead)).Put("/preferences", n.SavePreferences)
+					r.With(can(authMiddleware.PermNotificationsManage)).Get("/templates", n.ListTemplates)
+					r.With(can(authMiddleware.PermNotificationsManage)).Put("/templates/{event}/{locale}/{channel}", n.SaveTemplate)
+					r.With(can(authMiddleware.PermNotificationsManage)).Delete("/templates/{event}/{locale}/{channel}", n.DeleteTemplate)
+					r.With(can(authMiddleware.PermNotificationsRead)).Get("/all_my_notifications/{id}", n.ListUserNotifications)
+					r.With(can(authMiddleware.PermNotificationsManage)).Put("/{id}/status", n.UpdateNotificationStatus)
+					r.With(can(authMiddleware.PermNotificationsRead)).Patch("/{id}/read", n.MarkAsRead)
+					r.With(can(authMiddleware.PermNotificationsRead)).Delete("/{id}", n.DeleteNotification)
+					r.With(can(authMiddleware.PermNotificationsRead)).Patch("/mark_all_as_read/{id}", n.MarkAllAsRead)
+				})
+
+				// Stores
+				r.Route("/stores", func(r chi.Router) {
+					r.With(can(authMiddleware.PermStoresWrite)).Post("/create", s.CreateStore)
+					r.With(can(authMiddleware.PermStoresWrite)).Get("/mine", s.ListMyStores)
+					r.With(can(authMiddleware.PermStoresRead)).Get("/by-slug", s.GetStoreBySlug)
+					r.With(can(authMiddleware.PermStoresRead)).Get("/public", s.GetPublicStores)
+					r.With(can(authMiddleware.PermStoresRead)).Get("/by-id/{id}", s.GetStoreByID)
+					r.With(can(authMiddleware.PermStoresWrite)).Put("/{id}/update", s.UpdateStore)
+					r.With(can(authMiddleware.PermStoresWrite)).Delete("/{id}", s.DeleteStore)
+				})
+			})
 		})
 	})
 
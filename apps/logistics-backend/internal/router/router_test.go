@@ -0,0 +1,208 @@
+package router
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"logistics-backend/handlers"
+	"logistics-backend/internal/domain/apikey"
+	"logistics-backend/internal/domain/user"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+type testVerifier struct{ pub ed25519.PublicKey }
+
+func (v testVerifier) Keyfunc(*jwt.Token) (any, error) { return v.pub, nil }
+func (v testVerifier) Methods() []string               { return []string{"EdDSA"} }
+
+type allowTokens struct{}
+
+func (allowTokens) ValidateAccessToken(context.Context, uuid.UUID, string, int) error { return nil }
+
+type noKeys struct{}
+
+func (noKeys) AuthenticateAPIKey(context.Context, string) (*apikey.APIKey, error) {
+	return nil, apikey.ErrInvalidAPIKey
+}
+
+// newTestRouter mounts the real routes on handlers without use cases. Requests the RBAC layer
+// lets through reach a handler and fail there, which is all these tests need to tell apart.
+func newTestRouter(t *testing.T) (http.Handler, ed25519.PrivateKey) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRouter(
+		&handlers.UserHandler{},
+		&handlers.OrderHandler{},
+		&handlers.DriverHandler{},
+		&handlers.DeliveryHandler{},
+		&handlers.PaymentHandler{},
+		&handlers.FeedbackHandler{},
+		&handlers.NotificationHandler{},
+		&handlers.InventoryHandler{},
+		"",
+		&handlers.InviteHandler{},
+		&handlers.StoreHandler{},
+		&handlers.DocumentHandler{},
+		&handlers.MFAHandler{},
+		&handlers.APIKeyHandler{},
+		&handlers.WebhookHandler{},
+		&handlers.JWKSHandler{},
+		&handlers.SMSReportHandler{},
+		testVerifier{pub},
+		allowTokens{},
+		noKeys{},
+		nil,
+	)
+	return r, priv
+}
+
+func tokenFor(t *testing.T, key ed25519.PrivateKey, role user.Role) string {
+	t.Helper()
+
+	tok := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+		"sub":  uuid.NewString(),
+		"role": string(role),
+		"ver":  0,
+		"jti":  uuid.NewString(),
+		"exp":  time.Now().Add(time.Minute).Unix(),
+	})
+	s, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+var allRoles = []user.Role{user.Admin, user.Driver, user.Customer, user.Guest}
+
+// TestRoutePermissions checks, per route group, which roles get past RequirePermission.
+func TestRoutePermissions(t *testing.T) {
+	id := uuid.NewString()
+
+	tests := []struct {
+		group   string
+		method  string
+		path    string
+		allowed []user.Role
+	}{
+		{"auth", http.MethodPost, "/api/auth/logout", allRoles},
+
+		{"users", http.MethodGet, "/api/users/all_users", []user.Role{user.Admin}},
+		{"users", http.MethodGet, "/api/users/by-id/" + id, []user.Role{user.Admin}},
+		{"users", http.MethodPatch, "/api/users/" + id + "/profile", []user.Role{user.Admin, user.Driver, user.Customer}},
+		{"users", http.MethodPut, "/api/users/me/locale", []user.Role{user.Admin, user.Driver, user.Customer}},
+		{"users", http.MethodPut, "/api/users/" + id + "/update", []user.Role{user.Admin}},
+		{"users", http.MethodDelete, "/api/users/" + id, []user.Role{user.Admin}},
+
+		{"invites", http.MethodPost, "/api/invites/create", []user.Role{user.Admin}},
+		{"api-keys", http.MethodGet, "/api/api-keys/all_api_keys", []user.Role{user.Admin}},
+		{"webhooks", http.MethodGet, "/api/webhooks/", []user.Role{user.Admin}},
+
+		{"orders", http.MethodPost, "/api/orders/create", []user.Role{user.Admin}},
+		{"orders", http.MethodGet, "/api/orders/all_orders", []user.Role{user.Admin}},
+		{"orders", http.MethodGet, "/api/orders/by-id/" + id, []user.Role{user.Admin, user.Driver, user.Customer}},
+		{"orders", http.MethodPost, "/api/orders/assign", []user.Role{user.Admin}},
+		{"orders", http.MethodDelete, "/api/orders/" + id, []user.Role{user.Admin}},
+
+		{"inventories", http.MethodGet, "/api/inventories/all_inventories", allRoles},
+		{"inventories", http.MethodPost, "/api/inventories/create", []user.Role{user.Admin}},
+		{"inventories", http.MethodDelete, "/api/inventories/" + id, []user.Role{user.Admin}},
+
+		{"drivers", http.MethodGet, "/api/drivers/me/offers", []user.Role{user.Driver}},
+		{"drivers", http.MethodPost, "/api/drivers/me/deliveries/" + id + "/cash", []user.Role{user.Driver}},
+		{"drivers", http.MethodGet, "/api/drivers/all_drivers", []user.Role{user.Admin}},
+		{"drivers", http.MethodGet, "/api/drivers/by-id/" + id, []user.Role{user.Admin}},
+		{"drivers", http.MethodPatch, "/api/drivers/" + id + "/profile", []user.Role{user.Admin, user.Driver}},
+		{"drivers", http.MethodDelete, "/api/drivers/" + id, []user.Role{user.Admin}},
+
+		{"documents", http.MethodPost, "/api/drivers/" + id + "/documents", []user.Role{user.Admin, user.Driver}},
+		{"documents", http.MethodGet, "/api/documents/by-id/" + id, []user.Role{user.Admin, user.Driver}},
+		{"documents", http.MethodGet, "/api/documents/expiring", []user.Role{user.Admin}},
+		{"documents", http.MethodPut, "/api/documents/" + id + "/verify", []user.Role{user.Admin}},
+
+		{"deliveries", http.MethodGet, "/api/deliveries/all_deliveries", []user.Role{user.Admin}},
+		{"deliveries", http.MethodGet, "/api/deliveries/by-id/" + id, []user.Role{user.Admin, user.Driver}},
+		{"deliveries", http.MethodPut, "/api/deliveries/" + id + "/accept", []user.Role{user.Driver}},
+		{"deliveries", http.MethodDelete, "/api/deliveries/" + id, []user.Role{user.Admin}},
+
+		{"payments", http.MethodPost, "/api/payments/create", []user.Role{user.Admin}},
+		{"payments", http.MethodPost, "/api/payments/initiate", []user.Role{user.Admin, user.Customer}},
+		{"payments", http.MethodGet, "/api/payments/all_payments", []user.Role{user.Admin}},
+		{"payments", http.MethodGet, "/api/payments/" + id, []user.Role{user.Admin, user.Customer}},
+		{"payments", http.MethodPost, "/api/payments/" + id + "/refunds", []user.Role{user.Admin}},
+		{"payments", http.MethodGet, "/api/payments/cash/report", []user.Role{user.Admin}},
+
+		{"feedbacks", http.MethodPost, "/api/feedbacks/create", []user.Role{user.Admin, user.Customer}},
+		{"feedbacks", http.MethodGet, "/api/feedbacks/all_feedbacks", []user.Role{user.Admin}},
+
+		{"notifications", http.MethodGet, "/api/notifications/inbox", []user.Role{user.Admin, user.Driver, user.Customer}},
+		{"notifications", http.MethodPut, "/api/notifications/preferences", []user.Role{user.Admin, user.Driver, user.Customer}},
+		{"notifications", http.MethodPost, "/api/notifications/create", []user.Role{user.Admin}},
+
+		{"stores", http.MethodGet, "/api/stores/public", allRoles},
+		{"stores", http.MethodPost, "/api/stores/create", []user.Role{user.Admin}},
+		{"stores", http.MethodDelete, "/api/stores/" + id, []user.Role{user.Admin}},
+	}
+
+	r, key := newTestRouter(t)
+	tokens := make(map[user.Role]string, len(allRoles))
+	for _, role := range allRoles {
+		tokens[role] = tokenFor(t, key, role)
+	}
+
+	for _, tt := range tests {
+		for _, role := range allRoles {
+			t.Run(tt.group+"/"+tt.method+" "+tt.path+"/"+string(role), func(t *testing.T) {
+				req := httptest.NewRequest(tt.method, tt.path, strings.NewReader("{}"))
+				req.Header.Set("Authorization", "Bearer "+tokens[role])
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, req)
+
+				if rec.Code == http.StatusMethodNotAllowed || rec.Body.String() == "404 page not found\n" {
+					t.Fatalf("route not mounted: %d", rec.Code)
+				}
+
+				denied := rec.Code == http.StatusForbidden && strings.Contains(rec.Body.String(), "missing permission")
+				if want := slices.Contains(tt.allowed, role); denied == want {
+					t.Errorf("allowed = %v, want %v (status %d: %s)", !denied, want, rec.Code, rec.Body.String())
+				}
+			})
+		}
+	}
+}
+
+// TestProtectedRoutesNeedToken checks that every protected group rejects anonymous requests.
+func TestProtectedRoutesNeedToken(t *testing.T) {
+	r, _ := newTestRouter(t)
+
+	for _, path := range []string{
+		"/api/users/all_users",
+		"/api/orders/all_orders",
+		"/api/drivers/me/offers",
+		"/api/payments/all_payments",
+		"/api/notifications/inbox",
+		"/api/stores/mine",
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s: status %d, want 401", path, rec.Code)
+		}
+	}
+}
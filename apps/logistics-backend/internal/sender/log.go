@@ -0,0 +1,29 @@
+// Package sender holds the concrete email, SMS and push senders behind notification.MultiChannelSender.
+package sender
+
+import (
+	"context"
+	"log"
+	"logistics-backend/internal/domain/notification"
+)
+
+// LogSender stands in for a channel that has no provider configured, e.g. in local development.
+// Nothing is delivered: each send is logged without its address or content, which can carry
+// single-use links, and fails with notification.ErrChannelUnavailable so the delivery worker
+// dead-letters the notification instead of marking it sent.
+type LogSender struct{}
+
+func (LogSender) SendEmail(ctx context.Context, to, subject, text, html string) error {
+	log.Printf("email not sent, no provider configured (%d byte body)", len(text))
+	return notification.ErrChannelUnavailable
+}
+
+func (LogSender) SendSMS(ctx context.Context, phone, message string) (string, error) {
+	log.Printf("sms not sent, no provider configured (%d byte message)", len(message))
+	return "", notification.ErrChannelUnavailable
+}
+
+func (LogSender) SendPush(ctx context.Context, deviceToken, title, message string) error {
+	log.Printf("push not sent, no provider configured (%d byte message)", len(message))
+	return notification.ErrChannelUnavailable
+}
@@ -0,0 +1,37 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"logistics-backend/internal/domain/notification"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogSenderFailsWithoutLeakingContent(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	const secret = "https://app.example/reset?token=s3cr3t"
+	var s LogSender
+
+	if err := s.SendEmail(context.Background(), "a@example.com", "Reset", secret, secret); !errors.Is(err, notification.ErrChannelUnavailable) {
+		t.Errorf("SendEmail error = %v, want ErrChannelUnavailable", err)
+	}
+	if _, err := s.SendSMS(context.Background(), "+254700000000", secret); !errors.Is(err, notification.ErrChannelUnavailable) {
+		t.Errorf("SendSMS error = %v, want ErrChannelUnavailable", err)
+	}
+	if err := s.SendPush(context.Background(), "device-token", "Reset", secret); !errors.Is(err, notification.ErrChannelUnavailable) {
+		t.Errorf("SendPush error = %v, want ErrChannelUnavailable", err)
+	}
+
+	for _, leaked := range []string{"s3cr3t", "a@example.com", "+254700000000", "device-token"} {
+		if strings.Contains(buf.String(), leaked) {
+			t.Errorf("log contains %q:\n%s", leaked, buf.String())
+		}
+	}
+}
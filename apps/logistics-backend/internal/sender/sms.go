@@ -0,0 +1,107 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"logistics-backend/internal/domain/notification"
+	"logistics-backend/internal/utils"
+	"net/http"
+	"strings"
+)
+
+// SMSProvider is one HTTP SMS gateway. Send gets an E.164 number and returns the gateway's
+// message ID; ParseDeliveryReport authenticates and decodes the gateway's receipt callback,
+// returning a nil report for intermediate states.
+type SMSProvider interface {
+	Send(ctx context.Context, to, message string) (string, error)
+	ParseDeliveryReport(r *http.Request) (*notification.DeliveryReport, error)
+}
+
+// SMSSender is the provider-agnostic notification.SMSSender: it normalises the number, keeps the
+// message in the cheaper GSM-7 alphabet where it can and refuses messages over MaxSegments.
+type SMSSender struct {
+	provider    SMSProvider
+	maxSegments int
+}
+
+// NewSMSSender wraps provider; maxSegments <= 0 defaults to 4 (612 GSM-7 characters).
+func NewSMSSender(provider SMSProvider, maxSegments int) *SMSSender {
+	if maxSegments <= 0 {
+		maxSegments = 4
+	}
+	return &SMSSender{provider: provider, maxSegments: maxSegments}
+}
+
+func (s *SMSSender) SendSMS(ctx context.Context, phone, message string) (string, error) {
+	to, err := utils.NormalizePhone(phone)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q is not a valid phone number", notification.ErrUndeliverable, phone)
+	}
+
+	message = gsmFold(message)
+	if n, _ := SMSSegments(message); n > s.maxSegments {
+		return "", fmt.Errorf("%w: message needs %d SMS segments, limit is %d", notification.ErrUndeliverable, n, s.maxSegments)
+	}
+
+	return s.provider.Send(ctx, to, message)
+}
+
+func (s *SMSSender) ParseDeliveryReport(r *http.Request) (*notification.DeliveryReport, error) {
+	return s.provider.ParseDeliveryReport(r)
+}
+
+// GSM 03.38 default alphabet; extension characters cost two septets.
+const (
+	gsmBasic     = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+	gsmExtension = "^{}\\[~]|€\f"
+)
+
+// SMSSegments reports how many SMS parts message is billed as and whether it needs UCS-2.
+// A single part holds 160 GSM-7 or 70 UCS-2 characters; concatenated parts lose room to
+// the UDH header and hold 153 and 67.
+func SMSSegments(message string) (segments int, unicode bool) {
+	septets := 0
+	for _, r := range message {
+		switch {
+		case strings.ContainsRune(gsmBasic, r):
+			septets++
+		case strings.ContainsRune(gsmExtension, r):
+			septets += 2
+		default:
+			unicode = true
+		}
+	}
+
+	if unicode {
+		// UCS-2 counts UTF-16 code units, so emoji take two
+		units := 0
+		for _, r := range message {
+			units++
+			if r > 0xFFFF {
+				units++
+			}
+		}
+		return parts(units, 70, 67), true
+	}
+	return parts(septets, 160, 153), false
+}
+
+func parts(n, single, multi int) int {
+	if n <= single {
+		return 1
+	}
+	return (n + multi - 1) / multi
+}
+
+// gsmFolder swaps typographic characters for their GSM-7 look-alikes, so a stray curly quote
+// does not switch the whole message to UCS-2 and more than double its cost.
+var gsmFolder = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'",
+	"“", "\"", "”", "\"", "„", "\"",
+	"–", "-", "—", "-", "…", "...",
+	"\u00a0", " ", "•", "-",
+)
+
+func gsmFold(message string) string {
+	return gsmFolder.Replace(message)
+}
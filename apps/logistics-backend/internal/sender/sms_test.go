@@ -0,0 +1,201 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"logistics-backend/internal/domain/notification"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestSMSSegments(t *testing.T) {
+	cases := []struct {
+		name     string
+		msg      string
+		segments int
+		unicode  bool
+	}{
+		{"single gsm", strings.Repeat("a", 160), 1, false},
+		{"two gsm", strings.Repeat("a", 161), 2, false},
+		{"extension chars count double", strings.Repeat("€", 80), 1, false},
+		{"extension chars overflow", strings.Repeat("€", 81), 2, false},
+		{"single ucs2", strings.Repeat("ā", 70), 1, true},
+		{"two ucs2", strings.Repeat("ā", 71), 2, true},
+		{"emoji are two code units", strings.Repeat("✅", 70) + "😀", 2, true},
+	}
+	for _, c := range cases {
+		n, unicode := SMSSegments(c.msg)
+		if n != c.segments || unicode != c.unicode {
+			t.Errorf("%s: got (%d, %v), want (%d, %v)", c.name, n, unicode, c.segments, c.unicode)
+		}
+	}
+}
+
+type recordingProvider struct{ to, message string }
+
+func (p *recordingProvider) Send(ctx context.Context, to, message string) (string, error) {
+	p.to, p.message = to, message
+	return "ref-1", nil
+}
+
+func (p *recordingProvider) ParseDeliveryReport(r *http.Request) (*notification.DeliveryReport, error) {
+	return nil, nil
+}
+
+func TestSMSSenderNormalisesAndFolds(t *testing.T) {
+	p := &recordingProvider{}
+	s := NewSMSSender(p, 1)
+
+	ref, err := s.SendSMS(context.Background(), "0712 345-678", "Your driver’s on the way – ETA 5 min")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != "ref-1" || p.to != "+254712345678" {
+		t.Errorf("sent to %q (ref %q)", p.to, ref)
+	}
+	if p.message != "Your driver's on the way - ETA 5 min" {
+		t.Errorf("message = %q", p.message)
+	}
+
+	if _, err := s.SendSMS(context.Background(), "not a number", "hi"); !errors.Is(err, notification.ErrUndeliverable) {
+		t.Errorf("invalid number: err = %v", err)
+	}
+	if _, err := s.SendSMS(context.Background(), "+254712345678", strings.Repeat("a", 161)); !errors.Is(err, notification.ErrUndeliverable) {
+		t.Errorf("too long: err = %v", err)
+	}
+}
+
+func TestAfricasTalkingSend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version1/messaging" || r.Header.Get("apiKey") != "key" {
+			http.Error(w, "bad request", http.StatusUnauthorized)
+			return
+		}
+		r.ParseForm()
+		if r.PostForm.Get("username") != "sandbox" || r.PostForm.Get("from") != "FASTABIZ" {
+			http.Error(w, "bad form", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("to") == "+254700000000" {
+			w.Write([]byte(`{"SMSMessageData":{"Message":"Sent to 0/1","Recipients":[{"statusCode":403,"number":"+254700000000","status":"InvalidPhoneNumber","messageId":"None"}]}}`))
+			return
+		}
+		w.Write([]byte(`{"SMSMessageData":{"Message":"Sent to 1/1 Total Cost: KES 0.8000","Recipients":[{"statusCode":101,"number":"+254712345678","status":"Success","cost":"KES 0.8000","messageId":"ATXid_abc"}]}}`))
+	}))
+	defer srv.Close()
+
+	at := &AfricasTalking{BaseURL: srv.URL, Username: "sandbox", APIKey: "key", SenderID: "FASTABIZ"}
+
+	ref, err := at.Send(context.Background(), "+254712345678", "hello")
+	if err != nil || ref != "ATXid_abc" {
+		t.Fatalf("Send = %q, %v", ref, err)
+	}
+	if _, err := at.Send(context.Background(), "+254700000000", "hello"); !errors.Is(err, notification.ErrUndeliverable) {
+		t.Errorf("invalid number: err = %v", err)
+	}
+}
+
+func TestAfricasTalkingDeliveryReport(t *testing.T) {
+	at := &AfricasTalking{CallbackToken: "secret"}
+
+	post := func(token string, form url.Values) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/api/public/sms/delivery-reports?token="+token, strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+
+	if _, err := at.ParseDeliveryReport(post("wrong", url.Values{"id": {"ATXid_abc"}, "status": {"Success"}})); err != ErrInvalidCallback {
+		t.Errorf("wrong token: err = %v", err)
+	}
+
+	report, err := at.ParseDeliveryReport(post("secret", url.Values{"id": {"ATXid_abc"}, "status": {"Failed"}, "failureReason": {"AbsentSubscriber"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ProviderRef != "ATXid_abc" || report.Status != notification.Failed || report.Reason != "AbsentSubscriber" {
+		t.Errorf("report = %+v", report)
+	}
+
+	if report, err := at.ParseDeliveryReport(post("secret", url.Values{"id": {"ATXid_abc"}, "status": {"Buffered"}})); report != nil || err != nil {
+		t.Errorf("intermediate state: %+v, %v", report, err)
+	}
+}
+
+func TestTwilioSend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" || user != "AC123" || pass != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":20003,"message":"Authenticate"}`))
+			return
+		}
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("To") == "+15005550001" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":21211,"message":"The 'To' number +15005550001 is not a valid phone number."}`))
+			return
+		}
+		if r.PostForm.Get("MessagingServiceSid") != "MG1" || r.PostForm.Get("StatusCallback") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":21606,"message":"bad from"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid":"SM123","status":"queued"}`))
+	}))
+	defer srv.Close()
+
+	tw := &Twilio{BaseURL: srv.URL, AccountSID: "AC123", AuthToken: "token", From: "MG1", StatusCallbackURL: "https://api.example.com/api/public/sms/delivery-reports"}
+
+	ref, err := tw.Send(context.Background(), "+254712345678", "hello")
+	if err != nil || ref != "SM123" {
+		t.Fatalf("Send = %q, %v", ref, err)
+	}
+	if _, err := tw.Send(context.Background(), "+15005550001", "hello"); !errors.Is(err, notification.ErrUndeliverable) {
+		t.Errorf("invalid number: err = %v", err)
+	}
+}
+
+func TestTwilioDeliveryReport(t *testing.T) {
+	tw := &Twilio{AuthToken: "token", StatusCallbackURL: "https://api.example.com/api/public/sms/delivery-reports"}
+	form := url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"undelivered"}, "ErrorCode": {"30003"}}
+
+	post := func(sig string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/api/public/sms/delivery-reports", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("X-Twilio-Signature", sig)
+		return r
+	}
+
+	if _, err := tw.ParseDeliveryReport(post("forged")); err != ErrInvalidCallback {
+		t.Errorf("forged signature: err = %v", err)
+	}
+
+	report, err := tw.ParseDeliveryReport(post(tw.signature(form)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ProviderRef != "SM123" || report.Status != notification.Failed || report.Reason != "twilio error 30003" {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestTwilioSignatureMatchesReference(t *testing.T) {
+	// example from Twilio's webhook security documentation
+	tw := &Twilio{AuthToken: "12345", StatusCallbackURL: "https://mycompany.com/myapp.php?foo=1&bar=2"}
+	form := url.Values{
+		"CallSid": {"CA1234567890ABCDE"},
+		"Caller":  {"+12349013030"},
+		"Digits":  {"1234"},
+		"From":    {"+12349013030"},
+		"To":      {"+18005551212"},
+	}
+	if got := tw.signature(form); got != "0/KCTR6DLpKmkAf8muzZqo1nDgQ=" {
+		t.Errorf("signature = %s", got)
+	}
+}
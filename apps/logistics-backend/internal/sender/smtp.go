@@ -0,0 +1,273 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP connection security modes.
+const (
+	SMTPStartTLS = "starttls" // plain connection upgraded with STARTTLS, usually port 587
+	SMTPTLS      = "tls"      // implicit TLS from the first byte, usually port 465
+	SMTPNone     = "none"     // no encryption, only for local relays and development
+)
+
+var (
+	ErrSMTPNoHost       = errors.New("smtp host is required")
+	ErrSMTPNoFrom       = errors.New("smtp from address is required")
+	ErrSMTPSecurity     = errors.New("smtp security must be starttls, tls or none")
+	ErrSMTPNoStartTLS   = errors.New("smtp server does not support STARTTLS")
+	ErrSMTPAuthInsecure = errors.New("smtp credentials would be sent over an unencrypted connection")
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // empty disables AUTH
+	Password string
+	From     string // e.g. "FastaBiz <no-reply@fastabiz.app>"
+	Security string // SMTPStartTLS (default), SMTPTLS or SMTPNone
+	Timeout  time.Duration
+
+	// TLSConfig overrides the default verification against Host, e.g. to trust a private CA.
+	TLSConfig *tls.Config
+}
+
+// SMTPSender delivers email through an SMTP relay, one connection per message.
+type SMTPSender struct {
+	cfg  SMTPConfig
+	from *mail.Address
+}
+
+func NewSMTPSender(cfg SMTPConfig) (*SMTPSender, error) {
+	if cfg.Host == "" {
+		return nil, ErrSMTPNoHost
+	}
+	if cfg.From == "" {
+		return nil, ErrSMTPNoFrom
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp from address: %w", err)
+	}
+
+	switch cfg.Security {
+	case "":
+		cfg.Security = SMTPStartTLS
+	case SMTPStartTLS, SMTPTLS, SMTPNone:
+	default:
+		return nil, ErrSMTPSecurity
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+		if cfg.Security == SMTPTLS {
+			cfg.Port = 465
+		}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	return &SMTPSender{cfg: cfg, from: from}, nil
+}
+
+// SendEmail sends a multipart/alternative message; clients show the HTML part and fall back to
+// text. An empty htmlBody is rendered from text.
+func (s *SMTPSender) SendEmail(ctx context.Context, to, subject, text, htmlBody string) error {
+	if htmlBody == "" {
+		htmlBody = textToHTML(text)
+	}
+
+	rcpt, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", to, err)
+	}
+
+	msg, err := s.buildMessage(rcpt, subject, text, htmlBody)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	c, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	// Abort the SMTP conversation if the context ends mid-way
+	stop := context.AfterFunc(ctx, func() { c.Close() })
+	defer stop()
+
+	if err := s.deliver(c, rcpt.Address, msg); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("smtp: %w", ctx.Err())
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *SMTPSender) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("smtp dial %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if s.cfg.Security == SMTPTLS {
+		conn = tls.Client(conn, s.tlsConfig())
+	}
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp handshake: %w", err)
+	}
+	return c, nil
+}
+
+func (s *SMTPSender) deliver(c *smtp.Client, to string, msg []byte) error {
+	if err := c.Hello("localhost"); err != nil {
+		return fmt.Errorf("smtp hello: %w", err)
+	}
+
+	if s.cfg.Security == SMTPStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return ErrSMTPNoStartTLS
+		}
+		if err := c.StartTLS(s.tlsConfig()); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+
+	if s.cfg.Username != "" {
+		if s.cfg.Security == SMTPNone {
+			return ErrSMTPAuthInsecure
+		}
+		auth := smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+		if err := c.Auth(auth); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(s.from.Address); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	if err := c.Rcpt(to); err != nil {
+		return fmt.Errorf("smtp rcpt to: %w", err)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		w.Close()
+		return fmt.Errorf("smtp write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	return c.Quit()
+}
+
+func (s *SMTPSender) tlsConfig() *tls.Config {
+	if s.cfg.TLSConfig != nil {
+		cfg := s.cfg.TLSConfig.Clone()
+		if cfg.ServerName == "" {
+			cfg.ServerName = s.cfg.Host
+		}
+		return cfg
+	}
+	return &tls.Config{ServerName: s.cfg.Host, MinVersion: tls.VersionTLS12}
+}
+
+func (s *SMTPSender) buildMessage(to *mail.Address, subject, text, htmlBody string) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	h := []struct{ k, v string }{
+		{"From", s.from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID(s.from.Address)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", `multipart/alternative; boundary="` + mw.Boundary() + `"`},
+	}
+	for _, f := range h {
+		fmt.Fprintf(&buf, "%s: %s\r\n", f.k, f.v)
+	}
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct{ ctype, body string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.ctype},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// textToHTML renders a plain-text body as escaped HTML paragraphs.
+func textToHTML(text string) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html><html><body>")
+	for _, p := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(p), "\n", "<br>"))
+		b.WriteString("</p>")
+	}
+	b.WriteString("</body></html>")
+	return b.String()
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	var r [12]byte
+	rand.Read(r[:])
+	return fmt.Sprintf("<%d.%x@%s>", time.Now().UnixNano(), r, domain)
+}
@@ -0,0 +1,282 @@
+package sender
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is a minimal SMTP stand-in that records what the client sent.
+type fakeSMTP struct {
+	ln  net.Listener
+	tls *tls.Config // offers STARTTLS when set
+
+	mu       sync.Mutex
+	auth     string
+	from     string
+	rcpt     string
+	data     string
+	startTLS bool
+}
+
+func newFakeSMTP(t *testing.T, tlsCfg *tls.Config) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTP{ln: ln, tls: tlsCfg}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTP) port() int { return s.ln.Addr().(*net.TCPAddr).Port }
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	secure := false
+	tp.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			ext := []string{"250-fake"}
+			if s.tls != nil && !secure {
+				ext = append(ext, "250-STARTTLS")
+			}
+			if secure {
+				ext = append(ext, "250-AUTH PLAIN")
+			}
+			tp.PrintfLine("%s\r\n250 OK", strings.Join(ext, "\r\n"))
+		case "STARTTLS":
+			tp.PrintfLine("220 go ahead")
+			tc := tls.Server(conn, s.tls)
+			if err := tc.Handshake(); err != nil {
+				return
+			}
+			conn, tp, secure = tc, textproto.NewConn(tc), true
+			s.mu.Lock()
+			s.startTLS = true
+			s.mu.Unlock()
+		case "AUTH":
+			_, resp, _ := strings.Cut(arg, " ")
+			raw, _ := base64.StdEncoding.DecodeString(resp)
+			s.mu.Lock()
+			s.auth = string(raw)
+			s.mu.Unlock()
+			tp.PrintfLine("235 authenticated")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = arg
+			s.mu.Unlock()
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			s.mu.Lock()
+			s.rcpt = arg
+			s.mu.Unlock()
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 end with .")
+			b, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(b)
+			s.mu.Unlock()
+			tp.PrintfLine("250 queued")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func selfSignedTLS(t *testing.T) (server *tls.Config, roots *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots = x509.NewCertPool()
+	roots.AddCert(cert)
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}, roots
+}
+
+func TestSMTPSenderStartTLSAuthMultipart(t *testing.T) {
+	serverTLS, roots := selfSignedTLS(t)
+	srv := newFakeSMTP(t, serverTLS)
+
+	s, err := NewSMTPSender(SMTPConfig{
+		Host:      "127.0.0.1",
+		Port:      srv.port(),
+		Username:  "mailer",
+		Password:  "s3cret",
+		From:      "FastaBiz <no-reply@fastabiz.test>",
+		Security:  SMTPStartTLS,
+		Timeout:   5 * time.Second,
+		TLSConfig: &tls.Config{RootCAs: roots},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := "Your order #42 has shipped.\n\nTrack it <here> & relax."
+	if err := s.SendEmail(context.Background(), "jane@example.com", "Order shipped – #42", body, ""); err != nil {
+		t.Fatalf("SendEmail: %v", err)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if !srv.startTLS {
+		t.Error("connection was not upgraded with STARTTLS")
+	}
+	if srv.auth != "\x00mailer\x00s3cret" {
+		t.Errorf("auth = %q", srv.auth)
+	}
+	if srv.from != "FROM:<no-reply@fastabiz.test>" || srv.rcpt != "TO:<jane@example.com>" {
+		t.Errorf("envelope = %q -> %q", srv.from, srv.rcpt)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(srv.data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "Order shipped – #42" {
+		t.Errorf("subject = %q", subject)
+	}
+	if from := msg.Header.Get("From"); !strings.Contains(from, "no-reply@fastabiz.test") {
+		t.Errorf("From = %q", from)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("content type = %q (%v)", mediaType, err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	parts := map[string]string{}
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		ct, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		b, _ := io.ReadAll(bufio.NewReader(p))
+		parts[ct] = string(b)
+	}
+	if parts["text/plain"] != body {
+		t.Errorf("text part = %q", parts["text/plain"])
+	}
+	if !strings.Contains(parts["text/html"], "<p>Track it &lt;here&gt; &amp; relax.</p>") {
+		t.Errorf("html part = %q", parts["text/html"])
+	}
+}
+
+func TestSMTPSenderRequiresStartTLS(t *testing.T) {
+	srv := newFakeSMTP(t, nil) // server does not offer STARTTLS
+
+	s, err := NewSMTPSender(SMTPConfig{
+		Host:    "127.0.0.1",
+		Port:    srv.port(),
+		From:    "no-reply@fastabiz.test",
+		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SendEmail(context.Background(), "jane@example.com", "hi", "hello", ""); err != ErrSMTPNoStartTLS {
+		t.Fatalf("err = %v, want ErrSMTPNoStartTLS", err)
+	}
+}
+
+func TestSMTPSenderRefusesPlaintextAuth(t *testing.T) {
+	srv := newFakeSMTP(t, nil)
+
+	s, err := NewSMTPSender(SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     srv.port(),
+		Username: "mailer",
+		Password: "s3cret",
+		From:     "no-reply@fastabiz.test",
+		Security: SMTPNone,
+		Timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SendEmail(context.Background(), "jane@example.com", "hi", "hello", ""); err != ErrSMTPAuthInsecure {
+		t.Fatalf("err = %v, want ErrSMTPAuthInsecure", err)
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.auth != "" {
+		t.Error("credentials were sent in the clear")
+	}
+}
+
+func TestNewSMTPSenderDefaults(t *testing.T) {
+	s, err := NewSMTPSender(SMTPConfig{Host: "smtp.example.com", From: "a@example.com", Security: SMTPTLS})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.cfg.Port != 465 {
+		t.Errorf("implicit TLS port = %d", s.cfg.Port)
+	}
+	if _, err := NewSMTPSender(SMTPConfig{Host: "h", From: "a@example.com", Security: "ssl"}); err != ErrSMTPSecurity {
+		t.Errorf("err = %v, want ErrSMTPSecurity", err)
+	}
+}
@@ -0,0 +1,144 @@
+package sender
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"logistics-backend/internal/domain/notification"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const TwilioURL = "https://api.twilio.com"
+
+// Twilio sends SMS through the Twilio Programmable Messaging API.
+type Twilio struct {
+	BaseURL    string // TwilioURL or a local mock
+	AccountSID string
+	AuthToken  string
+	From       string // sender number, or a messaging service SID (MG...)
+
+	// StatusCallbackURL is where Twilio posts delivery reports. Signatures are computed over
+	// this exact URL, so it must be the public address Twilio calls, not the backend's own.
+	StatusCallbackURL string
+
+	Client *http.Client
+}
+
+type twilioMessage struct {
+	SID     string `json:"sid"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Twilio error codes that will not succeed on retry.
+var twilioPermanent = map[int]bool{
+	21211: true, // invalid 'To' number
+	21408: true, // region not enabled
+	21610: true, // recipient replied STOP
+	21612: true, // cannot route to this number
+	21614: true, // not a mobile number
+}
+
+func (t *Twilio) Send(ctx context.Context, to, message string) (string, error) {
+	form := url.Values{
+		"To":   {to},
+		"Body": {message},
+	}
+	if strings.HasPrefix(t.From, "MG") {
+		form.Set("MessagingServiceSid", t.From)
+	} else {
+		form.Set("From", t.From)
+	}
+	if t.StatusCallbackURL != "" {
+		form.Set("StatusCallback", t.StatusCallbackURL)
+	}
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", strings.TrimRight(t.BaseURL, "/"), url.PathEscape(t.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(t.AccountSID, t.AuthToken)
+
+	res, err := t.client().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("twilio: %w", err)
+	}
+	defer res.Body.Close()
+
+	var body twilioMessage
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("twilio: decode response (%s): %w", res.Status, err)
+	}
+
+	if res.StatusCode >= 300 {
+		if twilioPermanent[body.Code] {
+			return "", fmt.Errorf("%w: twilio: %s (%d)", notification.ErrUndeliverable, body.Message, body.Code)
+		}
+		return "", fmt.Errorf("twilio: %s (%d, %s)", body.Message, body.Code, res.Status)
+	}
+
+	return body.SID, nil
+}
+
+// ParseDeliveryReport verifies X-Twilio-Signature and reads the status callback.
+func (t *Twilio) ParseDeliveryReport(r *http.Request) (*notification.DeliveryReport, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(r.Header.Get("X-Twilio-Signature")), []byte(t.signature(r.PostForm))) {
+		return nil, ErrInvalidCallback
+	}
+
+	report := &notification.DeliveryReport{ProviderRef: r.PostForm.Get("MessageSid")}
+	switch r.PostForm.Get("MessageStatus") {
+	case "delivered":
+		report.Status = notification.Delivered
+	case "undelivered", "failed":
+		report.Status = notification.Failed
+		if code := r.PostForm.Get("ErrorCode"); code != "" {
+			report.Reason = "twilio error " + code
+		}
+	default: // accepted, queued, sending, sent
+		return nil, nil
+	}
+	return report, nil
+}
+
+// signature is base64(HMAC-SHA1(auth token, callback URL + sorted POST keys and values)).
+func (t *Twilio) signature(form url.Values) string {
+	keys := make([]string, 0, len(form))
+	for k := range form {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(t.StatusCallbackURL)
+	for _, k := range keys {
+		for _, v := range form[k] {
+			b.WriteString(k)
+			b.WriteString(v)
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(t.AuthToken))
+	mac.Write([]byte(b.String()))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (t *Twilio) client() *http.Client {
+	if t.Client != nil {
+		return t.Client
+	}
+	return &http.Client{Timeout: 20 * time.Second}
+}
@@ -0,0 +1,112 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"logistics-backend/internal/domain/webhook"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// maxWebhookResponse caps how much of an endpoint's answer is kept in the delivery log.
+const maxWebhookResponse = 4 << 10
+
+// reservedRanges are the special-purpose ranges netip has no predicate for.
+var reservedRanges = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "this network", reaches the host itself on Linux
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT, used for internal addresses by some clouds
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // reserved, including broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, reaches any IPv4 address
+}
+
+// internalAddress reports whether ip is anything but a public unicast address.
+func internalAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, p := range reservedRanges {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookPoster posts deliveries to store endpoints. Redirects are not followed and, unless
+// AllowInsecure is set, connections to private, loopback, link-local and other non-public
+// addresses are refused, so a subscription cannot be pointed at the backend's own network.
+// Answers from such addresses are never kept, so even with AllowInsecure the delivery log
+// cannot be used to read internal services.
+type WebhookPoster struct {
+	client *http.Client
+}
+
+func NewWebhookPoster(timeout time.Duration, allowInsecure bool) *WebhookPoster {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowInsecure {
+		// checked on the resolved address at connect time, so DNS cannot be used to slip past it
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil || internalAddress(ap.Addr()) {
+				return webhook.ErrBlockedAddress
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+
+	return &WebhookPoster{client: &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+func (p *WebhookPoster) Post(ctx context.Context, url string, header http.Header, body []byte) (*webhook.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+
+	var remote netip.Addr
+	req = req.WithContext(httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if addr, ok := info.Conn.RemoteAddr().(*net.TCPAddr); ok {
+				remote = addr.AddrPort().Addr()
+			}
+		},
+	}))
+
+	start := time.Now()
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+	defer res.Body.Close()
+
+	resp := &webhook.Response{StatusCode: res.StatusCode}
+	if !internalAddress(remote) {
+		// a slow body still counts against the timeout; what was read so far is kept
+		answer, _ := io.ReadAll(io.LimitReader(res.Body, maxWebhookResponse))
+		resp.Body = string(bytes.ToValidUTF8(bytes.ReplaceAll(answer, []byte{0}, nil), nil)) // text columns reject NUL
+	}
+	resp.Duration = time.Since(start)
+
+	return resp, nil
+}
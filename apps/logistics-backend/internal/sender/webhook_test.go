@@ -0,0 +1,69 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"logistics-backend/internal/domain/webhook"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestInternalAddress(t *testing.T) {
+	cases := map[string]bool{
+		"127.0.0.1":        true,
+		"0.0.0.0":          true,
+		"0.1.2.3":          true,
+		"10.0.0.1":         true,
+		"100.64.0.1":       true,
+		"100.127.255.254":  true,
+		"169.254.169.254":  true,
+		"192.168.1.1":      true,
+		"255.255.255.255":  true,
+		"::1":              true,
+		"::ffff:127.0.0.1": true,
+		"fd00::1":          true,
+		"64:ff9b::a00:1":   true,
+		"100.128.0.1":      false,
+		"93.184.216.34":    false,
+		"2606:4700::1111":  false,
+	}
+	for addr, want := range cases {
+		if got := internalAddress(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("internalAddress(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestWebhookPosterRefusesInternalAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+
+	p := NewWebhookPoster(time.Second, false)
+	_, err := p.Post(context.Background(), srv.URL, http.Header{}, []byte("{}"))
+	if !errors.Is(err, webhook.ErrBlockedAddress) {
+		t.Fatalf("err = %v, want ErrBlockedAddress", err)
+	}
+}
+
+func TestWebhookPosterDropsInternalAnswers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+
+	// AllowInsecure reaches the loopback server but must not report what it said
+	p := NewWebhookPoster(time.Second, true)
+	resp, err := p.Post(context.Background(), srv.URL, http.Header{}, []byte("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusTeapot || resp.Body != "" {
+		t.Errorf("got %d %q, want %d with no body", resp.StatusCode, resp.Body, http.StatusTeapot)
+	}
+}
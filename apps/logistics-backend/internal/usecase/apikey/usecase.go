@@ -0,0 +1,120 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	domain "logistics-backend/internal/domain/apikey"
+	"logistics-backend/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UseCase struct {
+	repo   domain.Repository
+	stores domain.StoreReader
+}
+
+func NewUseCase(repo domain.Repository, stores domain.StoreReader) *UseCase {
+	return &UseCase{repo: repo, stores: stores}
+}
+
+// CreateAPIKey issues a key for one of the owner's stores and returns the full key,
+// which is not stored and cannot be shown again.
+func (uc *UseCase) CreateAPIKey(ctx context.Context, k *domain.APIKey) (string, error) {
+	if len(k.Scopes) == 0 {
+		return "", domain.ErrNoScopes
+	}
+	for _, s := range k.Scopes {
+		if !domain.IsAllowedScope(s) {
+			return "", fmt.Errorf("%w: %s", domain.ErrInvalidScope, s)
+		}
+	}
+
+	s, err := uc.stores.GetByID(ctx, k.StoreID)
+	if err != nil || s.OwnerID != k.OwnerID {
+		return "", domain.ErrStoreNotOwned
+	}
+
+	prefix, err := newPrefix()
+	if err != nil {
+		return "", err
+	}
+	secret, err := utils.GenerateToken(32)
+	if err != nil {
+		return "", fmt.Errorf("could not generate api key: %w", err)
+	}
+
+	raw := domain.KeyPrefix + prefix + "_" + secret
+	k.Prefix = prefix
+	k.KeyHash = utils.HashToken(raw)
+
+	if err := uc.repo.Create(ctx, k); err != nil {
+		return "", err
+	}
+
+	return raw, nil
+}
+
+func (uc *UseCase) ListAPIKeys(ctx context.Context, ownerID uuid.UUID) ([]*domain.APIKey, error) {
+	return uc.repo.ListByOwner(ctx, ownerID)
+}
+
+func (uc *UseCase) RevokeAPIKey(ctx context.Context, id, ownerID uuid.UUID) error {
+	return uc.repo.Revoke(ctx, id, ownerID)
+}
+
+// AuthenticateAPIKey resolves a raw key sent by a client to the active key it belongs to.
+func (uc *UseCase) AuthenticateAPIKey(ctx context.Context, raw string) (*domain.APIKey, error) {
+	prefix, ok := parsePrefix(raw)
+	if !ok {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	k, err := uc.repo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("could not fetch api key: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(k.KeyHash), []byte(utils.HashToken(raw))) != 1 || !k.IsActive(time.Now()) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	if err := uc.repo.TouchLastUsed(ctx, k.ID); err != nil {
+		log.Printf("could not record api key use: %v", err)
+	}
+
+	return k, nil
+}
+
+// newPrefix returns the public, hex-encoded part of a key; hex keeps it free of the "_" separator.
+func newPrefix() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("could not generate api key prefix: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// parsePrefix extracts <prefix> from lk_<prefix>_<secret>.
+func parsePrefix(raw string) (string, bool) {
+	rest, ok := strings.CutPrefix(raw, domain.KeyPrefix)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || prefix == "" || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
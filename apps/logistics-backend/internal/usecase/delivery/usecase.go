@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"logistics-backend/internal/domain/delivery"
-	"logistics-backend/internal/domain/notification"
+	"logistics-backend/internal/domain/event"
+	"logistics-backend/internal/domain/money"
 	"logistics-backend/internal/usecase/common"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -15,17 +17,41 @@ type UseCase struct {
 	ordRepo   delivery.OrderReader
 	drvRepo   delivery.DriverReader
 	txManager common.TxManager
-	notfRepo  delivery.NotificationReader
+	events    delivery.EventPublisher
+	payout    money.Money // flat driver payout per completed delivery
 }
 
-func NewUseCase(repo delivery.Repository, ordRepo delivery.OrderReader, drvRepo delivery.DriverReader, txm common.TxManager, notf delivery.NotificationReader) *UseCase {
-	return &UseCase{repo: repo, ordRepo: ordRepo, drvRepo: drvRepo, txManager: txm, notfRepo: notf}
+func NewUseCase(repo delivery.Repository, ordRepo delivery.OrderReader, drvRepo delivery.DriverReader, txm common.TxManager, events delivery.EventPublisher, payout money.Money) *UseCase {
+	return &UseCase{repo: repo, ordRepo: ordRepo, drvRepo: drvRepo, txManager: txm, events: events, payout: payout}
 }
 
 func (uc *UseCase) GetDeliveryByID(ctx context.Context, deliveryId uuid.UUID) (*delivery.Delivery, error) {
 	return uc.repo.GetByID(ctx, deliveryId)
 }
 
+// GetDeliveryForAdmin returns the delivery only when its order belongs to adminID.
+func (uc *UseCase) GetDeliveryForAdmin(ctx context.Context, deliveryID, adminID uuid.UUID) (*delivery.Delivery, error) {
+	d, err := uc.repo.GetByID(ctx, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch delivery: %w", err)
+	}
+
+	o, err := uc.ordRepo.GetOrderByID(ctx, d.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch order: %w", err)
+	}
+	if o.AdminID != adminID {
+		return nil, delivery.ErrorNotFound
+	}
+
+	return d, nil
+}
+
+// DriverHoldsOrder reports whether the driver has been given a delivery for the order.
+func (uc *UseCase) DriverHoldsOrder(ctx context.Context, driverID, orderID uuid.UUID) (bool, error) {
+	return uc.repo.HeldByDriver(ctx, orderID, driverID)
+}
+
 func (uc *UseCase) UpdateDelivery(ctx context.Context, deliveryID uuid.UUID, column string, value any) error {
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
 		d, err := uc.repo.GetByID(txCtx, deliveryID)
@@ -37,12 +63,21 @@ func (uc *UseCase) UpdateDelivery(ctx context.Context, deliveryID uuid.UUID, col
 			return fmt.Errorf("update delivery failed: %w", err)
 		}
 
-		go func() {
-			msg := fmt.Sprintf("ℹ️ Delivery for order %s updated: '%s' changed.", d.OrderID, column)
-			_ = uc.notify(ctx, d.DriverID, msg)
-		}()
+		events := []event.Event{event.DeliveryUpdated{
+			DeliveryID: d.ID,
+			OrderID:    d.OrderID,
+			DriverID:   d.DriverID,
+			Field:      column,
+		}}
+		if column == "status" && fmt.Sprint(value) == string(delivery.Delivered) {
+			events = append(events, event.DeliveryCompleted{
+				DeliveryID: d.ID,
+				OrderID:    d.OrderID,
+				DriverID:   d.DriverID,
+			})
+		}
 
-		return nil
+		return uc.events.Publish(txCtx, events...)
 	})
 }
 
@@ -72,21 +107,23 @@ func (uc *UseCase) AcceptDelivery(ctx context.Context, d *delivery.Delivery) err
 		//     return err
 		// }
 
-		go func() {
-			msgCustomer := fmt.Sprintf("🚚 Your order %s is now in transit with driver %s.", order.ID, driver.FullName)
-			_ = uc.notify(ctx, order.CustomerID, msgCustomer)
-
-			msgDriver := fmt.Sprintf("✅ You have accepted delivery for order %s.", order.ID)
-			_ = uc.notify(ctx, driver.ID, msgDriver)
-		}()
+		if err := uc.repo.Create(txCtx, d); err != nil {
+			return err
+		}
 
-		return uc.repo.Create(txCtx, d)
+		return uc.events.Publish(txCtx, event.DeliveryAccepted{
+			DeliveryID: d.ID,
+			OrderID:    order.ID,
+			CustomerID: order.CustomerID,
+			DriverID:   driver.ID,
+			DriverName: driver.FullName,
+		})
 	})
 
 }
 
-func (uc *UseCase) ListDeliveries(ctx context.Context) ([]*delivery.Delivery, error) {
-	return uc.repo.List(ctx)
+func (uc *UseCase) ListDeliveries(ctx context.Context, adminID uuid.UUID) ([]*delivery.Delivery, error) {
+	return uc.repo.ListByAdmin(ctx, adminID)
 }
 
 func (uc *UseCase) ListActiveDeliveries(ctx context.Context) ([]*delivery.Delivery, error) {
@@ -98,6 +135,51 @@ func (uc *UseCase) ListActiveDeliveries(ctx context.Context) ([]*delivery.Delive
 	return uc.repo.ListByStatus(ctx, ativeStatuses)
 }
 
+// Driver self-service: all lookups are scoped to the authenticated driver.
+
+func (uc *UseCase) ListDriverActiveDeliveries(ctx context.Context, driverID uuid.UUID) ([]*delivery.Delivery, error) {
+	return uc.repo.ListByDriver(ctx, driverID, []delivery.DeliveryStatus{delivery.PickedUp}, 0, 0)
+}
+
+func (uc *UseCase) ListDriverDeliveryHistory(ctx context.Context, driverID uuid.UUID, limit, offset int) ([]*delivery.Delivery, error) {
+	finished := []delivery.DeliveryStatus{delivery.Delivered, delivery.Failed}
+	return uc.repo.ListByDriver(ctx, driverID, finished, limit, offset)
+}
+
+// ListDriverOffers returns deliveries assigned to the driver that are not yet picked up.
+func (uc *UseCase) ListDriverOffers(ctx context.Context, driverID uuid.UUID) ([]*delivery.Delivery, error) {
+	return uc.repo.ListByDriver(ctx, driverID, []delivery.DeliveryStatus{delivery.Assigned}, 0, 0)
+}
+
+func (uc *UseCase) GetDriverEarnings(ctx context.Context, driverID uuid.UUID) (*delivery.EarningsSummary, error) {
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	startOfWeek := startOfDay.AddDate(0, 0, -((int(startOfDay.Weekday()) + 6) % 7)) // weeks start on Monday
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	periods := []time.Time{startOfDay, startOfWeek, startOfMonth, {}}
+	earnings := make([]delivery.PeriodEarnings, len(periods))
+	for i, since := range periods {
+		count, err := uc.repo.CountDelivered(ctx, driverID, since)
+		if err != nil {
+			return nil, fmt.Errorf("count delivered failed: %w", err)
+		}
+		earnings[i] = delivery.PeriodEarnings{
+			Deliveries: count,
+			Amount:     uc.payout.Multiply(int64(count)),
+		}
+	}
+
+	return &delivery.EarningsSummary{
+		DriverID:  driverID,
+		Rate:      uc.payout,
+		Today:     earnings[0],
+		ThisWeek:  earnings[1],
+		ThisMonth: earnings[2],
+		AllTime:   earnings[3],
+	}, nil
+}
+
 func (uc *UseCase) DeleteDelivery(ctx context.Context, id uuid.UUID) error {
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
 		if err := uc.repo.Delete(txCtx, id); err != nil {
@@ -107,13 +189,3 @@ func (uc *UseCase) DeleteDelivery(ctx context.Context, id uuid.UUID) error {
 		return nil
 	})
 }
-
-func (uc *UseCase) notify(ctx context.Context, userID uuid.UUID, message string) error {
-	n := &notification.Notification{
-		UserID:  userID,
-		Message: message,
-		Type:    notification.System,
-		Status:  notification.Pending,
-	}
-	return uc.notfRepo.Create(ctx, n)
-}
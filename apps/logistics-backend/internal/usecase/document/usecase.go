@@ -0,0 +1,247 @@
+package document
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	domain "logistics-backend/internal/domain/document"
+	"logistics-backend/internal/domain/notification"
+	"logistics-backend/internal/domain/user"
+	"logistics-backend/internal/usecase/common"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UseCase struct {
+	repo      domain.Repository
+	blobs     domain.BlobStorage
+	txManager common.TxManager
+	notfRepo  domain.NotificationReader
+	drivers   domain.DriverTenants
+}
+
+func NewUseCase(repo domain.Repository, blobs domain.BlobStorage, txm common.TxManager, notf domain.NotificationReader, drivers domain.DriverTenants) *UseCase {
+	return &UseCase{repo: repo, blobs: blobs, txManager: txm, notfRepo: notf, drivers: drivers}
+}
+
+// AuthorizeRead lets a driver see their own documents and a store owner see the documents of
+// drivers working within their tenant. Anyone else gets ErrNotFound.
+func (uc *UseCase) AuthorizeRead(ctx context.Context, driverID, callerID uuid.UUID, role user.Role) error {
+	return uc.authorize(ctx, driverID, callerID, role, uc.drivers.BelongsToOwner)
+}
+
+// AuthorizeWrite lets a driver manage their own documents and a store owner manage those of
+// drivers whose account they provisioned; sharing a driver with other tenants is not enough.
+func (uc *UseCase) AuthorizeWrite(ctx context.Context, driverID, callerID uuid.UUID, role user.Role) error {
+	return uc.authorize(ctx, driverID, callerID, role, uc.drivers.OwnedBy)
+}
+
+func (uc *UseCase) authorize(ctx context.Context, driverID, callerID uuid.UUID, role user.Role, member func(ctx context.Context, driverID, ownerID uuid.UUID) (bool, error)) error {
+	switch role {
+	case user.Driver:
+		if driverID == callerID {
+			return nil
+		}
+	case user.Admin:
+		ok, err := member(ctx, driverID, callerID)
+		if err != nil {
+			return fmt.Errorf("could not check driver tenant: %w", err)
+		}
+		if ok {
+			return nil
+		}
+	}
+
+	return domain.ErrNotFound
+}
+
+// UploadDocument stores the file in blob storage and records the document as pending verification.
+func (uc *UseCase) UploadDocument(ctx context.Context, d *domain.Document, filename string, file io.Reader) error {
+	if !d.Type.IsValid() {
+		return domain.ErrInvalidType
+	}
+
+	if !d.ExpiresAt.After(d.IssuedAt) {
+		return domain.ErrInvalidDates
+	}
+
+	if file == nil {
+		return domain.ErrMissingFile
+	}
+
+	path, err := uc.blobs.Save(ctx, filename, file)
+	if err != nil {
+		return fmt.Errorf("could not store document file: %w", err)
+	}
+	d.FilePath = path
+	d.Status = domain.Pending
+
+	err = uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := uc.repo.Create(txCtx, d); err != nil {
+			return fmt.Errorf("could not create driver document: %w", err)
+		}
+
+		return uc.notify(txCtx, d.DriverID, notification.EventDocumentUploaded, notification.TemplateData{"document": string(d.Type)})
+	})
+	if err != nil {
+		_ = uc.blobs.Delete(ctx, path)
+		return err
+	}
+
+	return nil
+}
+
+// VerifyDocument records the admin's verification decision.
+func (uc *UseCase) VerifyDocument(ctx context.Context, id uuid.UUID, status domain.VerificationStatus, adminID uuid.UUID) error {
+	if status != domain.Verified && status != domain.Rejected {
+		return domain.ErrInvalidStatus
+	}
+
+	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		d, err := uc.repo.GetByID(txCtx, id)
+		if err != nil {
+			return fmt.Errorf("could not fetch driver document: %w", err)
+		}
+
+		if d.Status == domain.Verified && status == domain.Verified {
+			return domain.ErrAlreadyVerified
+		}
+
+		if err := uc.repo.UpdateStatus(txCtx, id, status, adminID); err != nil {
+			return fmt.Errorf("verify driver document failed: %w", err)
+		}
+
+		return uc.notify(txCtx, d.DriverID, notification.EventDocumentReviewed, notification.TemplateData{
+			"document": string(d.Type),
+			"status":   string(status),
+		})
+	})
+}
+
+func (uc *UseCase) GetDocument(ctx context.Context, id uuid.UUID) (*domain.Document, error) {
+	d, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("could not fetch driver document: %w", err)
+	}
+
+	return d, nil
+}
+
+func (uc *UseCase) OpenDocumentFile(ctx context.Context, d *domain.Document) (io.ReadCloser, error) {
+	return uc.blobs.Open(ctx, d.FilePath)
+}
+
+func (uc *UseCase) ListDriverDocuments(ctx context.Context, driverID uuid.UUID) ([]*domain.Document, error) {
+	return uc.repo.ListByDriver(ctx, driverID)
+}
+
+// ListExpiringDocuments returns the owner's drivers' documents that expired or expire within the window.
+func (uc *UseCase) ListExpiringDocuments(ctx context.Context, ownerID uuid.UUID, within time.Duration) ([]*domain.Document, error) {
+	return uc.repo.ListExpiringForOwner(ctx, ownerID, time.Now().Add(within))
+}
+
+func (uc *UseCase) DeleteDocument(ctx context.Context, id uuid.UUID) error {
+	var path string
+	err := uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		d, err := uc.repo.GetByID(txCtx, id)
+		if err != nil {
+			return fmt.Errorf("could not fetch driver document: %w", err)
+		}
+
+		if err := uc.repo.Delete(txCtx, id); err != nil {
+			return fmt.Errorf("delete driver document failed: %w", err)
+		}
+
+		path = d.FilePath
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// only once the row is gone for good; a rolled back delete must still find its file
+	if err := uc.blobs.Delete(ctx, path); err != nil {
+		log.Printf("could not remove document file %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// SendExpiryAlerts notifies drivers whose documents expire within the given window.
+// Each document is alerted at most once per alertEvery.
+func (uc *UseCase) SendExpiryAlerts(ctx context.Context, within, alertEvery time.Duration) (int, error) {
+	now := time.Now()
+	docs, err := uc.repo.ListExpiring(ctx, now.Add(within), now.Add(-alertEvery))
+	if err != nil {
+		return 0, fmt.Errorf("fetch expiring documents failed: %w", err)
+	}
+
+	sent := 0
+	for _, d := range docs {
+		event := notification.EventDocumentExpired
+		data := notification.TemplateData{
+			"document": string(d.Type),
+			"number":   d.Number,
+			"date":     d.ExpiresAt.Format("2006-01-02"),
+		}
+		if !d.IsExpired(now) {
+			event = notification.EventDocumentExpiring
+			data["days"] = strconv.Itoa(int(d.ExpiresAt.Sub(now).Hours() / 24))
+		}
+
+		// the alert and its timestamp commit together, so a document is neither skipped nor alerted twice
+		err := uc.txManager.Do(ctx, func(txCtx context.Context) error {
+			if err := uc.notify(txCtx, d.DriverID, event, data); err != nil {
+				return err
+			}
+
+			return uc.repo.MarkAlerted(txCtx, d.ID)
+		})
+		if err != nil {
+			log.Printf("document expiry alert for %s failed: %v", d.ID, err)
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// RunExpiryAlerts runs SendExpiryAlerts on every tick until ctx is cancelled.
+func (uc *UseCase) RunExpiryAlerts(ctx context.Context, interval, within, alertEvery time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := uc.SendExpiryAlerts(ctx, within, alertEvery); err != nil {
+			log.Printf("document expiry alerts: %v", err)
+		} else if n > 0 {
+			log.Printf("document expiry alerts: %d sent", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (uc *UseCase) notify(ctx context.Context, userID uuid.UUID, event notification.Event, data notification.TemplateData) error {
+	n := &notification.Notification{
+		UserID: userID,
+		Event:  event,
+		Data:   data,
+		Type:   notification.System,
+		Status: notification.Pending,
+	}
+	return uc.notfRepo.Create(ctx, n)
+}
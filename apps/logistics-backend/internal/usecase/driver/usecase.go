@@ -26,12 +26,12 @@ func (uc *UseCase) RegisterDriver(ctx context.Context, d *domain.Driver) error {
 		return domain.ErrMissingUserID
 	}
 
-	go func() {
-		msg := fmt.Sprintf("✅ Welcome %s! Your driver account has been created.", d.FullName)
-		_ = uc.notify(ctx, d.ID, msg)
-	}()
+	if err := uc.repo.Create(ctx, d); err != nil {
+		return err
+	}
 
-	return uc.repo.Create(ctx, d)
+	// ctx carries the caller's transaction (user registration), so the welcome message commits with it
+	return uc.notify(ctx, d.ID, notification.EventDriverRegistered, notification.TemplateData{"name": d.FullName})
 }
 
 func (uc *UseCase) UpdateDriverProfile(ctx context.Context, id uuid.UUID, req *domain.UpdateDriverProfileRequest) error {
@@ -46,12 +46,7 @@ func (uc *UseCase) UpdateDriverProfile(ctx context.Context, id uuid.UUID, req *d
 			return fmt.Errorf("update driver profile failed: %w", err)
 		}
 
-		go func() {
-			msg := "ℹ️ Your driver profile has been updated."
-			_ = uc.notify(ctx, driver.ID, msg)
-		}()
-
-		return nil
+		return uc.notify(txCtx, driver.ID, notification.EventDriverProfileUpdated, nil)
 	})
 }
 
@@ -66,12 +61,7 @@ func (uc *UseCase) UpdateDriver(ctx context.Context, id uuid.UUID, req *domain.U
 			return fmt.Errorf("update driver failed: %w", err)
 		}
 
-		go func() {
-			msg := fmt.Sprintf("ℹ️ Your driver account column '%s' has been updated.", req.Column)
-			_ = uc.notify(ctx, driver.ID, msg)
-		}()
-
-		return nil
+		return uc.notify(txCtx, driver.ID, notification.EventDriverAccountUpdated, notification.TemplateData{"field": req.Column})
 	})
 }
 
@@ -86,16 +76,11 @@ func (uc *UseCase) UpdateDriverAvailability(ctx context.Context, driverID uuid.U
 			return fmt.Errorf("update driver availability failed: %w", err)
 		}
 
-		go func() {
-			status := "unavailable"
-			if available {
-				status = "available"
-			}
-			msg := fmt.Sprintf("ℹ️ Your availability status has been set to '%s'.", status)
-			_ = uc.notify(ctx, driver.ID, msg)
-		}()
-
-		return nil
+		status := "unavailable"
+		if available {
+			status = "available"
+		}
+		return uc.notify(txCtx, driver.ID, notification.EventDriverAvailabilityChanged, notification.TemplateData{"status": status})
 	})
 }
 
@@ -112,8 +97,35 @@ func (uc *UseCase) GetDriverByEmail(ctx context.Context, email string) (*domain.
 	return uc.repo.GetByEmail(ctx, email)
 }
 
-func (uc *UseCase) ListDrivers(ctx context.Context) ([]*domain.Driver, error) {
-	return uc.repo.List(ctx)
+// GetDriverForOwner returns the driver only when they work within ownerID's tenant.
+func (uc *UseCase) GetDriverForOwner(ctx context.Context, id, ownerID uuid.UUID) (*domain.Driver, error) {
+	ok, err := uc.repo.BelongsToOwner(ctx, id, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("could not check driver tenant: %w", err)
+	}
+	if !ok {
+		return nil, domain.ErrDriverNotFound
+	}
+
+	return uc.repo.GetByID(ctx, id)
+}
+
+// GetDriverOwnedBy returns the driver only when ownerID provisioned their account; changes to a
+// driver go through it.
+func (uc *UseCase) GetDriverOwnedBy(ctx context.Context, id, ownerID uuid.UUID) (*domain.Driver, error) {
+	ok, err := uc.repo.OwnedBy(ctx, id, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("could not check driver owner: %w", err)
+	}
+	if !ok {
+		return nil, domain.ErrDriverNotFound
+	}
+
+	return uc.repo.GetByID(ctx, id)
+}
+
+func (uc *UseCase) ListDrivers(ctx context.Context, ownerID uuid.UUID) ([]*domain.Driver, error) {
+	return uc.repo.ListByOwner(ctx, ownerID)
 }
 
 func (uc *UseCase) ListAvailableDrivers(ctx context.Context, available bool) ([]*domain.Driver, error) {
@@ -131,12 +143,7 @@ func (uc *UseCase) DeleteDriver(ctx context.Context, id uuid.UUID) error {
 			return fmt.Errorf("delete driver failed: %w", err)
 		}
 
-		go func() {
-			msg := "🗑️ Your driver account has been deleted."
-			_ = uc.notify(ctx, driver.ID, msg)
-		}()
-
-		return nil
+		return uc.notify(txCtx, driver.ID, notification.EventDriverDeleted, nil)
 	})
 }
 
@@ -144,12 +151,13 @@ func (uc *UseCase) GetClosestDriver(ctx context.Context, pickup postgis.PointS,
 	return uc.repo.GetNearestDriver(ctx, pickup, maxDistance)
 }
 
-func (uc *UseCase) notify(ctx context.Context, userID uuid.UUID, message string) error {
+func (uc *UseCase) notify(ctx context.Context, userID uuid.UUID, event notification.Event, data notification.TemplateData) error {
 	n := &notification.Notification{
-		UserID:  userID,
-		Message: message,
-		Type:    notification.System,
-		Status:  notification.Pending,
+		UserID: userID,
+		Event:  event,
+		Data:   data,
+		Type:   notification.System,
+		Status: notification.Pending,
 	}
 	return uc.notfRepo.Create(ctx, n)
 }
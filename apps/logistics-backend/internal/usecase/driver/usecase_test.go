@@ -0,0 +1,72 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	domain "logistics-backend/internal/domain/driver"
+
+	"github.com/google/uuid"
+)
+
+// tenantRepo models a driver provisioned by one owner who has also carried another owner's orders.
+type tenantRepo struct {
+	domain.Repository
+	members map[uuid.UUID][]uuid.UUID
+	owners  map[uuid.UUID]uuid.UUID
+}
+
+func (r tenantRepo) BelongsToOwner(_ context.Context, driverID, ownerID uuid.UUID) (bool, error) {
+	for _, id := range r.members[ownerID] {
+		if id == driverID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r tenantRepo) OwnedBy(_ context.Context, driverID, ownerID uuid.UUID) (bool, error) {
+	owner, ok := r.owners[driverID]
+	return ok && owner == ownerID, nil
+}
+
+func (r tenantRepo) GetByID(_ context.Context, id uuid.UUID) (*domain.Driver, error) {
+	return &domain.Driver{ID: id}, nil
+}
+
+func TestCrossTenantAccess(t *testing.T) {
+	ownerA, ownerB, ownerC := uuid.New(), uuid.New(), uuid.New()
+	drv := uuid.New()
+
+	uc := NewUseCase(tenantRepo{
+		members: map[uuid.UUID][]uuid.UUID{ownerA: {drv}, ownerB: {drv}},
+		owners:  map[uuid.UUID]uuid.UUID{drv: ownerA},
+	}, nil, nil)
+
+	cases := []struct {
+		name        string
+		owner       uuid.UUID
+		read, write bool
+	}{
+		{"provisioning owner", ownerA, true, true},
+		{"owner whose orders they carried", ownerB, true, false},
+		{"unrelated owner", ownerC, false, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := uc.GetDriverForOwner(context.Background(), drv, c.owner)
+			if got := err == nil; got != c.read {
+				t.Errorf("read allowed = %v, want %v (%v)", got, c.read, err)
+			}
+
+			_, err = uc.GetDriverOwnedBy(context.Background(), drv, c.owner)
+			if got := err == nil; got != c.write {
+				t.Errorf("write allowed = %v, want %v (%v)", got, c.write, err)
+			}
+			if err != nil && !errors.Is(err, domain.ErrDriverNotFound) {
+				t.Errorf("write error = %v, want ErrDriverNotFound", err)
+			}
+		})
+	}
+}
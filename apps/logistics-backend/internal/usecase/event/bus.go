@@ -0,0 +1,142 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	domain "logistics-backend/internal/domain/event"
+	"logistics-backend/internal/domain/outbox"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bus hands published events to their subscribers once the publishing transaction commits.
+//
+// Synchronous subscribers run in the publisher's goroutine right after the commit. They suit
+// cheap, best-effort work: an error is logged and cannot undo the change that was committed.
+//
+// Async subscribers get their own outbox message, written in the publishing transaction, so
+// they are retried with backoff by the outbox dispatcher and one failing subscriber does not
+// hold up the others.
+type Bus struct {
+	queue     domain.Queue
+	committer domain.Committer
+	sync      map[domain.Name][]domain.Handler
+	async     map[domain.Name][]string                  // subscriber names per event
+	handlers  map[string]map[domain.Name]domain.Handler // async handlers by subscriber and event
+}
+
+// queued is the outbox payload for one async subscriber.
+type queued struct {
+	Subscriber string          `json:"subscriber"`
+	ID         uuid.UUID       `json:"id"`
+	Name       domain.Name     `json:"name"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func NewBus(queue domain.Queue, committer domain.Committer) *Bus {
+	return &Bus{
+		queue:     queue,
+		committer: committer,
+		sync:      make(map[domain.Name][]domain.Handler),
+		async:     make(map[domain.Name][]string),
+		handlers:  make(map[string]map[domain.Name]domain.Handler),
+	}
+}
+
+// Subscribe adds a synchronous subscriber for an event, or for domain.All. Call it at startup.
+func (b *Bus) Subscribe(name domain.Name, h domain.Handler) {
+	b.sync[name] = append(b.sync[name], h)
+}
+
+// SubscribeAsync adds a durable subscriber for an event. The subscriber name keys its queued
+// deliveries, so it must stay the same across deploys while deliveries may be pending.
+// Call it at startup, before the outbox dispatcher runs.
+func (b *Bus) SubscribeAsync(subscriber string, name domain.Name, h domain.Handler) {
+	if b.handlers[subscriber] == nil {
+		b.handlers[subscriber] = make(map[domain.Name]domain.Handler)
+	}
+	if _, ok := b.handlers[subscriber][name]; !ok {
+		b.async[name] = append(b.async[name], subscriber)
+	}
+	b.handlers[subscriber][name] = h
+}
+
+// Publish queues the events for their async subscribers in the transaction carried by ctx and
+// runs the synchronous ones after it commits. A failure to queue fails the publish, so the
+// caller's transaction rolls back rather than losing the event.
+func (b *Bus) Publish(ctx context.Context, events ...domain.Event) error {
+	envs := make([]*domain.Envelope, 0, len(events))
+	for _, e := range events {
+		env := &domain.Envelope{ID: uuid.New(), Name: e.EventName(), OccurredAt: time.Now(), Event: e}
+
+		if subs := b.async[env.Name]; len(subs) > 0 {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return fmt.Errorf("encode %s event: %w", env.Name, err)
+			}
+			for _, s := range subs {
+				q := queued{Subscriber: s, ID: env.ID, Name: env.Name, OccurredAt: env.OccurredAt, Data: data}
+				if err := b.queue.Enqueue(ctx, outbox.TopicDomainEvent, q); err != nil {
+					return fmt.Errorf("queue %s event for %s: %w", env.Name, s, err)
+				}
+			}
+		}
+
+		envs = append(envs, env)
+	}
+
+	b.committer.AfterCommit(ctx, func(ctx context.Context) {
+		for _, env := range envs {
+			for _, h := range slices.Concat(b.sync[env.Name], b.sync[domain.All]) {
+				if err := runSafely(ctx, h, env); err != nil {
+					log.Printf("event %s %s: subscriber failed: %v", env.Name, env.ID, err)
+				}
+			}
+		}
+	})
+
+	return nil
+}
+
+// HandleQueued handles outbox.TopicDomainEvent messages by passing the event to the
+// subscriber it was queued for.
+func (b *Bus) HandleQueued(ctx context.Context, _ uuid.UUID, payload []byte) error {
+	var q queued
+	if err := json.Unmarshal(payload, &q); err != nil {
+		return fmt.Errorf("decode queued event: %w", err)
+	}
+
+	h, ok := b.handlers[q.Subscriber][q.Name]
+	if !ok {
+		return fmt.Errorf("%w: %s for %s", domain.ErrUnknownSubscriber, q.Name, q.Subscriber)
+	}
+
+	e, err := domain.Decode(q.Name, q.Data)
+	if err != nil {
+		return err
+	}
+
+	return h.Handle(ctx, &domain.Envelope{ID: q.ID, Name: q.Name, OccurredAt: q.OccurredAt, Event: e})
+}
+
+// Log is a synchronous subscriber that writes one line per committed event.
+func Log(_ context.Context, env *domain.Envelope) error {
+	log.Printf("event %s %s", env.Name, env.ID)
+	return nil
+}
+
+// runSafely keeps a panicking subscriber from taking the publisher down with it.
+func runSafely(ctx context.Context, h domain.Handler, env *domain.Envelope) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("subscriber panicked: %v", r)
+		}
+	}()
+
+	return h.Handle(ctx, env)
+}
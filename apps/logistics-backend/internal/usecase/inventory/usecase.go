@@ -3,8 +3,9 @@ package inventory
 import (
 	"context"
 	"fmt"
+	"logistics-backend/internal/domain/event"
 	domain "logistics-backend/internal/domain/inventory"
-	"logistics-backend/internal/domain/notification"
+	storedomain "logistics-backend/internal/domain/store"
 	"logistics-backend/internal/usecase/common"
 
 	"github.com/google/uuid"
@@ -13,39 +14,50 @@ import (
 type UseCase struct {
 	repo      domain.Repository
 	txManager common.TxManager
-	notfRepo  domain.NotificationReader
+	events    domain.EventPublisher
 	storeRepo domain.StoreReader
 }
 
-func NewUseCase(repo domain.Repository, txm common.TxManager, notf domain.NotificationReader, str domain.StoreReader) *UseCase {
-	return &UseCase{repo: repo, txManager: txm, notfRepo: notf, storeRepo: str}
+func NewUseCase(repo domain.Repository, txm common.TxManager, events domain.EventPublisher, str domain.StoreReader) *UseCase {
+	return &UseCase{repo: repo, txManager: txm, events: events, storeRepo: str}
 }
 
-func (uc *UseCase) CreateInventory(ctx context.Context, i *domain.Inventory) error {
+func (uc *UseCase) CreateInventory(ctx context.Context, i *domain.Inventory, ownerID uuid.UUID) error {
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
-		if err := uc.repo.Create(txCtx, i); err != nil {
-			return fmt.Errorf("could not create inventory: %w", err)
-		}
-
-		// Fetch store to get AdminID/ OwnerID
+		// Fetch store to get AdminID/ OwnerID and make sure the caller owns it
 		store, err := uc.storeRepo.GetByID(txCtx, i.StoreID)
 		if err != nil {
 			return fmt.Errorf("could not fetch store: %w", err)
 		}
+		if store.OwnerID != ownerID {
+			return storedomain.ErrStoreNotFound
+		}
 
-		// After successful creation, fire notification (async)
-		go func() {
-			msg := fmt.Sprintf("✅ New inventory '%s' has been added with stock %d.", i.Category, i.Stock)
-			_ = uc.notify(ctx, store.OwnerID, msg)
+		if err := uc.repo.Create(txCtx, i); err != nil {
+			return fmt.Errorf("could not create inventory: %w", err)
+		}
 
-			// Optional: immediately alert if created with low stock
-			if i.Stock <= 5 {
-				lowMsg := fmt.Sprintf("⚠️ Inventory '%s' was created with low stock (%d).", i.Category, i.Stock)
-				_ = uc.notify(ctx, store.OwnerID, lowMsg)
-			}
-		}()
+		// After successful creation, publish in the same transaction
+		events := []event.Event{event.InventoryCreated{
+			InventoryID: i.ID,
+			StoreID:     store.ID,
+			OwnerID:     store.OwnerID,
+			Item:        i.Category,
+			Stock:       i.Stock,
+		}}
+
+		// Optional: immediately alert if created with low stock
+		if i.Stock <= 5 {
+			events = append(events, event.StockLow{
+				InventoryID: i.ID,
+				StoreID:     store.ID,
+				OwnerID:     store.OwnerID,
+				Item:        i.Category,
+				Stock:       i.Stock,
+			})
+		}
 
-		return nil
+		return uc.events.Publish(txCtx, events...)
 	})
 }
 
@@ -53,8 +65,36 @@ func (uc *UseCase) GetInventory(ctx context.Context, id uuid.UUID) (*domain.Inve
 	return uc.repo.GetByID(ctx, id)
 }
 
+// GetOwnedInventory returns the inventory only when its store belongs to ownerID. keyStore is
+// the store an API key is bound to, nil for user sessions; a key only reaches its own store.
+func (uc *UseCase) GetOwnedInventory(ctx context.Context, id, ownerID uuid.UUID, keyStore *uuid.UUID) (*domain.Inventory, error) {
+	inv, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch inventory: %w", err)
+	}
+	if keyStore != nil && inv.StoreID != *keyStore {
+		return nil, domain.ErrInventoryNotFound
+	}
+
+	store, err := uc.storeRepo.GetByID(ctx, inv.StoreID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch store: %w", err)
+	}
+	if store.OwnerID != ownerID {
+		return nil, domain.ErrInventoryNotFound
+	}
+
+	return inv, nil
+}
+
 func (uc *UseCase) GetInventoryByName(ctx context.Context, name string) (*domain.Inventory, error) {
-	return uc.repo.GetByName(ctx, name)
+	return uc.repo.GetByName(ctx, name, nil, nil)
+}
+
+// GetOwnedInventoryByName looks the name up in ownerID's stores only, or only keyStore's when an
+// API key asks.
+func (uc *UseCase) GetOwnedInventoryByName(ctx context.Context, name string, ownerID uuid.UUID, keyStore *uuid.UUID) (*domain.Inventory, error) {
+	return uc.repo.GetByName(ctx, name, &ownerID, keyStore)
 }
 
 func (uc *UseCase) UpdateInventory(ctx context.Context, inventoryId uuid.UUID, column string, value any) error {
@@ -76,13 +116,40 @@ func (uc *UseCase) UpdateInventory(ctx context.Context, inventoryId uuid.UUID, c
 			return fmt.Errorf("update inventory failed: %w", err)
 		}
 
-		// 4. Fire notification async (after commit)
-		go func() {
-			msg := fmt.Sprintf("ℹ️ Inventory %s updated: column '%s' changed.", inv.Category, column)
-			_ = uc.notify(ctx, store.OwnerID, msg) // you can use AdminID if available
-		}()
+		// 4. Publish in the same transaction
+		return uc.events.Publish(txCtx, event.InventoryUpdated{
+			InventoryID: inv.ID,
+			StoreID:     store.ID,
+			OwnerID:     store.OwnerID,
+			Item:        inv.Category,
+			Field:       column,
+		})
+	})
+}
+
+// RestockTx puts quantity back in stock within the caller's transaction, adding to whatever the
+// stock is at that moment rather than writing a value read earlier.
+func (uc *UseCase) RestockTx(ctx context.Context, inventoryId uuid.UUID, quantity int) error {
+	inv, err := uc.repo.GetByID(ctx, inventoryId)
+	if err != nil {
+		return fmt.Errorf("could not fetch inventory: %w", err)
+	}
 
-		return nil
+	store, err := uc.storeRepo.GetByID(ctx, inv.StoreID)
+	if err != nil {
+		return fmt.Errorf("could not fetch store: %w", err)
+	}
+
+	if err := uc.repo.AddStock(ctx, inventoryId, quantity); err != nil {
+		return fmt.Errorf("restock inventory failed: %w", err)
+	}
+
+	return uc.events.Publish(ctx, event.InventoryUpdated{
+		InventoryID: inv.ID,
+		StoreID:     store.ID,
+		OwnerID:     store.OwnerID,
+		Item:        inv.Category,
+		Field:       "stock",
 	})
 }
 
@@ -90,19 +157,47 @@ func (uc *UseCase) List(ctx context.Context, limit, offset int) ([]*domain.Inven
 	return uc.repo.List(ctx, limit, offset)
 }
 
+// ListByOwner lists inventories across ownerID's stores, or only keyStore's when an API key asks.
+func (uc *UseCase) ListByOwner(ctx context.Context, ownerID uuid.UUID, keyStore *uuid.UUID, limit, offset int) ([]*domain.Inventory, error) {
+	return uc.repo.ListByOwner(ctx, ownerID, keyStore, limit, offset)
+}
+
 func (uc *UseCase) GetByCategory(ctx context.Context, category string) ([]*domain.Inventory, error) {
-	return uc.repo.GetByCategory(ctx, category)
+	return uc.repo.GetByCategory(ctx, category, nil, nil)
+}
+
+// GetOwnedByCategory lists the category across ownerID's stores, or only keyStore's when an API key asks.
+func (uc *UseCase) GetOwnedByCategory(ctx context.Context, category string, ownerID uuid.UUID, keyStore *uuid.UUID) ([]*domain.Inventory, error) {
+	return uc.repo.GetByCategory(ctx, category, &ownerID, keyStore)
 }
 
 func (uc *UseCase) GetByStore(ctx context.Context, storeID uuid.UUID) ([]*domain.Inventory, error) {
 	return uc.repo.GetByStoreID(ctx, storeID)
 }
 
+// GetByOwnedStore lists a store's inventories once the store is confirmed to belong to ownerID,
+// and to be keyStore when an API key asks.
+func (uc *UseCase) GetByOwnedStore(ctx context.Context, storeID, ownerID uuid.UUID, keyStore *uuid.UUID) ([]*domain.Inventory, error) {
+	if keyStore != nil && storeID != *keyStore {
+		return nil, storedomain.ErrStoreNotFound
+	}
+
+	store, err := uc.storeRepo.GetByID(ctx, storeID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch store: %w", err)
+	}
+	if store.OwnerID != ownerID {
+		return nil, storedomain.ErrStoreNotFound
+	}
+
+	return uc.repo.GetByStoreID(ctx, storeID)
+}
+
 func (uc *UseCase) ListCategories(ctx context.Context) ([]string, error) {
 	return uc.repo.ListCategories(ctx)
 }
 
-func (uc *UseCase) DeleteByID(ctx context.Context, id uuid.UUID) error {
+func (uc *UseCase) DeleteByID(ctx context.Context, id, ownerID uuid.UUID) error {
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
 		// 1. Fetch inventory to get Category
 		inv, err := uc.repo.GetByID(txCtx, id)
@@ -116,31 +211,25 @@ func (uc *UseCase) DeleteByID(ctx context.Context, id uuid.UUID) error {
 			return fmt.Errorf("could not fetch store: %w", err)
 		}
 
+		if store.OwnerID != ownerID {
+			return domain.ErrInventoryNotFound
+		}
+
 		// 3. Delete
 		if err := uc.repo.Delete(txCtx, id); err != nil {
 			return fmt.Errorf("delete inventory failed: %w", err)
 		}
 
-		// 4. Fire notification async
-		go func() {
-			msg := fmt.Sprintf("🗑️ Inventory '%s' has been deleted.", inv.Category)
-			_ = uc.notify(ctx, store.OwnerID, msg)
-		}()
-
-		return nil
+		// 4. Publish in the same transaction
+		return uc.events.Publish(txCtx, event.InventoryDeleted{
+			InventoryID: inv.ID,
+			StoreID:     store.ID,
+			OwnerID:     store.OwnerID,
+			Item:        inv.Category,
+		})
 	})
 }
 
-func (uc *UseCase) GetAllInventories(ctx context.Context) ([]domain.AllInventory, error) {
-	return uc.repo.GetAllInventories(ctx)
-}
-
-func (uc *UseCase) notify(ctx context.Context, userID uuid.UUID, message string) error {
-	n := &notification.Notification{
-		UserID:  userID,
-		Message: message,
-		Type:    notification.System,
-		Status:  notification.Pending,
-	}
-	return uc.notfRepo.Create(ctx, n)
+func (uc *UseCase) GetAllInventories(ctx context.Context, ownerID uuid.UUID) ([]domain.AllInventory, error) {
+	return uc.repo.GetAllInventories(ctx, ownerID)
 }
@@ -0,0 +1,96 @@
+package inventory
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	domain "logistics-backend/internal/domain/inventory"
+
+	"github.com/google/uuid"
+)
+
+// inventoryRepo filters the way the postgres queries do: by category, then by the owner of the
+// item's store and by the store itself when those are given.
+type inventoryRepo struct {
+	domain.Repository
+	items  []*domain.Inventory
+	owners map[uuid.UUID]uuid.UUID // store -> owner
+}
+
+func (r *inventoryRepo) GetByCategory(_ context.Context, category string, ownerID, storeID *uuid.UUID) ([]*domain.Inventory, error) {
+	var found []*domain.Inventory
+	for _, i := range r.items {
+		if i.Category != category {
+			continue
+		}
+		if ownerID != nil && r.owners[i.StoreID] != *ownerID {
+			continue
+		}
+		if storeID != nil && i.StoreID != *storeID {
+			continue
+		}
+		found = append(found, i)
+	}
+	return found, nil
+}
+
+func (r *inventoryRepo) GetByName(ctx context.Context, name string, ownerID, storeID *uuid.UUID) (*domain.Inventory, error) {
+	found, _ := r.GetByCategory(ctx, name, ownerID, storeID)
+	if len(found) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return found[0], nil
+}
+
+func TestSearchesStayInTheCallersStores(t *testing.T) {
+	owner, otherOwner := uuid.New(), uuid.New()
+	shop, secondShop, otherShop := uuid.New(), uuid.New(), uuid.New()
+	repo := &inventoryRepo{
+		items: []*domain.Inventory{
+			{ID: uuid.New(), StoreID: shop, Category: "Dairy"},
+			{ID: uuid.New(), StoreID: secondShop, Category: "Dairy"},
+			{ID: uuid.New(), StoreID: otherShop, Category: "Dairy"},
+		},
+		owners: map[uuid.UUID]uuid.UUID{shop: owner, secondShop: owner, otherShop: otherOwner},
+	}
+	uc := NewUseCase(repo, nil, nil, nil)
+	ctx := context.Background()
+
+	items, err := uc.GetOwnedByCategory(ctx, "Dairy", owner, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want the owner's 2", len(items))
+	}
+	for _, i := range items {
+		if i.StoreID == otherShop {
+			t.Error("another tenant's item was returned")
+		}
+	}
+
+	items, err = uc.GetOwnedByCategory(ctx, "Dairy", owner, &secondShop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].StoreID != secondShop {
+		t.Errorf("API key for one store got %+v, want only that store's item", items)
+	}
+
+	// a key issued for another tenant's store finds nothing for this owner
+	if items, _ := uc.GetOwnedByCategory(ctx, "Dairy", owner, &otherShop); len(items) != 0 {
+		t.Errorf("got %d items through another tenant's store, want none", len(items))
+	}
+
+	i, err := uc.GetOwnedInventoryByName(ctx, "Dairy", otherOwner, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.StoreID != otherShop {
+		t.Errorf("by name returned store %s, want the caller's %s", i.StoreID, otherShop)
+	}
+	if _, err := uc.GetOwnedInventoryByName(ctx, "Dairy", uuid.New(), nil); err == nil {
+		t.Error("an owner without stores found an item by name")
+	}
+}
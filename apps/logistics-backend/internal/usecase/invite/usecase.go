@@ -2,53 +2,184 @@ package invite
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	domain "logistics-backend/internal/domain/invite"
+	"logistics-backend/internal/domain/notification"
+	"logistics-backend/internal/domain/user"
 	"logistics-backend/internal/usecase/common"
+	"logistics-backend/internal/utils"
+	"net/url"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type UseCase struct {
 	repo      domain.Repository
+	usrUC     domain.UserProvisioner
 	txManager common.TxManager
-	// optional user repo - auto-create users on accept
+	notfRepo  domain.NotificationWriter
+	acceptURL string
+	ttl       time.Duration
 }
 
-func NewUseCase(repo domain.Repository, txm common.TxManager) *UseCase {
-	return &UseCase{repo: repo, txManager: txm}
+func NewUseCase(repo domain.Repository, usrUC domain.UserProvisioner, txm common.TxManager, notf domain.NotificationWriter, acceptURL string, ttl time.Duration) *UseCase {
+	return &UseCase{repo: repo, usrUC: usrUC, txManager: txm, notfRepo: notf, acceptURL: acceptURL, ttl: ttl}
 }
 
-// Add a new invited member from the shareable invite link
+// InviteMember stores a new invite with a server generated token and emails the invite link.
 func (uc *UseCase) InviteMember(ctx context.Context, i *domain.Invite) error {
-	// generate token, store invite
-	// optionally notify user
+	if !i.Role.CanInvite() {
+		return domain.ErrInvalidRole
+	}
+
+	if err := uc.ensureEmailFree(ctx, i.Email); err != nil {
+		return err
+	}
+
+	raw, err := utils.GenerateToken(32)
+	if err != nil {
+		return fmt.Errorf("could not generate invite token: %w", err)
+	}
+	i.TokenHash = utils.HashToken(raw)
+	i.ExpiresAt = time.Now().Add(uc.ttl)
+
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
 		if err := uc.repo.Create(txCtx, i); err != nil {
 			return fmt.Errorf("create invite failed: %w", err)
 		}
 
+		// the invitee has no account yet, so the email is theirs alone and the link never lands
+		// in the inviter's notifications
+		link := fmt.Sprintf("%s?token=%s", uc.acceptURL, url.QueryEscape(raw))
+		n := &notification.Notification{
+			Recipient: &i.Email,
+			Event:     notification.EventInviteSent,
+			Data: notification.TemplateData{
+				"role":    string(i.Role),
+				"expires": i.ExpiresAt.Format(time.RFC1123),
+				"link":    link,
+			},
+			Type:   notification.Email,
+			Status: notification.Pending,
+		}
+		if err := uc.notfRepo.Create(txCtx, n); err != nil {
+			return fmt.Errorf("could not queue invite email: %w", err)
+		}
+
 		return nil
 	})
 }
 
-// Get invited member by token
+// GetMemberByToken returns a still acceptable invite for the raw token from the invite link.
 func (uc *UseCase) GetMemberByToken(ctx context.Context, token string) (*domain.Invite, error) {
-	return uc.repo.GetByToken(ctx, token)
+	i, err := uc.repo.GetByTokenHash(ctx, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInviteNotFound
+		}
+		return nil, fmt.Errorf("could not fetch invite: %w", err)
+	}
+
+	if i.IsAccepted() {
+		return nil, domain.ErrInviteAccepted
+	}
+	if i.IsExpired(time.Now()) {
+		return nil, domain.ErrInviteExpired
+	}
+
+	return i, nil
+}
+
+// AcceptInvite creates the invited account with the invited role, owned by the inviter,
+// and consumes the invite, all in one transaction.
+func (uc *UseCase) AcceptInvite(ctx context.Context, req *domain.AcceptInviteRequest) (*user.User, error) {
+	if len(req.Password) < user.MinPasswordLength {
+		return nil, user.ErrWeakPassword
+	}
+
+	i, err := uc.GetMemberByToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.ensureEmailFree(ctx, i.Email); err != nil {
+		return nil, err
+	}
+
+	inviter := i.InvitedBy
+	now := time.Now()
+	u := &user.User{
+		FullName:        req.FullName,
+		Email:           i.Email,
+		PasswordHash:    req.Password,
+		Role:            user.Role(i.Role),
+		Phone:           req.Phone,
+		Slug:            utils.GenerateSlug(req.FullName) + "-" + uuid.New().String()[:8],
+		Status:          user.Active, // the emailed link already proves the address
+		EmailVerifiedAt: &now,
+		OwnerID:         &inviter,
+		InvitedBy:       &inviter,
+	}
+
+	err = uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := uc.usrUC.CreateAccount(txCtx, u); err != nil {
+			return err
+		}
+
+		if err := uc.repo.MarkAccepted(txCtx, i.ID, u.ID); err != nil {
+			return err
+		}
+
+		return uc.notify(txCtx, inviter, notification.EventInviteAccepted, notification.TemplateData{
+			"name": u.FullName,
+			"role": string(u.Role),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
 }
 
-// List all invited members
-func (uc *UseCase) ListPendingMembers(ctx context.Context) ([]*domain.Invite, error) {
-	return uc.repo.ListPending(ctx)
+// List the inviter's invites that are neither accepted nor expired
+func (uc *UseCase) ListPendingMembers(ctx context.Context, invitedBy uuid.UUID) ([]*domain.Invite, error) {
+	return uc.repo.ListPending(ctx, invitedBy)
 }
 
-// Delete selected invited member
-func (uc *UseCase) DeleteMember(ctx context.Context, id uuid.UUID) error {
+// Delete (revoke) one of the inviter's invites
+func (uc *UseCase) DeleteMember(ctx context.Context, id, invitedBy uuid.UUID) error {
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
-		if err := uc.repo.Delete(txCtx, id); err != nil {
+		if err := uc.repo.Delete(txCtx, id, invitedBy); err != nil {
 			return fmt.Errorf("delete invite failed: %w", err)
 		}
 
 		return nil
 	})
 }
+
+func (uc *UseCase) ensureEmailFree(ctx context.Context, email string) error {
+	_, err := uc.usrUC.GetUserByEmail(ctx, email)
+	switch {
+	case err == nil:
+		return domain.ErrEmailTaken
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	default:
+		return fmt.Errorf("could not check email: %w", err)
+	}
+}
+
+func (uc *UseCase) notify(ctx context.Context, userID uuid.UUID, event notification.Event, data notification.TemplateData) error {
+	n := &notification.Notification{
+		UserID: userID,
+		Event:  event,
+		Data:   data,
+		Type:   notification.System,
+		Status: notification.Pending,
+	}
+	return uc.notfRepo.Create(ctx, n)
+}
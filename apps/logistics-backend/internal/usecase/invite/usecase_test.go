@@ -0,0 +1,158 @@
+package invite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	domain "logistics-backend/internal/domain/invite"
+	"logistics-backend/internal/domain/notification"
+	"logistics-backend/internal/domain/user"
+	notificationusecase "logistics-backend/internal/usecase/notification"
+
+	"github.com/google/uuid"
+)
+
+type noTx struct{}
+
+func (noTx) Do(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) }
+
+type inviteRepo struct {
+	domain.Repository
+}
+
+func (inviteRepo) Create(_ context.Context, i *domain.Invite) error {
+	i.ID = uuid.New()
+	return nil
+}
+
+type noUsers struct {
+	domain.UserProvisioner
+}
+
+func (noUsers) GetUserByEmail(context.Context, string) (*user.User, error) {
+	return nil, sql.ErrNoRows
+}
+
+// outbox keeps the payloads the way the outbox table does, as JSON.
+type outbox struct {
+	payloads [][]byte
+}
+
+func (o *outbox) Create(_ context.Context, n *notification.Notification) error {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	o.payloads = append(o.payloads, payload)
+	return nil
+}
+
+type notificationRepo struct {
+	notification.Repository
+	stored map[uuid.UUID]*notification.Notification
+	sent   []uuid.UUID
+	failed []string
+}
+
+func (r *notificationRepo) CreateIfAbsent(_ context.Context, n *notification.Notification) error {
+	if _, ok := r.stored[n.ID]; !ok {
+		r.stored[n.ID] = n
+	}
+	return nil
+}
+
+func (r *notificationRepo) ClaimDue(context.Context, int, time.Duration) ([]*notification.Notification, error) {
+	var due []*notification.Notification
+	for _, n := range r.stored {
+		due = append(due, n)
+	}
+	return due, nil
+}
+
+func (r *notificationRepo) MarkSent(_ context.Context, id uuid.UUID, _ *string) error {
+	r.sent = append(r.sent, id)
+	return nil
+}
+
+func (r *notificationRepo) MarkFailed(_ context.Context, _ uuid.UUID, reason string, _ time.Time, _ bool) error {
+	r.failed = append(r.failed, reason)
+	return nil
+}
+
+// accounts knows no users, like the invitee before they accept
+type accounts struct {
+	notification.ContactReader
+}
+
+func (accounts) GetContact(context.Context, uuid.UUID) (*notification.Contact, error) {
+	return nil, sql.ErrNoRows
+}
+
+type mailbox struct {
+	to   []string
+	text []string
+}
+
+func (m *mailbox) Send(_ context.Context, _ *notification.Notification, to *notification.Contact, msg *notification.Message) (string, error) {
+	m.to = append(m.to, to.Email)
+	m.text = append(m.text, msg.Text)
+	return "", nil
+}
+
+func TestInviteEmailReachesTheInvitee(t *testing.T) {
+	queue := &outbox{}
+	uc := NewUseCase(inviteRepo{}, noUsers{}, noTx{}, queue, "https://app.test/accept-invite", time.Hour)
+
+	i := &domain.Invite{Email: "new.driver@example.com", Role: domain.Driver, InvitedBy: uuid.New()}
+	if err := uc.InviteMember(context.Background(), i); err != nil {
+		t.Fatal(err)
+	}
+	if len(queue.payloads) != 1 {
+		t.Fatalf("queued %d notifications, want 1", len(queue.payloads))
+	}
+
+	templates, err := notificationusecase.NewTemplates(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &notificationRepo{stored: map[uuid.UUID]*notification.Notification{}}
+	mail := &mailbox{}
+	notifications := notificationusecase.NewUseCase(repo, noTx{}, accounts{}, mail, templates, nil,
+		notificationusecase.DeliveryConfig{BatchSize: 10, Lease: time.Minute, MaxAttempts: 3})
+
+	if err := notifications.CreateFromOutbox(context.Background(), uuid.New(), queue.payloads[0]); err != nil {
+		t.Fatalf("CreateFromOutbox: %v", err)
+	}
+	if _, err := notifications.DeliverDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(repo.failed) > 0 || len(repo.sent) != 1 {
+		t.Fatalf("sent %d, failed %v; want the invite sent once", len(repo.sent), repo.failed)
+	}
+	if len(mail.to) != 1 || mail.to[0] != i.Email {
+		t.Errorf("emailed %v, want %s", mail.to, i.Email)
+	}
+	if !strings.Contains(mail.text[0], "https://app.test/accept-invite?token=") {
+		t.Errorf("email has no invite link: %q", mail.text[0])
+	}
+	for _, n := range repo.stored {
+		if n.UserID != uuid.Nil {
+			t.Errorf("invite notification filed under user %s", n.UserID)
+		}
+	}
+}
+
+func TestInviteNeedsAnInvitableRole(t *testing.T) {
+	uc := NewUseCase(inviteRepo{}, noUsers{}, noTx{}, &outbox{}, "https://app.test/accept-invite", time.Hour)
+
+	err := uc.InviteMember(context.Background(), &domain.Invite{Email: "a@example.com", Role: domain.Role("owner")})
+	if !errors.Is(err, domain.ErrInvalidRole) {
+		t.Errorf("err = %v, want ErrInvalidRole", err)
+	}
+}
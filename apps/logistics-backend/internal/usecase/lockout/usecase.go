@@ -0,0 +1,190 @@
+package lockout
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	domain "logistics-backend/internal/domain/lockout"
+	"logistics-backend/internal/domain/notification"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	baseDelay = 250 * time.Millisecond // first failed attempt
+	maxDelay  = 5 * time.Second        // cap for the doubling delay
+)
+
+// Config bounds failed logins. Counts restart once Window has passed since the last failure.
+type Config struct {
+	MaxAccountFailures int
+	MaxIPFailures      int
+	Window             time.Duration
+	LockoutDuration    time.Duration
+}
+
+type UseCase struct {
+	repo     domain.Repository
+	notfRepo domain.NotificationWriter
+	cfg      Config
+}
+
+func NewUseCase(repo domain.Repository, notf domain.NotificationWriter, cfg Config) *UseCase {
+	return &UseCase{repo: repo, notfRepo: notf, cfg: cfg}
+}
+
+// Check refuses a login attempt while the account or the client IP is locked,
+// returning how long the caller should wait.
+func (uc *UseCase) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	now := time.Now()
+
+	for _, s := range []struct {
+		scope domain.Scope
+		key   string
+		err   error
+	}{
+		{domain.Account, accountKey(email), domain.ErrAccountLocked},
+		{domain.IP, ip, domain.ErrTooManyAttempts},
+	} {
+		if s.key == "" {
+			continue
+		}
+
+		f, err := uc.repo.Get(ctx, s.scope, s.key)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return 0, fmt.Errorf("could not check login failures: %w", err)
+		}
+
+		if f.IsLocked(now) {
+			return f.LockedUntil.Sub(now), s.err
+		}
+	}
+
+	return 0, nil
+}
+
+// RecordFailure counts a failed login for the account and the IP, locks whichever went over
+// its limit and then holds the caller back for a delay that doubles with every failure.
+// userID is set when the email belongs to an account, which is then told about a lockout.
+func (uc *UseCase) RecordFailure(ctx context.Context, email, ip string, userID *uuid.UUID) (time.Duration, error) {
+	until := time.Now().Add(uc.cfg.LockoutDuration)
+
+	account, err := uc.repo.RecordFailure(ctx, domain.Account, accountKey(email), uc.cfg.Window)
+	if err != nil {
+		return 0, err
+	}
+
+	var lockErr error
+	if account.Count >= uc.cfg.MaxAccountFailures {
+		if err := uc.repo.Lock(ctx, domain.Account, account.Key, until); err != nil {
+			return 0, err
+		}
+		lockErr = domain.ErrAccountLocked
+
+		if userID != nil {
+			data := notification.TemplateData{
+				"until":    until.Format(time.RFC1123),
+				"attempts": strconv.Itoa(account.Count),
+			}
+			if err := uc.notify(ctx, *userID, notification.EventAccountLocked, data); err != nil {
+				log.Printf("login: could not queue lockout notice for %s: %v", *userID, err)
+			}
+		}
+	}
+
+	if ip != "" {
+		client, err := uc.repo.RecordFailure(ctx, domain.IP, ip, uc.cfg.Window)
+		if err != nil {
+			return 0, err
+		}
+
+		if client.Count >= uc.cfg.MaxIPFailures {
+			if err := uc.repo.Lock(ctx, domain.IP, ip, until); err != nil {
+				return 0, err
+			}
+			if lockErr == nil {
+				lockErr = domain.ErrTooManyAttempts
+			}
+			log.Printf("login: locked client %s after %d failed attempts", ip, client.Count)
+		}
+	}
+
+	if lockErr != nil {
+		return uc.cfg.LockoutDuration, lockErr
+	}
+
+	uc.wait(ctx, delayFor(account.Count))
+	return 0, nil
+}
+
+// RecordSuccess clears the account's failures. The IP count is kept so a single valid
+// account cannot be used to reset an address that is guessing other accounts.
+func (uc *UseCase) RecordSuccess(ctx context.Context, email string) error {
+	return uc.repo.Reset(ctx, domain.Account, accountKey(email))
+}
+
+// Unlock lifts an account lockout ahead of time.
+func (uc *UseCase) Unlock(ctx context.Context, email string) error {
+	return uc.repo.Reset(ctx, domain.Account, accountKey(email))
+}
+
+// RunCleanup periodically drops failure counts that are outside the window and not locked.
+func (uc *UseCase) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := uc.repo.DeleteStale(ctx, time.Now().Add(-uc.cfg.Window)); err != nil {
+				log.Printf("login failure cleanup failed: %v", err)
+			} else if n > 0 {
+				log.Printf("login failure cleanup removed %d entries", n)
+			}
+		}
+	}
+}
+
+func (uc *UseCase) wait(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
+
+func (uc *UseCase) notify(ctx context.Context, userID uuid.UUID, event notification.Event, data notification.TemplateData) error {
+	n := &notification.Notification{
+		UserID: userID,
+		Event:  event,
+		Data:   data,
+		Type:   notification.Email,
+		Status: notification.Pending,
+	}
+	return uc.notfRepo.Create(ctx, n)
+}
+
+func accountKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// delayFor doubles the delay with every consecutive failure, up to maxDelay.
+func delayFor(failures int) time.Duration {
+	d := baseDelay
+	for i := 1; i < failures && d < maxDelay; i++ {
+		d *= 2
+	}
+	return min(d, maxDelay)
+}
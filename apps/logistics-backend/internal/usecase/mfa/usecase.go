@@ -0,0 +1,302 @@
+package mfa
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	domain "logistics-backend/internal/domain/mfa"
+	"logistics-backend/internal/domain/notification"
+	"logistics-backend/internal/domain/user"
+	"logistics-backend/internal/usecase/common"
+	"logistics-backend/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	challengeTTL         = 5 * time.Minute
+	maxChallengeAttempts = 5
+	recoveryCodeCount    = 10
+	totpSkew             = 1 // accept the previous and next 30s step for clock drift
+)
+
+type UseCase struct {
+	repo      domain.Repository
+	usrRepo   domain.UserReader
+	txManager common.TxManager
+	notfRepo  domain.NotificationWriter
+	issuer    string
+	policy    domain.Policy
+}
+
+func NewUseCase(repo domain.Repository, usrRepo domain.UserReader, txm common.TxManager, notf domain.NotificationWriter, issuer string, policy domain.Policy) *UseCase {
+	return &UseCase{repo: repo, usrRepo: usrRepo, txManager: txm, notfRepo: notf, issuer: issuer, policy: policy}
+}
+
+// Requires reports whether the role must have 2FA enabled.
+func (uc *UseCase) Requires(role user.Role) bool {
+	return uc.policy.Requires(role)
+}
+
+// Enroll creates a new, unconfirmed authenticator secret for the user.
+func (uc *UseCase) Enroll(ctx context.Context, userID uuid.UUID) (*domain.Enrollment, error) {
+	u, err := uc.usrRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch user: %w", err)
+	}
+	if u.MFAEnabled {
+		return nil, domain.ErrAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate totp secret: %w", err)
+	}
+
+	if err := uc.repo.UpsertTOTP(ctx, &domain.TOTP{UserID: userID, Secret: secret}); err != nil {
+		return nil, err
+	}
+
+	return &domain.Enrollment{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(uc.issuer, u.Email, secret),
+	}, nil
+}
+
+// Confirm turns 2FA on once the user proves the authenticator works, and returns fresh recovery codes.
+func (uc *UseCase) Confirm(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	t, err := uc.getTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if t.IsConfirmed() {
+		return nil, domain.ErrAlreadyEnabled
+	}
+
+	step, ok := utils.ValidateTOTP(t.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, domain.ErrInvalidCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := uc.repo.ConfirmTOTP(txCtx, userID, step); err != nil {
+			return err
+		}
+
+		if err := uc.repo.SetEnabled(txCtx, userID, true); err != nil {
+			return err
+		}
+
+		if err := uc.repo.ReplaceRecoveryCodes(txCtx, userID, hashes); err != nil {
+			return err
+		}
+
+		return uc.notify(txCtx, userID, notification.EventMFAEnabled, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable turns 2FA off after checking a current code. Roles covered by the policy cannot opt out.
+func (uc *UseCase) Disable(ctx context.Context, userID uuid.UUID, role user.Role, code string) error {
+	if uc.policy.Requires(role) {
+		return domain.ErrRequiredByPolicy
+	}
+
+	if err := uc.verifyTOTP(ctx, userID, code); err != nil {
+		return err
+	}
+
+	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := uc.repo.DeleteTOTP(txCtx, userID); err != nil {
+			return err
+		}
+
+		if err := uc.repo.SetEnabled(txCtx, userID, false); err != nil {
+			return err
+		}
+
+		return uc.notify(txCtx, userID, notification.EventMFADisabled, nil)
+	})
+}
+
+// RegenerateRecoveryCodes replaces every recovery code after checking a current code.
+func (uc *UseCase) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	if err := uc.verifyTOTP(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// StartChallenge is called by LoginUser after a correct password for users with 2FA enabled.
+func (uc *UseCase) StartChallenge(ctx context.Context, userID uuid.UUID) (*domain.ChallengeResponse, error) {
+	raw, err := utils.GenerateToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate challenge token: %w", err)
+	}
+
+	c := &domain.Challenge{
+		UserID:    userID,
+		TokenHash: utils.HashToken(raw),
+		ExpiresAt: time.Now().Add(challengeTTL),
+	}
+	if err := uc.repo.CreateChallenge(ctx, c); err != nil {
+		return nil, err
+	}
+
+	return &domain.ChallengeResponse{
+		MFARequired:    true,
+		ChallengeToken: raw,
+		ExpiresAt:      c.ExpiresAt,
+	}, nil
+}
+
+// ChallengeUser returns whose login a still usable challenge belongs to, so the caller can check
+// that account's lockout before a code is tried.
+func (uc *UseCase) ChallengeUser(ctx context.Context, token string) (uuid.UUID, error) {
+	c, err := uc.usableChallenge(ctx, token)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return c.UserID, nil
+}
+
+// VerifyChallenge completes a login challenge with a TOTP or recovery code and returns the user
+// to issue tokens for. Each challenge allows a handful of wrong codes.
+func (uc *UseCase) VerifyChallenge(ctx context.Context, req *domain.VerifyLoginRequest) (uuid.UUID, error) {
+	c, err := uc.usableChallenge(ctx, req.ChallengeToken)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if req.RecoveryCode != "" {
+		err = uc.repo.UseRecoveryCode(ctx, c.UserID, utils.HashToken(normalizeRecoveryCode(req.RecoveryCode)))
+	} else {
+		err = uc.verifyTOTP(ctx, c.UserID, req.Code)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCode) {
+			if incErr := uc.repo.IncrementChallengeAttempts(ctx, c.ID); incErr != nil {
+				return uuid.Nil, incErr
+			}
+		}
+		return uuid.Nil, err
+	}
+
+	err = uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := uc.repo.MarkChallengeUsed(txCtx, c.ID); err != nil {
+			return err
+		}
+
+		if req.RecoveryCode != "" {
+			return uc.notify(txCtx, c.UserID, notification.EventMFARecoveryCodeUsed, nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return c.UserID, nil
+}
+
+func (uc *UseCase) usableChallenge(ctx context.Context, token string) (*domain.Challenge, error) {
+	c, err := uc.repo.GetChallengeByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidChallenge
+		}
+		return nil, fmt.Errorf("could not fetch challenge: %w", err)
+	}
+	if !c.IsUsable(time.Now(), maxChallengeAttempts) {
+		return nil, domain.ErrInvalidChallenge
+	}
+	return c, nil
+}
+
+// verifyTOTP accepts each code once, so an observed code cannot be replayed.
+func (uc *UseCase) verifyTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	t, err := uc.getTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !t.IsConfirmed() {
+		return domain.ErrNotEnrolled
+	}
+
+	step, ok := utils.ValidateTOTP(t.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return domain.ErrInvalidCode
+	}
+
+	return uc.repo.UseTOTPStep(ctx, userID, step)
+}
+
+func (uc *UseCase) getTOTP(ctx context.Context, userID uuid.UUID) (*domain.TOTP, error) {
+	t, err := uc.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotEnrolled
+		}
+		return nil, fmt.Errorf("could not fetch totp: %w", err)
+	}
+	return t, nil
+}
+
+func (uc *UseCase) notify(ctx context.Context, userID uuid.UUID, event notification.Event, data notification.TemplateData) error {
+	n := &notification.Notification{
+		UserID: userID,
+		Event:  event,
+		Data:   data,
+		Type:   notification.Email,
+		Status: notification.Pending,
+	}
+	return uc.notfRepo.Create(ctx, n)
+}
+
+// newRecoveryCodes returns codes formatted as xxxxx-xxxxx together with the hashes to store.
+func newRecoveryCodes() ([]string, []string, error) {
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, fmt.Errorf("could not generate recovery code: %w", err)
+		}
+		raw := strings.ToLower(enc.EncodeToString(buf))[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = utils.HashToken(raw)
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
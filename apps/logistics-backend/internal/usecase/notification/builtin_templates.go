@@ -0,0 +1,325 @@
+package notification
+
+import domain "logistics-backend/internal/domain/notification"
+
+// builtinTemplates ship with the code and can be overridden per event, locale and channel.
+// Every event needs an English in-app (System) template; other locales and channels fall back
+// to it. SMS variants avoid emoji so they stay in the cheaper GSM-7 alphabet.
+var builtinTemplates = []domain.Template{
+	// Accounts
+	{Event: domain.EventUserRegistered, Locale: domain.English, Channel: domain.System,
+		Subject: "Welcome to FastaBiz",
+		Body:    "✅ New user '{{.name}}' has been registered with role '{{.role}}'."},
+	{Event: domain.EventUserRegistered, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Karibu FastaBiz",
+		Body:    "✅ Mtumiaji mpya '{{.name}}' amesajiliwa kama '{{.role}}'."},
+
+	{Event: domain.EventUserProfileUpdated, Locale: domain.English, Channel: domain.System,
+		Subject: "Profile updated",
+		Body:    "ℹ️ Your profile was updated successfully."},
+	{Event: domain.EventUserProfileUpdated, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Wasifu umesasishwa",
+		Body:    "ℹ️ Wasifu wako umesasishwa."},
+
+	{Event: domain.EventUserAccountUpdated, Locale: domain.English, Channel: domain.System,
+		Subject: "Account updated",
+		Body:    "ℹ️ Your account field '{{.field}}' was updated."},
+	{Event: domain.EventUserAccountUpdated, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Akaunti imesasishwa",
+		Body:    "ℹ️ Sehemu '{{.field}}' ya akaunti yako imesasishwa."},
+
+	{Event: domain.EventUserDeleted, Locale: domain.English, Channel: domain.System,
+		Subject: "Account deleted",
+		Body:    "🗑️ User '{{.name}}' has been deleted."},
+	{Event: domain.EventUserDeleted, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Akaunti imefutwa",
+		Body:    "🗑️ Mtumiaji '{{.name}}' amefutwa."},
+
+	{Event: domain.EventEmailVerification, Locale: domain.English, Channel: domain.System,
+		Subject: "Confirm your email address",
+		Body:    "👋 Welcome {{.name}}! Confirm your email address within {{.ttl}} to activate your account: {{.link}}"},
+	{Event: domain.EventEmailVerification, Locale: domain.English, Channel: domain.Email,
+		Subject: "Confirm your FastaBiz email address",
+		Body:    "Welcome {{.name}}!\n\nConfirm your email address within {{.ttl}} to activate your account:\n{{.link}}\n\nIf you did not sign up, ignore this email.",
+		HTML:    `<p>Welcome {{.name}}!</p><p>Confirm your email address within {{.ttl}} to activate your account.</p><p><a href="{{.link}}">Confirm email address</a></p><p>If you did not sign up, ignore this email.</p>`},
+	{Event: domain.EventEmailVerification, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Thibitisha barua pepe yako",
+		Body:    "👋 Karibu {{.name}}! Thibitisha barua pepe yako ndani ya {{.ttl}} ili kuwezesha akaunti yako: {{.link}}"},
+	{Event: domain.EventEmailVerification, Locale: domain.Swahili, Channel: domain.Email,
+		Subject: "Thibitisha barua pepe yako ya FastaBiz",
+		Body:    "Karibu {{.name}}!\n\nThibitisha barua pepe yako ndani ya {{.ttl}} ili kuwezesha akaunti yako:\n{{.link}}\n\nIkiwa hukujisajili, puuza barua hii.",
+		HTML:    `<p>Karibu {{.name}}!</p><p>Thibitisha barua pepe yako ndani ya {{.ttl}} ili kuwezesha akaunti yako.</p><p><a href="{{.link}}">Thibitisha barua pepe</a></p><p>Ikiwa hukujisajili, puuza barua hii.</p>`},
+
+	{Event: domain.EventEmailVerified, Locale: domain.English, Channel: domain.System,
+		Subject: "Account activated",
+		Body:    "✅ Your email address is verified and your account is now active."},
+	{Event: domain.EventEmailVerified, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Akaunti imewezeshwa",
+		Body:    "✅ Barua pepe yako imethibitishwa na akaunti yako sasa inatumika."},
+
+	{Event: domain.EventPasswordResetRequested, Locale: domain.English, Channel: domain.System,
+		Subject: "Reset your password",
+		Body:    "🔑 A password reset was requested for your account. Use this link within {{.ttl}} to set a new password: {{.link}}"},
+	{Event: domain.EventPasswordResetRequested, Locale: domain.English, Channel: domain.Email,
+		Subject: "Reset your FastaBiz password",
+		Body:    "A password reset was requested for your account.\n\nUse this link within {{.ttl}} to set a new password:\n{{.link}}\n\nIf you did not ask for this, ignore this email; your password stays the same.",
+		HTML:    `<p>A password reset was requested for your account.</p><p><a href="{{.link}}">Set a new password</a> (valid for {{.ttl}})</p><p>If you did not ask for this, ignore this email; your password stays the same.</p>`},
+	{Event: domain.EventPasswordResetRequested, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Weka upya nenosiri lako",
+		Body:    "🔑 Ombi la kuweka upya nenosiri limetumwa kwa akaunti yako. Tumia kiungo hiki ndani ya {{.ttl}} kuweka nenosiri jipya: {{.link}}"},
+	{Event: domain.EventPasswordResetRequested, Locale: domain.Swahili, Channel: domain.Email,
+		Subject: "Weka upya nenosiri lako la FastaBiz",
+		Body:    "Ombi la kuweka upya nenosiri limetumwa kwa akaunti yako.\n\nTumia kiungo hiki ndani ya {{.ttl}} kuweka nenosiri jipya:\n{{.link}}\n\nIkiwa hukuomba hili, puuza barua hii; nenosiri lako halitabadilika.",
+		HTML:    `<p>Ombi la kuweka upya nenosiri limetumwa kwa akaunti yako.</p><p><a href="{{.link}}">Weka nenosiri jipya</a> (halali kwa {{.ttl}})</p><p>Ikiwa hukuomba hili, puuza barua hii; nenosiri lako halitabadilika.</p>`},
+
+	{Event: domain.EventPasswordReset, Locale: domain.English, Channel: domain.System,
+		Subject: "Password reset",
+		Body:    "🔒 Your password was reset. If this wasn't you, contact support immediately."},
+	{Event: domain.EventPasswordReset, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Nenosiri limewekwa upya",
+		Body:    "🔒 Nenosiri lako limewekwa upya. Ikiwa si wewe, wasiliana na huduma kwa wateja mara moja."},
+
+	{Event: domain.EventPasswordChanged, Locale: domain.English, Channel: domain.System,
+		Subject: "Password changed",
+		Body:    "🔒 Your password was changed."},
+	{Event: domain.EventPasswordChanged, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Nenosiri limebadilishwa",
+		Body:    "🔒 Nenosiri lako limebadilishwa."},
+
+	{Event: domain.EventAccountLocked, Locale: domain.English, Channel: domain.System,
+		Subject: "Account locked",
+		Body:    "⚠️ Your account was locked until {{.until}} after {{.attempts}} failed login attempts. If this wasn't you, reset your password."},
+	{Event: domain.EventAccountLocked, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Akaunti imefungwa",
+		Body:    "⚠️ Akaunti yako imefungwa hadi {{.until}} baada ya majaribio {{.attempts}} ya kuingia yaliyoshindwa. Ikiwa si wewe, weka upya nenosiri lako."},
+
+	// Two-factor authentication
+	{Event: domain.EventMFAEnabled, Locale: domain.English, Channel: domain.System,
+		Subject: "Two-factor authentication enabled",
+		Body:    "🔐 Two-factor authentication was enabled on your account."},
+	{Event: domain.EventMFAEnabled, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Uthibitishaji wa hatua mbili umewashwa",
+		Body:    "🔐 Uthibitishaji wa hatua mbili umewashwa kwenye akaunti yako."},
+
+	{Event: domain.EventMFADisabled, Locale: domain.English, Channel: domain.System,
+		Subject: "Two-factor authentication disabled",
+		Body:    "⚠️ Two-factor authentication was disabled on your account. If this wasn't you, reset your password."},
+	{Event: domain.EventMFADisabled, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Uthibitishaji wa hatua mbili umezimwa",
+		Body:    "⚠️ Uthibitishaji wa hatua mbili umezimwa kwenye akaunti yako. Ikiwa si wewe, weka upya nenosiri lako."},
+
+	{Event: domain.EventMFARecoveryCodeUsed, Locale: domain.English, Channel: domain.System,
+		Subject: "Recovery code used",
+		Body:    "🔑 A recovery code was used to sign in to your account."},
+	{Event: domain.EventMFARecoveryCodeUsed, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Msimbo wa urejeshaji umetumika",
+		Body:    "🔑 Msimbo wa urejeshaji umetumika kuingia kwenye akaunti yako."},
+
+	// Invites
+	{Event: domain.EventInviteSent, Locale: domain.English, Channel: domain.System,
+		Subject: "You're invited to FastaBiz",
+		Body:    "✉️ You have been invited to join as {{.role}}. Accept the invite before {{.expires}}: {{.link}}"},
+	{Event: domain.EventInviteSent, Locale: domain.English, Channel: domain.Email,
+		Subject: "You're invited to join FastaBiz",
+		Body:    "You have been invited to join FastaBiz as {{.role}}.\n\nAccept the invite before {{.expires}}:\n{{.link}}",
+		HTML:    `<p>You have been invited to join FastaBiz as <strong>{{.role}}</strong>.</p><p><a href="{{.link}}">Accept the invite</a> before {{.expires}}.</p>`},
+	{Event: domain.EventInviteSent, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Umealikwa FastaBiz",
+		Body:    "✉️ Umealikwa kujiunga kama {{.role}}. Kubali mwaliko kabla ya {{.expires}}: {{.link}}"},
+	{Event: domain.EventInviteSent, Locale: domain.Swahili, Channel: domain.Email,
+		Subject: "Umealikwa kujiunga na FastaBiz",
+		Body:    "Umealikwa kujiunga na FastaBiz kama {{.role}}.\n\nKubali mwaliko kabla ya {{.expires}}:\n{{.link}}",
+		HTML:    `<p>Umealikwa kujiunga na FastaBiz kama <strong>{{.role}}</strong>.</p><p><a href="{{.link}}">Kubali mwaliko</a> kabla ya {{.expires}}.</p>`},
+
+	{Event: domain.EventInviteAccepted, Locale: domain.English, Channel: domain.System,
+		Subject: "Invite accepted",
+		Body:    "🎉 {{.name}} accepted your invite and joined as {{.role}}."},
+	{Event: domain.EventInviteAccepted, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Mwaliko umekubaliwa",
+		Body:    "🎉 {{.name}} amekubali mwaliko wako na kujiunga kama {{.role}}."},
+
+	// Drivers
+	{Event: domain.EventDriverRegistered, Locale: domain.English, Channel: domain.System,
+		Subject: "Driver account created",
+		Body:    "✅ Welcome {{.name}}! Your driver account has been created."},
+	{Event: domain.EventDriverRegistered, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Akaunti ya dereva imeundwa",
+		Body:    "✅ Karibu {{.name}}! Akaunti yako ya dereva imeundwa."},
+
+	{Event: domain.EventDriverProfileUpdated, Locale: domain.English, Channel: domain.System,
+		Subject: "Driver profile updated",
+		Body:    "ℹ️ Your driver profile has been updated."},
+	{Event: domain.EventDriverProfileUpdated, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Wasifu wa dereva umesasishwa",
+		Body:    "ℹ️ Wasifu wako wa dereva umesasishwa."},
+
+	{Event: domain.EventDriverAccountUpdated, Locale: domain.English, Channel: domain.System,
+		Subject: "Driver account updated",
+		Body:    "ℹ️ Your driver account field '{{.field}}' has been updated."},
+	{Event: domain.EventDriverAccountUpdated, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Akaunti ya dereva imesasishwa",
+		Body:    "ℹ️ Sehemu '{{.field}}' ya akaunti yako ya dereva imesasishwa."},
+
+	{Event: domain.EventDriverAvailabilityChanged, Locale: domain.English, Channel: domain.System,
+		Subject: "Availability updated",
+		Body:    "ℹ️ Your availability status has been set to '{{.status}}'."},
+	{Event: domain.EventDriverAvailabilityChanged, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Upatikanaji umesasishwa",
+		Body:    "ℹ️ Hali yako ya upatikanaji imewekwa kuwa '{{.status}}'."},
+
+	{Event: domain.EventDriverDeleted, Locale: domain.English, Channel: domain.System,
+		Subject: "Driver account deleted",
+		Body:    "🗑️ Your driver account has been deleted."},
+	{Event: domain.EventDriverDeleted, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Akaunti ya dereva imefutwa",
+		Body:    "🗑️ Akaunti yako ya dereva imefutwa."},
+
+	// Driver documents
+	{Event: domain.EventDocumentUploaded, Locale: domain.English, Channel: domain.System,
+		Subject: "Document uploaded",
+		Body:    "📄 Your {{.document}} document has been uploaded and is awaiting verification."},
+	{Event: domain.EventDocumentUploaded, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Hati imepakiwa",
+		Body:    "📄 Hati yako ya {{.document}} imepakiwa na inasubiri kuthibitishwa."},
+
+	{Event: domain.EventDocumentReviewed, Locale: domain.English, Channel: domain.System,
+		Subject: "Document reviewed",
+		Body:    "ℹ️ Your {{.document}} document has been {{.status}}."},
+	{Event: domain.EventDocumentReviewed, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Hati imekaguliwa",
+		Body:    "ℹ️ Hali ya hati yako ya {{.document}} sasa ni: {{.status}}."},
+
+	{Event: domain.EventDocumentExpiring, Locale: domain.English, Channel: domain.System,
+		Subject: "Document expiring soon",
+		Body:    "⚠️ Your {{.document}} ({{.number}}) expires in {{.days}} day(s) on {{.date}}. Please upload a renewed copy."},
+	{Event: domain.EventDocumentExpiring, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Hati inakaribia kuisha muda",
+		Body:    "⚠️ Hati yako ya {{.document}} ({{.number}}) itaisha muda baada ya siku {{.days}}, tarehe {{.date}}. Tafadhali pakia nakala mpya."},
+
+	{Event: domain.EventDocumentExpired, Locale: domain.English, Channel: domain.System,
+		Subject: "Document expired",
+		Body:    "⛔ Your {{.document}} ({{.number}}) expired on {{.date}}. You will not receive deliveries until it is renewed."},
+	{Event: domain.EventDocumentExpired, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Hati imeisha muda",
+		Body:    "⛔ Hati yako ya {{.document}} ({{.number}}) iliisha muda tarehe {{.date}}. Hutapokea usafirishaji hadi uifanye upya."},
+
+	// Orders
+	{Event: domain.EventOrderCreated, Locale: domain.English, Channel: domain.System,
+		Subject: "Order placed",
+		Body:    "Your order {{.order_id}} has been created successfully."},
+	{Event: domain.EventOrderCreated, Locale: domain.English, Channel: domain.SMS,
+		Body: "FastaBiz: your order {{.order_id}} has been received."},
+	{Event: domain.EventOrderCreated, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Agizo limewekwa",
+		Body:    "Agizo lako {{.order_id}} limeundwa."},
+	{Event: domain.EventOrderCreated, Locale: domain.Swahili, Channel: domain.SMS,
+		Body: "FastaBiz: agizo lako {{.order_id}} limepokelewa."},
+
+	{Event: domain.EventOrderAssigned, Locale: domain.English, Channel: domain.System,
+		Subject: "Driver assigned",
+		Body:    "Your order {{.order_id}} has been assigned to driver {{.driver_name}}."},
+	{Event: domain.EventOrderAssigned, Locale: domain.English, Channel: domain.SMS,
+		Body: "FastaBiz: {{.driver_name}} will deliver your order {{.order_id}}."},
+	{Event: domain.EventOrderAssigned, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Dereva amepangiwa",
+		Body:    "Agizo lako {{.order_id}} limepangiwa dereva {{.driver_name}}."},
+	{Event: domain.EventOrderAssigned, Locale: domain.Swahili, Channel: domain.SMS,
+		Body: "FastaBiz: {{.driver_name}} atawasilisha agizo lako {{.order_id}}."},
+
+	{Event: domain.EventOrderAssignedDriver, Locale: domain.English, Channel: domain.System,
+		Subject: "New delivery",
+		Body:    "You have been assigned a new delivery: order {{.order_id}}."},
+	{Event: domain.EventOrderAssignedDriver, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Usafirishaji mpya",
+		Body:    "Umepangiwa usafirishaji mpya: agizo {{.order_id}}."},
+
+	// Deliveries
+	{Event: domain.EventDeliveryUpdated, Locale: domain.English, Channel: domain.System,
+		Subject: "Delivery updated",
+		Body:    "ℹ️ Delivery for order {{.order_id}} updated: '{{.field}}' changed."},
+	{Event: domain.EventDeliveryUpdated, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Usafirishaji umesasishwa",
+		Body:    "ℹ️ Usafirishaji wa agizo {{.order_id}} umesasishwa: '{{.field}}' imebadilika."},
+
+	{Event: domain.EventDeliveryInTransit, Locale: domain.English, Channel: domain.System,
+		Subject: "Order on the way",
+		Body:    "🚚 Your order {{.order_id}} is now in transit with driver {{.driver_name}}."},
+	{Event: domain.EventDeliveryInTransit, Locale: domain.English, Channel: domain.SMS,
+		Body: "FastaBiz: your order {{.order_id}} is on the way with {{.driver_name}}."},
+	{Event: domain.EventDeliveryInTransit, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Agizo liko njiani",
+		Body:    "🚚 Agizo lako {{.order_id}} liko njiani na dereva {{.driver_name}}."},
+	{Event: domain.EventDeliveryInTransit, Locale: domain.Swahili, Channel: domain.SMS,
+		Body: "FastaBiz: agizo lako {{.order_id}} liko njiani na {{.driver_name}}."},
+
+	{Event: domain.EventDeliveryAccepted, Locale: domain.English, Channel: domain.System,
+		Subject: "Delivery accepted",
+		Body:    "✅ You have accepted delivery for order {{.order_id}}."},
+	{Event: domain.EventDeliveryAccepted, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Usafirishaji umekubaliwa",
+		Body:    "✅ Umekubali kusafirisha agizo {{.order_id}}."},
+
+	// Inventory
+	{Event: domain.EventInventoryCreated, Locale: domain.English, Channel: domain.System,
+		Subject: "Inventory added",
+		Body:    "✅ New inventory '{{.item}}' has been added with stock {{.stock}}."},
+	{Event: domain.EventInventoryCreated, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Bidhaa imeongezwa",
+		Body:    "✅ Bidhaa mpya '{{.item}}' imeongezwa ikiwa na akiba ya {{.stock}}."},
+
+	{Event: domain.EventInventoryUpdated, Locale: domain.English, Channel: domain.System,
+		Subject: "Inventory updated",
+		Body:    "ℹ️ Inventory {{.item}} updated: field '{{.field}}' changed."},
+	{Event: domain.EventInventoryUpdated, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Bidhaa imesasishwa",
+		Body:    "ℹ️ Bidhaa {{.item}} imesasishwa: '{{.field}}' imebadilika."},
+
+	{Event: domain.EventInventoryDeleted, Locale: domain.English, Channel: domain.System,
+		Subject: "Inventory deleted",
+		Body:    "🗑️ Inventory '{{.item}}' has been deleted."},
+	{Event: domain.EventInventoryDeleted, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Bidhaa imefutwa",
+		Body:    "🗑️ Bidhaa '{{.item}}' imefutwa."},
+
+	{Event: domain.EventInventoryLowStock, Locale: domain.English, Channel: domain.System,
+		Subject: "Low stock",
+		Body:    "⚠️ Inventory '{{.item}}' stock is low: only {{.stock}} left."},
+	{Event: domain.EventInventoryLowStock, Locale: domain.English, Channel: domain.SMS,
+		Body: "FastaBiz: '{{.item}}' is low on stock, {{.stock}} left."},
+	{Event: domain.EventInventoryLowStock, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Akiba ndogo",
+		Body:    "⚠️ Akiba ya bidhaa '{{.item}}' iko chini: zimebaki {{.stock}} tu."},
+	{Event: domain.EventInventoryLowStock, Locale: domain.Swahili, Channel: domain.SMS,
+		Body: "FastaBiz: akiba ya '{{.item}}' iko chini, zimebaki {{.stock}}."},
+
+	// Payments
+	{Event: domain.EventPaymentReceived, Locale: domain.English, Channel: domain.System,
+		Subject: "Payment received",
+		Body:    "✅ We received your payment of {{.amount}} for order {{.order_id}}.{{if .receipt}} Receipt: {{.receipt}}.{{end}}"},
+	{Event: domain.EventPaymentReceived, Locale: domain.English, Channel: domain.SMS,
+		Body: "FastaBiz: payment of {{.amount}} for order {{.order_id}} received.{{if .receipt}} Ref {{.receipt}}.{{end}}"},
+	{Event: domain.EventPaymentReceived, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Malipo yamepokelewa",
+		Body:    "✅ Tumepokea malipo yako ya {{.amount}} kwa agizo {{.order_id}}.{{if .receipt}} Risiti: {{.receipt}}.{{end}}"},
+	{Event: domain.EventPaymentReceived, Locale: domain.Swahili, Channel: domain.SMS,
+		Body: "FastaBiz: malipo ya {{.amount}} kwa agizo {{.order_id}} yamepokelewa.{{if .receipt}} Kumb {{.receipt}}.{{end}}"},
+
+	{Event: domain.EventPaymentFailed, Locale: domain.English, Channel: domain.System,
+		Subject: "Payment failed",
+		Body:    "⚠️ Your payment for order {{.order_id}} did not go through: {{.reason}}. Please try again."},
+	{Event: domain.EventPaymentFailed, Locale: domain.English, Channel: domain.SMS,
+		Body: "FastaBiz: payment for order {{.order_id}} failed ({{.reason}}). Please try again."},
+	{Event: domain.EventPaymentFailed, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Malipo hayakufanikiwa",
+		Body:    "⚠️ Malipo yako ya agizo {{.order_id}} hayakufanikiwa: {{.reason}}. Tafadhali jaribu tena."},
+	{Event: domain.EventPaymentFailed, Locale: domain.Swahili, Channel: domain.SMS,
+		Body: "FastaBiz: malipo ya agizo {{.order_id}} hayakufanikiwa ({{.reason}}). Tafadhali jaribu tena."},
+
+	// Integrations
+	{Event: domain.EventWebhookDisabled, Locale: domain.English, Channel: domain.System,
+		Subject: "Webhook disabled",
+		Body:    "⛔ Your webhook {{.url}} was disabled after {{.failures}} failed deliveries in a row. Fix the endpoint and re-enable it to resume deliveries."},
+	{Event: domain.EventWebhookDisabled, Locale: domain.Swahili, Channel: domain.System,
+		Subject: "Webhook imezimwa",
+		Body:    "⛔ Webhook yako {{.url}} imezimwa baada ya kushindwa kutuma mara {{.failures}} mfululizo. Rekebisha anwani kisha iwashe tena ili kuendelea kupokea."},
+}
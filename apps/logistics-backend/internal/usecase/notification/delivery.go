@@ -0,0 +1,207 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	domain "logistics-backend/internal/domain/notification"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	baseRetryDelay = time.Minute   // wait after the first failed attempt
+	maxRetryDelay  = 6 * time.Hour // cap for the doubling delay
+	sendTimeout    = 30 * time.Second
+)
+
+// DeliveryConfig tunes the delivery worker. Lease is how long a claimed notification stays
+// hidden from other workers and must be longer than sendTimeout.
+type DeliveryConfig struct {
+	BatchSize   int
+	Lease       time.Duration
+	MaxAttempts int
+}
+
+// RunDelivery periodically sends due notifications until ctx is cancelled.
+func (uc *UseCase) RunDelivery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// drain the backlog before waiting for the next tick
+			for {
+				n, err := uc.DeliverDue(ctx)
+				if err != nil {
+					log.Printf("notification delivery failed: %v", err)
+					break
+				}
+				if n < uc.delivery.BatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// DeliverDue sends one batch of pending and retryable notifications and reports how many it claimed.
+func (uc *UseCase) DeliverDue(ctx context.Context) (int, error) {
+	due, err := uc.repo.ClaimDue(ctx, uc.delivery.BatchSize, uc.delivery.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, n := range due {
+		uc.deliver(ctx, n)
+	}
+
+	return len(due), nil
+}
+
+func (uc *UseCase) deliver(ctx context.Context, n *domain.Notification) {
+	until, err := uc.hold(ctx, n)
+	if errors.Is(err, domain.ErrMuted) {
+		// the recipient's choice, not a delivery problem
+		if err := uc.repo.MarkSuppressed(ctx, n.ID, err.Error()); err != nil {
+			log.Printf("notification %s: %v", n.ID, err)
+		}
+		return
+	}
+	if err != nil {
+		uc.fail(ctx, n, err)
+		return
+	}
+	if !until.IsZero() {
+		if err := uc.repo.Defer(ctx, n.ID, until); err != nil {
+			log.Printf("notification %s: %v", n.ID, err)
+		}
+		return
+	}
+
+	ref, err := uc.send(ctx, n)
+	if err == nil {
+		var providerRef *string
+		if ref != "" {
+			providerRef = &ref
+		}
+		if err := uc.repo.MarkSent(ctx, n.ID, providerRef); err != nil {
+			log.Printf("notification %s: %v", n.ID, err)
+		}
+		return
+	}
+
+	uc.fail(ctx, n, err)
+}
+
+func (uc *UseCase) fail(ctx context.Context, n *domain.Notification, cause error) {
+	// a missing address or channel will not fix itself, so there is no point retrying
+	dead := n.Attempts >= uc.delivery.MaxAttempts ||
+		errors.Is(cause, domain.ErrNoAddress) || errors.Is(cause, domain.ErrChannelUnavailable) ||
+		errors.Is(cause, domain.ErrUndeliverable) || errors.Is(cause, domain.ErrUnknownEvent)
+	if dead {
+		log.Printf("notification %s: giving up after %d attempt(s): %v", n.ID, n.Attempts, cause)
+	}
+
+	if err := uc.repo.MarkFailed(ctx, n.ID, cause.Error(), time.Now().Add(retryDelay(n.Attempts)), dead); err != nil {
+		log.Printf("notification %s: %v", n.ID, err)
+	}
+}
+
+// HandleDeliveryReport applies a gateway receipt to the notification it was sent for. A failed
+// receipt puts the notification back in the retry queue like a failed send.
+func (uc *UseCase) HandleDeliveryReport(ctx context.Context, report *domain.DeliveryReport) error {
+	n, err := uc.repo.GetByProviderRef(ctx, report.ProviderRef)
+	if err != nil {
+		return err
+	}
+
+	switch report.Status {
+	case domain.Delivered:
+		return uc.repo.MarkDelivered(ctx, n.ID)
+	case domain.Failed:
+		// receipts can arrive late or twice; only the latest send may be failed
+		if n.Status != domain.Sent {
+			return nil
+		}
+		reason := report.Reason
+		if reason == "" {
+			reason = "rejected by carrier"
+		}
+		uc.fail(ctx, n, errors.New("delivery report: "+reason))
+	}
+	return nil
+}
+
+// hold checks a claimed notification against the recipient's current preferences. It fails with
+// ErrMuted when the channel was turned off after the notification was queued, and returns when
+// the quiet hours end if they are on. In-app and mandatory notifications are never held back.
+func (uc *UseCase) hold(ctx context.Context, n *domain.Notification) (time.Time, error) {
+	if n.Type == domain.System || n.Event.Mandatory() || n.Recipient != nil {
+		return time.Time{}, nil
+	}
+
+	settings, err := uc.prefs.GetSettings(ctx, n.UserID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not load notification preferences: %w", err)
+	}
+	if settings.Muted(n) {
+		return time.Time{}, domain.ErrMuted
+	}
+	if settings.QuietHours != nil {
+		if until, quiet := settings.QuietHours.Until(time.Now()); quiet {
+			return until, nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+func (uc *UseCase) send(ctx context.Context, n *domain.Notification) (string, error) {
+	// in-app notifications are delivered by being stored
+	if n.Type == domain.System {
+		return "", nil
+	}
+
+	to, err := uc.contact(ctx, n)
+	if err != nil {
+		return "", err
+	}
+
+	msg, err := uc.templates.Render(ctx, n, n.Type, to)
+	if err != nil {
+		return "", fmt.Errorf("render notification: %w", err)
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	return uc.sender.Send(sendCtx, n, to, msg)
+}
+
+// contact resolves who a notification goes to. Notifications without a user are addressed to
+// someone who has no account yet, such as an invitee, and use the default locale.
+func (uc *UseCase) contact(ctx context.Context, n *domain.Notification) (*domain.Contact, error) {
+	if n.UserID == uuid.Nil && n.Recipient != nil {
+		return &domain.Contact{Email: *n.Recipient, Locale: domain.DefaultLocale}, nil
+	}
+
+	to, err := uc.contacts.GetContact(ctx, n.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve recipient: %w", err)
+	}
+	return to, nil
+}
+
+// retryDelay doubles the wait with every failed attempt, up to maxRetryDelay.
+func retryDelay(attempts int) time.Duration {
+	d := baseRetryDelay
+	for i := 1; i < attempts && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	return min(d, maxRetryDelay)
+}
@@ -0,0 +1,112 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"logistics-backend/internal/domain/event"
+	"logistics-backend/internal/domain/money"
+	domain "logistics-backend/internal/domain/notification"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// Events lists the domain events HandleEvent turns into notifications.
+var Events = []event.Name{
+	event.NameOrderCreated,
+	event.NameOrderAssigned,
+	event.NameDeliveryAccepted,
+	event.NameDeliveryUpdated,
+	event.NameInventoryCreated,
+	event.NameInventoryUpdated,
+	event.NameInventoryDeleted,
+	event.NameStockLow,
+	event.NamePaymentCompleted,
+	event.NamePaymentFailed,
+}
+
+// HandleEvent notifies the people a domain event concerns. Each notification's ID is derived
+// from the event ID and the template event, so handling an event again creates nothing new.
+func (uc *UseCase) HandleEvent(ctx context.Context, env *event.Envelope) error {
+	var out []*domain.Notification
+	add := func(userID uuid.UUID, e domain.Event, data domain.TemplateData) {
+		out = append(out, &domain.Notification{
+			ID:     uuid.NewSHA1(env.ID, []byte(e)),
+			UserID: userID,
+			Event:  e,
+			Data:   data,
+			Type:   domain.System,
+			Status: domain.Pending,
+		})
+	}
+
+	switch e := env.Event.(type) {
+	case event.OrderCreated:
+		add(e.CustomerID, domain.EventOrderCreated, domain.TemplateData{"order_id": e.OrderID.String()})
+
+	case event.OrderAssigned:
+		driver, err := uc.contacts.GetContact(ctx, e.DriverID)
+		if err != nil {
+			return fmt.Errorf("could not fetch assigned driver: %w", err)
+		}
+		add(e.CustomerID, domain.EventOrderAssigned, domain.TemplateData{
+			"order_id":    e.OrderID.String(),
+			"driver_name": driver.Name,
+		})
+		add(e.DriverID, domain.EventOrderAssignedDriver, domain.TemplateData{"order_id": e.OrderID.String()})
+
+	case event.DeliveryAccepted:
+		add(e.CustomerID, domain.EventDeliveryInTransit, domain.TemplateData{
+			"order_id":    e.OrderID.String(),
+			"driver_name": e.DriverName,
+		})
+		add(e.DriverID, domain.EventDeliveryAccepted, domain.TemplateData{"order_id": e.OrderID.String()})
+
+	case event.DeliveryUpdated:
+		add(e.DriverID, domain.EventDeliveryUpdated, domain.TemplateData{
+			"order_id": e.OrderID.String(),
+			"field":    e.Field,
+		})
+
+	case event.InventoryCreated:
+		add(e.OwnerID, domain.EventInventoryCreated, domain.TemplateData{
+			"item":  e.Item,
+			"stock": strconv.Itoa(e.Stock),
+		})
+
+	case event.InventoryUpdated:
+		add(e.OwnerID, domain.EventInventoryUpdated, domain.TemplateData{
+			"item":  e.Item,
+			"field": e.Field,
+		})
+
+	case event.InventoryDeleted:
+		add(e.OwnerID, domain.EventInventoryDeleted, domain.TemplateData{"item": e.Item})
+
+	case event.StockLow:
+		add(e.OwnerID, domain.EventInventoryLowStock, domain.TemplateData{
+			"item":  e.Item,
+			"stock": strconv.Itoa(e.Stock),
+		})
+
+	case event.PaymentCompleted:
+		add(e.CustomerID, domain.EventPaymentReceived, domain.TemplateData{
+			"order_id": e.OrderID.String(),
+			"amount":   money.FromCents(e.Amount, e.Currency).String(),
+			"receipt":  e.Receipt,
+		})
+
+	case event.PaymentFailed:
+		add(e.CustomerID, domain.EventPaymentFailed, domain.TemplateData{
+			"order_id": e.OrderID.String(),
+			"reason":   e.Reason,
+		})
+	}
+
+	for _, n := range out {
+		if err := uc.createOnce(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	domain "logistics-backend/internal/domain/notification"
+	"slices"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+
+	"github.com/google/uuid"
+)
+
+type templateKey struct {
+	event   domain.Event
+	locale  string
+	channel domain.NotificationType
+}
+
+// compiled is a parsed Template, ready to execute.
+type compiled struct {
+	src     *domain.Template
+	subject *texttemplate.Template
+	body    *texttemplate.Template
+	html    *htmltemplate.Template // nil when the template has no HTML part
+}
+
+// Templates renders notifications from the built-in templates, with a store owner's overrides
+// from the database taking precedence for that owner's users. Overrides are read on every render
+// so all instances see an edit immediately.
+type Templates struct {
+	builtin   map[templateKey]*compiled
+	overrides domain.TemplateRepository
+}
+
+// NewTemplates compiles and validates the built-in templates; a template using an undeclared
+// variable or an event without a default-locale in-app template is a startup error.
+func NewTemplates(overrides domain.TemplateRepository) (*Templates, error) {
+	t := &Templates{builtin: make(map[templateKey]*compiled, len(builtinTemplates)), overrides: overrides}
+
+	for i := range builtinTemplates {
+		src := &builtinTemplates[i]
+		c, err := compile(src)
+		if err != nil {
+			return nil, err
+		}
+		key := templateKey{src.Event, src.Locale, src.Channel}
+		if _, dup := t.builtin[key]; dup {
+			return nil, fmt.Errorf("%w: duplicate built-in template %s/%s/%s", domain.ErrInvalidTemplate, src.Event, src.Locale, src.Channel)
+		}
+		t.builtin[key] = c
+	}
+
+	for event := range domain.EventVars {
+		if _, ok := t.builtin[templateKey{event, domain.DefaultLocale, domain.System}]; !ok {
+			return nil, fmt.Errorf("%w: %s has no %s %s template", domain.ErrInvalidTemplate, event, domain.DefaultLocale, domain.System)
+		}
+	}
+
+	return t, nil
+}
+
+// Render renders n for channel in the locale of to, using the overrides of to's tenant. Missing
+// variants fall back to the in-app text, then to DefaultLocale. Free-text notifications without
+// an event are sent as they are.
+func (t *Templates) Render(ctx context.Context, n *domain.Notification, channel domain.NotificationType, to *domain.Contact) (*domain.Message, error) {
+	if n.Event == "" {
+		return &domain.Message{Subject: "Notification", Text: n.Message}, nil
+	}
+
+	var overrides []*domain.Template
+	if to.Tenant != nil {
+		var err error
+		if overrides, err = t.overrides.ListOverridesForEvent(ctx, *to.Tenant, n.Event); err != nil {
+			return nil, err
+		}
+	}
+
+	c := t.lookup(n.Event, to.Locale, channel, overrides)
+	if c == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnknownEvent, n.Event)
+	}
+
+	return c.execute(n.Data)
+}
+
+func (t *Templates) lookup(event domain.Event, locale string, channel domain.NotificationType, overrides []*domain.Template) *compiled {
+	for _, l := range fallbacks(locale, domain.DefaultLocale) {
+		for _, ch := range fallbacks(channel, domain.System) {
+			for _, o := range overrides {
+				if o.Locale != l || o.Channel != ch {
+					continue
+				}
+				c, err := compile(o)
+				if err != nil {
+					// overrides are validated when saved, so this only happens after a variable is retired
+					log.Printf("notification template override %s/%s/%s ignored: %v", o.Event, o.Locale, o.Channel, err)
+					break
+				}
+				return c
+			}
+			if c, ok := t.builtin[templateKey{event, l, ch}]; ok {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// List returns the effective template for ownerID's users for every event, locale and channel
+// that has one.
+func (t *Templates) List(ctx context.Context, ownerID uuid.UUID) ([]*domain.Template, error) {
+	overrides, err := t.overrides.ListOverrides(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	effective := make(map[templateKey]*domain.Template, len(t.builtin)+len(overrides))
+	for key, c := range t.builtin {
+		effective[key] = c.src
+	}
+	for _, o := range overrides {
+		o.Override = true
+		effective[templateKey{o.Event, o.Locale, o.Channel}] = o
+	}
+
+	list := make([]*domain.Template, 0, len(effective))
+	for _, tpl := range effective {
+		list = append(list, tpl)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Event != b.Event {
+			return a.Event < b.Event
+		}
+		if a.Locale != b.Locale {
+			return a.Locale < b.Locale
+		}
+		return a.Channel < b.Channel
+	})
+	return list, nil
+}
+
+// Validate checks a template the way the built-in ones are checked at startup.
+func (t *Templates) Validate(tpl *domain.Template) error {
+	_, err := compile(tpl)
+	return err
+}
+
+func compile(tpl *domain.Template) (*compiled, error) {
+	name := fmt.Sprintf("%s/%s/%s", tpl.Event, tpl.Locale, tpl.Channel)
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s: %s", domain.ErrInvalidTemplate, name, fmt.Sprintf(format, args...))
+	}
+
+	vars, ok := domain.EventVars[tpl.Event]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnknownEvent, tpl.Event)
+	}
+	if !domain.IsSupportedLocale(tpl.Locale) {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnsupportedLocale, tpl.Locale)
+	}
+	switch tpl.Channel {
+	case domain.System, domain.Email, domain.Push:
+		if strings.TrimSpace(tpl.Subject) == "" {
+			return nil, invalid("subject is required")
+		}
+	case domain.SMS:
+	default:
+		return nil, invalid("unknown channel %q", tpl.Channel)
+	}
+	if strings.TrimSpace(tpl.Body) == "" {
+		return nil, invalid("body is required")
+	}
+	if tpl.HTML != "" && tpl.Channel != domain.Email {
+		return nil, invalid("only email templates have an HTML part")
+	}
+
+	c := &compiled{src: tpl}
+	var err error
+	if c.subject, err = parseText(name+"/subject", tpl.Subject, vars); err != nil {
+		return nil, invalid("subject: %v", err)
+	}
+	if c.body, err = parseText(name+"/body", tpl.Body, vars); err != nil {
+		return nil, invalid("body: %v", err)
+	}
+	if tpl.HTML != "" {
+		h, err := htmltemplate.New(name + "/html").Option("missingkey=zero").Parse(tpl.HTML)
+		if err == nil {
+			err = checkTree(h.Tree, len(h.Templates()), vars)
+		}
+		if err != nil {
+			return nil, invalid("html: %v", err)
+		}
+		c.html = h
+	}
+
+	// a dry run catches what parsing cannot, e.g. calling a builtin with the wrong arguments
+	sample := make(domain.TemplateData, len(vars))
+	for _, v := range vars {
+		sample[v] = v
+	}
+	if _, err := c.execute(sample); err != nil {
+		return nil, invalid("%v", err)
+	}
+
+	return c, nil
+}
+
+func parseText(name, src string, vars []string) (*texttemplate.Template, error) {
+	t, err := texttemplate.New(name).Option("missingkey=zero").Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return t, checkTree(t.Tree, len(t.Templates()), vars)
+}
+
+// checkTree allows plain {{.var}} output and {{if}} on the event's variables only.
+func checkTree(tree *parse.Tree, templates int, vars []string) error {
+	if templates > 1 {
+		return fmt.Errorf("{{define}} is not supported")
+	}
+	if tree == nil {
+		return nil
+	}
+	return checkNode(tree.Root, vars)
+}
+
+func checkNode(node parse.Node, vars []string) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkNode(child, vars); err != nil {
+				return err
+			}
+		}
+	case *parse.TextNode, *parse.CommentNode:
+	case *parse.ActionNode:
+		return checkNode(n.Pipe, vars)
+	case *parse.IfNode:
+		for _, child := range []parse.Node{n.Pipe, n.List, n.ElseList} {
+			if err := checkNode(child, vars); err != nil {
+				return err
+			}
+		}
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		if len(n.Decl) > 0 {
+			return fmt.Errorf("variables are not supported")
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if err := checkNode(arg, vars); err != nil {
+					return err
+				}
+			}
+		}
+	case *parse.FieldNode:
+		if len(n.Ident) != 1 || !slices.Contains(vars, n.Ident[0]) {
+			return fmt.Errorf("unknown variable {{%s}}, allowed: %s", n, strings.Join(vars, ", "))
+		}
+	case *parse.IdentifierNode, *parse.StringNode, *parse.NumberNode, *parse.BoolNode:
+	default:
+		return fmt.Errorf("%q is not supported", node)
+	}
+	return nil
+}
+
+func (c *compiled) execute(data domain.TemplateData) (*domain.Message, error) {
+	var subject, body bytes.Buffer
+	if err := c.subject.Execute(&subject, data); err != nil {
+		return nil, err
+	}
+	if err := c.body.Execute(&body, data); err != nil {
+		return nil, err
+	}
+
+	msg := &domain.Message{Subject: strings.TrimSpace(subject.String()), Text: strings.TrimSpace(body.String())}
+	if c.html != nil {
+		var html bytes.Buffer
+		if err := c.html.Execute(&html, data); err != nil {
+			return nil, err
+		}
+		msg.HTML = html.String()
+	}
+	return msg, nil
+}
+
+// fallbacks is the lookup order: the requested value, then the fallback.
+func fallbacks[T ~string](first, fallback T) []T {
+	if first == "" || first == fallback {
+		return []T{fallback}
+	}
+	return []T{first, fallback}
+}
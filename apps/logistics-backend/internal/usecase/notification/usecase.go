@@ -2,6 +2,7 @@ package notification
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	domain "logistics-backend/internal/domain/notification"
 	"logistics-backend/internal/usecase/common"
@@ -12,25 +13,138 @@ import (
 type UseCase struct {
 	repo      domain.Repository
 	txManager common.TxManager
+	contacts  domain.ContactReader
+	sender    domain.Sender
+	templates *Templates
+	prefs     domain.PreferenceRepository
+	delivery  DeliveryConfig
 }
 
-func NewUseCase(repo domain.Repository, txm common.TxManager) *UseCase {
-	return &UseCase{repo: repo, txManager: txm}
+func NewUseCase(repo domain.Repository, txm common.TxManager, contacts domain.ContactReader, sender domain.Sender, templates *Templates, prefs domain.PreferenceRepository, delivery DeliveryConfig) *UseCase {
+	return &UseCase{repo: repo, txManager: txm, contacts: contacts, sender: sender, templates: templates, prefs: prefs, delivery: delivery}
 }
 
 func (uc *UseCase) CreateNotification(ctx context.Context, n *domain.Notification) error {
+	if err := uc.renderInApp(ctx, n); err != nil {
+		return err
+	}
+
+	routed, err := uc.route(ctx, n)
+	if err != nil {
+		return err
+	}
+
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
-		if err := uc.repo.Create(txCtx, n); err != nil {
-			return fmt.Errorf("create notification failed: %w", err)
+		for _, r := range routed {
+			if err := uc.repo.Create(txCtx, r); err != nil {
+				return fmt.Errorf("create notification failed: %w", err)
+			}
 		}
 
 		return nil
 	})
 }
 
-func (uc *UseCase) UpdateNotificationStatus(ctx context.Context, id uuid.UUID, status domain.NotificationStatus) error {
+// CreateNotificationFor creates a notification an admin wrote for a user within their tenant.
+func (uc *UseCase) CreateNotificationFor(ctx context.Context, n *domain.Notification, adminID uuid.UUID) error {
+	ok, err := uc.contacts.BelongsToOwner(ctx, n.UserID, adminID)
+	if err != nil {
+		return fmt.Errorf("could not check recipient tenant: %w", err)
+	}
+	if !ok {
+		return domain.ErrRecipientNotFound
+	}
+
+	return uc.CreateNotification(ctx, n)
+}
+
+// CreateFromOutbox handles outbox.TopicNotificationCreate messages. The notification takes the
+// message ID, so a message dispatched twice still yields one notification.
+func (uc *UseCase) CreateFromOutbox(ctx context.Context, id uuid.UUID, payload []byte) error {
+	var n domain.Notification
+	if err := json.Unmarshal(payload, &n); err != nil {
+		return fmt.Errorf("decode queued notification: %w", err)
+	}
+
+	n.ID = id
+	return uc.createOnce(ctx, &n)
+}
+
+// createOnce stores a notification with a caller-chosen ID, doing nothing for copies that
+// already exist, so a queued message or event handled twice notifies once.
+func (uc *UseCase) createOnce(ctx context.Context, n *domain.Notification) error {
+	if n.Status == "" {
+		n.Status = domain.Pending
+	}
+	if err := uc.renderInApp(ctx, n); err != nil {
+		return err
+	}
+
+	routed, err := uc.route(ctx, n)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range routed {
+		if err := uc.repo.CreateIfAbsent(ctx, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// route applies the user's preferences to a new notification: it is dropped when its channel is
+// turned off and copied to every extra channel turned on for its event. Copies of a notification
+// with an ID get IDs derived from it, so a redelivered outbox message creates the same rows.
+func (uc *UseCase) route(ctx context.Context, n *domain.Notification) ([]*domain.Notification, error) {
+	// free text has no event to choose by, and a notification for someone else's address
+	// (e.g. an invitee) is not the user's to mute
+	if n.Event == "" || n.Recipient != nil {
+		return []*domain.Notification{n}, nil
+	}
+
+	settings, err := uc.prefs.GetSettings(ctx, n.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load notification preferences: %w", err)
+	}
+
+	var routed []*domain.Notification
+	for _, ch := range settings.Channels(n.Event, n.Type) {
+		c := *n
+		if ch != n.Type {
+			c.Type = ch
+			if n.ID != uuid.Nil {
+				c.ID = uuid.NewSHA1(n.ID, []byte(ch))
+			}
+		}
+		routed = append(routed, &c)
+	}
+	return routed, nil
+}
+
+// renderInApp fills in the inbox text of an event notification in the recipient's locale.
+func (uc *UseCase) renderInApp(ctx context.Context, n *domain.Notification) error {
+	if n.Event == "" || n.Message != "" {
+		return nil
+	}
+
+	to, err := uc.contact(ctx, n)
+	if err != nil {
+		return err
+	}
+
+	msg, err := uc.templates.Render(ctx, n, domain.System, to)
+	if err != nil {
+		return fmt.Errorf("render notification: %w", err)
+	}
+	n.Message = msg.Text
+	return nil
+}
+
+// UpdateNotificationStatus changes the status of a notification to a user within the admin's tenant.
+func (uc *UseCase) UpdateNotificationStatus(ctx context.Context, id, adminID uuid.UUID, status domain.NotificationStatus) error {
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
-		if err := uc.repo.UpdateStatus(txCtx, id, status); err != nil {
+		if err := uc.repo.UpdateStatus(txCtx, id, adminID, status); err != nil {
 			return fmt.Errorf("update notification failed: %w", err)
 		}
 
@@ -38,35 +152,117 @@ func (uc *UseCase) UpdateNotificationStatus(ctx context.Context, id uuid.UUID, s
 	})
 }
 
-func (uc *UseCase) MarkAsRead(ctx context.Context, id uuid.UUID) error {
-	return uc.repo.UpdateStatus(ctx, id, domain.Read)
+// MarkAsRead marks one of the user's notifications read.
+func (uc *UseCase) MarkAsRead(ctx context.Context, userID, id uuid.UUID) error {
+	n, err := uc.repo.MarkRead(ctx, userID, []uuid.UUID{id})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotificationNotFound
+	}
+	return nil
 }
 
 func (uc *UseCase) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
 	return uc.repo.UpdateAllAsRead(ctx, userID)
 }
 
-func (uc *UseCase) ListPendingNotifications(ctx context.Context) ([]*domain.Notification, error) {
-	return uc.repo.ListPending(ctx)
+// ListPendingNotifications lists the not yet sent notifications of ownerID's tenant.
+func (uc *UseCase) ListPendingNotifications(ctx context.Context, ownerID uuid.UUID) ([]*domain.Notification, error) {
+	return uc.repo.ListPending(ctx, ownerID)
 }
 
 func (uc *UseCase) ListNotificationsByCustomer(ctx context.Context, userID uuid.UUID, status domain.NotificationStatus) ([]*domain.Notification, error) {
-	return uc.repo.ListByUserAndStatus(ctx, userID, domain.Sent)
+	return uc.repo.ListByUserAndStatus(ctx, userID, status)
+}
+
+// ListInbox returns a page of the user's inbox, newest first.
+func (uc *UseCase) ListInbox(ctx context.Context, userID uuid.UUID, filter domain.InboxFilter) (*domain.InboxPage, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	// one extra row tells whether another page follows
+	limit := filter.Limit
+	filter.Limit++
+	items, err := uc.repo.ListInbox(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.InboxPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = (&domain.InboxCursor{CreatedAt: last.CreatedAt, ID: last.ID}).Encode()
+	}
+	if page.Items == nil {
+		page.Items = []*domain.Notification{}
+	}
+	return page, nil
+}
+
+// CountUnread is the user's unread badge count, for one channel or all when typ is empty.
+func (uc *UseCase) CountUnread(ctx context.Context, userID uuid.UUID, typ domain.NotificationType) (int, error) {
+	return uc.repo.CountUnread(ctx, userID, typ)
+}
+
+// MarkRead marks the given notifications read; ids that are not the user's are skipped.
+func (uc *UseCase) MarkRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, domain.ErrNoIDs
+	}
+	return uc.repo.MarkRead(ctx, userID, ids)
+}
+
+// Archive hides the given notifications from the inbox; ids that are not the user's are skipped.
+func (uc *UseCase) Archive(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, domain.ErrNoIDs
+	}
+	return uc.repo.Archive(ctx, userID, ids)
+}
+
+func (uc *UseCase) DeleteNotification(ctx context.Context, userID, id uuid.UUID) error {
+	return uc.repo.Delete(ctx, userID, id)
 }
 
-func (uc *UseCase) SendNotification(ctx context.Context, n *domain.Notification, sender domain.Sender) error {
-	if err := uc.repo.Create(ctx, n); err != nil {
+// GetPreferences returns the user's notification preferences and quiet hours.
+func (uc *UseCase) GetPreferences(ctx context.Context, userID uuid.UUID) (*domain.Settings, error) {
+	return uc.prefs.GetSettings(ctx, userID)
+}
+
+// SavePreferences replaces the user's notification preferences and quiet hours.
+func (uc *UseCase) SavePreferences(ctx context.Context, userID uuid.UUID, s *domain.Settings) error {
+	if err := s.Validate(); err != nil {
 		return err
 	}
 
-	if err := sender.Send(ctx, n); err != nil {
-		_ = uc.repo.UpdateStatus(ctx, n.ID, domain.Failed)
+	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		return uc.prefs.ReplaceSettings(txCtx, userID, s)
+	})
+}
+
+// ListTemplates returns the effective template for the admin's users for every event, locale and
+// channel.
+func (uc *UseCase) ListTemplates(ctx context.Context, adminID uuid.UUID) ([]*domain.Template, error) {
+	return uc.templates.List(ctx, adminID)
+}
+
+// SaveTemplate stores an admin override after checking it like the built-in templates. The
+// override only applies to notifications for the admin's own users.
+func (uc *UseCase) SaveTemplate(ctx context.Context, t *domain.Template, adminID uuid.UUID) error {
+	if err := uc.templates.Validate(t); err != nil {
 		return err
 	}
 
-	return uc.repo.UpdateStatus(ctx, n.ID, domain.Sent)
+	t.OwnerID = adminID
+	t.UpdatedBy = &adminID
+	return uc.templates.overrides.UpsertOverride(ctx, t)
 }
 
-// TODO: Implement actual notification sending via external services (e.g., Twilio, SendGrid).
-// The system should select the appropriate sender based on Notification.Type (email, sms, push)
-// and update the notification status accordingly.
+// DeleteTemplate removes the admin's override, restoring the built-in template for their users.
+func (uc *UseCase) DeleteTemplate(ctx context.Context, event domain.Event, locale string, channel domain.NotificationType, adminID uuid.UUID) error {
+	return uc.templates.overrides.DeleteOverride(ctx, adminID, event, locale, channel)
+}
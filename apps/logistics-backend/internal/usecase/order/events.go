@@ -0,0 +1,63 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"logistics-backend/internal/domain/event"
+	"logistics-backend/internal/domain/order"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Events lists the domain events HandleEvent reacts to.
+var Events = []event.Name{
+	event.NamePaymentFailed,
+	event.NamePaymentRefunded,
+}
+
+// HandleEvent keeps orders in step with their payments: a failed payment cancels an order that
+// is still pending, and a full refund cancels one that has not left the store. Partial refunds
+// leave the order alone.
+func (uc *UseCase) HandleEvent(ctx context.Context, env *event.Envelope) error {
+	switch e := env.Event.(type) {
+	case event.PaymentFailed:
+		return uc.cancel(ctx, e.OrderID, order.Pending)
+	case event.PaymentRefunded:
+		if e.Full {
+			return uc.cancel(ctx, e.OrderID, order.Pending, order.Assigned)
+		}
+	}
+	return nil
+}
+
+// cancel cancels the order when it is in one of the given statuses and puts its quantity back
+// in stock. Orders in any other status, including already cancelled ones, are left as they are.
+// The order stays locked until the stock is back, so a redelivered event or a concurrent status
+// change cannot restock it twice or cancel an order that has just left the store.
+func (uc *UseCase) cancel(ctx context.Context, orderID uuid.UUID, from ...order.OrderStatus) error {
+	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		o, err := uc.repo.GetForUpdate(txCtx, orderID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return fmt.Errorf("could not fetch order: %w", err)
+		}
+		if !slices.Contains(from, o.Status) {
+			return nil
+		}
+
+		if err := uc.repo.Update(txCtx, o.ID, "status", order.Cancelled); err != nil {
+			return fmt.Errorf("could not cancel order: %w", err)
+		}
+
+		if err := uc.invRepo.RestockInventory(txCtx, o.InventoryID, o.Quantity); err != nil {
+			return fmt.Errorf("could not restore inventory stock: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,128 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"logistics-backend/internal/domain/event"
+	"logistics-backend/internal/domain/order"
+
+	"github.com/google/uuid"
+)
+
+type noTx struct{}
+
+func (noTx) Do(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) }
+
+type orderRepo struct {
+	order.Repository
+	orders map[uuid.UUID]*order.Order
+	locked int
+}
+
+func (r *orderRepo) GetForUpdate(_ context.Context, id uuid.UUID) (*order.Order, error) {
+	o, ok := r.orders[id]
+	if !ok {
+		return nil, fmt.Errorf("get order for update: %w", sql.ErrNoRows)
+	}
+	r.locked++
+	locked := *o
+	return &locked, nil
+}
+
+func (r *orderRepo) Update(_ context.Context, id uuid.UUID, column string, value any) error {
+	if column != "status" {
+		return fmt.Errorf("unexpected column %s", column)
+	}
+	r.orders[id].Status = value.(order.OrderStatus)
+	return nil
+}
+
+type stock struct {
+	order.InventoryReader
+	restocked map[uuid.UUID]int
+}
+
+func (s *stock) RestockInventory(_ context.Context, id uuid.UUID, quantity int) error {
+	s.restocked[id] += quantity
+	return nil
+}
+
+func TestPaymentEventsCancelOrders(t *testing.T) {
+	cases := []struct {
+		name      string
+		status    order.OrderStatus
+		event     event.Event
+		want      order.OrderStatus
+		restocked int
+	}{
+		{"failed payment cancels a pending order", order.Pending, event.PaymentFailed{}, order.Cancelled, 4},
+		{"failed payment leaves an assigned order", order.Assigned, event.PaymentFailed{}, order.Assigned, 0},
+		{"full refund cancels a pending order", order.Pending, event.PaymentRefunded{Full: true}, order.Cancelled, 4},
+		{"full refund cancels an assigned order", order.Assigned, event.PaymentRefunded{Full: true}, order.Cancelled, 4},
+		{"full refund leaves an order in transit", order.InTransit, event.PaymentRefunded{Full: true}, order.InTransit, 0},
+		{"full refund leaves a delivered order", order.Delivered, event.PaymentRefunded{Full: true}, order.Delivered, 0},
+		{"partial refund leaves the order", order.Pending, event.PaymentRefunded{}, order.Pending, 0},
+		{"cancelled order is not restocked again", order.Cancelled, event.PaymentRefunded{Full: true}, order.Cancelled, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			o := &order.Order{ID: uuid.New(), InventoryID: uuid.New(), Quantity: 4, Status: c.status}
+			repo := &orderRepo{orders: map[uuid.UUID]*order.Order{o.ID: o}}
+			inv := &stock{restocked: map[uuid.UUID]int{}}
+			uc := NewUseCase(repo, inv, nil, noTx{}, nil, nil)
+
+			var e event.Event
+			switch v := c.event.(type) {
+			case event.PaymentFailed:
+				v.OrderID = o.ID
+				e = v
+			case event.PaymentRefunded:
+				v.OrderID = o.ID
+				e = v
+			}
+			if err := uc.HandleEvent(context.Background(), &event.Envelope{Event: e}); err != nil {
+				t.Fatal(err)
+			}
+
+			if o.Status != c.want {
+				t.Errorf("status = %s, want %s", o.Status, c.want)
+			}
+			if got := inv.restocked[o.InventoryID]; got != c.restocked {
+				t.Errorf("restocked %d, want %d", got, c.restocked)
+			}
+			if c.restocked > 0 && repo.locked == 0 {
+				t.Error("order was cancelled without being locked")
+			}
+		})
+	}
+}
+
+func TestRedeliveredRefundRestocksOnce(t *testing.T) {
+	o := &order.Order{ID: uuid.New(), InventoryID: uuid.New(), Quantity: 2, Status: order.Pending}
+	repo := &orderRepo{orders: map[uuid.UUID]*order.Order{o.ID: o}}
+	inv := &stock{restocked: map[uuid.UUID]int{}}
+	uc := NewUseCase(repo, inv, nil, noTx{}, nil, nil)
+
+	env := &event.Envelope{Event: event.PaymentRefunded{OrderID: o.ID, Full: true}}
+	for range 2 {
+		if err := uc.HandleEvent(context.Background(), env); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := inv.restocked[o.InventoryID]; got != 2 {
+		t.Errorf("restocked %d, want 2", got)
+	}
+}
+
+func TestCancelIgnoresMissingOrder(t *testing.T) {
+	uc := NewUseCase(&orderRepo{orders: map[uuid.UUID]*order.Order{}}, &stock{restocked: map[uuid.UUID]int{}}, nil, noTx{}, nil, nil)
+
+	env := &event.Envelope{Event: event.PaymentFailed{OrderID: uuid.New()}}
+	if err := uc.HandleEvent(context.Background(), env); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"logistics-backend/internal/domain/driver"
-	"logistics-backend/internal/domain/notification"
+	"logistics-backend/internal/domain/event"
 	"logistics-backend/internal/domain/order"
 	"logistics-backend/internal/usecase/common"
 
@@ -18,15 +18,17 @@ type UseCase struct {
 	usrRepo   order.CustomerReader
 	drvRepo   order.DriverReader
 	txManager common.TxManager
-	notfRepo  order.NotificationReader
+	events    order.EventPublisher
 	storeRepo order.StoreReader
 }
 
-func NewUseCase(repo order.Repository, invRepo order.InventoryReader, usrRepo order.CustomerReader, txm common.TxManager, notf order.NotificationReader, str order.StoreReader) *UseCase {
-	return &UseCase{repo: repo, invRepo: invRepo, usrRepo: usrRepo, txManager: txm, notfRepo: notf, storeRepo: str}
+func NewUseCase(repo order.Repository, invRepo order.InventoryReader, usrRepo order.CustomerReader, txm common.TxManager, events order.EventPublisher, str order.StoreReader) *UseCase {
+	return &UseCase{repo: repo, invRepo: invRepo, usrRepo: usrRepo, txManager: txm, events: events, storeRepo: str}
 }
 
-func (uc *UseCase) CreateOrder(ctx context.Context, o *order.Order) (err error) {
+// CreateOrder places an order against the admin's inventory. keyStore is the store an API key is
+// bound to, nil for user sessions; a key may only order from its own store.
+func (uc *UseCase) CreateOrder(ctx context.Context, o *order.Order, keyStore *uuid.UUID) (err error) {
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
 		// 1. check order quantity
 		if o.Quantity <= 0 {
@@ -38,12 +40,18 @@ func (uc *UseCase) CreateOrder(ctx context.Context, o *order.Order) (err error)
 		if err != nil {
 			return fmt.Errorf("could not fetch inventory: %w", err)
 		}
+		if keyStore != nil && inv.StoreID != *keyStore {
+			return order.ErrorInventoryNotFound
+		}
 
-		// get store
+		// get store; orders may only be placed against the admin's own inventory
 		store, err := uc.storeRepo.GetByID(txCtx, inv.StoreID)
 		if err != nil {
 			return fmt.Errorf("could not fetch store: %w", err)
 		}
+		if store.OwnerID != o.AdminID {
+			return order.ErrorInventoryNotFound
+		}
 
 		// 3. check available stock
 		if inv.Stock < o.Quantity {
@@ -62,20 +70,30 @@ func (uc *UseCase) CreateOrder(ctx context.Context, o *order.Order) (err error)
 			return fmt.Errorf("could not create order: %w", err)
 		}
 
-		// 6. Fire notifications (async, after commit)
-		go func() {
-			// a. Notify customer
-			msgCustomer := fmt.Sprintf("Your order %s has been created successfully.", o.ID)
-			_ = uc.notify(ctx, o.CustomerID, msgCustomer)
-
-			// b. Notify admin if stock low
-			if newStock <= 5 { // example threshold
-				msgAdmin := fmt.Sprintf("⚠️ Inventory '%s' stock is low: only %d left.", inv.Category, newStock)
-				_ = uc.notify(ctx, store.OwnerID, msgAdmin)
-			}
-		}()
+		// 6. Publish events (subscribers only see them if the order commits)
+		events := []event.Event{event.OrderCreated{
+			OrderID:         o.ID,
+			StoreID:         store.ID,
+			AdminID:         o.AdminID,
+			CustomerID:      o.CustomerID,
+			InventoryID:     inv.ID,
+			Quantity:        o.Quantity,
+			PickupAddress:   o.PickupAddress,
+			DeliveryAddress: o.DeliveryAddress,
+		}}
+
+		// Alert the admin if stock is low
+		if newStock <= 5 { // example threshold
+			events = append(events, event.StockLow{
+				InventoryID: inv.ID,
+				StoreID:     store.ID,
+				OwnerID:     store.OwnerID,
+				Item:        inv.Category,
+				Stock:       newStock,
+			})
+		}
 
-		return nil
+		return uc.events.Publish(txCtx, events...)
 	})
 }
 
@@ -83,6 +101,76 @@ func (uc *UseCase) GetOrder(ctx context.Context, id uuid.UUID) (*order.Order, er
 	return uc.repo.GetByID(ctx, id)
 }
 
+// GetOrderForAdmin returns the order only when it belongs to adminID, and to keyStore when an
+// API key bound to a store asks.
+func (uc *UseCase) GetOrderForAdmin(ctx context.Context, id, adminID uuid.UUID, keyStore *uuid.UUID) (*order.Order, error) {
+	o, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch order: %w", err)
+	}
+	if o.AdminID != adminID {
+		return nil, order.ErrorNotFound
+	}
+
+	if keyStore != nil {
+		ok, err := uc.inStore(ctx, o, *keyStore, nil)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, order.ErrorNotFound
+		}
+	}
+
+	return o, nil
+}
+
+// GetAdminOrdersByCustomer lists a customer's orders placed with adminID only, and with
+// keyStore only when an API key asks.
+func (uc *UseCase) GetAdminOrdersByCustomer(ctx context.Context, customerID, adminID uuid.UUID, keyStore *uuid.UUID) ([]*order.Order, error) {
+	orders, err := uc.repo.ListByCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	stores := make(map[uuid.UUID]uuid.UUID)
+	owned := make([]*order.Order, 0, len(orders))
+	for _, o := range orders {
+		if o.AdminID != adminID {
+			continue
+		}
+		if keyStore != nil {
+			ok, err := uc.inStore(ctx, o, *keyStore, stores)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		owned = append(owned, o)
+	}
+
+	return owned, nil
+}
+
+// inStore reports whether the order's inventory is stocked by storeID. stores caches the store of
+// each inventory looked up so far and may be nil.
+func (uc *UseCase) inStore(ctx context.Context, o *order.Order, storeID uuid.UUID, stores map[uuid.UUID]uuid.UUID) (bool, error) {
+	invStore, ok := stores[o.InventoryID]
+	if !ok {
+		inv, err := uc.invRepo.GetInventoryByID(ctx, o.InventoryID)
+		if err != nil {
+			return false, fmt.Errorf("could not fetch inventory: %w", err)
+		}
+		invStore = inv.StoreID
+		if stores != nil {
+			stores[o.InventoryID] = invStore
+		}
+	}
+	return invStore == storeID, nil
+}
+
 func (uc *UseCase) GetOrderByCustomer(ctx context.Context, customerID uuid.UUID) ([]*order.Order, error) {
 	return uc.repo.ListByCustomer(ctx, customerID)
 }
@@ -97,12 +185,17 @@ func (uc *UseCase) UpdateOrder(ctx context.Context, orderID uuid.UUID, column st
 	})
 }
 
-func (uc *UseCase) ListOrders(ctx context.Context) ([]*order.Order, error) {
-	return uc.repo.List(ctx)
+// ListOrders lists adminID's orders, only those of keyStore when an API key asks.
+func (uc *UseCase) ListOrders(ctx context.Context, adminID uuid.UUID, keyStore *uuid.UUID) ([]*order.Order, error) {
+	return uc.repo.ListByAdmin(ctx, adminID, keyStore)
 }
 
-func (uc *UseCase) DeleteOrder(ctx context.Context, id uuid.UUID) error {
+func (uc *UseCase) DeleteOrder(ctx context.Context, id, adminID uuid.UUID) error {
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if _, err := uc.GetOrderForAdmin(txCtx, id, adminID, nil); err != nil {
+			return err
+		}
+
 		if err := uc.repo.Delete(txCtx, id); err != nil {
 			return fmt.Errorf("delete order failed: %w", err)
 		}
@@ -111,12 +204,12 @@ func (uc *UseCase) DeleteOrder(ctx context.Context, id uuid.UUID) error {
 	})
 }
 
-func (uc *UseCase) GetAllInventories(ctx context.Context) ([]order.Inventory, error) {
-	return uc.invRepo.GetAllInventories(ctx)
+func (uc *UseCase) GetAllInventories(ctx context.Context, adminID uuid.UUID) ([]order.Inventory, error) {
+	return uc.invRepo.GetAllInventories(ctx, adminID)
 }
 
-func (uc *UseCase) GetAllCustomers(ctx context.Context) ([]order.Customer, error) {
-	return uc.usrRepo.GetAllCustomers(ctx)
+func (uc *UseCase) GetAllCustomers(ctx context.Context, adminID uuid.UUID) ([]order.Customer, error) {
+	return uc.usrRepo.GetAllCustomers(ctx, adminID)
 }
 
 func (uc *UseCase) GetOrderPickupPoint(ctx context.Context, orderID uuid.UUID) (postgis.PointS, error) {
@@ -151,8 +244,18 @@ func (uc *UseCase) AssignOrderToDriver(ctx context.Context, orderID, driverID uu
 	}
 
 	// 4. Update order status to assigned
-	if err := uc.UpdateOrder(ctx, orderID, "status", order.Assigned); err != nil {
-		return nil, fmt.Errorf("update order status: %w", err)
+	err = uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := uc.repo.Update(txCtx, orderID, "status", order.Assigned); err != nil {
+			return fmt.Errorf("update order status: %w", err)
+		}
+		return uc.events.Publish(txCtx, event.OrderAssigned{
+			OrderID:    o.ID,
+			CustomerID: o.CustomerID,
+			DriverID:   driverID,
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// 5. Optionally: create a pending assignment record
@@ -165,13 +268,3 @@ func (uc *UseCase) AssignOrderToDriver(ctx context.Context, orderID, driverID uu
 	// 6. Return assigned driver for confirmation / logging
 	return nearestDriver, nil
 }
-
-func (uc *UseCase) notify(ctx context.Context, userID uuid.UUID, message string) error {
-	n := &notification.Notification{
-		UserID:  userID,
-		Message: message,
-		Type:    notification.System,
-		Status:  notification.Pending,
-	}
-	return uc.notfRepo.Create(ctx, n)
-}
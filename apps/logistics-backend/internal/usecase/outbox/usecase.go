@@ -0,0 +1,152 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	domain "logistics-backend/internal/domain/outbox"
+	"time"
+)
+
+const (
+	baseBackoff = 30 * time.Second // first retry
+	maxBackoff  = time.Hour        // cap for the doubling backoff
+)
+
+// Config tunes the dispatcher. Lease is how long a claimed message stays hidden from other
+// dispatchers; it must be longer than any handler takes.
+type Config struct {
+	BatchSize   int
+	Lease       time.Duration
+	MaxAttempts int
+	Retention   time.Duration // how long dispatched messages are kept
+}
+
+type UseCase struct {
+	repo     domain.Repository
+	handlers map[string]domain.Handler
+	cfg      Config
+}
+
+func NewUseCase(repo domain.Repository, cfg Config) *UseCase {
+	return &UseCase{repo: repo, handlers: make(map[string]domain.Handler), cfg: cfg}
+}
+
+// Register sets the handler for a topic. Call it at startup, before RunDispatcher.
+func (uc *UseCase) Register(topic string, h domain.Handler) {
+	uc.handlers[topic] = h
+}
+
+// Enqueue records a message in the transaction carried by ctx, so it only exists if that
+// transaction commits.
+func (uc *UseCase) Enqueue(ctx context.Context, topic string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not encode outbox payload: %w", err)
+	}
+
+	return uc.repo.Add(ctx, &domain.Message{Topic: topic, Payload: data})
+}
+
+// RunDispatcher periodically hands due messages to their handlers until ctx is cancelled.
+func (uc *UseCase) RunDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// drain the backlog before waiting for the next tick
+			for {
+				n, err := uc.DispatchDue(ctx)
+				if err != nil {
+					log.Printf("outbox dispatch failed: %v", err)
+					break
+				}
+				if n < uc.cfg.BatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// DispatchDue runs one batch and reports how many messages it claimed.
+func (uc *UseCase) DispatchDue(ctx context.Context) (int, error) {
+	msgs, err := uc.repo.ClaimDue(ctx, uc.cfg.BatchSize, uc.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, m := range msgs {
+		uc.dispatch(ctx, m)
+	}
+
+	return len(msgs), nil
+}
+
+func (uc *UseCase) dispatch(ctx context.Context, m *domain.Message) {
+	err := uc.handle(ctx, m)
+	if err == nil {
+		if err := uc.repo.MarkDispatched(ctx, m.ID); err != nil {
+			log.Printf("outbox: %v", err)
+		}
+		return
+	}
+
+	dead := m.Attempts >= uc.cfg.MaxAttempts
+	if dead {
+		log.Printf("outbox: giving up on %s message %s after %d attempts: %v", m.Topic, m.ID, m.Attempts, err)
+	}
+
+	if err := uc.repo.MarkFailed(ctx, m.ID, err.Error(), time.Now().Add(backoffFor(m.Attempts)), dead); err != nil {
+		log.Printf("outbox: %v", err)
+	}
+}
+
+func (uc *UseCase) handle(ctx context.Context, m *domain.Message) (err error) {
+	h, ok := uc.handlers[m.Topic]
+	if !ok {
+		return fmt.Errorf("%w: %s", domain.ErrNoHandler, m.Topic)
+	}
+
+	// a panicking handler must not take the dispatcher down with it
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+
+	return h.Handle(ctx, m.ID, m.Payload)
+}
+
+// RunCleanup periodically deletes dispatched messages older than the retention period.
+func (uc *UseCase) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := uc.repo.DeleteDispatched(ctx, time.Now().Add(-uc.cfg.Retention)); err != nil {
+				log.Printf("outbox cleanup failed: %v", err)
+			} else if n > 0 {
+				log.Printf("outbox cleanup removed %d messages", n)
+			}
+		}
+	}
+}
+
+// backoffFor doubles the wait with every failed attempt, up to maxBackoff.
+func backoffFor(attempts int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxBackoff)
+}
@@ -0,0 +1,296 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"logistics-backend/internal/domain/delivery"
+	"logistics-backend/internal/domain/money"
+	"logistics-backend/internal/domain/order"
+	domain "logistics-backend/internal/domain/payment"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxReportDays bounds the daily cash report.
+const maxReportDays = 92
+
+// CollectCash records the cash a driver took for one of their deliveries and completes the
+// order's cash-on-delivery payment, creating it if the customer never started one. The amount
+// must match what is due; short or over payments are settled with the store owner, not here.
+func (uc *UseCase) CollectCash(ctx context.Context, driverID, deliveryID uuid.UUID, amount int64) (*domain.CashCollection, error) {
+	if amount <= 0 {
+		return nil, domain.ErrInvalidAmount
+	}
+
+	d, err := uc.deliveries.GetDeliveryByID(ctx, deliveryID)
+	if err != nil || d.DriverID != driverID {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	if d.Status != delivery.PickedUp && d.Status != delivery.Delivered {
+		return nil, domain.ErrDeliveryNotCollectable
+	}
+
+	o, err := uc.orders.GetOrderByID(ctx, d.OrderID)
+	if err != nil {
+		return nil, domain.ErrOrderNotFound
+	}
+	if o.Status == order.Cancelled {
+		return nil, domain.ErrOrderNotPayable
+	}
+
+	inv, err := uc.inventories.GetInventoryByID(ctx, o.InventoryID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch order inventory: %w", err)
+	}
+
+	var c *domain.CashCollection
+	err = uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := uc.repo.LockOrder(txCtx, o.ID); err != nil {
+			return fmt.Errorf("could not lock order: %w", err)
+		}
+
+		p, err := uc.repo.GetByOrder(txCtx, o.ID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), err == nil && (p.Status == domain.StatusFailed || p.Status == domain.StatusRefunded):
+			p = &domain.Payment{
+				OrderID:  o.ID,
+				Amount:   inv.PriceAmount * int64(o.Quantity),
+				Currency: inv.PriceCurrency,
+				Method:   domain.MethodCashOnDelivery,
+				Status:   domain.StatusPending,
+			}
+			if p.Amount <= 0 {
+				return domain.ErrInvalidAmount
+			}
+			if err := uc.repo.Create(txCtx, p); err != nil {
+				return fmt.Errorf("create payment failed: %w", err)
+			}
+		case err != nil:
+			return fmt.Errorf("could not fetch order payments: %w", err)
+		case p.Method != domain.MethodCashOnDelivery:
+			if p.Status == domain.StatusPending || p.Status == domain.StatusAuthorized {
+				return domain.ErrPaymentInProgress
+			}
+			return domain.ErrOrderAlreadyPaid
+		case p.Status != domain.StatusPending:
+			return domain.ErrCashAlreadyCollected
+		}
+
+		if amount != p.Amount {
+			return fmt.Errorf("%w: %s due", domain.ErrCashAmountMismatch, money.FromCents(p.Amount, p.Currency))
+		}
+
+		events, err := uc.complete(txCtx, p, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := uc.repo.Save(txCtx, p); err != nil {
+			return err
+		}
+
+		c = &domain.CashCollection{
+			PaymentID:  p.ID,
+			OrderID:    o.ID,
+			DeliveryID: d.ID,
+			DriverID:   driverID,
+			AdminID:    o.AdminID,
+			Amount:     amount,
+			Currency:   p.Currency,
+		}
+		if err := uc.cash.CreateCollection(txCtx, c); err != nil {
+			return err
+		}
+
+		return uc.events.Publish(txCtx, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (uc *UseCase) ListCashCollections(ctx context.Context, driverID uuid.UUID, limit, offset int) ([]*domain.CashCollection, error) {
+	return uc.cash.ListCollections(ctx, driverID, limit, offset)
+}
+
+// DriverCashBalances returns the cash the driver holds for each store owner.
+func (uc *UseCase) DriverCashBalances(ctx context.Context, driverID uuid.UUID) ([]*domain.CashBalance, error) {
+	return uc.balances(ctx, &driverID, nil)
+}
+
+// AdminCashBalances returns the cash each driver holds for the store owner.
+func (uc *UseCase) AdminCashBalances(ctx context.Context, adminID uuid.UUID, driverID *uuid.UUID) ([]*domain.CashBalance, error) {
+	return uc.balances(ctx, driverID, &adminID)
+}
+
+// DeclareRemittance records the driver handing cash in to a store owner. The amount may not
+// exceed what they hold for that owner less handovers still waiting to be counted.
+func (uc *UseCase) DeclareRemittance(ctx context.Context, driverID uuid.UUID, req *domain.DeclareRemittanceRequest) (*domain.CashRemittance, error) {
+	if req.Amount <= 0 {
+		return nil, domain.ErrInvalidAmount
+	}
+
+	rm := &domain.CashRemittance{
+		DriverID:       driverID,
+		AdminID:        req.AdminID,
+		Currency:       strings.ToUpper(strings.TrimSpace(req.Currency)),
+		DeclaredAmount: req.Amount,
+		Status:         domain.RemittancePending,
+		Note:           req.Note,
+	}
+	if rm.Currency == "" {
+		rm.Currency = "KES"
+	}
+
+	err := uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := uc.cash.LockDriver(txCtx, driverID); err != nil {
+			return fmt.Errorf("could not lock driver: %w", err)
+		}
+
+		b, err := uc.balance(txCtx, driverID, rm.AdminID, rm.Currency)
+		if err != nil {
+			return err
+		}
+		if rm.DeclaredAmount > b.InHand-b.Pending {
+			return domain.ErrExceedsCashInHand
+		}
+
+		return uc.cash.CreateRemittance(txCtx, rm)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rm, nil
+}
+
+// ConfirmRemittance records what the store owner counted. Anything short of the declared
+// amount stays in the driver's hand and shows as a shortfall in the daily report.
+func (uc *UseCase) ConfirmRemittance(ctx context.Context, adminID, id uuid.UUID, req *domain.ConfirmRemittanceRequest) (*domain.CashRemittance, error) {
+	if req.ReceivedAmount < 0 {
+		return nil, domain.ErrInvalidAmount
+	}
+
+	var rm *domain.CashRemittance
+	err := uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		var err error
+		if rm, err = uc.cash.GetRemittanceForUpdate(txCtx, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrRemittanceNotFound
+			}
+			return fmt.Errorf("could not lock remittance: %w", err)
+		}
+		if rm.AdminID != adminID {
+			return domain.ErrRemittanceNotFound
+		}
+		if rm.Status != domain.RemittancePending {
+			return domain.ErrRemittanceConfirmed
+		}
+
+		if err := uc.cash.LockDriver(txCtx, rm.DriverID); err != nil {
+			return fmt.Errorf("could not lock driver: %w", err)
+		}
+		b, err := uc.balance(txCtx, rm.DriverID, rm.AdminID, rm.Currency)
+		if err != nil {
+			return err
+		}
+		if req.ReceivedAmount > b.InHand {
+			return domain.ErrExceedsCashInHand
+		}
+
+		now := time.Now()
+		rm.ReceivedAmount = &req.ReceivedAmount
+		rm.Status = domain.RemittanceConfirmed
+		rm.ConfirmedAt = &now
+		rm.ConfirmedBy = &adminID
+		if req.Note != nil {
+			rm.Note = req.Note
+		}
+
+		return uc.cash.ConfirmRemittance(txCtx, rm)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rm, nil
+}
+
+func (uc *UseCase) ListDriverRemittances(ctx context.Context, driverID uuid.UUID, status *domain.RemittanceStatus, limit, offset int) ([]*domain.CashRemittance, error) {
+	return uc.cash.ListRemittances(ctx, &driverID, nil, status, limit, offset)
+}
+
+func (uc *UseCase) ListAdminRemittances(ctx context.Context, adminID uuid.UUID, driverID *uuid.UUID, status *domain.RemittanceStatus, limit, offset int) ([]*domain.CashRemittance, error) {
+	return uc.cash.ListRemittances(ctx, driverID, &adminID, status, limit, offset)
+}
+
+// CashReport returns the store owner's cash per driver and day for the dates from to to
+// inclusive, in tz (default CashReportTimezone). Empty dates mean today.
+func (uc *UseCase) CashReport(ctx context.Context, adminID uuid.UUID, driverID *uuid.UUID, from, to, tz string) ([]*domain.CashDay, error) {
+	if tz == "" {
+		tz = domain.CashReportTimezone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, domain.ErrInvalidReportRange
+	}
+
+	today := time.Now().In(loc).Format(time.DateOnly)
+	if from == "" {
+		from = today
+	}
+	if to == "" {
+		to = from
+	}
+
+	start, err := time.ParseInLocation(time.DateOnly, from, loc)
+	if err != nil {
+		return nil, domain.ErrInvalidReportRange
+	}
+	last, err := time.ParseInLocation(time.DateOnly, to, loc)
+	if err != nil || last.Before(start) || last.After(start.AddDate(0, 0, maxReportDays-1)) {
+		return nil, domain.ErrInvalidReportRange
+	}
+
+	days, err := uc.cash.DailyReport(ctx, domain.CashReportQuery{
+		AdminID:  adminID,
+		DriverID: driverID,
+		From:     start,
+		To:       last.AddDate(0, 0, 1),
+		Location: loc,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range days {
+		d.Unremitted = d.Collected - d.Received
+	}
+	return days, nil
+}
+
+func (uc *UseCase) balances(ctx context.Context, driverID, adminID *uuid.UUID) ([]*domain.CashBalance, error) {
+	bs, err := uc.cash.Balances(ctx, driverID, adminID)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bs {
+		b.InHand = b.Collected - b.Remitted
+	}
+	return bs, nil
+}
+
+// balance returns one driver's balance with one owner in one currency, zero if they have none.
+func (uc *UseCase) balance(ctx context.Context, driverID, adminID uuid.UUID, currency string) (*domain.CashBalance, error) {
+	bs, err := uc.balances(ctx, &driverID, &adminID)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bs {
+		if b.Currency == currency {
+			return b, nil
+		}
+	}
+	return &domain.CashBalance{DriverID: driverID, AdminID: adminID, Currency: currency}, nil
+}
@@ -2,42 +2,180 @@ package payment
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"logistics-backend/internal/domain/event"
+	"logistics-backend/internal/domain/order"
 	domain "logistics-backend/internal/domain/payment"
+	"logistics-backend/internal/domain/user"
 	"logistics-backend/internal/usecase/common"
+	"logistics-backend/internal/utils"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type UseCase struct {
-	repo      domain.Repository
-	txManager common.TxManager
+	repo        domain.Repository
+	txManager   common.TxManager
+	orders      domain.OrderReader
+	inventories domain.InventoryReader
+	deliveries  domain.DeliveryReader
+	cash        domain.CashRepository
+	events      domain.EventPublisher
+	providers   map[domain.PaymentMethod]domain.Provider
 }
 
-func NewUseCase(repo domain.Repository, txm common.TxManager) *UseCase {
-	return &UseCase{repo: repo, txManager: txm}
+// NewUseCase takes the configured gateways; methods without one can only be recorded by hand.
+func NewUseCase(repo domain.Repository, cash domain.CashRepository, txm common.TxManager, orders domain.OrderReader, inventories domain.InventoryReader, deliveries domain.DeliveryReader, events domain.EventPublisher, providers ...domain.Provider) *UseCase {
+	uc := &UseCase{
+		repo:        repo,
+		cash:        cash,
+		txManager:   txm,
+		orders:      orders,
+		inventories: inventories,
+		deliveries:  deliveries,
+		events:      events,
+		providers:   make(map[domain.PaymentMethod]domain.Provider),
+	}
+	for _, p := range providers {
+		uc.providers[p.Method()] = p
+	}
+	return uc
 }
 
-func (uc *UseCase) CreatePayment(ctx context.Context, p *domain.Payment) error {
-	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+// CreatePayment records a payment the store owner took outside the gateways, e.g. a bank
+// transfer, for one of their pending orders. It is charged the order's total.
+func (uc *UseCase) CreatePayment(ctx context.Context, adminID uuid.UUID, req *domain.CreatePaymentRequest) (*domain.Payment, error) {
+	o, err := uc.orderFor(ctx, req.OrderID, adminID, user.Admin)
+	if err != nil {
+		return nil, err
+	}
+	if o.Status != order.Pending {
+		return nil, domain.ErrOrderNotPayable
+	}
+
+	p, err := uc.charge(ctx, o, req.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := uc.repo.LockOrder(txCtx, o.ID); err != nil {
+			return fmt.Errorf("could not lock order: %w", err)
+		}
+
+		last, err := uc.repo.GetByOrder(txCtx, o.ID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+		case err != nil:
+			return fmt.Errorf("could not fetch order payments: %w", err)
+		case last.Status == domain.StatusPending || last.Status == domain.StatusAuthorized:
+			return domain.ErrPaymentInProgress
+		case last.Status != domain.StatusFailed && last.Status != domain.StatusRefunded:
+			return domain.ErrOrderAlreadyPaid
+		}
+
 		if err := uc.repo.Create(txCtx, p); err != nil {
 			return fmt.Errorf("create payment failed: %w", err)
 		}
 
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// orderFor returns the order when the caller may see its payments: the store owner it belongs
+// to, or the customer who placed it.
+func (uc *UseCase) orderFor(ctx context.Context, orderID, callerID uuid.UUID, role user.Role) (*order.Order, error) {
+	o, err := uc.orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	switch {
+	case role == user.Admin && o.AdminID == callerID:
+	case role == user.Customer && o.CustomerID == callerID:
+	default:
+		return nil, domain.ErrOrderNotFound
+	}
+	return o, nil
+}
+
+// charge is a pending payment of the order's total.
+func (uc *UseCase) charge(ctx context.Context, o *order.Order, method domain.PaymentMethod) (*domain.Payment, error) {
+	inv, err := uc.inventories.GetInventoryByID(ctx, o.InventoryID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch order inventory: %w", err)
+	}
+
+	p := &domain.Payment{
+		OrderID:  o.ID,
+		Amount:   inv.PriceAmount * int64(o.Quantity),
+		Currency: inv.PriceCurrency,
+		Method:   method,
+		Status:   domain.StatusPending,
+	}
+	if p.Amount <= 0 {
+		return nil, domain.ErrInvalidAmount
+	}
+	return p, nil
 }
 
 func (uc *UseCase) GetPaymentByID(ctx context.Context, id uuid.UUID) (*domain.Payment, error) {
-	return uc.repo.GetByID(ctx, id)
+	p, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("could not fetch payment: %w", err)
+	}
+	return p, nil
 }
 
-func (uc *UseCase) GetPaymentByOrderID(ctx context.Context, orderID uuid.UUID) (*domain.Payment, error) {
-	return uc.repo.GetByOrder(ctx, orderID)
+// GetPaymentForAdmin returns the payment only when its order belongs to adminID.
+func (uc *UseCase) GetPaymentForAdmin(ctx context.Context, id, adminID uuid.UUID) (*domain.Payment, error) {
+	return uc.GetPaymentFor(ctx, id, adminID, user.Admin)
+}
+
+// GetPaymentFor returns the payment only to the store owner or the customer of its order.
+func (uc *UseCase) GetPaymentFor(ctx context.Context, id, callerID uuid.UUID, role user.Role) (*domain.Payment, error) {
+	p, err := uc.GetPaymentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.orderFor(ctx, p.OrderID, callerID, role); err != nil {
+		return nil, domain.ErrPaymentNotFound
+	}
+
+	return p, nil
+}
+
+// GetPaymentByOrderID returns the latest payment of an order the caller owns or placed.
+func (uc *UseCase) GetPaymentByOrderID(ctx context.Context, orderID, callerID uuid.UUID, role user.Role) (*domain.Payment, error) {
+	if _, err := uc.orderFor(ctx, orderID, callerID, role); err != nil {
+		return nil, err
+	}
+
+	p, err := uc.repo.GetByOrder(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("could not fetch payment: %w", err)
+	}
+	return p, nil
 }
 
-func (uc *UseCase) ListPayments(ctx context.Context) ([]*domain.Payment, error) {
-	return uc.repo.List(ctx)
+// ListPayments returns the payments for the store owner's orders, newest first.
+func (uc *UseCase) ListPayments(ctx context.Context, adminID uuid.UUID) ([]*domain.Payment, error) {
+	return uc.repo.ListByAdmin(ctx, adminID)
 }
 
 func (uc *UseCase) DeletePayment(ctx context.Context, id uuid.UUID) error {
@@ -49,3 +187,265 @@ func (uc *UseCase) DeletePayment(ctx context.Context, id uuid.UUID) error {
 		return nil
 	})
 }
+
+// Authorize records that the gateway holds the funds, keeping its reference when given.
+func (uc *UseCase) Authorize(ctx context.Context, id uuid.UUID, providerRef *string) (*domain.Payment, error) {
+	return uc.transition(ctx, id, func(_ context.Context, p *domain.Payment, now time.Time) ([]event.Event, error) {
+		return nil, p.Authorize(providerRef, now)
+	})
+}
+
+// Capture takes amount of the payment, or all of it when amount is 0.
+func (uc *UseCase) Capture(ctx context.Context, id uuid.UUID, amount int64) (*domain.Payment, error) {
+	return uc.transition(ctx, id, func(_ context.Context, p *domain.Payment, now time.Time) ([]event.Event, error) {
+		return nil, p.Capture(amount, now)
+	})
+}
+
+func (uc *UseCase) Complete(ctx context.Context, id uuid.UUID) (*domain.Payment, error) {
+	return uc.transition(ctx, id, func(txCtx context.Context, p *domain.Payment, now time.Time) ([]event.Event, error) {
+		return uc.complete(txCtx, p, now)
+	})
+}
+
+// Fail marks the payment failed; a pending order it was paying for is cancelled.
+func (uc *UseCase) Fail(ctx context.Context, id uuid.UUID, reason string) (*domain.Payment, error) {
+	return uc.transition(ctx, id, func(txCtx context.Context, p *domain.Payment, now time.Time) ([]event.Event, error) {
+		return uc.fail(txCtx, p, reason, now)
+	})
+}
+
+// Refund returns amount of the captured payment, or everything left when amount is 0.
+// createdBy is nil when the gateway reported the refund. Gateways that can refund return the
+// money before the refund is recorded. A full refund cancels the order unless it is already
+// on its way.
+func (uc *UseCase) Refund(ctx context.Context, id uuid.UUID, amount int64, reason *string, createdBy *uuid.UUID) (*domain.Refund, error) {
+	var rf *domain.Refund
+	_, err := uc.transition(ctx, id, func(txCtx context.Context, p *domain.Payment, _ time.Time) ([]event.Event, error) {
+		var err error
+		if rf, err = p.Refund(amount, reason, createdBy); err != nil {
+			return nil, err
+		}
+
+		// the gateway refund runs under the payment lock, keyed by the refund ID so a retry
+		// after a lost response is not refunded twice
+		if refunder, ok := uc.providers[p.Method].(domain.Refunder); ok && p.ProviderRef != nil {
+			ref, err := refunder.Refund(txCtx, p, rf)
+			if err != nil {
+				return nil, err
+			}
+			rf.ProviderRef = &ref
+		}
+
+		if err := uc.repo.CreateRefund(txCtx, rf); err != nil {
+			return nil, err
+		}
+		return []event.Event{event.PaymentRefunded{
+			PaymentID: p.ID,
+			OrderID:   p.OrderID,
+			RefundID:  rf.ID,
+			Amount:    rf.Amount,
+			Currency:  rf.Currency,
+			Full:      p.Status == domain.StatusRefunded,
+		}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (uc *UseCase) ListRefunds(ctx context.Context, id uuid.UUID) ([]*domain.Refund, error) {
+	return uc.repo.ListRefunds(ctx, id)
+}
+
+// InitiatePayment charges the order's total to customerID through the method's gateway. The
+// pending payment is recorded before the gateway is called, and failed if the gateway refuses
+// the charge; otherwise its outcome arrives later through HandleResult. Only one payment for an
+// order may be in progress at a time; asking again resumes it when the gateway allows that.
+func (uc *UseCase) InitiatePayment(ctx context.Context, customerID uuid.UUID, req *domain.InitiatePaymentRequest) (*domain.Payment, *domain.Initiation, error) {
+	provider, ok := uc.providers[req.Method]
+	if !ok {
+		return nil, nil, domain.ErrMethodUnavailable
+	}
+
+	phone := req.Phone
+	if req.Method == domain.MethodMobileMoney {
+		var err error
+		if phone, err = utils.NormalizePhone(req.Phone); err != nil {
+			return nil, nil, domain.ErrInvalidPhone
+		}
+	}
+
+	o, err := uc.orders.GetOrderByID(ctx, req.OrderID)
+	if err != nil || o.CustomerID != customerID {
+		return nil, nil, domain.ErrOrderNotFound
+	}
+	if o.Status != order.Pending {
+		return nil, nil, domain.ErrOrderNotPayable
+	}
+
+	p, err := uc.charge(ctx, o, req.Method)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var resume *domain.Payment
+	err = uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := uc.repo.LockOrder(txCtx, o.ID); err != nil {
+			return fmt.Errorf("could not lock order: %w", err)
+		}
+
+		last, err := uc.repo.GetByOrder(txCtx, o.ID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+		case err != nil:
+			return fmt.Errorf("could not fetch order payments: %w", err)
+		case last.Status == domain.StatusPending || last.Status == domain.StatusAuthorized:
+			if _, ok := provider.(domain.Resumer); ok && last.Method == req.Method && last.ProviderRef != nil {
+				resume = last
+				return nil
+			}
+			return domain.ErrPaymentInProgress
+		case last.Status != domain.StatusFailed && last.Status != domain.StatusRefunded:
+			return domain.ErrOrderAlreadyPaid
+		}
+
+		return uc.repo.Create(txCtx, p)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resume != nil {
+		started, err := provider.(domain.Resumer).Resume(ctx, resume)
+		if err != nil {
+			return nil, nil, err
+		}
+		return resume, started, nil
+	}
+
+	started, err := provider.Initiate(ctx, &domain.Charge{
+		PaymentID:   p.ID,
+		Amount:      p.Amount,
+		Currency:    p.Currency,
+		Phone:       phone,
+		Reference:   o.ID.String()[:8],
+		Description: "Order " + o.ID.String()[:8],
+	})
+	if err != nil {
+		if _, ferr := uc.Fail(ctx, p.ID, err.Error()); ferr != nil {
+			log.Printf("payment %s: could not record gateway failure: %v", p.ID, ferr)
+		}
+		return nil, nil, err
+	}
+
+	p, err = uc.transition(ctx, p.ID, func(_ context.Context, p *domain.Payment, _ time.Time) ([]event.Event, error) {
+		p.ProviderRef = &started.ProviderRef
+		return nil, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return p, started, nil
+}
+
+// HandleResult applies a gateway's callback to the payment it names: the payment completes or
+// fails, and the customer is told either way. Gateways may repeat callbacks, so a result the
+// payment already reflects changes nothing.
+func (uc *UseCase) HandleResult(ctx context.Context, method domain.PaymentMethod, res *domain.Result) error {
+	p, err := uc.repo.GetByProviderRef(ctx, method, res.ProviderRef)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrPaymentNotFound
+		}
+		return fmt.Errorf("could not fetch payment: %w", err)
+	}
+
+	_, err = uc.transition(ctx, p.ID, func(txCtx context.Context, p *domain.Payment, now time.Time) ([]event.Event, error) {
+		switch {
+		case res.Succeeded && p.Status == domain.StatusCompleted, !res.Succeeded && p.Status == domain.StatusFailed:
+			return nil, nil
+		case res.Succeeded:
+			if res.Receipt != "" {
+				p.Receipt = &res.Receipt
+			}
+			return uc.complete(txCtx, p, now)
+		default:
+			return uc.fail(txCtx, p, res.Reason, now)
+		}
+	})
+	return err
+}
+
+func (uc *UseCase) complete(ctx context.Context, p *domain.Payment, now time.Time) ([]event.Event, error) {
+	if err := p.Complete(now); err != nil {
+		return nil, err
+	}
+
+	o, err := uc.orders.GetOrderByID(ctx, p.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch paid order: %w", err)
+	}
+
+	var receipt string
+	if p.Receipt != nil {
+		receipt = *p.Receipt
+	}
+
+	return []event.Event{event.PaymentCompleted{
+		PaymentID:  p.ID,
+		OrderID:    p.OrderID,
+		CustomerID: o.CustomerID,
+		Amount:     p.CapturedAmount,
+		Currency:   p.Currency,
+		Method:     string(p.Method),
+		Receipt:    receipt,
+	}}, nil
+}
+
+func (uc *UseCase) fail(ctx context.Context, p *domain.Payment, reason string, now time.Time) ([]event.Event, error) {
+	if err := p.Fail(reason, now); err != nil {
+		return nil, err
+	}
+
+	o, err := uc.orders.GetOrderByID(ctx, p.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch order of failed payment: %w", err)
+	}
+
+	return []event.Event{event.PaymentFailed{PaymentID: p.ID, OrderID: p.OrderID, CustomerID: o.CustomerID, Reason: reason}}, nil
+}
+
+// transition applies one state change to a locked payment, saves it and publishes the events
+// it raised, all in one transaction.
+func (uc *UseCase) transition(ctx context.Context, id uuid.UUID, apply func(txCtx context.Context, p *domain.Payment, now time.Time) ([]event.Event, error)) (*domain.Payment, error) {
+	var p *domain.Payment
+	err := uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		var err error
+		if p, err = uc.repo.GetForUpdate(txCtx, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrPaymentNotFound
+			}
+			return fmt.Errorf("could not lock payment: %w", err)
+		}
+
+		events, err := apply(txCtx, p, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if err := uc.repo.Save(txCtx, p); err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		return uc.events.Publish(txCtx, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,114 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"logistics-backend/internal/domain/inventory"
+	"logistics-backend/internal/domain/order"
+	domain "logistics-backend/internal/domain/payment"
+	"logistics-backend/internal/domain/user"
+
+	"github.com/google/uuid"
+)
+
+type noTx struct{}
+
+func (noTx) Do(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) }
+
+type paymentRepo struct {
+	domain.Repository
+	payments map[uuid.UUID]*domain.Payment
+}
+
+func (r *paymentRepo) Create(_ context.Context, p *domain.Payment) error {
+	p.ID = uuid.New()
+	r.payments[p.ID] = p
+	return nil
+}
+
+func (r *paymentRepo) GetByID(_ context.Context, id uuid.UUID) (*domain.Payment, error) {
+	if p, ok := r.payments[id]; ok {
+		return p, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *paymentRepo) GetByOrder(_ context.Context, orderID uuid.UUID) (*domain.Payment, error) {
+	for _, p := range r.payments {
+		if p.OrderID == orderID {
+			return p, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *paymentRepo) LockOrder(context.Context, uuid.UUID) error { return nil }
+
+type orderReader map[uuid.UUID]*order.Order
+
+func (o orderReader) GetOrderByID(_ context.Context, id uuid.UUID) (*order.Order, error) {
+	if v, ok := o[id]; ok {
+		return v, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+type inventoryReader struct{}
+
+func (inventoryReader) GetInventoryByID(_ context.Context, id uuid.UUID) (*inventory.Inventory, error) {
+	return &inventory.Inventory{ID: id, PriceAmount: 1250, PriceCurrency: "KES"}, nil
+}
+
+func TestPaymentsStayWithTheirOrder(t *testing.T) {
+	admin, otherAdmin, customer, otherCustomer := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	o := &order.Order{ID: uuid.New(), AdminID: admin, CustomerID: customer, InventoryID: uuid.New(), Quantity: 3, Status: order.Pending}
+
+	repo := &paymentRepo{payments: map[uuid.UUID]*domain.Payment{}}
+	uc := NewUseCase(repo, nil, noTx{}, orderReader{o.ID: o}, inventoryReader{}, nil, nil)
+	ctx := context.Background()
+
+	if _, err := uc.CreatePayment(ctx, otherAdmin, &domain.CreatePaymentRequest{OrderID: o.ID, Method: domain.MethodPayPal}); !errors.Is(err, domain.ErrOrderNotFound) {
+		t.Fatalf("other store's create: err = %v, want ErrOrderNotFound", err)
+	}
+
+	p, err := uc.CreatePayment(ctx, admin, &domain.CreatePaymentRequest{OrderID: o.ID, Method: domain.MethodPayPal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Amount != 3750 || p.Currency != "KES" {
+		t.Errorf("amount = %d %s, want the order total 3750 KES", p.Amount, p.Currency)
+	}
+
+	if _, err := uc.CreatePayment(ctx, admin, &domain.CreatePaymentRequest{OrderID: o.ID, Method: domain.MethodPayPal}); !errors.Is(err, domain.ErrPaymentInProgress) {
+		t.Errorf("second create: err = %v, want ErrPaymentInProgress", err)
+	}
+
+	cases := []struct {
+		name   string
+		caller uuid.UUID
+		role   user.Role
+		ok     bool
+	}{
+		{"store owner", admin, user.Admin, true},
+		{"customer", customer, user.Customer, true},
+		{"other store owner", otherAdmin, user.Admin, false},
+		{"other customer", otherCustomer, user.Customer, false},
+		{"customer id with admin role", customer, user.Admin, false},
+		{"driver", uuid.New(), user.Driver, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := uc.GetPaymentFor(ctx, p.ID, c.caller, c.role)
+			if c.ok != (err == nil) {
+				t.Errorf("GetPaymentFor: err = %v, want ok %v", err, c.ok)
+			}
+			_, err = uc.GetPaymentByOrderID(ctx, o.ID, c.caller, c.role)
+			if c.ok != (err == nil) {
+				t.Errorf("GetPaymentByOrderID: err = %v, want ok %v", err, c.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"logistics-backend/internal/domain/session"
+	"logistics-backend/internal/domain/user"
+	"logistics-backend/internal/usecase/common"
+	"logistics-backend/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UseCase struct {
+	repo       session.Repository
+	usrRepo    session.UserReader
+	txManager  common.TxManager
+	signer     session.TokenSigner
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	mfaPolicy  session.MFAPolicy
+}
+
+func NewUseCase(repo session.Repository, usrRepo session.UserReader, txm common.TxManager, signer session.TokenSigner, accessTTL, refreshTTL time.Duration, mfaPolicy session.MFAPolicy) *UseCase {
+	return &UseCase{repo: repo, usrRepo: usrRepo, txManager: txm, signer: signer, accessTTL: accessTTL, refreshTTL: refreshTTL, mfaPolicy: mfaPolicy}
+}
+
+// IssueTokens starts a new session (refresh token family) for a freshly authenticated user.
+func (uc *UseCase) IssueTokens(ctx context.Context, u *user.User) (*session.TokenPair, error) {
+	var pair *session.TokenPair
+
+	err := uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		raw, rt, err := uc.newRefreshToken(u.ID, uuid.New())
+		if err != nil {
+			return err
+		}
+
+		if err := uc.repo.Create(txCtx, rt); err != nil {
+			return fmt.Errorf("could not store refresh token: %w", err)
+		}
+
+		pair, err = uc.newPair(u, raw)
+		return err
+	})
+
+	return pair, err
+}
+
+// Refresh rotates a refresh token: the presented token is revoked and replaced by a new one in
+// the same family. Presenting an already rotated token revokes the whole family, since either
+// the client or an attacker holds a stolen copy.
+func (uc *UseCase) Refresh(ctx context.Context, rawToken string) (*session.TokenPair, error) {
+	current, err := uc.repo.GetByHash(ctx, utils.HashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, session.ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("could not fetch refresh token: %w", err)
+	}
+
+	if current.IsRevoked() {
+		uc.revokeFamily(ctx, current.FamilyID)
+		return nil, session.ErrRefreshTokenReused
+	}
+
+	if current.IsExpired(time.Now()) {
+		return nil, session.ErrInvalidRefreshToken
+	}
+
+	u, err := uc.usrRepo.GetByID(ctx, current.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch user: %w", err)
+	}
+	if err := u.CheckStatus(); err != nil {
+		return nil, fmt.Errorf("%w: %w", session.ErrUserInactive, err)
+	}
+
+	var pair *session.TokenPair
+	err = uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		raw, next, err := uc.newRefreshToken(u.ID, current.FamilyID)
+		if err != nil {
+			return err
+		}
+
+		if err := uc.repo.Create(txCtx, next); err != nil {
+			return fmt.Errorf("could not store refresh token: %w", err)
+		}
+
+		if err := uc.repo.Revoke(txCtx, current.ID, &next.ID); err != nil {
+			return err
+		}
+
+		pair, err = uc.newPair(u, raw)
+		return err
+	})
+
+	// lost a race against another refresh with the same token
+	if errors.Is(err, session.ErrRefreshTokenReused) {
+		uc.revokeFamily(ctx, current.FamilyID)
+	}
+
+	return pair, err
+}
+
+// Logout revokes the access token in use and, when given, the refresh token chain it came with.
+func (uc *UseCase) Logout(ctx context.Context, userID uuid.UUID, jti string, accessExpiresAt time.Time, rawRefresh string) error {
+	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if jti != "" {
+			if err := uc.repo.RevokeAccessToken(txCtx, jti, userID, accessExpiresAt); err != nil {
+				return err
+			}
+		}
+
+		if rawRefresh == "" {
+			return nil
+		}
+
+		rt, err := uc.repo.GetByHash(txCtx, utils.HashToken(rawRefresh))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return fmt.Errorf("could not fetch refresh token: %w", err)
+		}
+
+		// never let one user end another user's session
+		if rt.UserID != userID {
+			return nil
+		}
+
+		return uc.repo.RevokeFamily(txCtx, rt.FamilyID)
+	})
+}
+
+// RevokeAllSessions invalidates every access and refresh token issued to the user so far.
+func (uc *UseCase) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		return uc.RevokeAllSessionsTx(txCtx, userID)
+	})
+}
+
+// RevokeAllSessionsTx does the work of RevokeAllSessions on the caller's context, so the
+// revocation commits or rolls back with the caller's transaction.
+func (uc *UseCase) RevokeAllSessionsTx(ctx context.Context, userID uuid.UUID) error {
+	if _, err := uc.repo.BumpTokenVersion(ctx, userID); err != nil {
+		return err
+	}
+
+	return uc.repo.RevokeAllForUser(ctx, userID)
+}
+
+// ValidateAccessToken is consulted by the auth middleware on every request.
+func (uc *UseCase) ValidateAccessToken(ctx context.Context, userID uuid.UUID, jti string, version int) error {
+	u, err := uc.usrRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("could not fetch user: %w", err)
+	}
+	if err := u.CheckStatus(); err != nil {
+		return fmt.Errorf("%w: %w", session.ErrUserInactive, err)
+	}
+	if u.TokenVersion != version {
+		return session.ErrTokenRevoked
+	}
+
+	if jti != "" {
+		revoked, err := uc.repo.IsAccessTokenRevoked(ctx, jti)
+		if err != nil {
+			return fmt.Errorf("could not check token revocation: %w", err)
+		}
+		if revoked {
+			return session.ErrTokenRevoked
+		}
+	}
+
+	if u.Must_change_password {
+		return session.ErrPasswordChangeRequired
+	}
+	if !u.MFAEnabled && uc.mfaPolicy.Requires(u.Role) {
+		return session.ErrMFAEnrollmentRequired
+	}
+
+	return nil
+}
+
+// RunCleanup periodically drops expired refresh tokens and revocation list entries.
+func (uc *UseCase) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := uc.repo.DeleteExpired(ctx, time.Now()); err != nil {
+				log.Printf("session cleanup failed: %v", err)
+			} else if n > 0 {
+				log.Printf("session cleanup removed %d expired tokens", n)
+			}
+		}
+	}
+}
+
+func (uc *UseCase) newPair(u *user.User, rawRefresh string) (*session.TokenPair, error) {
+	expiresAt := time.Now().Add(uc.accessTTL)
+
+	claims := map[string]any{
+		"iss":   "my-client",   // Kong
+		"sub":   u.ID.String(), // subject
+		"email": u.Email,
+		"role":  u.Role, // custom claim
+		"name":  u.FullName,
+		"ver":   u.TokenVersion,
+		"jti":   uuid.NewString(),
+		"iat":   time.Now().Unix(),
+		"exp":   expiresAt.Unix(),
+	}
+
+	signed, err := uc.signer.SignAccessToken(claims)
+	if err != nil {
+		return nil, fmt.Errorf("could not sign access token: %w", err)
+	}
+
+	return &session.TokenPair{
+		AccessToken:  signed,
+		RefreshToken: rawRefresh,
+		TokenType:    "Bearer",
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+func (uc *UseCase) newRefreshToken(userID, familyID uuid.UUID) (string, *session.RefreshToken, error) {
+	raw, err := utils.GenerateToken(32)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not generate refresh token: %w", err)
+	}
+
+	return raw, &session.RefreshToken{
+		UserID:    userID,
+		TokenHash: utils.HashToken(raw),
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(uc.refreshTTL),
+	}, nil
+}
+
+func (uc *UseCase) revokeFamily(ctx context.Context, familyID uuid.UUID) {
+	if err := uc.repo.RevokeFamily(ctx, familyID); err != nil {
+		log.Printf("could not revoke refresh token family %s: %v", familyID, err)
+	}
+}
@@ -32,12 +32,33 @@ func (uc *UseCase) GetStoreByID(ctx context.Context, id uuid.UUID) (*store.Store
 	return uc.repo.GetByID(ctx, id)
 }
 
+// GetOwnedStore returns the store only when it belongs to ownerID.
+func (uc *UseCase) GetOwnedStore(ctx context.Context, id, ownerID uuid.UUID) (*store.Store, error) {
+	s, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch store: %w", err)
+	}
+	if s.OwnerID != ownerID {
+		return nil, store.ErrStoreNotFound
+	}
+
+	return s, nil
+}
+
+func (uc *UseCase) ListOwnerStores(ctx context.Context, ownerID uuid.UUID) ([]*store.Store, error) {
+	return uc.repo.ListByOwner(ctx, ownerID)
+}
+
 func (uc *UseCase) GetStoreBySlug(ctx context.Context, slug string) (*store.Store, error) {
 	return uc.repo.GetBySlug(ctx, slug)
 }
 
-func (uc *UseCase) UpdateStore(ctx context.Context, storeID uuid.UUID, column string, value any) error {
+func (uc *UseCase) UpdateStore(ctx context.Context, storeID, ownerID uuid.UUID, column string, value any) error {
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if _, err := uc.GetOwnedStore(txCtx, storeID, ownerID); err != nil {
+			return err
+		}
+
 		if err := uc.repo.Update(txCtx, storeID, column, value); err != nil {
 			return fmt.Errorf("update store failed: %w", err)
 		}
@@ -50,8 +71,12 @@ func (uc *UseCase) GetPublicStores(ctx context.Context) ([]*store.Store, error)
 	return uc.repo.ListPublic(ctx)
 }
 
-func (uc *UseCase) DeleteStore(ctx context.Context, id uuid.UUID) error {
+func (uc *UseCase) DeleteStore(ctx context.Context, id, ownerID uuid.UUID) error {
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if _, err := uc.GetOwnedStore(txCtx, id, ownerID); err != nil {
+			return err
+		}
+
 		if err := uc.repo.Delete(txCtx, id); err != nil {
 			return fmt.Errorf("delete store failed: %w", err)
 		}
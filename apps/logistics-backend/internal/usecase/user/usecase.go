@@ -2,11 +2,16 @@ package user
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"logistics-backend/internal/domain/driver"
 	"logistics-backend/internal/domain/notification"
 	domain "logistics-backend/internal/domain/user"
 	"logistics-backend/internal/usecase/common"
+	"logistics-backend/internal/utils"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/cridenour/go-postgis"
@@ -19,58 +24,87 @@ type UseCase struct {
 	drvRepo   domain.DriverReader
 	txManager common.TxManager
 	notfRepo  domain.NotificationReader
+	sessions  domain.SessionRevoker
+	links     Links
 }
 
-func NewUseCase(repo domain.Repository, drvRepo domain.DriverReader, txm common.TxManager, notf domain.NotificationReader) *UseCase {
-	return &UseCase{repo: repo, drvRepo: drvRepo, txManager: txm, notfRepo: notf}
+// Links configures the emailed account links; each URL receives the raw token as ?token=.
+type Links struct {
+	PasswordResetURL string
+	PasswordResetTTL time.Duration
+	VerifyEmailURL   string
+	VerifyEmailTTL   time.Duration
+}
+
+func NewUseCase(repo domain.Repository, drvRepo domain.DriverReader, txm common.TxManager, notf domain.NotificationReader, sessions domain.SessionRevoker, links Links) *UseCase {
+	return &UseCase{repo: repo, drvRepo: drvRepo, txManager: txm, notfRepo: notf, sessions: sessions, links: links}
 }
 
 func (uc *UseCase) RegisterUser(ctx context.Context, u *domain.User) error {
 
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := uc.CreateAccount(txCtx, u); err != nil {
+			return err
+		}
 
-		// 1. hash password
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.PasswordHash), bcrypt.DefaultCost)
-		if err != nil {
-			return fmt.Errorf("failed to hash password: %w", err)
-		}
-		u.PasswordHash = string(hashedPassword)
-
-		// 2. insert user to DB
-		if err := uc.repo.Create(txCtx, u); err != nil {
-			return fmt.Errorf("could not create user: %w", err)
-		}
-
-		// 3. if role is driver, insert into drivers table
-		if u.Role == "driver" {
-			driver := &driver.Driver{
-				ID:          u.ID,
-				FullName:    u.FullName,
-				Email:       u.Email,
-				VehicleInfo: "not set",
-				CurrentLocation: postgis.PointS{
-					SRID: 4326,
-					X:    36.8219, // longitude
-					Y:    -1.2921, // latitude
-				},
-				Available: true,
-				CreatedAt: time.Now(),
-			}
-			if err := uc.drvRepo.RegisterDriver(txCtx, driver); err != nil {
-				return fmt.Errorf("could not register driver: %w", err)
+		// self-registered accounts stay pending until the emailed link is opened
+		if u.Status == domain.Pending {
+			if err := uc.sendVerification(txCtx, u); err != nil {
+				return err
 			}
 		}
 
-		// async notification after commit
-		go func() {
-			msg := fmt.Sprintf("✅ New user '%s' has been registered with role '%s'.", u.FullName, u.Role)
-			_ = uc.notify(ctx, u.ID, msg)
-		}()
-
-		return nil
+		return uc.notify(txCtx, u.ID, notification.EventUserRegistered, notification.TemplateData{
+			"name": u.FullName,
+			"role": string(u.Role),
+		})
 	})
 }
 
+// CreateAccount hashes the raw password in u.PasswordHash, stores the user and, for drivers,
+// the driver row. It runs on the caller's context so it can join the caller's transaction.
+func (uc *UseCase) CreateAccount(ctx context.Context, u *domain.User) error {
+	phone, err := normalizePhone(u.Phone)
+	if err != nil {
+		return err
+	}
+	u.Phone = phone
+
+	// 1. hash password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.PasswordHash), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	u.PasswordHash = string(hashedPassword)
+
+	// 2. insert user to DB
+	if err := uc.repo.Create(ctx, u); err != nil {
+		return fmt.Errorf("could not create user: %w", err)
+	}
+
+	// 3. if role is driver, insert into drivers table
+	if u.Role == "driver" {
+		driver := &driver.Driver{
+			ID:          u.ID,
+			FullName:    u.FullName,
+			Email:       u.Email,
+			VehicleInfo: "not set",
+			CurrentLocation: postgis.PointS{
+				SRID: 4326,
+				X:    36.8219, // longitude
+				Y:    -1.2921, // latitude
+			},
+			Available: true,
+			CreatedAt: time.Now(),
+		}
+		if err := uc.drvRepo.RegisterDriver(ctx, driver); err != nil {
+			return fmt.Errorf("could not register driver: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // PATCH method for users to update details
 func (uc *UseCase) UpdateUserProfile(ctx context.Context, id uuid.UUID, req *domain.UpdateDriverUserProfileRequest) error {
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
@@ -80,19 +114,37 @@ func (uc *UseCase) UpdateUserProfile(ctx context.Context, id uuid.UUID, req *dom
 			return fmt.Errorf("could not fetch user: %w", err)
 		}
 
-		if err := uc.repo.UpdateProfile(txCtx, id, req.Phone); err != nil {
-			return fmt.Errorf("update user profile failed: %w", err)
+		phone, err := normalizePhone(req.Phone)
+		if err != nil {
+			return err
 		}
 
-		go func() {
-			msg := "ℹ️ Your profile was updated successfully."
-			_ = uc.notify(ctx, user.ID, msg)
-		}()
+		if err := uc.repo.UpdateProfile(txCtx, id, phone); err != nil {
+			return fmt.Errorf("update user profile failed: %w", err)
+		}
 
-		return nil
+		return uc.notify(txCtx, user.ID, notification.EventUserProfileUpdated, nil)
 	})
 }
 
+// SetDeviceToken points push notifications at the user's current device, or stops them for an empty token.
+func (uc *UseCase) SetDeviceToken(ctx context.Context, userID uuid.UUID, token string) error {
+	var t *string
+	if token = strings.TrimSpace(token); token != "" {
+		t = &token
+	}
+	return uc.repo.UpdateDeviceToken(ctx, userID, t)
+}
+
+// SetLocale picks the language the user's notifications are rendered in.
+func (uc *UseCase) SetLocale(ctx context.Context, userID uuid.UUID, locale string) error {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if !notification.IsSupportedLocale(locale) {
+		return notification.ErrUnsupportedLocale
+	}
+	return uc.repo.UpdateLocale(ctx, userID, locale)
+}
+
 // PATCH method for user details
 func (uc *UseCase) UpdateUser(ctx context.Context, userID uuid.UUID, req *domain.UpdateUserRequest) error {
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
@@ -101,16 +153,19 @@ func (uc *UseCase) UpdateUser(ctx context.Context, userID uuid.UUID, req *domain
 			return fmt.Errorf("could not fetch user: %w", err)
 		}
 
-		if err := uc.repo.UpdateColum(txCtx, userID, req.Column, req.Value); err != nil {
-			return fmt.Errorf("update user failed: %w", err)
+		value := req.Value
+		if req.Column == "phone" {
+			raw, _ := value.(string)
+			if value, err = normalizePhone(raw); err != nil {
+				return err
+			}
 		}
 
-		go func() {
-			msg := fmt.Sprintf("ℹ️ Your account column '%s' was updated.", req.Column)
-			_ = uc.notify(ctx, user.ID, msg)
-		}()
+		if err := uc.repo.UpdateColum(txCtx, userID, req.Column, value); err != nil {
+			return fmt.Errorf("update user failed: %w", err)
+		}
 
-		return nil
+		return uc.notify(txCtx, user.ID, notification.EventUserAccountUpdated, notification.TemplateData{"field": req.Column})
 	})
 }
 
@@ -122,11 +177,40 @@ func (uc *UseCase) GetUserByEmail(ctx context.Context, email string) (*domain.Us
 	return uc.repo.GetByEmail(ctx, email)
 }
 
-func (uc *UseCase) ListUsers(ctx context.Context) ([]*domain.User, error) {
-	return uc.repo.List(ctx)
+// GetUserForOwner returns the user only when it belongs to the given store owner's tenant.
+func (uc *UseCase) GetUserForOwner(ctx context.Context, id, ownerID uuid.UUID) (*domain.User, error) {
+	ok, err := uc.repo.BelongsToOwner(ctx, id, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("could not check user tenant: %w", err)
+	}
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+
+	return uc.repo.GetByID(ctx, id)
 }
 
-func (uc *UseCase) DeleteUser(ctx context.Context, id uuid.UUID) error {
+// GetUserOwnedBy returns the user only when ownerID provisioned their account. Changes to a
+// user go through it, so a customer or driver shared between tenants is not edited by any of them.
+func (uc *UseCase) GetUserOwnedBy(ctx context.Context, id, ownerID uuid.UUID) (*domain.User, error) {
+	ok, err := uc.repo.OwnedBy(ctx, id, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("could not check user owner: %w", err)
+	}
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+
+	return uc.repo.GetByID(ctx, id)
+}
+
+func (uc *UseCase) ListUsers(ctx context.Context, ownerID uuid.UUID) ([]*domain.User, error) {
+	return uc.repo.ListByOwner(ctx, ownerID)
+}
+
+// DeleteUser removes the account and tells the acting admin; the deleted user's own
+// notifications go with the account.
+func (uc *UseCase) DeleteUser(ctx context.Context, id, actorID uuid.UUID) error {
 	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
 		user, err := uc.repo.GetByID(txCtx, id)
 		if err != nil {
@@ -137,25 +221,239 @@ func (uc *UseCase) DeleteUser(ctx context.Context, id uuid.UUID) error {
 			return fmt.Errorf("delete user failed: %w", err)
 		}
 
-		go func() {
-			msg := fmt.Sprintf("🗑️ User '%s' has been deleted.", user.FullName)
-			_ = uc.notify(ctx, user.ID, msg)
-		}()
+		return uc.notify(txCtx, actorID, notification.EventUserDeleted, notification.TemplateData{"name": user.FullName})
+	})
+}
+
+func (uc *UseCase) GetAllCustomers(ctx context.Context, ownerID uuid.UUID) ([]domain.AllCustomers, error) {
+	return uc.repo.GetAllCustomers(ctx, ownerID)
+}
+
+// RequestPasswordReset mails a single-use reset link to the account behind the email, if any.
+// Unknown emails are silently ignored so the endpoint cannot be used to probe for accounts.
+func (uc *UseCase) RequestPasswordReset(ctx context.Context, email string) error {
+	u, err := uc.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("could not fetch user: %w", err)
+	}
+
+	raw, err := utils.GenerateToken(32)
+	if err != nil {
+		return fmt.Errorf("could not generate reset token: %w", err)
+	}
+
+	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		reset := &domain.PasswordReset{
+			UserID:    u.ID,
+			TokenHash: utils.HashToken(raw),
+			ExpiresAt: time.Now().Add(uc.links.PasswordResetTTL),
+		}
+		if err := uc.repo.CreatePasswordReset(txCtx, reset); err != nil {
+			return fmt.Errorf("could not store reset token: %w", err)
+		}
+
+		// queued as an email notification, picked up by the notification senders
+		link := fmt.Sprintf("%s?token=%s", uc.links.PasswordResetURL, url.QueryEscape(raw))
+		n := &notification.Notification{
+			UserID: u.ID,
+			Event:  notification.EventPasswordResetRequested,
+			Data: notification.TemplateData{
+				"link": link,
+				"ttl":  uc.links.PasswordResetTTL.String(),
+			},
+			Type:   notification.Email,
+			Status: notification.Pending,
+		}
+		if err := uc.notfRepo.Create(txCtx, n); err != nil {
+			return fmt.Errorf("could not queue reset email: %w", err)
+		}
 
 		return nil
 	})
 }
 
-func (uc *UseCase) GetAllCustomers(ctx context.Context) ([]domain.AllCustomers, error) {
-	return uc.repo.GetAllCustomers(ctx)
+// ResetPassword sets a new password using a token from RequestPasswordReset and ends every session.
+func (uc *UseCase) ResetPassword(ctx context.Context, rawToken, newPassword string) error {
+	if len(newPassword) < domain.MinPasswordLength {
+		return domain.ErrWeakPassword
+	}
+
+	reset, err := uc.repo.GetPasswordResetByHash(ctx, utils.HashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrInvalidResetToken
+		}
+		return fmt.Errorf("could not fetch reset token: %w", err)
+	}
+	if !reset.IsUsable(time.Now()) {
+		return domain.ErrInvalidResetToken
+	}
+
+	hashed, err := domain.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		// claim the token first so two concurrent resets cannot both succeed
+		if err := uc.repo.MarkPasswordResetUsed(txCtx, reset.ID); err != nil {
+			return err
+		}
+
+		if err := uc.repo.UpdatePassword(txCtx, reset.UserID, hashed, false); err != nil {
+			return err
+		}
+
+		if err := uc.sessions.RevokeAllSessionsTx(txCtx, reset.UserID); err != nil {
+			return fmt.Errorf("could not revoke sessions: %w", err)
+		}
+
+		return uc.notify(txCtx, reset.UserID, notification.EventPasswordReset, nil)
+	})
+}
+
+// ChangePassword replaces the password of an authenticated user, clearing must_change_password,
+// and ends every existing session. The caller is expected to issue fresh tokens afterwards.
+func (uc *UseCase) ChangePassword(ctx context.Context, userID uuid.UUID, req *domain.ChangePasswordRequest) (*domain.User, error) {
+	if len(req.NewPassword) < domain.MinPasswordLength {
+		return nil, domain.ErrWeakPassword
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return nil, domain.ErrPasswordUnchanged
+	}
+
+	u, err := uc.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch user: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(req.CurrentPassword)); err != nil {
+		return nil, domain.ErrInvalidPassword
+	}
+
+	hashed, err := domain.HashPassword(req.NewPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	err = uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := uc.repo.UpdatePassword(txCtx, userID, hashed, false); err != nil {
+			return err
+		}
+
+		if err := uc.sessions.RevokeAllSessionsTx(txCtx, userID); err != nil {
+			return fmt.Errorf("could not revoke sessions: %w", err)
+		}
+
+		return uc.notify(txCtx, userID, notification.EventPasswordChanged, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// reload so the caller signs tokens with the bumped token version
+	return uc.repo.GetByID(ctx, userID)
+}
+
+// VerifyEmail consumes a verification token and activates the pending account it was issued for.
+func (uc *UseCase) VerifyEmail(ctx context.Context, rawToken string) error {
+	v, err := uc.repo.GetEmailVerificationByHash(ctx, utils.HashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrInvalidVerificationToken
+		}
+		return fmt.Errorf("could not fetch verification token: %w", err)
+	}
+	if !v.IsUsable(time.Now()) {
+		return domain.ErrInvalidVerificationToken
+	}
+
+	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := uc.repo.MarkEmailVerificationUsed(txCtx, v.ID); err != nil {
+			return err
+		}
+
+		if err := uc.repo.MarkEmailVerified(txCtx, v.UserID); err != nil {
+			return err
+		}
+
+		return uc.notify(txCtx, v.UserID, notification.EventEmailVerified, nil)
+	})
 }
 
-func (uc *UseCase) notify(ctx context.Context, userID uuid.UUID, message string) error {
+// ResendVerification mails a fresh verification link to a still pending account.
+// Unknown or already verified emails are silently ignored.
+func (uc *UseCase) ResendVerification(ctx context.Context, email string) error {
+	u, err := uc.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("could not fetch user: %w", err)
+	}
+
+	if u.Status != domain.Pending || u.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		return uc.sendVerification(txCtx, u)
+	})
+}
+
+func (uc *UseCase) sendVerification(ctx context.Context, u *domain.User) error {
+	raw, err := utils.GenerateToken(32)
+	if err != nil {
+		return fmt.Errorf("could not generate verification token: %w", err)
+	}
+
+	v := &domain.EmailVerification{
+		UserID:    u.ID,
+		TokenHash: utils.HashToken(raw),
+		ExpiresAt: time.Now().Add(uc.links.VerifyEmailTTL),
+	}
+	if err := uc.repo.CreateEmailVerification(ctx, v); err != nil {
+		return fmt.Errorf("could not store verification token: %w", err)
+	}
+
+	link := fmt.Sprintf("%s?token=%s", uc.links.VerifyEmailURL, url.QueryEscape(raw))
+	n := &notification.Notification{
+		UserID: u.ID,
+		Event:  notification.EventEmailVerification,
+		Data: notification.TemplateData{
+			"name": u.FullName,
+			"link": link,
+			"ttl":  uc.links.VerifyEmailTTL.String(),
+		},
+		Type:   notification.Email,
+		Status: notification.Pending,
+	}
+	if err := uc.notfRepo.Create(ctx, n); err != nil {
+		return fmt.Errorf("could not queue verification email: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *UseCase) notify(ctx context.Context, userID uuid.UUID, event notification.Event, data notification.TemplateData) error {
 	n := &notification.Notification{
-		UserID:  userID,
-		Message: message,
-		Type:    notification.System,
-		Status:  notification.Pending,
+		UserID: userID,
+		Event:  event,
+		Data:   data,
+		Type:   notification.System,
+		Status: notification.Pending,
 	}
 	return uc.notfRepo.Create(ctx, n)
 }
+
+// normalizePhone stores phone numbers in E.164 so SMS gateways accept them as-is.
+func normalizePhone(raw string) (string, error) {
+	phone, err := utils.NormalizePhone(raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", domain.ErrInvalidPhone, raw)
+	}
+	return phone, nil
+}
@@ -0,0 +1,80 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	domain "logistics-backend/internal/domain/user"
+
+	"github.com/google/uuid"
+)
+
+// tenantRepo models two store owners: each provisioned one account, and a customer who
+// ordered from both is a member of both tenants while being owned by neither.
+type tenantRepo struct {
+	domain.Repository
+	members map[uuid.UUID][]uuid.UUID
+	owners  map[uuid.UUID]uuid.UUID
+}
+
+func (r tenantRepo) BelongsToOwner(_ context.Context, userID, ownerID uuid.UUID) (bool, error) {
+	for _, id := range r.members[ownerID] {
+		if id == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r tenantRepo) OwnedBy(_ context.Context, userID, ownerID uuid.UUID) (bool, error) {
+	owner, ok := r.owners[userID]
+	return ok && owner == ownerID, nil
+}
+
+func (r tenantRepo) GetByID(_ context.Context, id uuid.UUID) (*domain.User, error) {
+	return &domain.User{ID: id}, nil
+}
+
+func TestCrossTenantAccess(t *testing.T) {
+	ownerA, ownerB := uuid.New(), uuid.New()
+	staffA, staffB, shared := uuid.New(), uuid.New(), uuid.New()
+
+	uc := NewUseCase(tenantRepo{
+		members: map[uuid.UUID][]uuid.UUID{
+			ownerA: {staffA, shared},
+			ownerB: {staffB, shared},
+		},
+		owners: map[uuid.UUID]uuid.UUID{staffA: ownerA, staffB: ownerB},
+	}, nil, nil, nil, nil, Links{})
+
+	cases := []struct {
+		name        string
+		user, owner uuid.UUID
+		read, write bool
+	}{
+		{"own staff", staffA, ownerA, true, true},
+		{"other tenant's staff", staffB, ownerA, false, false},
+		{"shared customer from A", shared, ownerA, true, false},
+		{"shared customer from B", shared, ownerB, true, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := uc.GetUserForOwner(context.Background(), c.user, c.owner)
+			if got := err == nil; got != c.read {
+				t.Errorf("read allowed = %v, want %v (%v)", got, c.read, err)
+			}
+			if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+				t.Errorf("read error = %v, want ErrUserNotFound", err)
+			}
+
+			_, err = uc.GetUserOwnedBy(context.Background(), c.user, c.owner)
+			if got := err == nil; got != c.write {
+				t.Errorf("write allowed = %v, want %v (%v)", got, c.write, err)
+			}
+			if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+				t.Errorf("write error = %v, want ErrUserNotFound", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"logistics-backend/internal/domain/notification"
+	domain "logistics-backend/internal/domain/webhook"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	baseRetryDelay = time.Minute
+	maxRetryDelay  = 6 * time.Hour
+)
+
+// Config tunes the delivery worker. Lease is how long a claimed delivery stays hidden from
+// other workers and must be longer than the poster's timeout.
+type Config struct {
+	BatchSize     int
+	Lease         time.Duration
+	MaxAttempts   int           // per delivery, then it is dead
+	DisableAfter  int           // failed attempts in a row, across deliveries, before the subscription is disabled
+	Retention     time.Duration // how long finished deliveries stay in the log
+	AllowInsecure bool          // accept http:// urls, for local development
+}
+
+// RunDelivery periodically sends due deliveries until ctx is cancelled.
+func (uc *UseCase) RunDelivery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// drain the backlog before waiting for the next tick
+			for {
+				n, err := uc.DeliverDue(ctx)
+				if err != nil {
+					log.Printf("webhook delivery failed: %v", err)
+					break
+				}
+				if n < uc.cfg.BatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// DeliverDue sends one batch of due deliveries and reports how many it claimed.
+func (uc *UseCase) DeliverDue(ctx context.Context) (int, error) {
+	due, err := uc.repo.ClaimDue(ctx, uc.cfg.BatchSize, uc.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, d := range due {
+		uc.deliver(ctx, d)
+	}
+
+	return len(due), nil
+}
+
+// RunCleanup periodically drops finished deliveries older than the retention period.
+func (uc *UseCase) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := uc.repo.DeleteDeliveries(ctx, time.Now().Add(-uc.cfg.Retention)); err != nil {
+				log.Printf("webhook delivery cleanup failed: %v", err)
+			} else if n > 0 {
+				log.Printf("webhook delivery cleanup removed %d deliveries", n)
+			}
+		}
+	}
+}
+
+func (uc *UseCase) deliver(ctx context.Context, d *domain.Delivery) {
+	s, err := uc.repo.GetByID(ctx, d.SubscriptionID)
+	if err != nil {
+		log.Printf("webhook delivery %s: could not fetch subscription: %v", d.ID, err)
+		return // retried once the lease runs out
+	}
+
+	now := time.Now()
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("User-Agent", "FastaBiz-Webhooks/1.0")
+	header.Set(domain.HeaderEventID, d.EventID.String())
+	header.Set(domain.HeaderEventType, string(d.EventType))
+	header.Set(domain.HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	header.Set(domain.HeaderSignature, domain.Sign(s.Secret, now, d.Payload))
+
+	resp, err := uc.poster.Post(ctx, s.URL, header, d.Payload)
+
+	d.ResponseCode, d.ResponseBody, d.DurationMS, d.LastError = nil, nil, nil, nil
+	if resp != nil {
+		ms := int(resp.Duration.Milliseconds())
+		d.ResponseCode, d.ResponseBody, d.DurationMS = &resp.StatusCode, &resp.Body, &ms
+	}
+
+	if err == nil && resp.OK() {
+		d.Status = domain.Succeeded
+		d.DeliveredAt = &now
+		if err := uc.repo.SaveAttempt(ctx, d); err != nil {
+			log.Printf("webhook delivery %s: %v", d.ID, err)
+		}
+		if s.ConsecutiveFailures > 0 {
+			if err := uc.repo.ResetFailures(ctx, s.ID); err != nil {
+				log.Printf("webhook subscription %s: %v", s.ID, err)
+			}
+		}
+		return
+	}
+
+	reason := ""
+	if err != nil {
+		reason = err.Error()
+	} else {
+		reason = fmt.Sprintf("endpoint answered %d", resp.StatusCode)
+	}
+	d.LastError = &reason
+	d.Status = domain.Failed
+	if d.Attempts >= uc.cfg.MaxAttempts {
+		d.Status = domain.Dead
+	}
+	d.NextAttemptAt = now.Add(retryDelay(d.Attempts))
+	if err := uc.repo.SaveAttempt(ctx, d); err != nil {
+		log.Printf("webhook delivery %s: %v", d.ID, err)
+	}
+
+	uc.recordFailure(ctx, s, reason)
+}
+
+// recordFailure counts a failed attempt against the subscription and disables it, telling
+// the owner, once it has failed DisableAfter times in a row.
+func (uc *UseCase) recordFailure(ctx context.Context, s *domain.Subscription, reason string) {
+	disabled, err := uc.repo.RecordFailure(ctx, s.ID, uc.cfg.DisableAfter, reason)
+	if err != nil {
+		log.Printf("webhook subscription %s: %v", s.ID, err)
+		return
+	}
+	if !disabled {
+		return
+	}
+
+	log.Printf("webhook subscription %s disabled after %d failed attempts: %s", s.ID, uc.cfg.DisableAfter, reason)
+	n := &notification.Notification{
+		UserID: s.OwnerID,
+		Event:  notification.EventWebhookDisabled,
+		Data: notification.TemplateData{
+			"url":      s.URL,
+			"failures": strconv.Itoa(uc.cfg.DisableAfter),
+		},
+		Type:   notification.System,
+		Status: notification.Pending,
+	}
+	if err := uc.notfRepo.Create(ctx, n); err != nil {
+		log.Printf("webhook subscription %s: could not queue disabled notice: %v", s.ID, err)
+	}
+}
+
+// retryDelay doubles the wait with every failed attempt, up to maxRetryDelay.
+func retryDelay(attempts int) time.Duration {
+	d := baseRetryDelay
+	for i := 1; i < attempts && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	return min(d, maxRetryDelay)
+}
@@ -0,0 +1,286 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"logistics-backend/internal/domain/event"
+	domain "logistics-backend/internal/domain/webhook"
+	"logistics-backend/internal/usecase/common"
+	"logistics-backend/internal/utils"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UseCase struct {
+	repo      domain.Repository
+	txManager common.TxManager
+	stores    domain.StoreReader
+	notfRepo  domain.NotificationWriter
+	poster    domain.Poster
+	cfg       Config
+}
+
+func NewUseCase(repo domain.Repository, txm common.TxManager, stores domain.StoreReader, notf domain.NotificationWriter, poster domain.Poster, cfg Config) *UseCase {
+	return &UseCase{repo: repo, txManager: txm, stores: stores, notfRepo: notf, poster: poster, cfg: cfg}
+}
+
+// CreateSubscription registers an endpoint for one of the owner's stores and returns the
+// signing secret, which is only shown again when it is rotated.
+func (uc *UseCase) CreateSubscription(ctx context.Context, s *domain.Subscription) (string, error) {
+	if err := uc.validate(s); err != nil {
+		return "", err
+	}
+
+	st, err := uc.stores.GetByID(ctx, s.StoreID)
+	if err != nil || st.OwnerID != s.OwnerID {
+		return "", domain.ErrStoreNotOwned
+	}
+
+	if s.Secret, err = newSecret(); err != nil {
+		return "", err
+	}
+	s.Active = true
+
+	if err := uc.repo.Create(ctx, s); err != nil {
+		return "", err
+	}
+
+	return s.Secret, nil
+}
+
+func (uc *UseCase) ListSubscriptions(ctx context.Context, ownerID uuid.UUID) ([]*domain.Subscription, error) {
+	return uc.repo.ListByOwner(ctx, ownerID)
+}
+
+// GetSubscription returns the subscription only when it belongs to ownerID.
+func (uc *UseCase) GetSubscription(ctx context.Context, id, ownerID uuid.UUID) (*domain.Subscription, error) {
+	s, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("could not fetch webhook subscription: %w", err)
+	}
+	if s.OwnerID != ownerID {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+
+	return s, nil
+}
+
+// UpdateSubscription changes the endpoint, its events or its state. Re-enabling clears the
+// failure count, so the endpoint gets a fresh run before it can be disabled again.
+func (uc *UseCase) UpdateSubscription(ctx context.Context, id, ownerID uuid.UUID, req *domain.UpdateSubscriptionRequest) (*domain.Subscription, error) {
+	s, err := uc.GetSubscription(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		s.URL = *req.URL
+	}
+	if req.Events != nil {
+		s.Events = req.Events
+	}
+	if err := uc.validate(s); err != nil {
+		return nil, err
+	}
+
+	if req.Active != nil && *req.Active != s.Active {
+		s.Active = *req.Active
+		if s.Active {
+			s.ConsecutiveFailures = 0
+			s.DisabledAt, s.DisabledReason = nil, nil
+		} else {
+			now := time.Now()
+			reason := "disabled by owner"
+			s.DisabledAt, s.DisabledReason = &now, &reason
+		}
+	}
+
+	if err := uc.repo.Update(ctx, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RotateSecret replaces the signing secret; deliveries from then on are signed with the new one.
+func (uc *UseCase) RotateSecret(ctx context.Context, id, ownerID uuid.UUID) (*domain.Subscription, string, error) {
+	s, err := uc.GetSubscription(ctx, id, ownerID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if s.Secret, err = newSecret(); err != nil {
+		return nil, "", err
+	}
+	if err := uc.repo.Update(ctx, s); err != nil {
+		return nil, "", err
+	}
+
+	return s, s.Secret, nil
+}
+
+func (uc *UseCase) DeleteSubscription(ctx context.Context, id, ownerID uuid.UUID) error {
+	return uc.repo.Delete(ctx, id, ownerID)
+}
+
+// ListDeliveries returns the subscription's delivery log, newest first.
+func (uc *UseCase) ListDeliveries(ctx context.Context, id, ownerID uuid.UUID, limit, offset int) ([]*domain.Delivery, error) {
+	if _, err := uc.GetSubscription(ctx, id, ownerID); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.ListDeliveries(ctx, id, limit, offset)
+}
+
+// Redeliver sends a logged event again as a new delivery with the same event ID.
+func (uc *UseCase) Redeliver(ctx context.Context, id, deliveryID, ownerID uuid.UUID) (*domain.Delivery, error) {
+	s, err := uc.GetSubscription(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.Active {
+		return nil, domain.ErrSubscriptionDisabled
+	}
+
+	d, err := uc.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("could not fetch webhook delivery: %w", err)
+	}
+	if d.SubscriptionID != s.ID {
+		return nil, domain.ErrDeliveryNotFound
+	}
+
+	again := &domain.Delivery{
+		ID:             uuid.New(),
+		SubscriptionID: s.ID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        d.Payload,
+		Status:         domain.Pending,
+		NextAttemptAt:  time.Now(),
+	}
+	if err := uc.repo.CreateDelivery(ctx, again); err != nil {
+		return nil, err
+	}
+	return again, nil
+}
+
+// Events lists the domain events HandleEvent sends to store webhooks.
+var Events = []event.Name{
+	event.NameOrderCreated,
+	event.NameOrderAssigned,
+	event.NameDeliveryAccepted,
+	event.NameDeliveryCompleted,
+	event.NamePaymentCompleted,
+}
+
+// HandleEvent queues a delivery of a domain event for every active subscription of the order's
+// store that wants it. The event ID, with the subscription, fixes the delivery ID, so handling
+// an event again queues nothing new.
+func (uc *UseCase) HandleEvent(ctx context.Context, env *event.Envelope) error {
+	var (
+		t       domain.EventType
+		orderID uuid.UUID
+	)
+	switch e := env.Event.(type) {
+	case event.OrderCreated:
+		t, orderID = domain.EventOrderCreated, e.OrderID
+	case event.OrderAssigned:
+		t, orderID = domain.EventOrderAssigned, e.OrderID
+	case event.DeliveryAccepted:
+		t, orderID = domain.EventOrderPickedUp, e.OrderID
+	case event.DeliveryCompleted:
+		t, orderID = domain.EventOrderDelivered, e.OrderID
+	case event.PaymentCompleted:
+		t, orderID = domain.EventPaymentCompleted, e.OrderID
+	default:
+		return nil
+	}
+
+	storeID, err := uc.repo.StoreIDForOrder(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil // the order is gone, nobody to tell
+		}
+		return fmt.Errorf("could not resolve store for order %s: %w", orderID, err)
+	}
+
+	subs, err := uc.repo.ListActiveForEvent(ctx, storeID, t)
+	if err != nil || len(subs) == 0 {
+		return err
+	}
+
+	data, err := json.Marshal(env.Event)
+	if err != nil {
+		return fmt.Errorf("encode webhook data: %w", err)
+	}
+	body, err := json.Marshal(domain.Event{
+		ID:        env.ID,
+		Type:      t,
+		CreatedAt: env.OccurredAt,
+		StoreID:   storeID,
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("encode webhook event: %w", err)
+	}
+
+	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		for _, s := range subs {
+			d := &domain.Delivery{
+				ID:             uuid.NewSHA1(env.ID, s.ID[:]),
+				SubscriptionID: s.ID,
+				EventID:        env.ID,
+				EventType:      t,
+				Payload:        body,
+				Status:         domain.Pending,
+				NextAttemptAt:  time.Now(),
+			}
+			if err := uc.repo.CreateDelivery(txCtx, d); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (uc *UseCase) validate(s *domain.Subscription) error {
+	u, err := url.Parse(strings.TrimSpace(s.URL))
+	if err != nil || u.Host == "" || u.User != nil || (u.Scheme != "https" && !(uc.cfg.AllowInsecure && u.Scheme == "http")) {
+		return domain.ErrInvalidURL
+	}
+	s.URL = u.String()
+
+	if len(s.Events) == 0 {
+		return domain.ErrNoEvents
+	}
+	for _, e := range s.Events {
+		if !domain.IsEventType(e) {
+			return fmt.Errorf("%w: %s", domain.ErrInvalidEvent, e)
+		}
+	}
+	slices.Sort(s.Events)
+	s.Events = slices.Compact(s.Events)
+
+	return nil
+}
+
+func newSecret() (string, error) {
+	raw, err := utils.GenerateToken(32)
+	if err != nil {
+		return "", fmt.Errorf("could not generate webhook secret: %w", err)
+	}
+	return domain.SecretPrefix + raw, nil
+}
@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const minRSABits = 2048
+
+// SigningKey is one JWT key, identified by the kid header. Private is nil for retired keys
+// that are only kept so tokens they signed still verify.
+type SigningKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// KeySet holds the key that signs new access tokens and every key whose tokens are still accepted.
+//
+// Keys are read once from a directory: <kid>.pem holds a private key (RSA for RS256, Ed25519 for
+// EdDSA), <kid>.pub.pem a public key only. To rotate, add a new key and make it active; keep the
+// old one (its .pub.pem is enough) until the access tokens it signed have expired.
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+func LoadKeySet(dir, activeID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &KeySet{keys: make(map[string]*SigningKey)}
+	for _, path := range paths {
+		k, err := loadKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		if _, dup := ks.keys[k.ID]; dup {
+			return nil, fmt.Errorf("duplicate key id %q", k.ID)
+		}
+		ks.keys[k.ID] = k
+	}
+
+	active, ok := ks.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("active key %q not found in %s", activeID, dir)
+	}
+	if active.Private == nil {
+		return nil, fmt.Errorf("active key %q has no private key", activeID)
+	}
+	ks.active = active
+
+	return ks, nil
+}
+
+// SignAccessToken signs the claims with the active key and names it in the kid header.
+func (ks *KeySet) SignAccessToken(claims map[string]any) (string, error) {
+	t := jwt.NewWithClaims(ks.active.Method, jwt.MapClaims(claims))
+	t.Header["kid"] = ks.active.ID
+	return t.SignedString(ks.active.Private)
+}
+
+// Keyfunc picks the verification key named by the token's kid, for use with jwt.Parse.
+func (ks *KeySet) Keyfunc(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	k, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if t.Method.Alg() != k.Method.Alg() {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return k.Public, nil
+}
+
+// Methods lists the algorithms of the loaded keys, for jwt.WithValidMethods.
+func (ks *KeySet) Methods() []string {
+	seen := map[string]bool{}
+	var methods []string
+	for _, k := range ks.keys {
+		if alg := k.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// JWK is a public key in JSON Web Key form (RFC 7517, RFC 8037 for Ed25519).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public half of every key, active one first.
+func (ks *KeySet) JWKS() JWKS {
+	ids := make([]string, 0, len(ks.keys))
+	for id := range ks.keys {
+		if id != ks.active.ID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	ids = append([]string{ks.active.ID}, ids...)
+
+	set := JWKS{Keys: make([]JWK, 0, len(ids))}
+	for _, id := range ids {
+		k := ks.keys[id]
+		jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Method.Alg()}
+		switch pub := k.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+func loadKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	name := filepath.Base(path)
+	if id, ok := strings.CutSuffix(name, ".pub.pem"); ok {
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key: %w", err)
+		}
+		return newSigningKey(id, nil, pub)
+	}
+
+	var priv any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	return newSigningKey(strings.TrimSuffix(name, ".pem"), signer, signer.Public())
+}
+
+func newSigningKey(id string, priv crypto.Signer, pub crypto.PublicKey) (*SigningKey, error) {
+	k := &SigningKey{ID: id, Private: priv, Public: pub}
+
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("rsa key must be at least %d bits", minRSABits)
+		}
+		k.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		k.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, errors.New("only RSA (RS256) and Ed25519 (EdDSA) keys are supported")
+	}
+
+	return k, nil
+}
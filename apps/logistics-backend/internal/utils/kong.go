@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// kongSecretsPlaceholder is the line in kong/kong.yml that RenderKongConfig replaces with
+// one jwt_secrets entry per key.
+const kongSecretsPlaceholder = "    jwt_secrets: []"
+
+// KongJWTSecrets renders the jwt_secrets list of a Kong consumer, one entry per kid, so the
+// gateway accepts exactly the keys the backend verifies. Kong 3.5's jwt plugin cannot verify
+// EdDSA, so those keys are left out and returned in skipped.
+func (ks *KeySet) KongJWTSecrets() (secrets string, skipped []string, err error) {
+	ids := make([]string, 0, len(ks.keys))
+	for id := range ks.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var b strings.Builder
+	b.WriteString("    jwt_secrets:\n")
+	written := 0
+	for _, id := range ids {
+		k := ks.keys[id]
+		if k.Method == jwt.SigningMethodEdDSA {
+			skipped = append(skipped, id)
+			continue
+		}
+		der, err := x509.MarshalPKIXPublicKey(k.Public)
+		if err != nil {
+			return "", nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		fmt.Fprintf(&b, "      - key: %s\n", k.ID)
+		fmt.Fprintf(&b, "        algorithm: %s\n", k.Method.Alg())
+		b.WriteString("        rsa_public_key: |\n")
+		for _, line := range strings.SplitAfter(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), "\n") {
+			if line != "" {
+				b.WriteString("          " + line)
+			}
+		}
+		written++
+	}
+	if written == 0 {
+		return "", skipped, errors.New("no key Kong can verify (Kong needs an RS256 key)")
+	}
+
+	return b.String(), skipped, nil
+}
+
+// RenderKongConfig writes the declarative config at templatePath to outPath with the consumer's
+// empty jwt_secrets list filled in from the key set. The file is replaced atomically, so Kong
+// never reads a half written config.
+func RenderKongConfig(templatePath, outPath string, ks *KeySet) (skipped []string, err error) {
+	tmpl, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := bytes.Split(tmpl, []byte("\n"))
+	at := -1
+	for i, line := range lines {
+		if string(bytes.TrimRight(line, " \r")) == kongSecretsPlaceholder {
+			if at >= 0 {
+				return nil, fmt.Errorf("%s: more than one %q line", templatePath, strings.TrimSpace(kongSecretsPlaceholder))
+			}
+			at = i
+		}
+	}
+	if at < 0 {
+		return nil, fmt.Errorf("%s: no %q line to fill in", templatePath, strings.TrimSpace(kongSecretsPlaceholder))
+	}
+
+	secrets, skipped, err := ks.KongJWTSecrets()
+	if err != nil {
+		return skipped, err
+	}
+	lines[at] = []byte(strings.TrimSuffix(secrets, "\n"))
+	out := bytes.Join(lines, []byte("\n"))
+
+	tmp, err := os.CreateTemp(filepath.Dir(outPath), ".kong-*.yml")
+	if err != nil {
+		return skipped, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		return skipped, err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return skipped, err
+	}
+	if err := tmp.Close(); err != nil {
+		return skipped, err
+	}
+
+	return skipped, os.Rename(tmp.Name(), outPath)
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func writeKey(t *testing.T, dir, name string, key any) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, name), pemBytes, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRenderKongConfig(t *testing.T) {
+	dir := t.TempDir()
+	for _, kid := range []string{"new", "old"} {
+		k, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeKey(t, dir, kid+".pem", k)
+	}
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	writeKey(t, dir, "ed.pem", edKey)
+
+	ks, err := LoadKeySet(dir, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := filepath.Join(dir, "kong.yml")
+	os.WriteFile(template, []byte("_format_version: \"3.0\"\nconsumers:\n  - username: test-user\n    jwt_secrets: []\n"), 0o644)
+	out := filepath.Join(dir, "rendered.yml")
+
+	skipped, err := RenderKongConfig(template, out, ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(skipped, []string{"ed"}) {
+		t.Errorf("skipped = %v, want [ed]", skipped)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg struct {
+		Consumers []struct {
+			Username   string `yaml:"username"`
+			JWTSecrets []struct {
+				Key          string `yaml:"key"`
+				Algorithm    string `yaml:"algorithm"`
+				RSAPublicKey string `yaml:"rsa_public_key"`
+			} `yaml:"jwt_secrets"`
+		} `yaml:"consumers"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("rendered config is not valid yaml: %v\n%s", err, data)
+	}
+	secrets := cfg.Consumers[0].JWTSecrets
+	if len(secrets) != 2 || secrets[0].Key != "new" || secrets[1].Key != "old" {
+		t.Fatalf("jwt_secrets = %+v, want entries for new and old", secrets)
+	}
+	for _, s := range secrets {
+		block, _ := pem.Decode([]byte(s.RSAPublicKey))
+		if s.Algorithm != "RS256" || block == nil || !strings.HasSuffix(s.RSAPublicKey, "-----END PUBLIC KEY-----\n") {
+			t.Errorf("%s: algorithm %s, public key %q", s.Key, s.Algorithm, s.RSAPublicKey)
+			continue
+		}
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil || !pub.(*rsa.PublicKey).Equal(ks.keys[s.Key].Public) {
+			t.Errorf("%s: public key does not match the loaded key (%v)", s.Key, err)
+		}
+	}
+}
+
+func TestRenderKongConfigNeedsPlaceholder(t *testing.T) {
+	dir := t.TempDir()
+	k, _ := rsa.GenerateKey(rand.Reader, 2048)
+	writeKey(t, dir, "k.pem", k)
+	ks, err := LoadKeySet(dir, "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := filepath.Join(dir, "kong.yml")
+	os.WriteFile(template, []byte("consumers:\n  - username: test-user\n"), 0o644)
+	if _, err := RenderKongConfig(template, filepath.Join(dir, "out.yml"), ks); err == nil {
+		t.Error("rendered a config without a jwt_secrets placeholder")
+	}
+}
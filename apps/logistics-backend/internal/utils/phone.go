@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultCallingCode is assumed for numbers written in national format, e.g. 0712 345678 in Kenya.
+const DefaultCallingCode = "254"
+
+var ErrInvalidPhone = errors.New("phone number is not valid")
+
+// NormalizePhone converts a phone number to E.164 (+<country code><number>). Spaces, dashes,
+// dots and brackets are ignored; national numbers get DefaultCallingCode.
+func NormalizePhone(raw string) (string, error) {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(raw) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+	digits := b.String()
+	international := strings.HasPrefix(strings.TrimSpace(raw), "+")
+
+	switch {
+	case international:
+	case strings.HasPrefix(digits, "00"):
+		digits = digits[2:]
+	case strings.HasPrefix(digits, "0"):
+		digits = DefaultCallingCode + digits[1:]
+	case len(digits) == 9:
+		// national number typed without the trunk 0, e.g. 712345678
+		digits = DefaultCallingCode + digits
+	}
+
+	// E.164 allows at most 15 digits; anything under 8 is not a subscriber number
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", ErrInvalidPhone
+	}
+
+	return "+" + digits, nil
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"logistics-backend/handlers"
 	deliveryadapter "logistics-backend/internal/adapters/delivery"
@@ -12,22 +16,43 @@ import (
 	notificationadapter "logistics-backend/internal/adapters/notification"
 	orderadapter "logistics-backend/internal/adapters/order"
 	useradapter "logistics-backend/internal/adapters/user"
+	"logistics-backend/internal/domain/event"
+	"logistics-backend/internal/domain/mfa"
+	"logistics-backend/internal/domain/notification"
+	"logistics-backend/internal/domain/outbox"
+	"logistics-backend/internal/domain/payment"
+	"logistics-backend/internal/domain/user"
+	"logistics-backend/internal/gateway"
+	authMiddleware "logistics-backend/internal/middleware"
+	"logistics-backend/internal/repository/filesystem"
 	"logistics-backend/internal/repository/postgres"
 	"logistics-backend/internal/router"
+	"logistics-backend/internal/sender"
+	apikeyUsecase "logistics-backend/internal/usecase/apikey"
 	deliveryUsecase "logistics-backend/internal/usecase/delivery"
+	documentUsecase "logistics-backend/internal/usecase/document"
 	driverUsecase "logistics-backend/internal/usecase/driver"
+	eventUsecase "logistics-backend/internal/usecase/event"
 	feedbackUsecase "logistics-backend/internal/usecase/feedback"
 	inventoryUsecase "logistics-backend/internal/usecase/inventory"
 	inviteUsecase "logistics-backend/internal/usecase/invite"
+	lockoutUsecase "logistics-backend/internal/usecase/lockout"
+	mfaUsecase "logistics-backend/internal/usecase/mfa"
 	notificationUsecase "logistics-backend/internal/usecase/notification"
 	orderUsecase "logistics-backend/internal/usecase/order"
+	outboxUsecase "logistics-backend/internal/usecase/outbox"
 	paymentUsecase "logistics-backend/internal/usecase/payment"
+	sessionUsecase "logistics-backend/internal/usecase/session"
 	storeUsecase "logistics-backend/internal/usecase/store"
 	userUsecase "logistics-backend/internal/usecase/user"
+	webhookUsecase "logistics-backend/internal/usecase/webhook"
+	"logistics-backend/internal/utils"
 
 	"logistics-backend/internal/application"
+	"logistics-backend/internal/domain/money"
 
 	_ "logistics-backend/docs"
+	_ "time/tzdata" // quiet hours use IANA timezones; the runtime image has no zoneinfo
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -43,6 +68,10 @@ import (
 // @securityDefinitions.apikey JWT
 // @in header
 // @name Authorization
+
+// @securityDefinitions.apikey APIKey
+// @in header
+// @name X-API-Key
 func main() {
 	dbUrl := os.Getenv("DATABASE_URL")
 	if dbUrl == "" {
@@ -54,6 +83,180 @@ func main() {
 		log.Fatal("PUBLIC_API_BASE_URL not set")
 	}
 
+	blobDir := os.Getenv("BLOB_STORAGE_DIR")
+	if blobDir == "" {
+		blobDir = "./uploads"
+	}
+
+	// Flat driver payout per completed delivery, in cents
+	payoutCents, err := strconv.ParseInt(os.Getenv("DRIVER_PAYOUT_PER_DELIVERY"), 10, 64)
+	if err != nil {
+		payoutCents = 20000
+	}
+	payoutCurrency := os.Getenv("DRIVER_PAYOUT_CURRENCY")
+	if payoutCurrency == "" {
+		payoutCurrency = "KES"
+	}
+
+	// Access token signing keys, <kid>.pem per key; JWT_ACTIVE_KEY_ID signs new tokens,
+	// the others are only used to verify tokens issued before a rotation
+	jwtKeysDir := os.Getenv("JWT_KEYS_DIR")
+	if jwtKeysDir == "" {
+		jwtKeysDir = "./keys"
+	}
+	jwtKeys, err := utils.LoadKeySet(jwtKeysDir, os.Getenv("JWT_ACTIVE_KEY_ID"))
+	if err != nil {
+		log.Fatalf("could not load jwt signing keys: %v", err)
+	}
+
+	// Kong verifies tokens itself; give it the public half of the same keys by rendering its
+	// declarative config from kong/kong.yml. Kong starts once the backend is healthy.
+	if kongOut := os.Getenv("KONG_CONFIG_OUT"); kongOut != "" {
+		kongTemplate := os.Getenv("KONG_CONFIG_TEMPLATE")
+		if kongTemplate == "" {
+			kongTemplate = "./kong/kong.yml"
+		}
+		skipped, err := utils.RenderKongConfig(kongTemplate, kongOut, jwtKeys)
+		if err != nil {
+			log.Fatalf("could not render kong config: %v", err)
+		}
+		if len(skipped) > 0 {
+			log.Printf("warning: kong cannot verify EdDSA keys %v; only services reading the JWKS accept their tokens", skipped)
+		}
+	}
+
+	// Access tokens are short-lived; refresh tokens rotate on every use
+	accessTTL, err := time.ParseDuration(os.Getenv("ACCESS_TOKEN_TTL"))
+	if err != nil {
+		accessTTL = 15 * time.Minute
+	}
+	refreshTTL, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL"))
+	if err != nil {
+		refreshTTL = 30 * 24 * time.Hour
+	}
+
+	// Two-factor authentication: issuer shown in authenticator apps, and roles that may not opt out
+	mfaIssuer := os.Getenv("MFA_ISSUER")
+	if mfaIssuer == "" {
+		mfaIssuer = "Logistics"
+	}
+	mfaPolicy := mfa.Policy{RequiredRoles: []user.Role{user.Admin}}
+	if roles, ok := os.LookupEnv("MFA_REQUIRED_ROLES"); ok {
+		mfaPolicy.RequiredRoles = nil
+		for _, role := range strings.Split(roles, ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				mfaPolicy.RequiredRoles = append(mfaPolicy.RequiredRoles, user.Role(role))
+			}
+		}
+	}
+
+	// Failed login throttling: per account and per client IP within a sliding window
+	lockoutCfg := lockoutUsecase.Config{
+		MaxAccountFailures: 5,
+		MaxIPFailures:      20,
+		Window:             15 * time.Minute,
+		LockoutDuration:    15 * time.Minute,
+	}
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_MAX_FAILURES")); err == nil && n > 0 {
+		lockoutCfg.MaxAccountFailures = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_MAX_IP_FAILURES")); err == nil && n > 0 {
+		lockoutCfg.MaxIPFailures = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("LOGIN_LOCKOUT_DURATION")); err == nil {
+		lockoutCfg.LockoutDuration = d
+	}
+
+	// Proxies whose X-Forwarded-For is believed (Kong); without any, the peer address is the client
+	trustedProxies, err := authMiddleware.ParseProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
+
+	// Frontend page that receives the emailed reset token as ?token=
+	passwordResetURL := os.Getenv("PASSWORD_RESET_URL")
+	if passwordResetURL == "" {
+		passwordResetURL = "http://localhost:3000/reset-password"
+	}
+	passwordResetTTL, err := time.ParseDuration(os.Getenv("PASSWORD_RESET_TTL"))
+	if err != nil {
+		passwordResetTTL = time.Hour
+	}
+
+	// Frontend page that receives the emailed verification token as ?token=
+	verifyEmailURL := os.Getenv("EMAIL_VERIFICATION_URL")
+	if verifyEmailURL == "" {
+		verifyEmailURL = "http://localhost:3000/verify-email"
+	}
+	verifyEmailTTL, err := time.ParseDuration(os.Getenv("EMAIL_VERIFICATION_TTL"))
+	if err != nil {
+		verifyEmailTTL = 24 * time.Hour
+	}
+
+	// Frontend page that receives the emailed invite token as ?token=
+	inviteAcceptURL := os.Getenv("INVITE_ACCEPT_URL")
+	if inviteAcceptURL == "" {
+		inviteAcceptURL = "http://localhost:3000/accept-invite"
+	}
+	inviteTTL, err := time.ParseDuration(os.Getenv("INVITE_TTL"))
+	if err != nil {
+		inviteTTL = 7 * 24 * time.Hour
+	}
+
+	// Transactional outbox dispatcher: how often it polls and how often a failing message is retried
+	outboxInterval, err := time.ParseDuration(os.Getenv("OUTBOX_POLL_INTERVAL"))
+	if err != nil {
+		outboxInterval = 2 * time.Second
+	}
+	outboxCfg := outboxUsecase.Config{
+		BatchSize:   100,
+		Lease:       time.Minute,
+		MaxAttempts: 10,
+		Retention:   7 * 24 * time.Hour,
+	}
+	if n, err := strconv.Atoi(os.Getenv("OUTBOX_MAX_ATTEMPTS")); err == nil && n > 0 {
+		outboxCfg.MaxAttempts = n
+	}
+
+	// Notification delivery worker: poll interval and attempts before a notification is marked dead
+	deliveryInterval, err := time.ParseDuration(os.Getenv("NOTIFICATION_POLL_INTERVAL"))
+	if err != nil {
+		deliveryInterval = 5 * time.Second
+	}
+	deliveryCfg := notificationUsecase.DeliveryConfig{
+		BatchSize:   50,
+		Lease:       2 * time.Minute,
+		MaxAttempts: 8,
+	}
+	if n, err := strconv.Atoi(os.Getenv("NOTIFICATION_MAX_ATTEMPTS")); err == nil && n > 0 {
+		deliveryCfg.MaxAttempts = n
+	}
+
+	// Webhook delivery worker: poll interval, attempts per delivery, failures in a row before a
+	// subscription is disabled and the per-request timeout; insecure allows http:// and private addresses
+	webhookInterval, err := time.ParseDuration(os.Getenv("WEBHOOK_POLL_INTERVAL"))
+	if err != nil {
+		webhookInterval = 5 * time.Second
+	}
+	webhookTimeout, err := time.ParseDuration(os.Getenv("WEBHOOK_TIMEOUT"))
+	if err != nil {
+		webhookTimeout = 10 * time.Second
+	}
+	webhookCfg := webhookUsecase.Config{
+		BatchSize:     50,
+		Lease:         2 * time.Minute,
+		MaxAttempts:   8,
+		DisableAfter:  20,
+		Retention:     30 * 24 * time.Hour,
+		AllowInsecure: os.Getenv("WEBHOOK_ALLOW_INSECURE") == "true",
+	}
+	if n, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS")); err == nil && n > 0 {
+		webhookCfg.MaxAttempts = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("WEBHOOK_DISABLE_AFTER")); err == nil && n > 0 {
+		webhookCfg.DisableAfter = n
+	}
+
 	db := sqlx.MustConnect("postgres", dbUrl)
 
 	txm := application.NewTxManager(db)
@@ -64,22 +267,168 @@ func main() {
 	driverRepo := postgres.NewDriverRepository(db)
 	deliveryRepo := postgres.NewDeliveryRepository(db)
 	paymentRepo := postgres.NewPaymentRepository(db)
+	cashRepo := postgres.NewCashRepository(db)
 	feedbackRepo := postgres.NewFeedbackRepository(db)
 	notificationRepo := postgres.NewNotificationRepository(db)
 	inventoryRepo := postgres.NewInventoryRespository(db)
 	inviteRepo := postgres.NewInviteRepository(db)
 	storeRepo := postgres.NewStoreRepository(db)
+	documentRepo := postgres.NewDocumentRepository(db)
+	sessionRepo := postgres.NewSessionRepository(db)
+	lockoutRepo := postgres.NewLockoutRepository(db)
+	mfaRepo := postgres.NewMFARepository(db)
+	apiKeyRepo := postgres.NewAPIKeyRepository(db)
+	outboxRepo := postgres.NewOutboxRepository(db)
+	templateRepo := postgres.NewNotificationTemplateRepository(db)
+	preferenceRepo := postgres.NewNotificationPreferenceRepository(db)
+	webhookRepo := postgres.NewWebhookRepository(db)
+
+	// Set up blob storage
+	blobStorage, err := filesystem.NewLocalBlobStorage(blobDir)
+	if err != nil {
+		log.Fatalf("could not set up blob storage: %v", err)
+	}
+
+	// Built-in notification templates are checked once at startup
+	notificationTemplates, err := notificationUsecase.NewTemplates(templateRepo)
+	if err != nil {
+		log.Fatalf("invalid notification templates: %v", err)
+	}
+
+	// Set up notification senders; notifications on a channel without a provider are dead-lettered
+	logSender := sender.LogSender{}
+	var emailSender notification.EmailSender = logSender
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		smtpPort, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
+		smtpSender, err := sender.NewSMTPSender(sender.SMTPConfig{
+			Host:     host,
+			Port:     smtpPort,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+			Security: os.Getenv("SMTP_SECURITY"),
+		})
+		if err != nil {
+			log.Fatalf("could not set up smtp sender: %v", err)
+		}
+		emailSender = smtpSender
+	}
+	var smsSender notification.SMSSender = logSender
+	var smsReports handlers.DeliveryReportParser
+	maxSMSSegments, _ := strconv.Atoi(os.Getenv("SMS_MAX_SEGMENTS"))
+	switch provider := os.Getenv("SMS_PROVIDER"); provider {
+	case "":
+	case "africastalking":
+		baseURL := os.Getenv("AT_BASE_URL")
+		if baseURL == "" {
+			baseURL = sender.AfricasTalkingLiveURL
+		}
+		sms := sender.NewSMSSender(&sender.AfricasTalking{
+			BaseURL:       baseURL,
+			Username:      os.Getenv("AT_USERNAME"),
+			APIKey:        os.Getenv("AT_API_KEY"),
+			SenderID:      os.Getenv("AT_SENDER_ID"),
+			CallbackToken: os.Getenv("AT_CALLBACK_TOKEN"),
+		}, maxSMSSegments)
+		smsSender, smsReports = sms, sms
+	case "twilio":
+		baseURL := os.Getenv("TWILIO_BASE_URL")
+		if baseURL == "" {
+			baseURL = sender.TwilioURL
+		}
+		sms := sender.NewSMSSender(&sender.Twilio{
+			BaseURL:           baseURL,
+			AccountSID:        os.Getenv("TWILIO_ACCOUNT_SID"),
+			AuthToken:         os.Getenv("TWILIO_AUTH_TOKEN"),
+			From:              os.Getenv("TWILIO_FROM"),
+			StatusCallbackURL: os.Getenv("TWILIO_STATUS_CALLBACK_URL"),
+		}, maxSMSSegments)
+		smsSender, smsReports = sms, sms
+	default:
+		log.Fatalf("unknown SMS_PROVIDER %q, expected africastalking or twilio", provider)
+	}
+	notificationSender := notification.NewMultiChannelSender(emailSender, smsSender, logSender)
+
+	// Payment gateways; a method without one can only be recorded by an admin
+	var paymentProviders []payment.Provider
+	paymentCallbacks := map[payment.PaymentMethod]handlers.PaymentCallbackParser{}
+	if key := os.Getenv("MPESA_CONSUMER_KEY"); key != "" {
+		baseURL := os.Getenv("MPESA_BASE_URL")
+		if baseURL == "" {
+			baseURL = gateway.MpesaLiveURL
+		}
+		mpesa := &gateway.Mpesa{
+			BaseURL:         baseURL,
+			ConsumerKey:     key,
+			ConsumerSecret:  os.Getenv("MPESA_CONSUMER_SECRET"),
+			ShortCode:       os.Getenv("MPESA_SHORTCODE"),
+			PassKey:         os.Getenv("MPESA_PASSKEY"),
+			TransactionType: os.Getenv("MPESA_TRANSACTION_TYPE"),
+			CallbackURL:     os.Getenv("MPESA_CALLBACK_URL"),
+			CallbackToken:   os.Getenv("MPESA_CALLBACK_TOKEN"),
+		}
+		if mpesa.CallbackURL == "" || mpesa.CallbackToken == "" {
+			log.Fatal("MPESA_CALLBACK_URL and MPESA_CALLBACK_TOKEN are required with MPESA_CONSUMER_KEY")
+		}
+		paymentProviders = append(paymentProviders, mpesa)
+		paymentCallbacks[payment.MethodMobileMoney] = mpesa
+	}
+	if key := os.Getenv("STRIPE_SECRET_KEY"); key != "" {
+		baseURL := os.Getenv("STRIPE_BASE_URL")
+		if baseURL == "" {
+			baseURL = gateway.StripeURL
+		}
+		stripe := &gateway.Stripe{
+			BaseURL:       baseURL,
+			SecretKey:     key,
+			WebhookSecret: os.Getenv("STRIPE_WEBHOOK_SECRET"),
+		}
+		if stripe.WebhookSecret == "" {
+			log.Fatal("STRIPE_WEBHOOK_SECRET is required with STRIPE_SECRET_KEY")
+		}
+		paymentProviders = append(paymentProviders, stripe)
+		paymentCallbacks[payment.MethodStripe] = stripe
+	}
 
 	// Set up usecase
+	// Notifications raised by use cases are queued in the outbox within their transaction
+	outboxUC := outboxUsecase.NewUseCase(outboxRepo, outboxCfg)
+	notificationOutbox := &notificationadapter.OutboxAdapter{Outbox: outboxUC}
+	// Domain events reach async subscribers through the outbox too, and sync ones after commit
+	eventBus := eventUsecase.NewBus(outboxUC, txm)
+	outboxUC.Register(outbox.TopicDomainEvent, outbox.HandlerFunc(eventBus.HandleQueued))
+
 	// Individual
-	inviteUC := inviteUsecase.NewUseCase(inviteRepo, txm)
-	driverUC := driverUsecase.NewUseCase(driverRepo, txm, notificationRepo)
-	userUC := userUsecase.NewUseCase(userRepo, driverUC, txm, notificationRepo)
-	inventoryUC := inventoryUsecase.NewUseCase(inventoryRepo, txm, notificationRepo, storeRepo)
-	orderUC := orderUsecase.NewUseCase(orderRepo, &inventoryadapter.UseCaseAdapter{UseCase: inventoryUC}, &useradapter.UseCaseAdapter{UseCase: userUC}, txm, notificationRepo, storeRepo)
-	deliveryUC := deliveryUsecase.NewUseCase(deliveryRepo, &orderadapter.UseCaseAdapter{UseCase: orderUC}, &driveradapter.UseCaseAdapter{UseCase: driverUC}, txm, notificationRepo)
-	notificationUC := notificationUsecase.NewUseCase(notificationRepo, txm)
+	driverUC := driverUsecase.NewUseCase(driverRepo, txm, notificationOutbox)
+	mfaUC := mfaUsecase.NewUseCase(mfaRepo, userRepo, txm, notificationOutbox, mfaIssuer, mfaPolicy)
+	sessionUC := sessionUsecase.NewUseCase(sessionRepo, userRepo, txm, jwtKeys, accessTTL, refreshTTL, mfaUC)
+	userUC := userUsecase.NewUseCase(userRepo, driverUC, txm, notificationOutbox, sessionUC, userUsecase.Links{
+		PasswordResetURL: passwordResetURL,
+		PasswordResetTTL: passwordResetTTL,
+		VerifyEmailURL:   verifyEmailURL,
+		VerifyEmailTTL:   verifyEmailTTL,
+	})
+	inviteUC := inviteUsecase.NewUseCase(inviteRepo, userUC, txm, notificationOutbox, inviteAcceptURL, inviteTTL)
+	inventoryUC := inventoryUsecase.NewUseCase(inventoryRepo, txm, eventBus, storeRepo)
+	orderUC := orderUsecase.NewUseCase(orderRepo, &inventoryadapter.UseCaseAdapter{UseCase: inventoryUC}, &useradapter.UseCaseAdapter{UseCase: userUC}, txm, eventBus, storeRepo)
+	deliveryUC := deliveryUsecase.NewUseCase(deliveryRepo, &orderadapter.UseCaseAdapter{UseCase: orderUC}, &driveradapter.UseCaseAdapter{UseCase: driverUC}, txm, eventBus, money.FromCents(payoutCents, payoutCurrency))
+	notificationUC := notificationUsecase.NewUseCase(notificationRepo, txm, userRepo, notificationSender, notificationTemplates, preferenceRepo, deliveryCfg)
+	outboxUC.Register(outbox.TopicNotificationCreate, outbox.HandlerFunc(notificationUC.CreateFromOutbox))
 	storeUC := storeUsecase.NewUseCase(storeRepo, txm)
+	apiKeyUC := apikeyUsecase.NewUseCase(apiKeyRepo, storeRepo)
+	webhookUC := webhookUsecase.NewUseCase(webhookRepo, txm, storeRepo, notificationOutbox, sender.NewWebhookPoster(webhookTimeout, webhookCfg.AllowInsecure), webhookCfg)
+
+	// Domain event subscribers: notifications, webhooks and order updates are durable, the event log is synchronous
+	for _, name := range notificationUsecase.Events {
+		eventBus.SubscribeAsync("notifications", name, event.HandlerFunc(notificationUC.HandleEvent))
+	}
+	for _, name := range webhookUsecase.Events {
+		eventBus.SubscribeAsync("webhooks", name, event.HandlerFunc(webhookUC.HandleEvent))
+	}
+	for _, name := range orderUsecase.Events {
+		eventBus.SubscribeAsync("orders", name, event.HandlerFunc(orderUC.HandleEvent))
+	}
+	eventBus.Subscribe(event.All, event.HandlerFunc(eventUsecase.Log))
 
 	// Combined cross-domain service
 	orderService := application.NewOrderService(
@@ -92,20 +441,45 @@ func main() {
 	)
 
 	// Other usecases
-	paymentUC := paymentUsecase.NewUseCase(paymentRepo, txm)
+	paymentUC := paymentUsecase.NewUseCase(paymentRepo, cashRepo, txm, &orderadapter.UseCaseAdapter{UseCase: orderUC}, &inventoryadapter.UseCaseAdapter{UseCase: inventoryUC}, &deliveryadapter.UseCaseAdapter{UseCase: deliveryUC}, eventBus, paymentProviders...)
 	feedbackUC := feedbackUsecase.NewUseCase(feedbackRepo, txm)
+	documentUC := documentUsecase.NewUseCase(documentRepo, blobStorage, txm, notificationOutbox, driverRepo)
+
+	lockoutUC := lockoutUsecase.NewUseCase(lockoutRepo, notificationOutbox, lockoutCfg)
+
+	// Background jobs
+	// Outbox dispatch, and a daily purge of dispatched messages past retention.
+	go outboxUC.RunDispatcher(context.Background(), outboxInterval)
+	go outboxUC.RunCleanup(context.Background(), 24*time.Hour)
+	// Notification delivery with retries.
+	go notificationUC.RunDelivery(context.Background(), deliveryInterval)
+	// Daily driver document expiry alerts, 30 days ahead, repeated weekly per document.
+	go documentUC.RunExpiryAlerts(context.Background(), 24*time.Hour, 30*24*time.Hour, 7*24*time.Hour)
+	// Hourly purge of expired refresh tokens and revoked access tokens.
+	go sessionUC.RunCleanup(context.Background(), time.Hour)
+	// Hourly purge of login failure counts outside the throttling window.
+	go lockoutUC.RunCleanup(context.Background(), time.Hour)
+	// Webhook delivery with retries, and a daily purge of finished deliveries past retention.
+	go webhookUC.RunDelivery(context.Background(), webhookInterval)
+	go webhookUC.RunCleanup(context.Background(), 24*time.Hour)
 
 	// Set up Handlers
-	userHandler := handlers.NewUserHandler(orderService)
+	userHandler := handlers.NewUserHandler(orderService, sessionUC, lockoutUC, mfaUC)
 	orderHandler := handlers.NewOrderHandler(orderService)
 	driverHandler := handlers.NewDriverHandler(orderService)
 	deliveryHandler := handlers.NewDeliveryHandler(orderService)
-	paymentHandler := handlers.NewPaymentHandler(paymentUC)
+	paymentHandler := handlers.NewPaymentHandler(paymentUC, paymentCallbacks)
 	feedbackHandler := handlers.NewFeedbackHandler(feedbackUC)
 	notificationHandler := handlers.NewNotificationHandler(orderService)
 	inviteHandler := handlers.NewInviteHandler(inviteUC)
 	inventoryHandler := handlers.NewInventoryHandler(orderService)
 	storeHandler := handlers.NewStoreHandler(storeUC)
+	documentHandler := handlers.NewDocumentHandler(documentUC)
+	mfaHandler := handlers.NewMFAHandler(mfaUC)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyUC)
+	webhookHandler := handlers.NewWebhookHandler(webhookUC)
+	jwksHandler := handlers.NewJWKSHandler(jwtKeys)
+	smsReportHandler := handlers.NewSMSReportHandler(notificationUC, smsReports)
 
 	// Start server
 	r := router.NewRouter(
@@ -120,10 +494,20 @@ func main() {
 		publicApiBaseUrl,
 		inviteHandler,
 		storeHandler,
+		documentHandler,
+		mfaHandler,
+		apiKeyHandler,
+		webhookHandler,
+		jwksHandler,
+		smsReportHandler,
+		jwtKeys,
+		sessionUC,
+		apiKeyUC,
+		trustedProxies,
 	)
 
 	log.Println("Server starting at :8080")
 	if err := http.ListenAndServe("0.0.0.0:8080", r); err != nil {
-		log.Fatal("could not start server at: %v", err)
+		log.Fatalf("could not start server at: %v", err)
 	}
 }
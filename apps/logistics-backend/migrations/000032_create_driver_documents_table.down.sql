@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_driver_documents_expires_at;
+DROP INDEX IF EXISTS idx_driver_documents_driver_id;
+DROP TABLE IF EXISTS driver_documents;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- Create driver_documents table
+CREATE TABLE driver_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    driver_id UUID NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('licence', 'insurance', 'vehicle_inspection')),
+    number TEXT NOT NULL,
+    issued_at DATE NOT NULL,
+    expires_at DATE NOT NULL,
+    file_path TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'verified', 'rejected')),
+    verified_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    verified_at TIMESTAMPTZ,
+    last_alert_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    CONSTRAINT driver_documents_dates_check CHECK (expires_at > issued_at)
+);
+
+CREATE INDEX idx_driver_documents_driver_id ON driver_documents(driver_id);
+CREATE INDEX idx_driver_documents_expires_at ON driver_documents(expires_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_owner_id;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS owner_id;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- Link accounts (drivers, customers) to the store owner (admin) they belong to
+ALTER TABLE users
+ADD COLUMN owner_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_owner_id ON users(owner_id);
+
+-- Backfill customers from the admin of their earliest order
+UPDATE users u
+SET owner_id = o.admin_id
+FROM (
+    SELECT DISTINCT ON (user_id) user_id, admin_id
+    FROM orders
+    ORDER BY user_id, created_at ASC
+) o
+WHERE u.id = o.user_id AND u.owner_id IS NULL;
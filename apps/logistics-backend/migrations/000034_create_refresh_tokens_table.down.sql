@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS revoked_access_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS token_version;
\ No newline at end of file
@@ -0,0 +1,28 @@
+-- Bumped to invalidate every access token previously issued to a user
+ALTER TABLE users
+ADD COLUMN token_version INT NOT NULL DEFAULT 0;
+
+-- Rotating refresh tokens; only the SHA-256 hash of the token is stored
+CREATE TABLE refresh_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT UNIQUE NOT NULL,
+    family_id UUID NOT NULL, -- all tokens descended from one login
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    replaced_by UUID REFERENCES refresh_tokens(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+
+-- Access tokens revoked before expiry (logout), keyed by their jti claim
+CREATE TABLE revoked_access_tokens (
+    jti TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_revoked_access_tokens_expires_at ON revoked_access_tokens(expires_at);
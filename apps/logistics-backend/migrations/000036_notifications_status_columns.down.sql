@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_notifications_user_status;
+
+DELETE FROM notifications WHERE type = 'system';
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_type_check CHECK (type IN ('email', 'sms', 'push'));
+
+ALTER TABLE notifications
+DROP COLUMN IF EXISTS updated_at,
+DROP COLUMN IF EXISTS created_at,
+DROP COLUMN IF EXISTS status;
\ No newline at end of file
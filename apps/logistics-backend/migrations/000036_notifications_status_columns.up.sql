@@ -0,0 +1,12 @@
+-- Bring notifications in line with the model: delivery status, timestamps and the in-app 'system' type
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'sent', 'failed', 'read')),
+ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_type_check CHECK (type IN ('email', 'sms', 'push', 'system'));
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_status ON notifications (user_id, status);
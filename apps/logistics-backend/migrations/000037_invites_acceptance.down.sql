@@ -0,0 +1,15 @@
+ALTER TABLE notifications
+DROP COLUMN IF EXISTS recipient;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS invited_by;
+
+DROP INDEX IF EXISTS idx_invites_invited_by;
+
+ALTER TABLE invites
+DROP COLUMN IF EXISTS accepted_user_id,
+DROP COLUMN IF EXISTS accepted_at,
+ALTER COLUMN id DROP DEFAULT;
+
+-- stored hashes cannot be turned back into tokens; outstanding invites must be re-sent
+ALTER TABLE invites RENAME COLUMN token_hash TO token;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- Invite tokens are generated server-side and only their sha256 is stored
+ALTER TABLE invites RENAME COLUMN token TO token_hash;
+UPDATE invites SET token_hash = encode(sha256(token_hash::bytea), 'hex');
+
+ALTER TABLE invites
+ALTER COLUMN id SET DEFAULT gen_random_uuid(),
+ADD COLUMN accepted_at TIMESTAMP,
+ADD COLUMN accepted_user_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_invites_invited_by ON invites(invited_by);
+
+-- Who invited whom
+ALTER TABLE users
+ADD COLUMN invited_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+-- Emails to people without an account yet (invitees) are addressed explicitly
+ALTER TABLE notifications
+ADD COLUMN recipient TEXT;
@@ -0,0 +1,4 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS email_verified_at;
+
+DROP TABLE IF EXISTS email_verifications;
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- Single-use email verification tokens; only the SHA-256 hash of the token is stored
+CREATE TABLE email_verifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT UNIQUE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_email_verifications_user_id ON email_verifications(user_id);
+
+ALTER TABLE users
+ADD COLUMN email_verified_at TIMESTAMP WITH TIME ZONE;
+
+-- Accounts that are already active predate verification and count as verified
+UPDATE users SET email_verified_at = created_at WHERE status = 'active';
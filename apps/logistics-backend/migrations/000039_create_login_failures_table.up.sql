@@ -0,0 +1,11 @@
+-- Failed login tracking per account (email) and per client IP, used for progressive delays and lockouts
+CREATE TABLE login_failures (
+    scope TEXT NOT NULL CHECK (scope IN ('account', 'ip')),
+    key TEXT NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    locked_until TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_login_failures_last_failed_at ON login_failures(last_failed_at);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS mfa_challenges;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_totp;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS mfa_enabled;
\ No newline at end of file
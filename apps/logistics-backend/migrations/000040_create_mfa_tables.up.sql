@@ -0,0 +1,35 @@
+-- TOTP two-factor authentication
+ALTER TABLE users
+ADD COLUMN mfa_enabled BOOLEAN NOT NULL DEFAULT false;
+
+-- One authenticator per user; confirmed_at is set once a first code was verified
+CREATE TABLE user_totp (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    last_used_step BIGINT NOT NULL DEFAULT 0, -- rejects replay of an already accepted code
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+-- Single-use recovery codes; only the SHA-256 hash is stored
+CREATE TABLE mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    UNIQUE (user_id, code_hash)
+);
+
+-- Short-lived login challenges handed out after a correct password
+CREATE TABLE mfa_challenges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT UNIQUE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_mfa_challenges_expires_at ON mfa_challenges(expires_at);
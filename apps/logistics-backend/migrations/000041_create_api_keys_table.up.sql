@@ -0,0 +1,16 @@
+-- Store-owned API keys for machine-to-machine integrations; only the SHA-256 hash of the key is stored
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    store_id UUID NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    prefix TEXT UNIQUE NOT NULL, -- public part of the key, shown in listings and logs
+    key_hash TEXT UNIQUE NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_api_keys_owner_id ON api_keys(owner_id);
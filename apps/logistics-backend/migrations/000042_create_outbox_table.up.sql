@@ -0,0 +1,15 @@
+-- Transactional outbox: messages are written in the same transaction as the business change
+-- and handed to their handler by the dispatcher after commit
+CREATE TABLE outbox_messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    topic TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'dispatched', 'dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(), -- also pushed forward while a dispatcher holds the message
+    last_error TEXT,
+    dispatched_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_outbox_messages_due ON outbox_messages(next_attempt_at) WHERE status = 'pending';
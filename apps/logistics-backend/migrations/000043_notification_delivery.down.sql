@@ -0,0 +1,13 @@
+ALTER TABLE users DROP COLUMN IF EXISTS device_token;
+
+DROP INDEX IF EXISTS idx_notifications_due;
+
+UPDATE notifications SET status = 'failed' WHERE status = 'dead';
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_status_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_status_check CHECK (status IN ('pending', 'sent', 'failed', 'read'));
+
+ALTER TABLE notifications
+DROP COLUMN IF EXISTS last_error,
+DROP COLUMN IF EXISTS next_attempt_at,
+DROP COLUMN IF EXISTS attempts;
\ No newline at end of file
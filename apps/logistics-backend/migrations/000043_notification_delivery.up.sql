@@ -0,0 +1,16 @@
+-- Delivery bookkeeping for the notification worker: failed sends are retried with backoff
+-- until max attempts, then parked as 'dead'
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+ADD COLUMN IF NOT EXISTS last_error TEXT;
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_status_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_status_check CHECK (status IN ('pending', 'sent', 'failed', 'dead', 'read'));
+
+CREATE INDEX IF NOT EXISTS idx_notifications_due ON notifications (next_attempt_at) WHERE status IN ('pending', 'failed');
+
+-- Push notification target for the user's current device
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS device_token TEXT;
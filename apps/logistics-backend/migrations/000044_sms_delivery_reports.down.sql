@@ -0,0 +1,10 @@
+UPDATE notifications SET status = 'sent' WHERE status = 'delivered';
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_status_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_status_check CHECK (status IN ('pending', 'sent', 'failed', 'dead', 'read'));
+
+DROP INDEX IF EXISTS idx_notifications_provider_ref;
+
+ALTER TABLE notifications
+DROP COLUMN IF EXISTS provider_ref;
\ No newline at end of file
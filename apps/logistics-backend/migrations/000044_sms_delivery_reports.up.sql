@@ -0,0 +1,18 @@
+-- Gateway message IDs let delivery receipts find their notification; 'delivered' is confirmed by the handset's network
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS provider_ref TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_provider_ref ON notifications (provider_ref) WHERE provider_ref IS NOT NULL;
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_status_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_status_check CHECK (status IN ('pending', 'sent', 'delivered', 'failed', 'dead', 'read'));
+
+-- Store phone numbers in E.164; national Kenyan numbers (07..., 01...) and bare 254... get the + prefix
+UPDATE users
+SET phone = '+254' || substr(regexp_replace(phone, '[^0-9]', '', 'g'), 2)
+WHERE regexp_replace(phone, '[^0-9]', '', 'g') ~ '^0[17][0-9]{8}$';
+
+UPDATE users
+SET phone = '+' || regexp_replace(phone, '[^0-9]', '', 'g')
+WHERE phone !~ '^\+' AND regexp_replace(phone, '[^0-9]', '', 'g') ~ '^254[17][0-9]{8}$';
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS notification_templates;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS locale;
+
+ALTER TABLE notifications
+DROP COLUMN IF EXISTS data,
+DROP COLUMN IF EXISTS event;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- Notifications keep the event and its variables so each channel renders its own variant in the user's locale
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS event TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS data JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT 'en' CHECK (locale IN ('en', 'sw'));
+
+-- Admin edits to the built-in templates, one row per event, locale and channel
+CREATE TABLE IF NOT EXISTS notification_templates (
+    event TEXT NOT NULL,
+    locale TEXT NOT NULL CHECK (locale IN ('en', 'sw')),
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'sms', 'push', 'system')),
+    subject TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    html TEXT NOT NULL DEFAULT '',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (event, locale, channel)
+);
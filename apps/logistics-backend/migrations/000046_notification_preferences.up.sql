@@ -0,0 +1,19 @@
+-- Channels a user turned on or off per event; event '*' applies to every event without its own row
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event TEXT NOT NULL,
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'sms', 'push', 'system')),
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, event, channel)
+);
+
+-- Email, SMS and push are held back until end_time; the window may cross midnight
+CREATE TABLE IF NOT EXISTS notification_quiet_hours (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    timezone TEXT NOT NULL DEFAULT 'Africa/Nairobi',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (start_time <> end_time)
+);
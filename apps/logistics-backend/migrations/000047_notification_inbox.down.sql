@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_notifications_unread;
+DROP INDEX IF EXISTS idx_notifications_inbox;
+
+UPDATE notifications SET status = 'read' WHERE read_at IS NOT NULL AND status IN ('sent', 'delivered');
+
+ALTER TABLE notifications
+DROP COLUMN IF EXISTS archived_at,
+DROP COLUMN IF EXISTS read_at;
\ No newline at end of file
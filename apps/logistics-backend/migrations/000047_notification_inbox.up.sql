@@ -0,0 +1,10 @@
+-- Reads and archiving are the user's inbox state, kept apart from the delivery status
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS read_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+-- marking a notification read used to overwrite its delivery status
+UPDATE notifications SET read_at = updated_at, status = 'sent' WHERE status = 'read';
+
+CREATE INDEX IF NOT EXISTS idx_notifications_inbox ON notifications (user_id, created_at DESC, id DESC) WHERE recipient IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (user_id) WHERE recipient IS NULL AND read_at IS NULL AND archived_at IS NULL;
@@ -0,0 +1,40 @@
+-- Store endpoints that receive signed order and payment events; disabled after too many failures in a row
+CREATE TABLE webhook_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    store_id UUID NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    secret TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    disabled_at TIMESTAMP WITH TIME ZONE,
+    disabled_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_webhook_subscriptions_owner_id ON webhook_subscriptions(owner_id);
+CREATE INDEX idx_webhook_subscriptions_store_id ON webhook_subscriptions(store_id) WHERE active;
+
+-- One row per event per subscription; the row keeps the outcome of its latest attempt
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL, -- shared by retries and redeliveries of the same event
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed', 'dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    response_code INT,
+    response_body TEXT,
+    last_error TEXT,
+    duration_ms INT,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status IN ('pending', 'failed');
+CREATE INDEX idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at DESC);
@@ -0,0 +1,26 @@
+DROP TABLE IF EXISTS payment_refunds;
+DROP INDEX IF EXISTS idx_payments_provider_ref;
+
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_amounts_check;
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_status_check;
+
+UPDATE payments SET status = 'pending' WHERE status = 'authorized';
+UPDATE payments SET status = 'completed' WHERE status IN ('captured', 'partially_refunded', 'refunded');
+UPDATE payments SET paid_at = COALESCE(paid_at, failed_at, created_at);
+
+ALTER TABLE payments ALTER COLUMN paid_at SET DEFAULT now();
+
+ALTER TABLE payments
+DROP COLUMN captured_amount,
+DROP COLUMN refunded_amount,
+DROP COLUMN provider_ref,
+DROP COLUMN failure_reason,
+DROP COLUMN authorized_at,
+DROP COLUMN captured_at,
+DROP COLUMN failed_at,
+DROP COLUMN created_at,
+DROP COLUMN updated_at;
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_status_check
+CHECK (status IN ('pending', 'completed', 'failed'));
\ No newline at end of file
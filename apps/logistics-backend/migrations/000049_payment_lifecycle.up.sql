@@ -0,0 +1,43 @@
+-- Payments move through authorize, capture, complete, fail and refund; refunds never exceed what was captured
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_status_check;
+
+ALTER TABLE payments
+ADD COLUMN captured_amount BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN refunded_amount BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN provider_ref TEXT,
+ADD COLUMN failure_reason TEXT,
+ADD COLUMN authorized_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN captured_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN failed_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT now();
+
+-- paid_at was stamped on insert; it now records when the payment completed
+ALTER TABLE payments ALTER COLUMN paid_at DROP DEFAULT;
+
+UPDATE payments SET created_at = COALESCE(paid_at, now()), updated_at = COALESCE(paid_at, now());
+UPDATE payments SET captured_amount = amount, captured_at = paid_at WHERE status = 'completed';
+UPDATE payments SET failed_at = paid_at, paid_at = NULL WHERE status = 'failed';
+UPDATE payments SET paid_at = NULL WHERE status = 'pending';
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_status_check
+CHECK (status IN ('pending', 'authorized', 'captured', 'completed', 'failed', 'partially_refunded', 'refunded')),
+ADD CONSTRAINT payments_amounts_check
+CHECK (captured_amount BETWEEN 0 AND amount AND refunded_amount BETWEEN 0 AND captured_amount);
+
+-- Gateway callbacks find their payment by the provider's reference
+CREATE UNIQUE INDEX idx_payments_provider_ref ON payments(method, provider_ref) WHERE provider_ref IS NOT NULL;
+
+CREATE TABLE payment_refunds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    reason TEXT,
+    provider_ref TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_payment_refunds_payment_id ON payment_refunds(payment_id);
@@ -0,0 +1 @@
+ALTER TABLE payments DROP COLUMN IF EXISTS receipt;
\ No newline at end of file
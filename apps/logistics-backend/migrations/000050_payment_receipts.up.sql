@@ -0,0 +1,2 @@
+-- The receipt number the gateway gave the customer, e.g. the M-Pesa confirmation code
+ALTER TABLE payments ADD COLUMN receipt TEXT;
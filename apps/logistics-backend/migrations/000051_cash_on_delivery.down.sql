@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS cash_remittances;
+DROP TABLE IF EXISTS cash_collections;
\ No newline at end of file
@@ -0,0 +1,33 @@
+-- Cash drivers collect at delivery, and the cash they hand in to store owners
+CREATE TABLE cash_collections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_id UUID NOT NULL UNIQUE REFERENCES payments(id),
+    order_id UUID NOT NULL REFERENCES orders(id),
+    delivery_id UUID NOT NULL REFERENCES deliveries(id),
+    driver_id UUID NOT NULL REFERENCES drivers(id),
+    admin_id UUID NOT NULL REFERENCES users(id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    collected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_cash_collections_driver ON cash_collections(driver_id, collected_at);
+CREATE INDEX idx_cash_collections_admin ON cash_collections(admin_id, collected_at);
+
+CREATE TABLE cash_remittances (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    driver_id UUID NOT NULL REFERENCES drivers(id),
+    admin_id UUID NOT NULL REFERENCES users(id),
+    currency VARCHAR(3) NOT NULL,
+    declared_amount BIGINT NOT NULL CHECK (declared_amount > 0),
+    received_amount BIGINT CHECK (received_amount >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'confirmed')),
+    note TEXT,
+    declared_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    confirmed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    CHECK ((status = 'confirmed') = (received_amount IS NOT NULL))
+);
+
+CREATE INDEX idx_cash_remittances_driver ON cash_remittances(driver_id, declared_at);
+CREATE INDEX idx_cash_remittances_admin ON cash_remittances(admin_id, status, declared_at);
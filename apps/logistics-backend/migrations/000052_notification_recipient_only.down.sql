@@ -0,0 +1,5 @@
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_addressee;
+
+DELETE FROM notifications WHERE user_id IS NULL;
+
+ALTER TABLE notifications ALTER COLUMN user_id SET NOT NULL;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- Emails to people without an account yet (invitees) are stored under their address alone
+ALTER TABLE notifications ALTER COLUMN user_id DROP NOT NULL;
+
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_addressee CHECK (user_id IS NOT NULL OR recipient IS NOT NULL);
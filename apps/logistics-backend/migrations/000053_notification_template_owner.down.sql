@@ -0,0 +1,11 @@
+ALTER TABLE notification_templates DROP CONSTRAINT IF EXISTS notification_templates_pkey;
+
+-- keep the latest override for each template
+DELETE FROM notification_templates a
+USING notification_templates b
+WHERE a.event = b.event AND a.locale = b.locale AND a.channel = b.channel
+  AND (a.updated_at, a.owner_id) < (b.updated_at, b.owner_id);
+
+ALTER TABLE notification_templates ADD PRIMARY KEY (event, locale, channel);
+
+ALTER TABLE notification_templates DROP COLUMN IF EXISTS owner_id;
\ No newline at end of file
@@ -0,0 +1,16 @@
+-- Template overrides belong to the store owner who saved them and only apply to that owner's users
+ALTER TABLE notification_templates
+ADD COLUMN IF NOT EXISTS owner_id UUID REFERENCES users(id) ON DELETE CASCADE;
+
+UPDATE notification_templates t
+SET owner_id = u.id
+FROM users u
+WHERE u.id = t.updated_by AND u.role = 'admin';
+
+-- overrides nobody can be held to would otherwise apply to every tenant
+DELETE FROM notification_templates WHERE owner_id IS NULL;
+
+ALTER TABLE notification_templates ALTER COLUMN owner_id SET NOT NULL;
+
+ALTER TABLE notification_templates DROP CONSTRAINT IF EXISTS notification_templates_pkey;
+ALTER TABLE notification_templates ADD PRIMARY KEY (owner_id, event, locale, channel);
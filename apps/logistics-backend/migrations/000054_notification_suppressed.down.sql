@@ -0,0 +1,5 @@
+UPDATE notifications SET status = 'dead' WHERE status = 'suppressed';
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_status_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_status_check CHECK (status IN ('pending', 'sent', 'delivered', 'failed', 'dead', 'read'));
\ No newline at end of file
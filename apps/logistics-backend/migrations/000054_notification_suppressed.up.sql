@@ -0,0 +1,6 @@
+-- Notifications whose channel the recipient turned off before they went out are 'suppressed', not failed
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_status_check;
+ALTER TABLE notifications
+ADD CONSTRAINT notifications_status_check CHECK (status IN ('pending', 'sent', 'delivered', 'failed', 'dead', 'suppressed', 'read'));
+
+UPDATE notifications SET status = 'suppressed' WHERE status = 'dead' AND last_error = 'recipient turned this channel off';
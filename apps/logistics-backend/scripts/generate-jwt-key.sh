@@ -0,0 +1,46 @@
+#!/bin/bash
+# Creates a JWT signing key in ./keys. A local setup needs one before the first start:
+#
+#   ./scripts/generate-jwt-key.sh local-1
+#
+# Usage: ./scripts/generate-jwt-key.sh <kid> [rsa|ed25519]
+#
+# Kong gets the public keys from the backend, which renders kong/kong.yml with one jwt_secrets
+# entry per key on startup (KONG_CONFIG_OUT); there is nothing to copy by hand.
+#
+# Rotation: generate a new key, set JWT_ACTIVE_KEY_ID to the new kid and restart the backend, then
+# Kong. Keep the old key until ACCESS_TOKEN_TTL has passed, then delete it (or keep only its public
+# half as <kid>.pub.pem) and restart both again.
+#
+# Keys are never committed (keys/.gitignore).
+
+set -euo pipefail
+
+KEYS_DIR="./keys"
+KID="${1:?usage: $0 <kid> [rsa|ed25519]}"
+TYPE="${2:-rsa}"
+
+mkdir -p "$KEYS_DIR"
+if [ -e "$KEYS_DIR/$KID.pem" ]; then
+  echo "key $KID already exists" >&2
+  exit 1
+fi
+
+case "$TYPE" in
+  rsa)
+    openssl genpkey -algorithm RSA -pkeyopt rsa_keygen_bits:2048 -out "$KEYS_DIR/$KID.pem"
+    ALG="RS256"
+    ;;
+  ed25519)
+    # Kong 3.5's jwt plugin only verifies RS/ES/PS algorithms; use EdDSA keys for services reading the JWKS
+    openssl genpkey -algorithm ED25519 -out "$KEYS_DIR/$KID.pem"
+    ALG="EdDSA"
+    ;;
+  *)
+    echo "unknown key type $TYPE (rsa or ed25519)" >&2
+    exit 1
+    ;;
+esac
+chmod 600 "$KEYS_DIR/$KID.pem"
+
+echo "Created $KEYS_DIR/$KID.pem ($ALG). Set JWT_ACTIVE_KEY_ID=$KID to sign with it."